@@ -5,6 +5,7 @@ import (
 	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/db"
 	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/jobs"
 	"github.com/skinnykaen/rpa_clone/internal/server"
 	"github.com/skinnykaen/rpa_clone/internal/services"
 	resolvers "github.com/skinnykaen/rpa_clone/internal/transports/graphql"
@@ -37,8 +38,8 @@ func InvokeWith(m consts.Mode, options ...fx.Option) *fx.App {
 func RunApp() {
 	if len(os.Args) == 2 && (consts.Mode(os.Args[1]) == consts.Development ||
 		consts.Mode(os.Args[1]) == consts.Production) {
-		InvokeWith(consts.Mode(os.Args[1]), fx.Invoke(server.NewServer)).Run()
+		InvokeWith(consts.Mode(os.Args[1]), fx.Invoke(server.NewServer), fx.Invoke(jobs.StartJobs)).Run()
 	} else {
-		InvokeWith(consts.Development, fx.Invoke(server.NewServer)).Run()
+		InvokeWith(consts.Development, fx.Invoke(server.NewServer), fx.Invoke(jobs.StartJobs)).Run()
 	}
 }
@@ -8,10 +8,25 @@ const (
 )
 
 const (
-	KeyId   = "keyId"
-	KeyRole = "keyRole"
+	KeyId        = "keyId"
+	KeyRole      = "keyRole"
+	KeyIp        = "keyIp"
+	KeyUserAgent = "keyUserAgent"
+	// KeyResponseWriter and KeyRequest let resolvers read and set cookies
+	KeyResponseWriter = "keyResponseWriter"
+	KeyRequest        = "keyRequest"
 )
 
+// OidcBindingCookie ties the sso sign in session to the browser that started it
+const OidcBindingCookie = "oidc_binding"
+
+// LtiStateCookie ties the LTI launch to the browser that received the login initiation
+const LtiStateCookie = "lti_state"
+
+// TokenPurposeTwoFactor marks the short-lived token returned by SignIn
+// that can only be exchanged for tokens with a 2FA code
+const TokenPurposeTwoFactor = "two_factor"
+
 const EmptyProjectJson = "{\"targets\":[{\"isStage\":true,\"name\":\"Stage\",\"variables\":{\"`jEk@4|i[#Fk?(8x)AV." +
 	"-my variable\":[\"my variable\",0]},\"lists\":{},\"broadcasts\":{},\"blocks\":{},\"currentCostume\":0,\"costum" +
 	"es\":[{\"assetId\":\"cd21514d0531fdffb22204e0ec5ed84a\",\"name\":\"backdrop1\",\"md5ext\":\"cd21514d0531fdffb2" +
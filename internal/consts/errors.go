@@ -2,24 +2,138 @@ package consts
 
 // http code 400
 const (
-	ErrEmailAlreadyInUse        = "email already in use"
-	ErrAtoi                     = "string to int error"
-	ErrIncorrectPasswordOrEmail = "incorrect password or email"
-	ErrNotFoundInDB             = "not found"
-	ErrShortPassword            = "please input password, at least 6 symbols"
+	ErrEmailAlreadyInUse              = "email already in use"
+	ErrNicknameAlreadyInUse           = "nickname already in use"
+	ErrAtoi                           = "string to int error"
+	ErrIncorrectTimestamp             = "timestamp must have the format YYYY-MM-DD hh:mm:ss"
+	ErrIncorrectDate                  = "date must have the format YYYY-MM-DD"
+	ErrIncorrectUserProfile           = "birth date must be in the past and grade between 1 and 11"
+	ErrIncorrectPasswordOrEmail       = "incorrect password or email"
+	ErrNotFoundInDB                   = "not found"
+	ErrShortPassword                  = "please input password, at least %d symbols"
+	ErrPasswordNoUppercase            = "password must contain an uppercase letter"
+	ErrPasswordNoLowercase            = "password must contain a lowercase letter"
+	ErrPasswordNoDigit                = "password must contain a digit"
+	ErrPasswordNoSpecial              = "password must contain a special character"
+	ErrCommonPassword                 = "password is too common, please choose another one"
+	ErrIncorrectPasswordPolicy        = "minimum password length must be positive"
+	ErrIncorrectActivationLink        = "activation link is incorrect or already used"
+	ErrActivationLinkExpired          = "activation link has expired. please request a new one"
+	ErrUnknownOidcProvider            = "unknown sso provider"
+	ErrIncorrectOidcState             = "sso sign in session is incorrect or expired"
+	ErrEdxBadRequest                  = "edx rejected the request"
+	ErrUnknownLtiPlatform             = "unknown lti platform"
+	ErrIncorrectLtiLaunch             = "lti launch is incorrect or expired"
+	ErrIncorrectLtiScore              = "score must be between 0 and the positive maximum score"
+	ErrLtiUserNotLinked               = "the user has never opened the tool from the lms"
+	ErrLtiScoreUnavailable            = "the lms does not accept scores for the resource link"
+	ErrIncorrectParentRel             = "a relationship is possible only between a parent and a student"
+	ErrParentRelAlreadyExists         = "the parent relationship already exists"
+	ErrIncorrectParentLinkCode        = "link code is incorrect, expired or already used"
+	ErrParentLinkCodeUnavailable      = "link code is already redeemed, rejected or revoked"
+	ErrIncorrectGroupMember           = "groups consist of teachers and students of the unit of the group"
+	ErrIncorrectAssignment            = "deadline must be in the future, maximum grade positive and late penalty between 0 and 100"
+	ErrIncorrectAssignmentTemplate    = "template must be a not banned project page of the teacher"
+	ErrIncorrectAssignmentGrade       = "grade must be between 0 and the maximum grade"
+	ErrAssignmentNotSubmitted         = "the assignment is not submitted yet"
+	ErrAssignmentAlreadyReviewed      = "the assignment is already reviewed"
+	ErrAssignmentAlreadySubmitted     = "the assignment is already submitted"
+	ErrAssignmentProjectReadOnly      = "the assignment is submitted or its deadline has passed, the project cannot be changed"
+	ErrAssignmentDeadlinePassed       = "the deadline has passed and late submissions are not accepted"
+	ErrProjectPageInAssignment        = "the project page is a submission of an assignment and cannot be deleted"
+	ErrIncorrectProjectTemplate       = "the project page is not a template"
+	ErrIncorrectTemplateLock          = "sprite lock needs a sprite of the template and script lock the top block of its script"
+	ErrTemplateLockViolated           = "locked sprite or script of the template cannot be deleted: %s"
+	ErrEmptyUserImport                = "the file has no participants"
+	ErrTooManyImportRows              = "no more than %d participants can be imported at once"
+	ErrIncorrectImportFile            = "the file must be csv or xlsx"
+	ErrIncorrectImportHeader          = "the first row must name the columns: name, class, unit and optional email"
+	ErrIncorrectImportName            = "name must contain the last name and the first name"
+	ErrIncorrectImportEmail           = "email is incorrect"
+	ErrImportUnitNotFound             = "the unit is not found or not available"
+	ErrIncorrectCertificateTemplate   = "certificate template needs a name, an uploaded background and positive sizes of the page, the QR code and the text"
+	ErrIncorrectCertificateBackground = "background must be a png or jpeg image"
+	ErrIncorrectCertificate           = "certificate needs a contest and a user available to the client"
+	ErrCertificateRevoked             = "the certificate is revoked"
+	ErrIncorrectContest               = "title is required, end must be after start, ranges of ages, grades and team sizes correct and capacity positive"
+	ErrIncorrectContestParticipant    = "only students participate in contests"
+	ErrContestRegistrationClosed      = "registration is closed after the start of the contest"
+	ErrCourseEnrollmentClosed         = "enrollment on the course is closed"
+	ErrContestAlreadyRegistered       = "the user is already registered for the contest"
+	ErrContestBirthDateRequired       = "birth date in the profile is required to register for the contest"
+	ErrContestGradeRequired           = "grade in the profile is required to register for the contest"
+	ErrContestAgeNotEligible          = "the age does not meet the requirements of the contest"
+	ErrContestGradeNotEligible        = "the grade does not meet the requirements of the contest"
+	ErrContestConsentNotRequired      = "the registration does not need a consent or already has it"
+	ErrContestNotTeamBased            = "the contest is not a team contest"
+	ErrContestFinished                = "the contest is finished"
+	ErrContestNotRunning              = "projects are submitted while the contest is running"
+	ErrContestRegistrationInTeam      = "leave the team before canceling the registration"
+	ErrContestNotStarted              = "the contest has not started yet"
+	ErrIncorrectContestTask           = "task needs a title and a statement and position must not be negative"
+	ErrIncorrectClarification         = "question is required and the task must be of the contest"
+	ErrIncorrectClarificationAnswer   = "answer is required"
+	ErrClarificationsClosed           = "questions are asked while the contest is running"
+	ErrIncorrectAnnouncement          = "announcement text is required"
+	ErrIncorrectTeam                  = "team name is required"
+	ErrTeamRegistrationRequired       = "team members must have a confirmed registration for the contest"
+	ErrTeamMemberAlreadyInTeam        = "the user is already a member of a team of the contest"
+	ErrTeamFull                       = "the team has reached the maximum size of the contest"
+	ErrTeamSizeBelowTeams             = "the maximum team size is smaller than existing teams of the contest"
+	ErrTeamTooSmall                   = "the team has fewer members than the contest requires"
+	ErrTeamAlreadySubmitted           = "the team project is already submitted"
+	ErrTeamCaptainCannotLeave         = "the captain cannot leave the team, delete the team instead"
+	ErrIncorrectTeamResult            = "place must be positive and score not negative"
+	ErrProjectPageInTeam              = "the project page is the project of a contest team and cannot be deleted"
+	ErrUnitRequired                   = "unit admins must choose one of their units"
+	ErrIncorrectTwoFactorCode         = "incorrect two-factor authentication code"
+	ErrTwoFactorAlreadyEnabled        = "two-factor authentication is already enabled"
+	ErrTwoFactorNotEnabled            = "two-factor authentication is not enabled"
 )
 
 // http code 401
 const (
-	ErrTokenExpired     = "token expired"
-	ErrNotStandardToken = "token claims are not of type *StandardClaims"
+	ErrTokenExpired      = "token expired"
+	ErrNotStandardToken  = "token claims are not of type *StandardClaims"
+	ErrUnknownSigningKey = "unknown or expired signing key"
 )
 
 // http code 403
 const (
-	ErrUserIsNotActive     = "user is not active. please check your email"
-	ErrProjectPageIsBanned = "the projectPage is banned. no access"
-	ErrAccessDenied        = "access denied"
+	ErrUserIsNotActive        = "user is not active. please check your email"
+	ErrProjectPageIsBanned    = "the projectPage is banned. no access"
+	ErrAccessDenied           = "access denied"
+	ErrTwoFactorRequired      = "two-factor authentication is required for the role"
+	ErrOidcEmailNotVerified   = "email is not verified by the sso provider"
+	ErrOidcSignUpDisabled     = "sign up through the sso provider is disabled"
+	ErrOidcAdminLinkDenied    = "accounts of admins are not linked to sso providers by email"
+	ErrLtiEmailRequired       = "the lms did not share the email of the user"
+	ErrContestInvitationOnly  = "the contest is by invitation only"
+	ErrCourseInvitationOnly   = "the course is by invitation only"
+	ErrContestParticipantOnly = "only confirmed participants of the contest have access"
+)
+
+// http code 404
+const (
+	ErrNotFoundInEdx = "not found in edx"
+)
+
+// http code 409
+const (
+	ErrEdxEmailTaken = "the email belongs to another edx account"
+)
+
+// http code 429
+const (
+	ErrTooManyAttempts          = "too many sign in attempts. please try again later"
+	ErrAccountLocked            = "sign in is temporarily locked. please check your email"
+	ErrActivationResendTooOften = "activation email was sent recently. please try again later"
+)
+
+// http code 502
+const (
+	ErrEdxUnavailable = "edx request failed"
+	ErrLtiUnavailable = "lms request failed"
 )
 
 // ErrActivationLinkUnavailable have http code 503
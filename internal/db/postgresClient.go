@@ -76,6 +76,42 @@ func (c *PostgresClient) Migrate() (err error) {
 		&models.ProjectCore{},
 		&models.ParentRelCore{},
 		&models.SettingsCore{},
+		&models.RoleSettingsCore{},
+		&models.RecoveryCodeCore{},
+		&models.LoginAttemptCore{},
+		&models.LockoutEventCore{},
+		&models.RegistrationRequestCore{},
+		&models.OidcStateCore{},
+		&models.UserIdentityCore{},
+		&models.CohortCore{},
+		&models.CohortMismatchCore{},
+		&models.LtiLaunchCore{},
+		&models.LtiGradeLinkCore{},
+		&models.ParentLinkCodeCore{},
+		&models.ActivityCore{},
+		&models.UnitCore{},
+		&models.UnitMemberCore{},
+		&models.GroupCore{},
+		&models.GroupTeacherCore{},
+		&models.GroupStudentCore{},
+		&models.AssignmentCore{},
+		&models.AssignmentSubmissionCore{},
+		&models.TemplateLockCore{},
+		&models.CertificateTemplateCore{},
+		&models.CertificateFieldCore{},
+		&models.CertificateCore{},
+		&models.ContestCore{},
+		&models.ContestAgeCategoryCore{},
+		&models.ContestTaskCore{},
+		&models.ContestInvitationCore{},
+		&models.ContestRegistrationCore{},
+		&models.TeamCore{},
+		&models.TeamMemberCore{},
+		&models.TeamInvitationCore{},
+		&models.ClarificationCore{},
+		&models.AnnouncementCore{},
+		&models.NotificationCore{},
+		&models.NotificationPreferenceCore{},
 	)
 	if err != nil {
 		return err
@@ -0,0 +1,38 @@
+package gateways
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+)
+
+type ActivityGateway interface {
+	CreateActivity(activity models.ActivityCore) error
+	GetLatestActivity(userId uint, limit int) (activity []models.ActivityCore, err error)
+}
+
+type ActivityGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (a ActivityGatewayImpl) CreateActivity(activity models.ActivityCore) error {
+	if err := a.postgresClient.Db.Create(&activity).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (a ActivityGatewayImpl) GetLatestActivity(userId uint, limit int) (activity []models.ActivityCore, err error) {
+	if err := a.postgresClient.Db.Where("user_id = ?", userId).Order("created_at desc, id desc").
+		Limit(limit).Find(&activity).Error; err != nil {
+		return []models.ActivityCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return activity, nil
+}
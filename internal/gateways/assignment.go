@@ -0,0 +1,313 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+type AssignmentGateway interface {
+	CreateAssignment(assignment models.AssignmentCore, studentIds []uint, template models.ProjectPageCore, lockedBlocks string) (
+		models.AssignmentCore, error)
+	UpdateAssignment(assignment models.AssignmentCore) (models.AssignmentCore, error)
+	DeleteAssignment(id uint) error
+	GetAssignmentById(id uint) (models.AssignmentCore, error)
+	GetAssignmentsByGroup(groupId uint, offset, limit int) (assignments []models.AssignmentCore, countRows uint, err error)
+	CreateSubmission(assignment models.AssignmentCore, studentId uint, template models.ProjectPageCore, lockedBlocks string) (
+		models.AssignmentSubmissionCore, error)
+	GetSubmissionById(id uint) (models.AssignmentSubmissionCore, error)
+	GetSubmission(assignmentId, studentId uint) (models.AssignmentSubmissionCore, error)
+	GetSubmissionsByAssignment(assignmentId uint, offset, limit int) (submissions []models.AssignmentSubmissionCore, countRows uint, err error)
+	GetSubmissionsByStudent(studentId uint, offset, limit int) (submissions []models.AssignmentSubmissionCore, countRows uint, err error)
+	UpdateSubmission(submission models.AssignmentSubmissionCore) (models.AssignmentSubmissionCore, error)
+	StartSubmissionsByProject(projectId uint) error
+	IsSubmissionProjectPage(projectPageId uint) (bool, error)
+	IsReadOnlySubmissionProject(projectId uint) (bool, error)
+}
+
+type AssignmentGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+// CreateAssignment creates the assignment with personal copies of the template for the students,
+// lockedBlocks are locks of the template saved in the copies
+func (a AssignmentGatewayImpl) CreateAssignment(assignment models.AssignmentCore, studentIds []uint, template models.ProjectPageCore,
+	lockedBlocks string) (
+	models.AssignmentCore, error) {
+	if err := a.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&assignment).Error; err != nil {
+			return err
+		}
+		for _, studentId := range studentIds {
+			if _, err := createSubmission(tx, assignment, studentId, template, lockedBlocks); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return models.AssignmentCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return assignment, nil
+}
+
+func (a AssignmentGatewayImpl) UpdateAssignment(assignment models.AssignmentCore) (models.AssignmentCore, error) {
+	if err := a.postgresClient.Db.Model(&assignment).Clauses(clause.Returning{}).
+		Where("id = ?", assignment.ID).
+		Updates(map[string]interface{}{
+			"title":        assignment.Title,
+			"instruction":  assignment.Instruction,
+			"deadline":     assignment.Deadline,
+			"late_policy":  assignment.LatePolicy,
+			"late_penalty": assignment.LatePenalty,
+			"max_grade":    assignment.MaxGrade,
+		}).Error; err != nil {
+		return models.AssignmentCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return assignment, nil
+}
+
+// DeleteAssignment deletes the assignment, personal copies stay with the students
+func (a AssignmentGatewayImpl) DeleteAssignment(id uint) error {
+	if err := a.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("assignment_id = ?", id).Delete(&models.AssignmentSubmissionCore{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.AssignmentCore{}, id).Error
+	}); err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (a AssignmentGatewayImpl) GetAssignmentById(id uint) (assignment models.AssignmentCore, err error) {
+	if err := a.postgresClient.Db.First(&assignment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.AssignmentCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.AssignmentCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return assignment, nil
+}
+
+func (a AssignmentGatewayImpl) GetAssignmentsByGroup(groupId uint, offset, limit int) (
+	assignments []models.AssignmentCore, countRows uint, err error) {
+	var count int64
+	if err := a.postgresClient.Db.Model(&models.AssignmentCore{}).Where("group_id = ?", groupId).
+		Count(&count).Error; err != nil {
+		return []models.AssignmentCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := a.postgresClient.Db.Where("group_id = ?", groupId).Order("deadline desc").
+		Limit(limit).Offset(offset).Find(&assignments).Error; err != nil {
+		return []models.AssignmentCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return assignments, uint(count), nil
+}
+
+// CreateSubmission creates the personal copy for the student joined the group after the assignment was given
+func (a AssignmentGatewayImpl) CreateSubmission(assignment models.AssignmentCore, studentId uint, template models.ProjectPageCore,
+	lockedBlocks string) (
+	submission models.AssignmentSubmissionCore, err error) {
+	if err := a.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		submission, err = createSubmission(tx, assignment, studentId, template, lockedBlocks)
+		return err
+	}); err != nil {
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return a.GetSubmissionById(submission.ID)
+}
+
+func (a AssignmentGatewayImpl) GetSubmissionById(id uint) (submission models.AssignmentSubmissionCore, err error) {
+	if err := a.postgresClient.Db.Preload("Assignment").Preload("Student").First(&submission, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.AssignmentSubmissionCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return submission, nil
+}
+
+func (a AssignmentGatewayImpl) GetSubmission(assignmentId, studentId uint) (submission models.AssignmentSubmissionCore, err error) {
+	if err := a.postgresClient.Db.Preload("Assignment").Preload("Student").
+		Where("assignment_id = ? AND student_id = ?", assignmentId, studentId).
+		Take(&submission).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.AssignmentSubmissionCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return submission, nil
+}
+
+func (a AssignmentGatewayImpl) GetSubmissionsByAssignment(assignmentId uint, offset, limit int) (
+	submissions []models.AssignmentSubmissionCore, countRows uint, err error) {
+	return a.getSubmissions(func(db *gorm.DB) *gorm.DB {
+		return db.Where("assignment_id = ?", assignmentId)
+	}, "id", offset, limit)
+}
+
+func (a AssignmentGatewayImpl) GetSubmissionsByStudent(studentId uint, offset, limit int) (
+	submissions []models.AssignmentSubmissionCore, countRows uint, err error) {
+	return a.getSubmissions(func(db *gorm.DB) *gorm.DB {
+		return db.Where("student_id = ?", studentId)
+	}, "updated_at desc", offset, limit)
+}
+
+// getSubmissions returns a page of submissions of existing assignments matching the filter
+func (a AssignmentGatewayImpl) getSubmissions(filter func(db *gorm.DB) *gorm.DB, order string, offset, limit int) (
+	submissions []models.AssignmentSubmissionCore, countRows uint, err error) {
+	var count int64
+	assignments := a.postgresClient.Db.Model(&models.AssignmentCore{}).Select("id")
+	if err := a.postgresClient.Db.Model(&models.AssignmentSubmissionCore{}).Scopes(filter).
+		Where("assignment_id IN (?)", assignments).Count(&count).Error; err != nil {
+		return []models.AssignmentSubmissionCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := a.postgresClient.Db.Preload("Assignment").Preload("Student").Scopes(filter).
+		Where("assignment_id IN (?)", assignments).Order(order).
+		Limit(limit).Offset(offset).Find(&submissions).Error; err != nil {
+		return []models.AssignmentSubmissionCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return submissions, uint(count), nil
+}
+
+func (a AssignmentGatewayImpl) UpdateSubmission(submission models.AssignmentSubmissionCore) (models.AssignmentSubmissionCore, error) {
+	if err := a.postgresClient.Db.Model(&models.AssignmentSubmissionCore{ID: submission.ID}).
+		Updates(map[string]interface{}{
+			"status":         submission.Status,
+			"submitted_at":   submission.SubmittedAt,
+			"is_late":        submission.IsLate,
+			"grade":          submission.Grade,
+			"feedback":       submission.Feedback,
+			"reviewed_by_id": submission.ReviewedByID,
+			"reviewed_at":    submission.ReviewedAt,
+		}).Error; err != nil {
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return a.GetSubmissionById(submission.ID)
+}
+
+// StartSubmissionsByProject marks not started submissions as in progress once the student saves the project
+func (a AssignmentGatewayImpl) StartSubmissionsByProject(projectId uint) error {
+	if err := a.postgresClient.Db.Model(&models.AssignmentSubmissionCore{}).
+		Where("status = ? AND project_page_id IN (?)", models.AssignmentStatusNotStarted,
+			a.postgresClient.Db.Model(&models.ProjectPageCore{}).Select("id").Where("project_id = ?", projectId)).
+		Update("status", models.AssignmentStatusInProgress).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (a AssignmentGatewayImpl) IsSubmissionProjectPage(projectPageId uint) (bool, error) {
+	var count int64
+	if err := a.postgresClient.Db.Model(&models.AssignmentSubmissionCore{}).
+		Where("project_page_id = ?", projectPageId).Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+// IsReadOnlySubmissionProject reports whether the project is a personal copy that is submitted, reviewed,
+// or whose deadline has passed while late submissions are denied
+func (a AssignmentGatewayImpl) IsReadOnlySubmissionProject(projectId uint) (bool, error) {
+	var count int64
+	if err := a.postgresClient.Db.Model(&models.AssignmentSubmissionCore{}).
+		Joins("JOIN project_page_cores ON project_page_cores.id = assignment_submission_cores.project_page_id").
+		Joins("JOIN assignment_cores ON assignment_cores.id = assignment_submission_cores.assignment_id "+
+			"AND assignment_cores.deleted_at IS NULL").
+		Where("project_page_cores.project_id = ?", projectId).
+		Where("assignment_submission_cores.status IN ? OR (assignment_cores.late_policy = ? AND assignment_cores.deadline < ?)",
+			[]models.AssignmentStatus{models.AssignmentStatusSubmitted, models.AssignmentStatusReviewed},
+			models.AssignmentLatePolicyDeny, time.Now()).
+		Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+// createSubmission copies the template to a new project page of the student in the transaction
+func createSubmission(tx *gorm.DB, assignment models.AssignmentCore, studentId uint, template models.ProjectPageCore,
+	lockedBlocks string) (models.AssignmentSubmissionCore, error) {
+	projectPage := models.ProjectPageCore{
+		AuthorID:     studentId,
+		TemplateID:   &template.ID,
+		Title:        assignment.Title,
+		Instruction:  template.Instruction,
+		Notes:        template.Notes,
+		LockedBlocks: lockedBlocks,
+	}
+	if err := createProjectPage(tx, &projectPage, models.ProjectCore{
+		AuthorID: studentId,
+		Json:     template.Project.Json,
+	}); err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	submission := models.AssignmentSubmissionCore{
+		AssignmentID:  assignment.ID,
+		StudentID:     studentId,
+		ProjectPageID: projectPage.ID,
+		Status:        models.AssignmentStatusNotStarted,
+	}
+	if err := tx.Create(&submission).Error; err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	return submission, nil
+}
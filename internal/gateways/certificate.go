@@ -0,0 +1,237 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+type CertificateGateway interface {
+	CreateTemplate(template models.CertificateTemplateCore) (models.CertificateTemplateCore, error)
+	UpdateTemplate(template models.CertificateTemplateCore) (models.CertificateTemplateCore, error)
+	DeleteTemplate(id uint) error
+	GetTemplateById(id uint) (models.CertificateTemplateCore, error)
+	GetTemplates(offset, limit int) (templates []models.CertificateTemplateCore, countRows uint, err error)
+	GetTemplatesByCreator(userId uint, offset, limit int) (templates []models.CertificateTemplateCore, countRows uint, err error)
+	CreateCertificates(certificates []models.CertificateCore) ([]models.CertificateCore, error)
+	GetCertificateById(id uint) (models.CertificateCore, error)
+	GetCertificateByCode(code string) (models.CertificateCore, error)
+	GetCertificatesByTemplate(templateId uint, offset, limit int) (certificates []models.CertificateCore, countRows uint, err error)
+	GetCertificatesByUser(userId uint, offset, limit int) (certificates []models.CertificateCore, countRows uint, err error)
+	RevokeCertificate(id uint) error
+}
+
+type CertificateGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (c CertificateGatewayImpl) CreateTemplate(template models.CertificateTemplateCore) (models.CertificateTemplateCore, error) {
+	if err := c.postgresClient.Db.Create(&template).Error; err != nil {
+		return models.CertificateTemplateCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return template, nil
+}
+
+// UpdateTemplate updates the template and replaces its fields
+func (c CertificateGatewayImpl) UpdateTemplate(template models.CertificateTemplateCore) (models.CertificateTemplateCore, error) {
+	if err := c.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CertificateTemplateCore{}).Where("id = ?", template.ID).
+			Updates(map[string]interface{}{
+				"name":       template.Name,
+				"background": template.Background,
+				"width":      template.Width,
+				"height":     template.Height,
+				"qr_x":       template.QrX,
+				"qr_y":       template.QrY,
+				"qr_size":    template.QrSize,
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("template_id = ?", template.ID).Delete(&models.CertificateFieldCore{}).Error; err != nil {
+			return err
+		}
+		for i := range template.Fields {
+			template.Fields[i].ID = 0
+			template.Fields[i].TemplateID = template.ID
+		}
+		if len(template.Fields) == 0 {
+			return nil
+		}
+		return tx.Create(&template.Fields).Error
+	}); err != nil {
+		return models.CertificateTemplateCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return c.GetTemplateById(template.ID)
+}
+
+func (c CertificateGatewayImpl) DeleteTemplate(id uint) error {
+	if err := c.postgresClient.Db.Delete(&models.CertificateTemplateCore{}, id).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (c CertificateGatewayImpl) GetTemplateById(id uint) (template models.CertificateTemplateCore, err error) {
+	if err := c.postgresClient.Db.Preload("Fields").First(&template, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CertificateTemplateCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.CertificateTemplateCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return template, nil
+}
+
+func (c CertificateGatewayImpl) GetTemplates(offset, limit int) (
+	templates []models.CertificateTemplateCore, countRows uint, err error) {
+	return c.getTemplates(func(db *gorm.DB) *gorm.DB {
+		return db
+	}, offset, limit)
+}
+
+func (c CertificateGatewayImpl) GetTemplatesByCreator(userId uint, offset, limit int) (
+	templates []models.CertificateTemplateCore, countRows uint, err error) {
+	return c.getTemplates(func(db *gorm.DB) *gorm.DB {
+		return db.Where("created_by_id = ?", userId)
+	}, offset, limit)
+}
+
+func (c CertificateGatewayImpl) getTemplates(filter func(db *gorm.DB) *gorm.DB, offset, limit int) (
+	templates []models.CertificateTemplateCore, countRows uint, err error) {
+	var count int64
+	if err := c.postgresClient.Db.Model(&models.CertificateTemplateCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.CertificateTemplateCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := c.postgresClient.Db.Preload("Fields").Scopes(filter).Order("name").
+		Limit(limit).Offset(offset).Find(&templates).Error; err != nil {
+		return []models.CertificateTemplateCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return templates, uint(count), nil
+}
+
+// CreateCertificates creates all certificates in one transaction and returns them with their users
+func (c CertificateGatewayImpl) CreateCertificates(certificates []models.CertificateCore) ([]models.CertificateCore, error) {
+	ids := make([]uint, 0, len(certificates))
+	if err := c.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		for i := range certificates {
+			if err := tx.Omit("Template", "User").Create(&certificates[i]).Error; err != nil {
+				return err
+			}
+			ids = append(ids, certificates[i].ID)
+		}
+		return nil
+	}); err != nil {
+		return []models.CertificateCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	var created []models.CertificateCore
+	if err := c.postgresClient.Db.Preload("User").Where("id IN ?", ids).Order("id").Find(&created).Error; err != nil {
+		return []models.CertificateCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return created, nil
+}
+
+func (c CertificateGatewayImpl) GetCertificateById(id uint) (models.CertificateCore, error) {
+	return c.getCertificate(c.postgresClient.Db.Where("id = ?", id))
+}
+
+func (c CertificateGatewayImpl) GetCertificateByCode(code string) (models.CertificateCore, error) {
+	return c.getCertificate(c.postgresClient.Db.Where("code = ?", code))
+}
+
+func (c CertificateGatewayImpl) getCertificate(db *gorm.DB) (certificate models.CertificateCore, err error) {
+	if err := db.Preload("User").Scopes(c.preloadTemplate).Take(&certificate).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CertificateCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.CertificateCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return certificate, nil
+}
+
+func (c CertificateGatewayImpl) GetCertificatesByTemplate(templateId uint, offset, limit int) (
+	certificates []models.CertificateCore, countRows uint, err error) {
+	return c.getCertificates(func(db *gorm.DB) *gorm.DB {
+		return db.Where("template_id = ?", templateId)
+	}, offset, limit)
+}
+
+func (c CertificateGatewayImpl) GetCertificatesByUser(userId uint, offset, limit int) (
+	certificates []models.CertificateCore, countRows uint, err error) {
+	return c.getCertificates(func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userId)
+	}, offset, limit)
+}
+
+func (c CertificateGatewayImpl) getCertificates(filter func(db *gorm.DB) *gorm.DB, offset, limit int) (
+	certificates []models.CertificateCore, countRows uint, err error) {
+	var count int64
+	if err := c.postgresClient.Db.Model(&models.CertificateCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.CertificateCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := c.postgresClient.Db.Preload("User").Scopes(c.preloadTemplate).Scopes(filter).
+		Order("created_at desc, id").Limit(limit).Offset(offset).Find(&certificates).Error; err != nil {
+		return []models.CertificateCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return certificates, uint(count), nil
+}
+
+// preloadTemplate loads templates with their fields, certificates of deleted templates keep their layout
+func (c CertificateGatewayImpl) preloadTemplate(db *gorm.DB) *gorm.DB {
+	return db.Preload("Template", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Template.Fields")
+}
+
+func (c CertificateGatewayImpl) RevokeCertificate(id uint) error {
+	if err := c.postgresClient.Db.Model(&models.CertificateCore{}).Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type ClarificationGateway interface {
+	CreateClarification(clarification models.ClarificationCore) (models.ClarificationCore, error)
+	AnswerClarification(id uint, answer string, isPublic bool, answeredById uint) (models.ClarificationCore, error)
+	GetClarificationById(id uint) (models.ClarificationCore, error)
+	GetClarificationsByContest(contestId uint, offset, limit int) (
+		clarifications []models.ClarificationCore, countRows uint, err error)
+	GetClarificationsForUser(contestId, userId uint, offset, limit int) (
+		clarifications []models.ClarificationCore, countRows uint, err error)
+	CreateAnnouncement(announcement models.AnnouncementCore) (models.AnnouncementCore, error)
+	GetAnnouncementsByContest(contestId uint, offset, limit int) (
+		announcements []models.AnnouncementCore, countRows uint, err error)
+}
+
+type ClarificationGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (c ClarificationGatewayImpl) CreateClarification(clarification models.ClarificationCore) (
+	models.ClarificationCore, error) {
+	if err := c.postgresClient.Db.Create(&clarification).Error; err != nil {
+		return models.ClarificationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return c.GetClarificationById(clarification.ID)
+}
+
+func (c ClarificationGatewayImpl) AnswerClarification(id uint, answer string, isPublic bool, answeredById uint) (
+	models.ClarificationCore, error) {
+	if err := c.postgresClient.Db.Model(&models.ClarificationCore{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"answer":         answer,
+			"is_public":      isPublic,
+			"answered_by_id": answeredById,
+			"answered_at":    gorm.Expr("NOW()"),
+		}).Error; err != nil {
+		return models.ClarificationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return c.GetClarificationById(id)
+}
+
+func (c ClarificationGatewayImpl) GetClarificationById(id uint) (clarification models.ClarificationCore, err error) {
+	if err := c.postgresClient.Db.Scopes(preloadClarification).First(&clarification, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ClarificationCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.ClarificationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return clarification, nil
+}
+
+func (c ClarificationGatewayImpl) GetClarificationsByContest(contestId uint, offset, limit int) (
+	clarifications []models.ClarificationCore, countRows uint, err error) {
+	return c.getClarifications(func(db *gorm.DB) *gorm.DB {
+		return db.Where("contest_id = ?", contestId)
+	}, offset, limit)
+}
+
+// GetClarificationsForUser returns public clarifications of the contest and the questions asked by the user
+func (c ClarificationGatewayImpl) GetClarificationsForUser(contestId, userId uint, offset, limit int) (
+	clarifications []models.ClarificationCore, countRows uint, err error) {
+	return c.getClarifications(func(db *gorm.DB) *gorm.DB {
+		return db.Where("contest_id = ?", contestId).Where("is_public = true OR author_id = ?", userId)
+	}, offset, limit)
+}
+
+func (c ClarificationGatewayImpl) getClarifications(filter func(db *gorm.DB) *gorm.DB, offset, limit int) (
+	clarifications []models.ClarificationCore, countRows uint, err error) {
+	var count int64
+	if err := c.postgresClient.Db.Model(&models.ClarificationCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.ClarificationCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := c.postgresClient.Db.Scopes(preloadClarification, filter).Order("created_at DESC, id DESC").
+		Limit(limit).Offset(offset).Find(&clarifications).Error; err != nil {
+		return []models.ClarificationCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return clarifications, uint(count), nil
+}
+
+func (c ClarificationGatewayImpl) CreateAnnouncement(announcement models.AnnouncementCore) (
+	models.AnnouncementCore, error) {
+	if err := c.postgresClient.Db.Create(&announcement).Error; err != nil {
+		return models.AnnouncementCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return announcement, nil
+}
+
+func (c ClarificationGatewayImpl) GetAnnouncementsByContest(contestId uint, offset, limit int) (
+	announcements []models.AnnouncementCore, countRows uint, err error) {
+	var count int64
+	if err := c.postgresClient.Db.Model(&models.AnnouncementCore{}).
+		Where("contest_id = ?", contestId).Count(&count).Error; err != nil {
+		return []models.AnnouncementCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := c.postgresClient.Db.Where("contest_id = ?", contestId).Order("created_at DESC, id DESC").
+		Limit(limit).Offset(offset).Find(&announcements).Error; err != nil {
+		return []models.AnnouncementCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return announcements, uint(count), nil
+}
+
+func preloadClarification(db *gorm.DB) *gorm.DB {
+	return db.Preload("Task")
+}
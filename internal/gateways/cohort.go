@@ -0,0 +1,131 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type CohortGateway interface {
+	CreateCohort(cohort models.CohortCore) (models.CohortCore, error)
+	GetCohortById(id uint) (models.CohortCore, error)
+	GetCohortsByCourse(courseId string, unitIds []uint, teacherId *uint) (cohorts []models.CohortCore, countRows uint, err error)
+	GetAllCohorts() (cohorts []models.CohortCore, err error)
+	ReplaceMismatches(cohortId uint, mismatches []models.CohortMismatchCore) error
+	GetMismatches(offset, limit int, unitIds []uint, teacherId *uint) (
+		mismatches []models.CohortMismatchCore, countRows uint, err error)
+}
+
+type CohortGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (c CohortGatewayImpl) CreateCohort(cohort models.CohortCore) (models.CohortCore, error) {
+	if err := c.postgresClient.Db.Create(&cohort).Error; err != nil {
+		return models.CohortCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return cohort, nil
+}
+
+func (c CohortGatewayImpl) GetCohortById(id uint) (cohort models.CohortCore, err error) {
+	if err := c.postgresClient.Db.First(&cohort, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CohortCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.CohortCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return cohort, nil
+}
+
+func (c CohortGatewayImpl) GetCohortsByCourse(courseId string, unitIds []uint, teacherId *uint) (
+	cohorts []models.CohortCore, countRows uint, err error) {
+	if err := c.postgresClient.Db.Scopes(c.cohortsFilter(unitIds, teacherId)).Where("course_id = ?", courseId).
+		Order("name").Find(&cohorts).Error; err != nil {
+		return []models.CohortCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return cohorts, uint(len(cohorts)), nil
+}
+
+func (c CohortGatewayImpl) GetAllCohorts() (cohorts []models.CohortCore, err error) {
+	if err := c.postgresClient.Db.Find(&cohorts).Error; err != nil {
+		return []models.CohortCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return cohorts, nil
+}
+
+func (c CohortGatewayImpl) ReplaceMismatches(cohortId uint, mismatches []models.CohortMismatchCore) error {
+	if err := c.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cohort_id = ?", cohortId).Delete(&models.CohortMismatchCore{}).Error; err != nil {
+			return err
+		}
+		if len(mismatches) == 0 {
+			return nil
+		}
+		return tx.Create(&mismatches).Error
+	}); err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (c CohortGatewayImpl) GetMismatches(offset, limit int, unitIds []uint, teacherId *uint) (
+	mismatches []models.CohortMismatchCore, countRows uint, err error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		return db.Where("cohort_id IN (?)", c.postgresClient.Db.Model(&models.CohortCore{}).Select("id").
+			Scopes(c.cohortsFilter(unitIds, teacherId)))
+	}
+	var count int64
+	result := c.postgresClient.Db.Model(&models.CohortMismatchCore{}).Scopes(filter).Count(&count)
+	if result.Error != nil {
+		return []models.CohortMismatchCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if err := c.postgresClient.Db.Scopes(filter).Preload("Cohort").Limit(limit).Offset(offset).Order("cohort_id, username").
+		Find(&mismatches).Error; err != nil {
+		return []models.CohortMismatchCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return mismatches, uint(count), nil
+}
+
+// cohortsFilter limits cohorts to groups of the units or groups of the teacher,
+// nil unit ids and teacher id do not limit cohorts
+func (c CohortGatewayImpl) cohortsFilter(unitIds []uint, teacherId *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if unitIds != nil {
+			db = db.Where("group_id IN (?)", c.postgresClient.Db.Model(&models.GroupCore{}).
+				Select("id").Where("unit_id IN ?", unitIds))
+		}
+		if teacherId != nil {
+			db = db.Where("group_id IN (?)", c.postgresClient.Db.Model(&models.GroupTeacherCore{}).
+				Select("group_id").Where("user_id = ?", *teacherId))
+		}
+		return db
+	}
+}
@@ -0,0 +1,492 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+type ContestGateway interface {
+	CreateContest(contest models.ContestCore) (models.ContestCore, error)
+	UpdateContest(contest models.ContestCore) (models.ContestCore, error)
+	DeleteContest(id uint) error
+	GetContestById(id uint) (models.ContestCore, error)
+	GetContests(offset, limit int) (contests []models.ContestCore, countRows uint, err error)
+	GetContestsForUser(userId uint, offset, limit int) (contests []models.ContestCore, countRows uint, err error)
+	CreateTask(task models.ContestTaskCore) (models.ContestTaskCore, error)
+	UpdateTask(task models.ContestTaskCore) (models.ContestTaskCore, error)
+	DeleteTask(id uint) error
+	GetTaskById(id uint) (models.ContestTaskCore, error)
+	GetTasksByContest(contestId uint) ([]models.ContestTaskCore, error)
+	CreateInvitations(contestId uint, userIds []uint) error
+	DeleteInvitation(contestId, userId uint) error
+	IsInvited(contestId, userId uint) (bool, error)
+	CreateRegistration(registration models.ContestRegistrationCore, capacity *int) (models.ContestRegistrationCore, error)
+	DeleteRegistration(contestId, userId uint) error
+	GetRegistration(contestId, userId uint) (models.ContestRegistrationCore, error)
+	GetRegistrationById(id uint) (models.ContestRegistrationCore, error)
+	GetRegistrationsByContest(contestId uint, status *models.ContestRegistrationStatus, offset, limit int) (
+		registrations []models.ContestRegistrationCore, countRows uint, err error)
+	GetRegistrationsByUser(userId uint, offset, limit int) (registrations []models.ContestRegistrationCore, countRows uint, err error)
+	GetRegistrationsAwaitingConsent(parentId uint, offset, limit int) (
+		registrations []models.ContestRegistrationCore, countRows uint, err error)
+	SetConsent(id, consentedById uint) (models.ContestRegistrationCore, error)
+}
+
+type ContestGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (c ContestGatewayImpl) CreateContest(contest models.ContestCore) (models.ContestCore, error) {
+	if err := c.postgresClient.Db.Create(&contest).Error; err != nil {
+		return models.ContestCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return c.GetContestById(contest.ID)
+}
+
+// UpdateContest replaces age categories, assigns them to registrations again by the age
+// and moves waitlisted registrations to free places when the capacity grows
+func (c ContestGatewayImpl) UpdateContest(contest models.ContestCore) (models.ContestCore, error) {
+	if err := c.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&models.ContestCore{}, contest.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ContestCore{}).Where("id = ?", contest.ID).
+			Updates(map[string]interface{}{
+				"title":           contest.Title,
+				"description":     contest.Description,
+				"start_at":        contest.StartAt,
+				"end_at":          contest.EndAt,
+				"min_age":         contest.MinAge,
+				"max_age":         contest.MaxAge,
+				"min_grade":       contest.MinGrade,
+				"max_grade":       contest.MaxGrade,
+				"invitation_only": contest.InvitationOnly,
+				"capacity":        contest.Capacity,
+				"min_team_size":   contest.MinTeamSize,
+				"max_team_size":   contest.MaxTeamSize,
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("contest_id = ?", contest.ID).Delete(&models.ContestAgeCategoryCore{}).Error; err != nil {
+			return err
+		}
+		for i := range contest.AgeCategories {
+			contest.AgeCategories[i].ID = 0
+			contest.AgeCategories[i].ContestID = contest.ID
+		}
+		if len(contest.AgeCategories) > 0 {
+			if err := tx.Create(&contest.AgeCategories).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec(`UPDATE contest_registration_cores SET age_category_id = (
+			SELECT c.id FROM contest_age_category_cores AS c
+			WHERE c.contest_id = contest_registration_cores.contest_id
+				AND contest_registration_cores.age BETWEEN c.min_age AND c.max_age
+			ORDER BY c.min_age LIMIT 1
+		) WHERE contest_id = ?`, contest.ID).Error; err != nil {
+			return err
+		}
+		return promoteWaitlisted(tx, contest.ID, contest.Capacity)
+	}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ContestCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.ContestCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return c.GetContestById(contest.ID)
+}
+
+func (c ContestGatewayImpl) DeleteContest(id uint) error {
+	if err := c.postgresClient.Db.Delete(&models.ContestCore{}, id).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (c ContestGatewayImpl) GetContestById(id uint) (contest models.ContestCore, err error) {
+	if err := c.postgresClient.Db.Preload("AgeCategories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_age")
+	}).First(&contest, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ContestCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.ContestCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return contest, nil
+}
+
+func (c ContestGatewayImpl) GetContests(offset, limit int) (contests []models.ContestCore, countRows uint, err error) {
+	return c.getContests(func(db *gorm.DB) *gorm.DB {
+		return db
+	}, offset, limit)
+}
+
+// GetContestsForUser returns contests open to the user: without a unit or of units of the user,
+// and not invitation only unless the user is invited
+func (c ContestGatewayImpl) GetContestsForUser(userId uint, offset, limit int) (
+	contests []models.ContestCore, countRows uint, err error) {
+	return c.getContests(func(db *gorm.DB) *gorm.DB {
+		return db.Where("unit_id IS NULL OR unit_id IN (?)",
+			c.postgresClient.Db.Model(&models.UnitMemberCore{}).Select("unit_id").Where("user_id = ?", userId)).
+			Where("invitation_only = false OR id IN (?)",
+				c.postgresClient.Db.Model(&models.ContestInvitationCore{}).Select("contest_id").Where("user_id = ?", userId))
+	}, offset, limit)
+}
+
+func (c ContestGatewayImpl) getContests(filter func(db *gorm.DB) *gorm.DB, offset, limit int) (
+	contests []models.ContestCore, countRows uint, err error) {
+	var count int64
+	if err := c.postgresClient.Db.Model(&models.ContestCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.ContestCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := c.postgresClient.Db.Preload("AgeCategories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_age")
+	}).Scopes(filter).Order("start_at DESC").Limit(limit).Offset(offset).Find(&contests).Error; err != nil {
+		return []models.ContestCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return contests, uint(count), nil
+}
+
+func (c ContestGatewayImpl) CreateTask(task models.ContestTaskCore) (models.ContestTaskCore, error) {
+	if err := c.postgresClient.Db.Create(&task).Error; err != nil {
+		return models.ContestTaskCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return task, nil
+}
+
+func (c ContestGatewayImpl) UpdateTask(task models.ContestTaskCore) (models.ContestTaskCore, error) {
+	if err := c.postgresClient.Db.Model(&models.ContestTaskCore{}).Where("id = ?", task.ID).
+		Updates(map[string]interface{}{
+			"position":  task.Position,
+			"title":     task.Title,
+			"statement": task.Statement,
+		}).Error; err != nil {
+		return models.ContestTaskCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return c.GetTaskById(task.ID)
+}
+
+func (c ContestGatewayImpl) DeleteTask(id uint) error {
+	if err := c.postgresClient.Db.Delete(&models.ContestTaskCore{}, id).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (c ContestGatewayImpl) GetTaskById(id uint) (task models.ContestTaskCore, err error) {
+	if err := c.postgresClient.Db.First(&task, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ContestTaskCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.ContestTaskCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return task, nil
+}
+
+func (c ContestGatewayImpl) GetTasksByContest(contestId uint) (tasks []models.ContestTaskCore, err error) {
+	if err := c.postgresClient.Db.Where("contest_id = ?", contestId).Order("position, id").Find(&tasks).Error; err != nil {
+		return []models.ContestTaskCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return tasks, nil
+}
+
+func (c ContestGatewayImpl) CreateInvitations(contestId uint, userIds []uint) error {
+	invitations := make([]models.ContestInvitationCore, 0, len(userIds))
+	for _, userId := range userIds {
+		invitations = append(invitations, models.ContestInvitationCore{
+			ContestID: contestId,
+			UserID:    userId,
+		})
+	}
+	if len(invitations) == 0 {
+		return nil
+	}
+	if err := c.postgresClient.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&invitations).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (c ContestGatewayImpl) DeleteInvitation(contestId, userId uint) error {
+	result := c.postgresClient.Db.Where("contest_id = ? AND user_id = ?", contestId, userId).
+		Delete(&models.ContestInvitationCore{})
+	if result.Error != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if result.RowsAffected == 0 {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrNotFoundInDB,
+		}
+	}
+	return nil
+}
+
+func (c ContestGatewayImpl) IsInvited(contestId, userId uint) (bool, error) {
+	var count int64
+	if err := c.postgresClient.Db.Model(&models.ContestInvitationCore{}).
+		Where("contest_id = ? AND user_id = ?", contestId, userId).Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+// CreateRegistration waitlists the registration when the capacity is reached. The contest row is locked,
+// so concurrent registrations do not take more places than the capacity.
+func (c ContestGatewayImpl) CreateRegistration(registration models.ContestRegistrationCore, capacity *int) (
+	models.ContestRegistrationCore, error) {
+	if err := c.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Take(&models.ContestCore{}, registration.ContestID).Error; err != nil {
+			return err
+		}
+		if capacity != nil {
+			taken, err := countTakenPlaces(tx, registration.ContestID)
+			if err != nil {
+				return err
+			}
+			if taken >= int64(*capacity) {
+				registration.Status = models.ContestRegistrationStatusWaitlisted
+			}
+		}
+		return tx.Create(&registration).Error
+	}); err != nil {
+		return models.ContestRegistrationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return c.GetRegistrationById(registration.ID)
+}
+
+// DeleteRegistration gives the freed place to the first waitlisted registration
+func (c ContestGatewayImpl) DeleteRegistration(contestId, userId uint) error {
+	if err := c.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		var contest models.ContestCore
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&contest, contestId).Error; err != nil {
+			return err
+		}
+		result := tx.Where("contest_id = ? AND user_id = ?", contestId, userId).Delete(&models.ContestRegistrationCore{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return promoteWaitlisted(tx, contestId, contest.Capacity)
+	}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (c ContestGatewayImpl) GetRegistration(contestId, userId uint) (models.ContestRegistrationCore, error) {
+	return c.getRegistration(func(db *gorm.DB) *gorm.DB {
+		return db.Where("contest_id = ? AND user_id = ?", contestId, userId)
+	})
+}
+
+func (c ContestGatewayImpl) GetRegistrationById(id uint) (models.ContestRegistrationCore, error) {
+	return c.getRegistration(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ?", id)
+	})
+}
+
+func (c ContestGatewayImpl) getRegistration(filter func(db *gorm.DB) *gorm.DB) (
+	registration models.ContestRegistrationCore, err error) {
+	if err := c.postgresClient.Db.Scopes(preloadRegistration, filter).Take(&registration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ContestRegistrationCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.ContestRegistrationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return registration, nil
+}
+
+func (c ContestGatewayImpl) GetRegistrationsByContest(contestId uint, status *models.ContestRegistrationStatus,
+	offset, limit int) (registrations []models.ContestRegistrationCore, countRows uint, err error) {
+	return c.getRegistrations(func(db *gorm.DB) *gorm.DB {
+		db = db.Where("contest_id = ?", contestId)
+		if status != nil {
+			db = db.Where("status = ?", *status)
+		}
+		return db
+	}, offset, limit)
+}
+
+func (c ContestGatewayImpl) GetRegistrationsByUser(userId uint, offset, limit int) (
+	registrations []models.ContestRegistrationCore, countRows uint, err error) {
+	return c.getRegistrations(func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userId)
+	}, offset, limit)
+}
+
+// GetRegistrationsAwaitingConsent returns registrations of children of the parent without the required consent
+func (c ContestGatewayImpl) GetRegistrationsAwaitingConsent(parentId uint, offset, limit int) (
+	registrations []models.ContestRegistrationCore, countRows uint, err error) {
+	return c.getRegistrations(func(db *gorm.DB) *gorm.DB {
+		return db.Where("consent_required = true AND consented_at IS NULL").
+			Where("user_id IN (?)",
+				c.postgresClient.Db.Model(&models.ParentRelCore{}).Select("child_id").Where("parent_id = ?", parentId))
+	}, offset, limit)
+}
+
+func (c ContestGatewayImpl) getRegistrations(filter func(db *gorm.DB) *gorm.DB, offset, limit int) (
+	registrations []models.ContestRegistrationCore, countRows uint, err error) {
+	var count int64
+	if err := c.postgresClient.Db.Model(&models.ContestRegistrationCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.ContestRegistrationCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := c.postgresClient.Db.Scopes(preloadRegistration, filter).Order("created_at, id").
+		Limit(limit).Offset(offset).Find(&registrations).Error; err != nil {
+		return []models.ContestRegistrationCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return registrations, uint(count), nil
+}
+
+// SetConsent records the consent and confirms the registration unless it is waitlisted
+func (c ContestGatewayImpl) SetConsent(id, consentedById uint) (models.ContestRegistrationCore, error) {
+	if err := c.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ContestRegistrationCore{}).Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"consented_by_id": consentedById,
+				"consented_at":    gorm.Expr("NOW()"),
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ContestRegistrationCore{}).
+			Where("id = ? AND status = ?", id, models.ContestRegistrationStatusAwaitingConsent).
+			Update("status", models.ContestRegistrationStatusConfirmed).Error
+	}); err != nil {
+		return models.ContestRegistrationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return c.GetRegistrationById(id)
+}
+
+func preloadRegistration(db *gorm.DB) *gorm.DB {
+	return db.Preload("Contest").Preload("User").Preload("AgeCategory")
+}
+
+// countTakenPlaces counts registrations holding places of the contest
+func countTakenPlaces(tx *gorm.DB, contestId uint) (int64, error) {
+	var count int64
+	err := tx.Model(&models.ContestRegistrationCore{}).
+		Where("contest_id = ? AND status IN ?", contestId, []models.ContestRegistrationStatus{
+			models.ContestRegistrationStatusAwaitingConsent,
+			models.ContestRegistrationStatusConfirmed,
+		}).Count(&count).Error
+	return count, err
+}
+
+// promoteWaitlisted gives free places to waitlisted registrations in the order of registration
+func promoteWaitlisted(tx *gorm.DB, contestId uint, capacity *int) error {
+	query := tx.Where("contest_id = ? AND status = ?", contestId, models.ContestRegistrationStatusWaitlisted).
+		Order("created_at, id")
+	if capacity != nil {
+		taken, err := countTakenPlaces(tx, contestId)
+		if err != nil {
+			return err
+		}
+		free := int64(*capacity) - taken
+		if free <= 0 {
+			return nil
+		}
+		query = query.Limit(int(free))
+	}
+	var waitlisted []models.ContestRegistrationCore
+	if err := query.Find(&waitlisted).Error; err != nil {
+		return err
+	}
+	for _, registration := range waitlisted {
+		status := models.ContestRegistrationStatusConfirmed
+		if registration.ConsentRequired && registration.ConsentedAt == nil {
+			status = models.ContestRegistrationStatusAwaitingConsent
+		}
+		if err := tx.Model(&registration).Update("status", status).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package gateways
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EdxGateway is the client of Open edX REST API. Requests are authorized with
+// the OAuth2 client credentials token, which is refreshed when it expires.
+type EdxGateway interface {
+	GetCourse(courseId string) (course models.CourseCore, err error)
+	GetEnrollments(username string) (enrollments []models.EnrollmentCore, err error)
+	PostEnrollment(username, courseId string) error
+	PostRegistration(user models.UserCore, username, password string) error
+	PostCohort(courseId, name string) (edxCohortId int, err error)
+	PostCohortUsers(courseId string, edxCohortId int, usernames []string) error
+	GetCohortUsers(courseId string, edxCohortId int) (usernames []string, err error)
+}
+
+type EdxGatewayImpl struct {
+	client *http.Client
+	token  *edxToken
+}
+
+type edxToken struct {
+	mu        sync.Mutex
+	value     string
+	tokenType string
+	expiresAt time.Time
+}
+
+func NewEdxGateway() *EdxGatewayImpl {
+	return &EdxGatewayImpl{
+		client: &http.Client{Timeout: viper.GetDuration("edx.timeout")},
+		token:  &edxToken{},
+	}
+}
+
+func (e EdxGatewayImpl) GetCourse(courseId string) (course models.CourseCore, err error) {
+	err = e.doJson(http.MethodGet, viper.GetString("api_urls.getCourse")+url.PathEscape(courseId)+"/", "", nil, &course)
+	return course, err
+}
+
+// GetEnrollments returns all enrollments of the user following the pagination of edX
+func (e EdxGatewayImpl) GetEnrollments(username string) (enrollments []models.EnrollmentCore, err error) {
+	next := viper.GetString("api_urls.getEnrollment") + url.QueryEscape(username)
+	for next != "" {
+		var page struct {
+			Next    *string                 `json:"next"`
+			Results []models.EnrollmentCore `json:"results"`
+		}
+		if err := e.doJson(http.MethodGet, next, "", nil, &page); err != nil {
+			return []models.EnrollmentCore{}, err
+		}
+		enrollments = append(enrollments, page.Results...)
+		next = ""
+		if page.Next != nil {
+			next = *page.Next
+		}
+	}
+	return enrollments, nil
+}
+
+func (e EdxGatewayImpl) PostEnrollment(username, courseId string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"user": username,
+		"course_details": map[string]string{
+			"course_id": courseId,
+		},
+	})
+	if err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return e.doJson(http.MethodPost, viper.GetString("api_urls.postEnrollment"), "application/json", body, nil)
+}
+
+// PostRegistration creates the edX account. An already existing account is not an error
+// unless its email belongs to another edX username.
+func (e EdxGatewayImpl) PostRegistration(user models.UserCore, username, password string) error {
+	form := url.Values{}
+	form.Set("email", user.Email)
+	form.Set("name", strings.TrimSpace(user.Firstname+" "+user.Lastname))
+	form.Set("username", username)
+	form.Set("password", password)
+	form.Set("terms_of_service", "true")
+	form.Set("honor_code", "true")
+	err := e.doJson(http.MethodPost, viper.GetString("api_urls.postRegistration"),
+		"application/x-www-form-urlencoded", []byte(form.Encode()), nil)
+	if responseError, ok := err.(utils.ResponseError); ok && responseError.Code == http.StatusConflict {
+		return e.checkAccountEmail(user.Email, username)
+	}
+	return err
+}
+
+// checkAccountEmail fails if the edX account with the email has another username,
+// edX answers the registration of both a taken username and a taken email with conflict
+func (e EdxGatewayImpl) checkAccountEmail(email, username string) error {
+	var accounts []struct {
+		Username string `json:"username"`
+	}
+	if err := e.doJson(http.MethodGet, viper.GetString("api_urls.getAccounts")+url.QueryEscape(email),
+		"", nil, &accounts); err != nil {
+		// only the username is taken, it is generated from the user id and belongs to the user
+		if responseError, ok := err.(utils.ResponseError); ok && responseError.Code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	for _, account := range accounts {
+		if account.Username != username {
+			return utils.ResponseError{
+				Code:    http.StatusConflict,
+				Message: consts.ErrEdxEmailTaken,
+			}
+		}
+	}
+	return nil
+}
+
+func (e EdxGatewayImpl) PostCohort(courseId, name string) (edxCohortId int, err error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"name":            name,
+		"assignment_type": "manual",
+	})
+	if err != nil {
+		return 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	var cohort struct {
+		Id int `json:"id"`
+	}
+	if err := e.doJson(http.MethodPost, getCohortsUrl(courseId), "application/json", body, &cohort); err != nil {
+		return 0, err
+	}
+	return cohort.Id, nil
+}
+
+func (e EdxGatewayImpl) PostCohortUsers(courseId string, edxCohortId int, usernames []string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"users": strings.Join(usernames, ","),
+	})
+	if err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return e.doJson(http.MethodPost, getCohortsUrl(courseId)+strconv.Itoa(edxCohortId)+"/users",
+		"application/json", body, nil)
+}
+
+// GetCohortUsers returns usernames of all cohort members following the pagination of edX
+func (e EdxGatewayImpl) GetCohortUsers(courseId string, edxCohortId int) (usernames []string, err error) {
+	next := getCohortsUrl(courseId) + strconv.Itoa(edxCohortId) + "/users"
+	for next != "" {
+		var page struct {
+			Next    *string `json:"next"`
+			Results []struct {
+				Username string `json:"username"`
+			} `json:"results"`
+		}
+		if err := e.doJson(http.MethodGet, next, "", nil, &page); err != nil {
+			return []string{}, err
+		}
+		for _, user := range page.Results {
+			usernames = append(usernames, user.Username)
+		}
+		next = ""
+		if page.Next != nil {
+			next = *page.Next
+		}
+	}
+	return usernames, nil
+}
+
+// doJson sends the authorized request and decodes the response into v if it is not nil.
+// The token is refreshed and the request is repeated once if edX rejects the token.
+func (e EdxGatewayImpl) doJson(method, requestUrl, contentType string, body []byte, v interface{}) error {
+	for attempt := 0; ; attempt++ {
+		authorization, err := e.getAuthorization(attempt > 0)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest(method, requestUrl, bytes.NewReader(body))
+		if err != nil {
+			return utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		req.Header.Set("Authorization", authorization)
+		req.Header.Set("Accept", "application/json")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := e.client.Do(req)
+		if err != nil {
+			return utils.ResponseError{
+				Code:    http.StatusBadGateway,
+				Message: consts.ErrEdxUnavailable,
+			}
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+		resp.Body.Close()
+		if err != nil {
+			return utils.ResponseError{
+				Code:    http.StatusBadGateway,
+				Message: consts.ErrEdxUnavailable,
+			}
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			continue
+		}
+		if err := getEdxError(resp.StatusCode, data); err != nil {
+			return err
+		}
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return utils.ResponseError{
+				Code:    http.StatusBadGateway,
+				Message: err.Error(),
+			}
+		}
+		return nil
+	}
+}
+
+func (e EdxGatewayImpl) getAuthorization(refresh bool) (string, error) {
+	e.token.mu.Lock()
+	defer e.token.mu.Unlock()
+	// the token is renewed a bit earlier than it expires
+	if !refresh && e.token.value != "" && time.Now().Add(time.Minute).Before(e.token.expiresAt) {
+		return e.token.tokenType + " " + e.token.value, nil
+	}
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", viper.GetString("edx_client_id"))
+	form.Set("client_secret", viper.GetString("edx_client_secret"))
+	form.Set("token_type", "jwt")
+	resp, err := e.client.PostForm(viper.GetString("api_urls.refreshToken"), form)
+	if err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusBadGateway,
+			Message: consts.ErrEdxUnavailable,
+		}
+	}
+	defer resp.Body.Close()
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", utils.ResponseError{
+			Code:    http.StatusBadGateway,
+			Message: fmt.Sprintf("%s: token status %d", consts.ErrEdxUnavailable, resp.StatusCode),
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", utils.ResponseError{
+			Code:    http.StatusBadGateway,
+			Message: consts.ErrEdxUnavailable,
+		}
+	}
+	e.token.value = token.AccessToken
+	e.token.tokenType = token.TokenType
+	if strings.EqualFold(e.token.tokenType, "jwt") {
+		e.token.tokenType = "JWT"
+	} else {
+		e.token.tokenType = "Bearer"
+	}
+	e.token.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return e.token.tokenType + " " + e.token.value, nil
+}
+
+func getCohortsUrl(courseId string) string {
+	return viper.GetString("api_urls.postCohort") + url.PathEscape(courseId) + "/cohorts/"
+}
+
+// getEdxError maps edX response status to the platform error
+func getEdxError(status int, data []byte) error {
+	switch {
+	case status >= 200 && status < 300:
+		return nil
+	case status == http.StatusNotFound:
+		return utils.ResponseError{
+			Code:    http.StatusNotFound,
+			Message: consts.ErrNotFoundInEdx,
+		}
+	case status == http.StatusBadRequest || status == http.StatusConflict:
+		var message struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &message)
+		if message.Message == "" {
+			message.Message = consts.ErrEdxBadRequest
+		}
+		return utils.ResponseError{
+			Code:    utils.Status(status),
+			Message: message.Message,
+		}
+	default:
+		return utils.ResponseError{
+			Code:    http.StatusBadGateway,
+			Message: fmt.Sprintf("%s: status %d", consts.ErrEdxUnavailable, status),
+		}
+	}
+}
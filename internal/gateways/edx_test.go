@@ -0,0 +1,231 @@
+package gateways
+
+import (
+	"encoding/json"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// mockEdx is a local stand-in of Open edX: it issues client credentials tokens
+// and serves the REST API used by the gateway
+type mockEdx struct {
+	server *httptest.Server
+
+	mu sync.Mutex
+	// tokens counts issued tokens, the token value is its number
+	tokens int
+	// rejectToken makes the API reject the next request as unauthorized
+	rejectToken bool
+	// accounts maps emails to usernames of existing edX accounts
+	accounts map[string]string
+}
+
+func newMockEdx(t *testing.T) *mockEdx {
+	t.Helper()
+	m := &mockEdx{accounts: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/access_token", m.token)
+	mux.HandleFunc("/api/courses/v1/courses/", m.authorized(m.course))
+	mux.HandleFunc("/api/enrollment/v1/enrollments", m.authorized(m.enrollments))
+	mux.HandleFunc("/api/user/v1/account/registration/", m.authorized(m.registration))
+	mux.HandleFunc("/api/user/v1/accounts", m.authorized(m.accountsByEmail))
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	for key, path := range map[string]string{
+		"api_urls.refreshToken":     "/oauth2/access_token",
+		"api_urls.getCourse":        "/api/courses/v1/courses/",
+		"api_urls.getEnrollment":    "/api/enrollment/v1/enrollments?username=",
+		"api_urls.postRegistration": "/api/user/v1/account/registration/",
+		"api_urls.getAccounts":      "/api/user/v1/accounts?email=",
+	} {
+		viper.Set(key, m.server.URL+path)
+	}
+	return m
+}
+
+func (m *mockEdx) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		writeEdxJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	m.mu.Lock()
+	m.tokens++
+	token := strconv.Itoa(m.tokens)
+	m.mu.Unlock()
+	writeEdxJson(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "JWT",
+		"expires_in":   3600,
+	})
+}
+
+// authorized accepts only the last issued token
+func (m *mockEdx) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		valid := !m.rejectToken && r.Header.Get("Authorization") == "JWT "+strconv.Itoa(m.tokens)
+		m.rejectToken = false
+		m.mu.Unlock()
+		if !valid {
+			writeEdxJson(w, http.StatusUnauthorized, map[string]string{"detail": "invalid token"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (m *mockEdx) course(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/courses/v1/courses/course-v1:robbo+1+2023/" {
+		writeEdxJson(w, http.StatusNotFound, map[string]string{"detail": "not found"})
+		return
+	}
+	writeEdxJson(w, http.StatusOK, map[string]string{"id": "course-v1:robbo+1+2023", "name": "Robotics"})
+}
+
+// enrollments returns a page of one enrollment per request
+func (m *mockEdx) enrollments(w http.ResponseWriter, r *http.Request) {
+	number := r.URL.Query().Get("page")
+	if number == "" {
+		number = "1"
+	}
+	page := map[string]interface{}{
+		"next": nil,
+		"results": []map[string]interface{}{{
+			"course_id": "course-" + number,
+			"is_active": true,
+		}},
+	}
+	if number == "1" {
+		page["next"] = m.server.URL + "/api/enrollment/v1/enrollments?username=" +
+			r.URL.Query().Get("username") + "&page=2"
+	}
+	writeEdxJson(w, http.StatusOK, page)
+}
+
+// registration answers conflict if the email or the username is taken like edX does
+func (m *mockEdx) registration(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeEdxJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	email, username := r.PostForm.Get("email"), r.PostForm.Get("username")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, emailTaken := m.accounts[email]
+	usernameTaken := false
+	for _, existing := range m.accounts {
+		usernameTaken = usernameTaken || existing == username
+	}
+	if emailTaken || usernameTaken {
+		writeEdxJson(w, http.StatusConflict, map[string]string{"error_code": "duplicate-email-username"})
+		return
+	}
+	m.accounts[email] = username
+	writeEdxJson(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (m *mockEdx) accountsByEmail(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	username, ok := m.accounts[r.URL.Query().Get("email")]
+	m.mu.Unlock()
+	if !ok {
+		writeEdxJson(w, http.StatusNotFound, map[string]string{"detail": "not found"})
+		return
+	}
+	writeEdxJson(w, http.StatusOK, []map[string]string{{"username": username}})
+}
+
+func writeEdxJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestEdxGetCourse(t *testing.T) {
+	newMockEdx(t)
+	e := NewEdxGateway()
+	course, err := e.GetCourse("course-v1:robbo+1+2023")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if course.ID != "course-v1:robbo+1+2023" || course.Name != "Robotics" {
+		t.Fatalf("unexpected course %+v", course)
+	}
+	_, err = e.GetCourse("course-v1:robbo+2+2023")
+	if responseError, ok := err.(utils.ResponseError); !ok || responseError.Message != consts.ErrNotFoundInEdx {
+		t.Fatalf("unknown course: %v", err)
+	}
+}
+
+func TestEdxGetEnrollmentsPagination(t *testing.T) {
+	m := newMockEdx(t)
+	enrollments, err := NewEdxGateway().GetEnrollments("robbo_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(enrollments) != 2 || enrollments[0].CourseID != "course-1" || enrollments[1].CourseID != "course-2" {
+		t.Fatalf("unexpected enrollments %+v", enrollments)
+	}
+	// the token is reused by the next page
+	if m.tokens != 1 {
+		t.Fatalf("token is issued %d times, want 1", m.tokens)
+	}
+}
+
+func TestEdxRejectedTokenIsRefreshed(t *testing.T) {
+	m := newMockEdx(t)
+	e := NewEdxGateway()
+	if _, err := e.GetCourse("course-v1:robbo+1+2023"); err != nil {
+		t.Fatal(err)
+	}
+	m.rejectToken = true
+	if _, err := e.GetCourse("course-v1:robbo+1+2023"); err != nil {
+		t.Fatal(err)
+	}
+	if m.tokens != 2 {
+		t.Fatalf("token is issued %d times, want 2", m.tokens)
+	}
+}
+
+func TestEdxPostRegistration(t *testing.T) {
+	m := newMockEdx(t)
+	e := NewEdxGateway()
+	user := models.UserCore{Email: "student@example.com", Firstname: "Ivan", Lastname: "Ivanov"}
+	if err := e.PostRegistration(user, "robbo_1", "password"); err != nil {
+		t.Fatal(err)
+	}
+	if m.accounts[user.Email] != "robbo_1" {
+		t.Fatalf("account is not registered: %v", m.accounts)
+	}
+	// the account of the user already exists
+	if err := e.PostRegistration(user, "robbo_1", "password"); err != nil {
+		t.Fatalf("registration of the existing account: %v", err)
+	}
+}
+
+func TestEdxPostRegistrationUsernameTaken(t *testing.T) {
+	m := newMockEdx(t)
+	m.accounts["old@example.com"] = "robbo_1"
+	user := models.UserCore{Email: "new@example.com"}
+	if err := NewEdxGateway().PostRegistration(user, "robbo_1", "password"); err != nil {
+		t.Fatalf("registration of the account with a changed email: %v", err)
+	}
+}
+
+func TestEdxPostRegistrationEmailTaken(t *testing.T) {
+	m := newMockEdx(t)
+	m.accounts["student@example.com"] = "someone"
+	user := models.UserCore{Email: "student@example.com"}
+	err := NewEdxGateway().PostRegistration(user, "robbo_1", "password")
+	if responseError, ok := err.(utils.ResponseError); !ok || responseError.Message != consts.ErrEdxEmailTaken {
+		t.Fatalf("registration with the email of another account: %v", err)
+	}
+}
@@ -7,19 +7,55 @@ import (
 
 type Gateways struct {
 	fx.Out
-	UserGateway UserGateway
-	ParentRel   ParentRel
-	Project     ProjectGateway
-	ProjectPage ProjectPageGateway
-	Settings    SettingsGateway
+	UserGateway   UserGateway
+	ParentRel     ParentRel
+	Project       ProjectGateway
+	ProjectPage   ProjectPageGateway
+	Settings      SettingsGateway
+	RecoveryCode  RecoveryCodeGateway
+	LoginAttempt  LoginAttemptGateway
+	Registration  RegistrationRequestGateway
+	Oidc          OidcGateway
+	Edx           EdxGateway
+	Cohort        CohortGateway
+	Lti           LtiGateway
+	ParentLink    ParentLinkCodeGateway
+	Activity      ActivityGateway
+	Unit          UnitGateway
+	Group         GroupGateway
+	Assignment    AssignmentGateway
+	TemplateLock  TemplateLockGateway
+	Certificate   CertificateGateway
+	Contest       ContestGateway
+	Team          TeamGateway
+	Clarification ClarificationGateway
+	Notification  NotificationGateway
 }
 
 func SetupGateways(pc db.PostgresClient) Gateways {
 	return Gateways{
-		UserGateway: UserGatewayImpl{pc},
-		ParentRel:   ParentRelGatewayImpl{pc},
-		Project:     ProjectGatewayImpl{pc},
-		ProjectPage: ProjectPageGatewayImpl{pc},
-		Settings:    SettingsGatewayImpl{pc},
+		UserGateway:   UserGatewayImpl{pc},
+		ParentRel:     ParentRelGatewayImpl{pc},
+		Project:       ProjectGatewayImpl{pc},
+		ProjectPage:   ProjectPageGatewayImpl{pc},
+		Settings:      SettingsGatewayImpl{pc},
+		RecoveryCode:  RecoveryCodeGatewayImpl{pc},
+		LoginAttempt:  LoginAttemptGatewayImpl{pc},
+		Registration:  RegistrationRequestGatewayImpl{pc},
+		Oidc:          OidcGatewayImpl{pc},
+		Edx:           NewEdxGateway(),
+		Cohort:        CohortGatewayImpl{pc},
+		Lti:           LtiGatewayImpl{pc},
+		ParentLink:    ParentLinkCodeGatewayImpl{pc},
+		Activity:      ActivityGatewayImpl{pc},
+		Unit:          UnitGatewayImpl{pc},
+		Group:         GroupGatewayImpl{pc},
+		Assignment:    AssignmentGatewayImpl{pc},
+		TemplateLock:  TemplateLockGatewayImpl{pc},
+		Certificate:   CertificateGatewayImpl{pc},
+		Contest:       ContestGatewayImpl{pc},
+		Team:          TeamGatewayImpl{pc},
+		Clarification: ClarificationGatewayImpl{pc},
+		Notification:  NotificationGatewayImpl{pc},
 	}
 }
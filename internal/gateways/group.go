@@ -0,0 +1,270 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+type GroupGateway interface {
+	CreateGroup(group models.GroupCore) (models.GroupCore, error)
+	UpdateGroup(group models.GroupCore) (models.GroupCore, error)
+	DeleteGroup(id uint) error
+	GetGroupById(id uint) (models.GroupCore, error)
+	GetGroupsByUnit(unitId uint, offset, limit int) (groups []models.GroupCore, countRows uint, err error)
+	GetGroupsByTeacher(teacherId uint, offset, limit int) (groups []models.GroupCore, countRows uint, err error)
+	GetGroupsByStudent(studentId uint, offset, limit int) (groups []models.GroupCore, countRows uint, err error)
+	GetTeachers(groupId uint) (teachers []models.UserCore, err error)
+	GetStudents(groupId uint, offset, limit int) (students []models.UserCore, countRows uint, err error)
+	GetStudentIds(groupId uint) (studentIds []uint, err error)
+	AddTeacher(groupId, teacherId uint) error
+	DeleteTeacher(groupId, teacherId uint) error
+	AddStudents(groupId uint, studentIds []uint) error
+	DeleteStudent(groupId, studentId uint) error
+	IsTeacher(groupId, teacherId uint) (bool, error)
+	IsStudent(groupId, studentId uint) (bool, error)
+	DoesTeachUser(teacherId, userId uint) (bool, error)
+}
+
+type GroupGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (g GroupGatewayImpl) CreateGroup(group models.GroupCore) (models.GroupCore, error) {
+	if err := g.postgresClient.Db.Create(&group).Error; err != nil {
+		return models.GroupCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return group, nil
+}
+
+func (g GroupGatewayImpl) UpdateGroup(group models.GroupCore) (models.GroupCore, error) {
+	if err := g.postgresClient.Db.Model(&group).Clauses(clause.Returning{}).
+		Where("id = ?", group.ID).
+		Updates(map[string]interface{}{
+			"name":        group.Name,
+			"description": group.Description,
+		}).Error; err != nil {
+		return models.GroupCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return group, nil
+}
+
+func (g GroupGatewayImpl) DeleteGroup(id uint) error {
+	if err := g.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&models.GroupTeacherCore{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.GroupStudentCore{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.GroupCore{}, id).Error
+	}); err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (g GroupGatewayImpl) GetGroupById(id uint) (group models.GroupCore, err error) {
+	if err := g.postgresClient.Db.First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.GroupCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.GroupCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return group, nil
+}
+
+func (g GroupGatewayImpl) GetGroupsByUnit(unitId uint, offset, limit int) (groups []models.GroupCore, countRows uint, err error) {
+	return g.getGroups(func(db *gorm.DB) *gorm.DB {
+		return db.Where("unit_id = ?", unitId)
+	}, offset, limit)
+}
+
+func (g GroupGatewayImpl) GetGroupsByTeacher(teacherId uint, offset, limit int) (groups []models.GroupCore, countRows uint, err error) {
+	return g.getGroups(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN (?)", g.postgresClient.Db.Model(&models.GroupTeacherCore{}).
+			Select("group_id").Where("user_id = ?", teacherId))
+	}, offset, limit)
+}
+
+func (g GroupGatewayImpl) GetGroupsByStudent(studentId uint, offset, limit int) (groups []models.GroupCore, countRows uint, err error) {
+	return g.getGroups(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN (?)", g.postgresClient.Db.Model(&models.GroupStudentCore{}).
+			Select("group_id").Where("user_id = ?", studentId))
+	}, offset, limit)
+}
+
+// getGroups returns a page of groups matching the filter
+func (g GroupGatewayImpl) getGroups(filter func(db *gorm.DB) *gorm.DB, offset, limit int) (groups []models.GroupCore, countRows uint, err error) {
+	var count int64
+	if err := g.postgresClient.Db.Model(&models.GroupCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.GroupCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := g.postgresClient.Db.Scopes(filter).Order("name").
+		Limit(limit).Offset(offset).Find(&groups).Error; err != nil {
+		return []models.GroupCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return groups, uint(count), nil
+}
+
+func (g GroupGatewayImpl) GetTeachers(groupId uint) (teachers []models.UserCore, err error) {
+	if err := g.postgresClient.Db.Where("id IN (?)", g.postgresClient.Db.Model(&models.GroupTeacherCore{}).
+		Select("user_id").Where("group_id = ?", groupId)).
+		Order("lastname, firstname").Find(&teachers).Error; err != nil {
+		return []models.UserCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return teachers, nil
+}
+
+func (g GroupGatewayImpl) GetStudents(groupId uint, offset, limit int) (students []models.UserCore, countRows uint, err error) {
+	var count int64
+	members := g.postgresClient.Db.Model(&models.GroupStudentCore{}).Select("user_id").Where("group_id = ?", groupId)
+	if err := g.postgresClient.Db.Model(&models.UserCore{}).Where("id IN (?)", members).
+		Count(&count).Error; err != nil {
+		return []models.UserCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := g.postgresClient.Db.Where("id IN (?)", members).Order("lastname, firstname").
+		Limit(limit).Offset(offset).Find(&students).Error; err != nil {
+		return []models.UserCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return students, uint(count), nil
+}
+
+func (g GroupGatewayImpl) GetStudentIds(groupId uint) (studentIds []uint, err error) {
+	if err := g.postgresClient.Db.Model(&models.GroupStudentCore{}).Where("group_id = ?", groupId).
+		Pluck("user_id", &studentIds).Error; err != nil {
+		return []uint{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return studentIds, nil
+}
+
+func (g GroupGatewayImpl) AddTeacher(groupId, teacherId uint) error {
+	if err := g.postgresClient.Db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.GroupTeacherCore{GroupID: groupId, UserID: teacherId}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (g GroupGatewayImpl) DeleteTeacher(groupId, teacherId uint) error {
+	if err := g.postgresClient.Db.Where("group_id = ? AND user_id = ?", groupId, teacherId).
+		Delete(&models.GroupTeacherCore{}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (g GroupGatewayImpl) AddStudents(groupId uint, studentIds []uint) error {
+	if len(studentIds) == 0 {
+		return nil
+	}
+	students := make([]models.GroupStudentCore, 0, len(studentIds))
+	for _, studentId := range studentIds {
+		students = append(students, models.GroupStudentCore{GroupID: groupId, UserID: studentId})
+	}
+	if err := g.postgresClient.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&students).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (g GroupGatewayImpl) DeleteStudent(groupId, studentId uint) error {
+	if err := g.postgresClient.Db.Where("group_id = ? AND user_id = ?", groupId, studentId).
+		Delete(&models.GroupStudentCore{}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (g GroupGatewayImpl) IsTeacher(groupId, teacherId uint) (bool, error) {
+	var count int64
+	if err := g.postgresClient.Db.Model(&models.GroupTeacherCore{}).
+		Where("group_id = ? AND user_id = ?", groupId, teacherId).Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+func (g GroupGatewayImpl) IsStudent(groupId, studentId uint) (bool, error) {
+	var count int64
+	if err := g.postgresClient.Db.Model(&models.GroupStudentCore{}).
+		Where("group_id = ? AND user_id = ?", groupId, studentId).Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+// DoesTeachUser reports whether the user is a student of a group of the teacher
+// or a parent of such a student
+func (g GroupGatewayImpl) DoesTeachUser(teacherId, userId uint) (bool, error) {
+	var count int64
+	if err := g.postgresClient.Db.Table("group_teacher_cores AS t").
+		Joins("JOIN group_student_cores AS s ON s.group_id = t.group_id").
+		Joins("JOIN group_cores ON group_cores.id = t.group_id AND group_cores.deleted_at IS NULL").
+		Where("t.user_id = ?", teacherId).
+		Where("s.user_id = ? OR s.user_id IN (?)", userId,
+			g.postgresClient.Db.Model(&models.ParentRelCore{}).Select("child_id").Where("parent_id = ?", userId)).
+		Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,134 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+type LoginAttemptGateway interface {
+	GetAttempt(key string) (attempt models.LoginAttemptCore, err error)
+	GetAttemptByUnlockToken(unlockToken string) (attempt models.LoginAttemptCore, err error)
+	RegisterFailure(key string, window time.Duration) (attempt models.LoginAttemptCore, err error)
+	Lock(key string, lockedUntil time.Time, unlockToken string) error
+	ResetAttempts(key string) error
+	CreateLockoutEvent(event models.LockoutEventCore) error
+	GetLockoutEvents(offset, limit int) (events []models.LockoutEventCore, countRows uint, err error)
+}
+
+type LoginAttemptGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (l LoginAttemptGatewayImpl) GetAttempt(key string) (attempt models.LoginAttemptCore, err error) {
+	if err := l.postgresClient.Db.Where("attempt_key = ?", key).Take(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LoginAttemptCore{Key: key}, nil
+		}
+		return models.LoginAttemptCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return attempt, nil
+}
+
+func (l LoginAttemptGatewayImpl) GetAttemptByUnlockToken(unlockToken string) (attempt models.LoginAttemptCore, err error) {
+	if err := l.postgresClient.Db.Where("unlock_token = ? AND unlock_token != ''", unlockToken).
+		Take(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LoginAttemptCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+		return models.LoginAttemptCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return attempt, nil
+}
+
+// RegisterFailure increments failures counter, failures older than the window are forgotten
+func (l LoginAttemptGatewayImpl) RegisterFailure(key string, window time.Duration) (attempt models.LoginAttemptCore, err error) {
+	if err := l.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("attempt_key = ?", key).
+			Take(&attempt).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			attempt = models.LoginAttemptCore{Key: key}
+		}
+		if time.Since(attempt.LastFailureAt) > window {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailureAt = time.Now()
+		return tx.Save(&attempt).Error
+	}); err != nil {
+		return models.LoginAttemptCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return attempt, nil
+}
+
+func (l LoginAttemptGatewayImpl) Lock(key string, lockedUntil time.Time, unlockToken string) error {
+	if err := l.postgresClient.Db.Model(&models.LoginAttemptCore{}).Where("attempt_key = ?", key).
+		Updates(map[string]interface{}{
+			"locked_until": lockedUntil,
+			"unlock_token": unlockToken,
+		}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (l LoginAttemptGatewayImpl) ResetAttempts(key string) error {
+	if err := l.postgresClient.Db.Where("attempt_key = ?", key).Delete(&models.LoginAttemptCore{}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (l LoginAttemptGatewayImpl) CreateLockoutEvent(event models.LockoutEventCore) error {
+	if err := l.postgresClient.Db.Create(&event).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (l LoginAttemptGatewayImpl) GetLockoutEvents(offset, limit int) (events []models.LockoutEventCore, countRows uint, err error) {
+	var count int64
+	result := l.postgresClient.Db.Model(&models.LockoutEventCore{}).Count(&count)
+	if result.Error != nil {
+		return []models.LockoutEventCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if err := l.postgresClient.Db.Limit(limit).Offset(offset).Order("created_at desc").
+		Find(&events).Error; err != nil {
+		return []models.LockoutEventCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return events, uint(count), nil
+}
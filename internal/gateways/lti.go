@@ -0,0 +1,124 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+type LtiGateway interface {
+	CreateLaunch(launch models.LtiLaunchCore) (models.LtiLaunchCore, error)
+	TakeLaunch(code string) (models.LtiLaunchCore, error)
+	GetLaunchById(id uint) (models.LtiLaunchCore, error)
+	UpsertGradeLink(gradeLink models.LtiGradeLinkCore) (models.LtiGradeLinkCore, error)
+	GetGradeLinkById(id uint) (models.LtiGradeLinkCore, error)
+	GetGradeLinksByProjectPage(projectPageId uint) ([]models.LtiGradeLinkCore, error)
+}
+
+type LtiGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (l LtiGatewayImpl) CreateLaunch(launch models.LtiLaunchCore) (models.LtiLaunchCore, error) {
+	// expired launches are removed on every new launch
+	if err := l.postgresClient.Db.Where("expires_at < ?", time.Now()).Delete(&models.LtiLaunchCore{}).Error; err != nil {
+		return models.LtiLaunchCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := l.postgresClient.Db.Create(&launch).Error; err != nil {
+		return models.LtiLaunchCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return launch, nil
+}
+
+// TakeLaunch marks the launch used, so its code can be exchanged only once
+func (l LtiGatewayImpl) TakeLaunch(code string) (launch models.LtiLaunchCore, err error) {
+	var launches []models.LtiLaunchCore
+	if err := l.postgresClient.Db.Model(&launches).Clauses(clause.Returning{}).
+		Where("code = ? AND is_used = ? AND expires_at > ?", code, false, time.Now()).
+		Update("is_used", true).Error; err != nil {
+		return models.LtiLaunchCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if len(launches) == 0 {
+		return models.LtiLaunchCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectLtiLaunch,
+		}
+	}
+	return launches[0], nil
+}
+
+func (l LtiGatewayImpl) GetLaunchById(id uint) (launch models.LtiLaunchCore, err error) {
+	if err := l.postgresClient.Db.Where("expires_at > ?", time.Now()).First(&launch, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LtiLaunchCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrIncorrectLtiLaunch,
+			}
+		}
+		return models.LtiLaunchCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return launch, nil
+}
+
+// UpsertGradeLink keeps one grade link per resource link, the line item may change between launches.
+// The owner is set once by the first instructor launch.
+func (l LtiGatewayImpl) UpsertGradeLink(gradeLink models.LtiGradeLinkCore) (models.LtiGradeLinkCore, error) {
+	if err := l.postgresClient.Db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "platform"}, {Name: "resource_link_id"}},
+		DoUpdates: append(clause.AssignmentColumns([]string{"updated_at", "title", "line_item_url", "project_page_id"}),
+			clause.Assignment{
+				Column: clause.Column{Name: "owner_id"},
+				Value:  gorm.Expr("COALESCE(lti_grade_link_cores.owner_id, excluded.owner_id)"),
+			}),
+	}).Create(&gradeLink).Error; err != nil {
+		return models.LtiGradeLinkCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return gradeLink, nil
+}
+
+func (l LtiGatewayImpl) GetGradeLinkById(id uint) (gradeLink models.LtiGradeLinkCore, err error) {
+	if err := l.postgresClient.Db.First(&gradeLink, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LtiGradeLinkCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.LtiGradeLinkCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return gradeLink, nil
+}
+
+func (l LtiGatewayImpl) GetGradeLinksByProjectPage(projectPageId uint) (gradeLinks []models.LtiGradeLinkCore, err error) {
+	if err := l.postgresClient.Db.Where("project_page_id = ?", projectPageId).Find(&gradeLinks).Error; err != nil {
+		return []models.LtiGradeLinkCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return gradeLinks, nil
+}
@@ -0,0 +1,138 @@
+package gateways
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+type NotificationGateway interface {
+	CreateNotification(notification models.NotificationCore) (models.NotificationCore, error)
+	GetNotificationsByUser(userId uint, unreadOnly bool, offset, limit int) (
+		notifications []models.NotificationCore, countRows uint, err error)
+	CountUnread(userId uint) (uint, error)
+	MarkRead(userId uint, ids []uint) error
+	MarkAllRead(userId uint) error
+	GetPreferences(userId uint) ([]models.NotificationPreferenceCore, error)
+	GetPreference(userId uint, kind models.NotificationKind) (preference models.NotificationPreferenceCore, ok bool, err error)
+	SetPreference(preference models.NotificationPreferenceCore) (models.NotificationPreferenceCore, error)
+}
+
+type NotificationGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (n NotificationGatewayImpl) CreateNotification(notification models.NotificationCore) (models.NotificationCore, error) {
+	if err := n.postgresClient.Db.Create(&notification).Error; err != nil {
+		return models.NotificationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return notification, nil
+}
+
+func (n NotificationGatewayImpl) GetNotificationsByUser(userId uint, unreadOnly bool, offset, limit int) (
+	notifications []models.NotificationCore, countRows uint, err error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("user_id = ?", userId)
+		if unreadOnly {
+			db = db.Where("read_at IS NULL")
+		}
+		return db
+	}
+	var count int64
+	if err := n.postgresClient.Db.Model(&models.NotificationCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.NotificationCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := n.postgresClient.Db.Scopes(filter).Order("created_at DESC, id DESC").
+		Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		return []models.NotificationCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return notifications, uint(count), nil
+}
+
+func (n NotificationGatewayImpl) CountUnread(userId uint) (uint, error) {
+	var count int64
+	if err := n.postgresClient.Db.Model(&models.NotificationCore{}).
+		Where("user_id = ? AND read_at IS NULL", userId).Count(&count).Error; err != nil {
+		return 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return uint(count), nil
+}
+
+// MarkRead marks notifications of the user, ids of notifications of other users are ignored
+func (n NotificationGatewayImpl) MarkRead(userId uint, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := n.postgresClient.Db.Model(&models.NotificationCore{}).
+		Where("user_id = ? AND id IN ? AND read_at IS NULL", userId, ids).
+		Update("read_at", gorm.Expr("NOW()")).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (n NotificationGatewayImpl) MarkAllRead(userId uint) error {
+	if err := n.postgresClient.Db.Model(&models.NotificationCore{}).
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Update("read_at", gorm.Expr("NOW()")).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (n NotificationGatewayImpl) GetPreferences(userId uint) (preferences []models.NotificationPreferenceCore, err error) {
+	if err := n.postgresClient.Db.Where("user_id = ?", userId).Find(&preferences).Error; err != nil {
+		return []models.NotificationPreferenceCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return preferences, nil
+}
+
+func (n NotificationGatewayImpl) GetPreference(userId uint, kind models.NotificationKind) (
+	preference models.NotificationPreferenceCore, ok bool, err error) {
+	result := n.postgresClient.Db.Where("user_id = ? AND kind = ?", userId, kind).Limit(1).Find(&preference)
+	if result.Error != nil {
+		return models.NotificationPreferenceCore{}, false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	return preference, result.RowsAffected > 0, nil
+}
+
+func (n NotificationGatewayImpl) SetPreference(preference models.NotificationPreferenceCore) (
+	models.NotificationPreferenceCore, error) {
+	if err := n.postgresClient.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email"}),
+	}).Create(&preference).Error; err != nil {
+		return models.NotificationPreferenceCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return preference, nil
+}
@@ -0,0 +1,98 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+type OidcGateway interface {
+	CreateState(state models.OidcStateCore) error
+	TakeState(state string) (models.OidcStateCore, error)
+	GetIdentity(provider, subject string) (identity models.UserIdentityCore, exist bool, err error)
+	CreateIdentity(identity models.UserIdentityCore) error
+	GetIdentityByUser(provider string, userId uint) (identity models.UserIdentityCore, exist bool, err error)
+}
+
+type OidcGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (o OidcGatewayImpl) CreateState(state models.OidcStateCore) error {
+	if err := o.postgresClient.Db.Create(&state).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+// TakeState deletes the state, so it can be used only once. Expired states are removed too.
+func (o OidcGatewayImpl) TakeState(state string) (stateCore models.OidcStateCore, err error) {
+	if err := o.postgresClient.Db.Where("expires_at < ?", time.Now()).Delete(&models.OidcStateCore{}).Error; err != nil {
+		return models.OidcStateCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	var states []models.OidcStateCore
+	if err := o.postgresClient.Db.Clauses(clause.Returning{}).Where("state = ?", state).
+		Delete(&states).Error; err != nil {
+		return models.OidcStateCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if len(states) == 0 {
+		return models.OidcStateCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectOidcState,
+		}
+	}
+	return states[0], nil
+}
+
+func (o OidcGatewayImpl) GetIdentity(provider, subject string) (identity models.UserIdentityCore, exist bool, err error) {
+	if err := o.postgresClient.Db.Preload("User").Where("provider = ? AND subject = ?", provider, subject).
+		Take(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.UserIdentityCore{}, false, nil
+		}
+		return models.UserIdentityCore{}, false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return identity, true, nil
+}
+
+func (o OidcGatewayImpl) CreateIdentity(identity models.UserIdentityCore) error {
+	if err := o.postgresClient.Db.Create(&identity).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (o OidcGatewayImpl) GetIdentityByUser(provider string, userId uint) (identity models.UserIdentityCore, exist bool, err error) {
+	if err := o.postgresClient.Db.Where("provider = ? AND user_id = ?", provider, userId).
+		Take(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.UserIdentityCore{}, false, nil
+		}
+		return models.UserIdentityCore{}, false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return identity, true, nil
+}
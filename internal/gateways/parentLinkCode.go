@@ -0,0 +1,187 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+type ParentLinkCodeGateway interface {
+	CreateCode(code models.ParentLinkCodeCore) (models.ParentLinkCodeCore, error)
+	GetCodeById(id uint) (models.ParentLinkCodeCore, error)
+	GetCodesByUser(userId uint, offset, limit int) (codes []models.ParentLinkCodeCore, countRows uint, err error)
+	RevokeCode(id uint) error
+	RedeemCode(code string, parentId uint) (models.ParentLinkCodeCore, error)
+	ApproveCode(id uint) error
+	RejectCode(id uint) error
+}
+
+type ParentLinkCodeGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (p ParentLinkCodeGatewayImpl) CreateCode(code models.ParentLinkCodeCore) (models.ParentLinkCodeCore, error) {
+	if err := p.postgresClient.Db.Create(&code).Error; err != nil {
+		return models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return p.GetCodeById(code.ID)
+}
+
+func (p ParentLinkCodeGatewayImpl) GetCodeById(id uint) (code models.ParentLinkCodeCore, err error) {
+	if err := p.postgresClient.Db.Preload("Child").Preload("RedeemedBy").First(&code, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ParentLinkCodeCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return code, nil
+}
+
+// GetCodesByUser returns codes of the student and codes created by the user for students
+func (p ParentLinkCodeGatewayImpl) GetCodesByUser(userId uint, offset, limit int) (
+	codes []models.ParentLinkCodeCore, countRows uint, err error) {
+	var count int64
+	result := p.postgresClient.Db.Model(&models.ParentLinkCodeCore{}).
+		Where("child_id = ? OR created_by_id = ?", userId, userId).Count(&count)
+	if result.Error != nil {
+		return []models.ParentLinkCodeCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if err := p.postgresClient.Db.Preload("Child").Preload("RedeemedBy").
+		Where("child_id = ? OR created_by_id = ?", userId, userId).
+		Limit(limit).Offset(offset).Order("created_at desc").Find(&codes).Error; err != nil {
+		return []models.ParentLinkCodeCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return codes, uint(count), nil
+}
+
+// RevokeCode revokes the code, which is not redeemed yet or waits for the approval
+func (p ParentLinkCodeGatewayImpl) RevokeCode(id uint) error {
+	return p.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		_, err := p.takeCode(tx, models.ParentLinkCodeStatusRevoked, nil, "id = ? AND status IN ?", id,
+			[]models.ParentLinkCodeStatus{models.ParentLinkCodeStatusActive, models.ParentLinkCodeStatusPendingApproval})
+		return err
+	})
+}
+
+// RedeemCode creates the parent relationship at once or leaves the code waiting for the approval
+// of the student. Expired codes can not be redeemed.
+func (p ParentLinkCodeGatewayImpl) RedeemCode(code string, parentId uint) (codeCore models.ParentLinkCodeCore, err error) {
+	err = p.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		var codes []models.ParentLinkCodeCore
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ? AND status = ? AND expires_at > ?", code, models.ParentLinkCodeStatusActive, time.Now()).
+			Find(&codes).Error; err != nil {
+			return utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		if len(codes) == 0 {
+			return utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrIncorrectParentLinkCode,
+			}
+		}
+		var count int64
+		if err := tx.Model(&models.ParentRelCore{}).
+			Where("parent_id = ? AND child_id = ?", parentId, codes[0].ChildID).Count(&count).Error; err != nil {
+			return utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		if count > 0 {
+			return utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrParentRelAlreadyExists,
+			}
+		}
+		status := models.ParentLinkCodeStatusRedeemed
+		if codes[0].RequireApproval {
+			status = models.ParentLinkCodeStatusPendingApproval
+		}
+		codeCore, err = p.takeCode(tx, status, &parentId, "id = ?", codes[0].ID)
+		return err
+	})
+	if err != nil {
+		return models.ParentLinkCodeCore{}, err
+	}
+	return p.GetCodeById(codeCore.ID)
+}
+
+// ApproveCode creates the parent relationship of the code waiting for the approval
+func (p ParentLinkCodeGatewayImpl) ApproveCode(id uint) error {
+	return p.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		_, err := p.takeCode(tx, models.ParentLinkCodeStatusRedeemed, nil,
+			"id = ? AND status = ?", id, models.ParentLinkCodeStatusPendingApproval)
+		return err
+	})
+}
+
+func (p ParentLinkCodeGatewayImpl) RejectCode(id uint) error {
+	return p.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		_, err := p.takeCode(tx, models.ParentLinkCodeStatusRejected, nil,
+			"id = ? AND status = ?", id, models.ParentLinkCodeStatusPendingApproval)
+		return err
+	})
+}
+
+// takeCode moves the code found by the condition to the status, the relationship
+// is created when the code becomes redeemed
+func (p ParentLinkCodeGatewayImpl) takeCode(tx *gorm.DB, status models.ParentLinkCodeStatus, redeemedById *uint,
+	query string, args ...interface{}) (models.ParentLinkCodeCore, error) {
+	updates := map[string]interface{}{
+		"status": status,
+	}
+	if redeemedById != nil {
+		updates["redeemed_by_id"] = *redeemedById
+		updates["redeemed_at"] = time.Now()
+	}
+	var codes []models.ParentLinkCodeCore
+	if err := tx.Model(&codes).Clauses(clause.Returning{}).Where(query, args...).Updates(updates).Error; err != nil {
+		return models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if len(codes) == 0 {
+		return models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrParentLinkCodeUnavailable,
+		}
+	}
+	if status == models.ParentLinkCodeStatusRedeemed {
+		// the parent may be linked by an admin while the code waits for the approval
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ParentRelCore{
+			ParentID: *codes[0].RedeemedByID,
+			ChildID:  codes[0].ChildID,
+		}).Error; err != nil {
+			return models.ParentLinkCodeCore{}, utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+	}
+	return codes[0], nil
+}
@@ -1,16 +1,21 @@
 package gateways
 
 import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/db"
 	"github.com/skinnykaen/rpa_clone/internal/models"
-	"gorm.io/gorm/clause"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"net/http"
 )
 
 //TODO maybe return []student or []parent а не rels
 
 type ParentRel interface {
 	CreateRel(parentId, childId uint) (models.ParentRelCore, error)
-	DeleteRel(id uint) (err error)
+	DeleteRel(parentId, childId uint) (err error)
+	DoesExistRel(parentId, childId uint) (bool, error)
 	GetRelsByParentId(parentId uint) (rels []models.ParentRelCore, err error)
 	GetRelsByChildId(childId uint) (rels []models.ParentRelCore, err error)
 }
@@ -24,20 +29,65 @@ func (p ParentRelGatewayImpl) CreateRel(parentId, childId uint) (models.ParentRe
 		ParentID: parentId,
 		ChildID:  childId,
 	}
-	result := p.postgresClient.Db.Create(&rel).Clauses(clause.Returning{})
-	return rel, result.Error
+	if err := p.postgresClient.Db.Create(&rel).Error; err != nil {
+		return models.ParentRelCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return rel, nil
+}
+
+func (p ParentRelGatewayImpl) DeleteRel(parentId, childId uint) (err error) {
+	result := p.postgresClient.Db.Where("parent_id = ? AND child_id = ?", parentId, childId).
+		Delete(&models.ParentRelCore{})
+	if result.Error != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if result.RowsAffected == 0 {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrNotFoundInDB,
+		}
+	}
+	return nil
 }
 
-func (p ParentRelGatewayImpl) DeleteRel(id uint) (err error) {
-	return p.postgresClient.Db.Delete(&models.ParentRelCore{}, id).Error
+func (p ParentRelGatewayImpl) DoesExistRel(parentId, childId uint) (bool, error) {
+	if err := p.postgresClient.Db.Where("parent_id = ? AND child_id = ?", parentId, childId).
+		Take(&models.ParentRelCore{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return true, nil
 }
 
 func (p ParentRelGatewayImpl) GetRelsByParentId(parentId uint) (rels []models.ParentRelCore, err error) {
-	result := p.postgresClient.Db.Where(models.ParentRelCore{ParentID: parentId}).Find(&rels)
-	return rels, result.Error
+	if err := p.postgresClient.Db.Preload("Child").Where("parent_id = ?", parentId).
+		Order("id").Find(&rels).Error; err != nil {
+		return []models.ParentRelCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return rels, nil
 }
 
-func (p ParentRelGatewayImpl) GetRelsByChildId(childId uint) ([]models.ParentRelCore, error) {
-	//TODO implement me
-	panic("implement me")
+func (p ParentRelGatewayImpl) GetRelsByChildId(childId uint) (rels []models.ParentRelCore, err error) {
+	if err := p.postgresClient.Db.Preload("Parent").Where("child_id = ?", childId).
+		Order("id").Find(&rels).Error; err != nil {
+		return []models.ParentRelCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return rels, nil
 }
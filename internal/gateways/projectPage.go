@@ -1,6 +1,8 @@
 package gateways
 
 import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/db"
 	"github.com/skinnykaen/rpa_clone/internal/models"
 	"github.com/skinnykaen/rpa_clone/pkg/utils"
@@ -16,10 +18,16 @@ type ProjectPageGateway interface {
 	DeleteProjectPage(id, clientId uint) error
 	GetAllProjectPages(offset, limit int) (projectPages []models.ProjectPageCore, countRows uint, err error)
 	GetProjectPagesByAuthorId(id uint, offset, limit int) (projectPages []models.ProjectPageCore, countRows uint, err error)
+	GetLatestProjectPagesByAuthorId(id uint, limit int) (projectPages []models.ProjectPageCore, countRows uint, err error)
+	GetProjectPagesByGroupId(groupId uint, offset, limit int) (projectPages []models.ProjectPageCore, countRows uint, err error)
 	UpdateProjectPage(projectPage models.ProjectPageCore) (updatedProjectPage models.ProjectPageCore, err error)
 	GetProjectPageById(id uint) (projectPage models.ProjectPageCore, err error)
+	GetProjectPageByProjectId(projectId uint) (projectPage models.ProjectPageCore, err error)
+	GetTemplates(clientId uint, offset, limit int) (projectPages []models.ProjectPageCore, countRows uint, err error)
+	ExportProjectPages(unitIds []uint, batchSize int, write func(projectPages []models.ProjectPageCore) error) error
 	SetIsShared(id uint, isShared bool) error
 	SetIsBanned(id uint, isBanned bool) error
+	SetIsTemplate(id uint, isTemplate bool) error
 }
 
 type ProjectPageGatewayImpl struct {
@@ -54,6 +62,50 @@ func (p ProjectPageGatewayImpl) SetIsBanned(id uint, isBanned bool) error {
 	return nil
 }
 
+// SetIsTemplate marks the project page as a template, the locks are removed together with the mark
+func (p ProjectPageGatewayImpl) SetIsTemplate(id uint, isTemplate bool) error {
+	if err := p.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ProjectPageCore{}).Where("id = ?", id).
+			Update("is_template", isTemplate).Error; err != nil {
+			return err
+		}
+		if isTemplate {
+			return nil
+		}
+		return tx.Where("project_page_id = ?", id).Delete(&models.TemplateLockCore{}).Error
+	}); err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+// GetTemplates returns not banned templates shared with everyone or created by the client
+func (p ProjectPageGatewayImpl) GetTemplates(clientId uint, offset, limit int) (
+	projectPages []models.ProjectPageCore, countRows uint, err error) {
+	var count int64
+	filter := func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_template = ? AND is_banned = ? AND (is_shared = ? OR author_id = ?)",
+			true, false, true, clientId)
+	}
+	if err := p.postgresClient.Db.Model(&models.ProjectPageCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.ProjectPageCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := p.postgresClient.Db.Preload("Project").Scopes(filter).Order("updated_at desc").
+		Limit(limit).Offset(offset).Find(&projectPages).Error; err != nil {
+		return []models.ProjectPageCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return projectPages, uint(count), nil
+}
+
 func (p ProjectPageGatewayImpl) GetProjectPagesByAuthorId(id uint, offset, limit int) (projectPages []models.ProjectPageCore, countRows uint, err error) {
 	var count int64
 	result := p.postgresClient.Db.Limit(limit).Offset(offset).Where("author_id = ? AND is_banned = ?", id, false).
@@ -68,6 +120,51 @@ func (p ProjectPageGatewayImpl) GetProjectPagesByAuthorId(id uint, offset, limit
 	return projectPages, uint(count), result.Error
 }
 
+// GetLatestProjectPagesByAuthorId returns recently updated project pages of the author including banned ones
+func (p ProjectPageGatewayImpl) GetLatestProjectPagesByAuthorId(id uint, limit int) (
+	projectPages []models.ProjectPageCore, countRows uint, err error) {
+	var count int64
+	result := p.postgresClient.Db.Model(&models.ProjectPageCore{}).Where("author_id = ?", id).Count(&count)
+	if result.Error != nil {
+		return []models.ProjectPageCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if err := p.postgresClient.Db.Preload("Project").Where("author_id = ?", id).
+		Order("updated_at desc").Limit(limit).Find(&projectPages).Error; err != nil {
+		return []models.ProjectPageCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return projectPages, uint(count), nil
+}
+
+// GetProjectPagesByGroupId returns not banned project pages of students of the group
+func (p ProjectPageGatewayImpl) GetProjectPagesByGroupId(groupId uint, offset, limit int) (
+	projectPages []models.ProjectPageCore, countRows uint, err error) {
+	var count int64
+	filter := func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_banned = ? AND author_id IN (?)", false,
+			p.postgresClient.Db.Model(&models.GroupStudentCore{}).Select("user_id").Where("group_id = ?", groupId))
+	}
+	if err := p.postgresClient.Db.Model(&models.ProjectPageCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.ProjectPageCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := p.postgresClient.Db.Preload("Project").Scopes(filter).Order("updated_at desc").
+		Limit(limit).Offset(offset).Find(&projectPages).Error; err != nil {
+		return []models.ProjectPageCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return projectPages, uint(count), nil
+}
+
 func (p ProjectPageGatewayImpl) GetAllProjectPages(offset, limit int) (projectPages []models.ProjectPageCore, countRows uint, err error) {
 	var count int64
 	result := p.postgresClient.Db.Limit(limit).Offset(offset).Find(&projectPages).Preload("Project")
@@ -81,28 +178,36 @@ func (p ProjectPageGatewayImpl) GetAllProjectPages(offset, limit int) (projectPa
 	return projectPages, uint(count), result.Error
 }
 
+// ExportProjectPages passes project pages with their authors to the write function in batches,
+// nil unit ids do not limit project pages, otherwise only pages of members of the units are exported
+func (p ProjectPageGatewayImpl) ExportProjectPages(unitIds []uint, batchSize int,
+	write func(projectPages []models.ProjectPageCore) error) error {
+	var projectPages []models.ProjectPageCore
+	db := p.postgresClient.Db.Preload("User").Preload("Project", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "updated_at")
+	})
+	if unitIds != nil {
+		db = db.Where("author_id IN (?)", p.postgresClient.Db.Model(&models.UnitMemberCore{}).
+			Select("user_id").Where("unit_id IN ?", unitIds))
+	}
+	if err := db.Order("id").FindInBatches(&projectPages, batchSize, func(tx *gorm.DB, batch int) error {
+		return write(projectPages)
+	}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
 func (p ProjectPageGatewayImpl) SetIsShared(id uint, isShared bool) error {
 	return p.postgresClient.Db.Where(&models.ProjectPageCore{}, id).Update("is_shared", isShared).Error
 }
 
 func (p ProjectPageGatewayImpl) CreateProjectPage(projectPage models.ProjectPageCore, project models.ProjectCore) (models.ProjectPageCore, error) {
 	if err := p.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(&project).Clauses(clause.Returning{}).Error; err != nil {
-			return utils.ResponseError{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			}
-		}
-		projectPage.Project = project
-		projectPage.LinkToScratch = viper.GetString("project_page_scratch_link") +
-			"?#" + strconv.FormatUint(uint64(project.ID), 10)
-		if err := tx.Create(&projectPage).Clauses(clause.Returning{}).Error; err != nil {
-			return utils.ResponseError{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			}
-		}
-		return nil
+		return createProjectPage(tx, &projectPage, project)
 	}); err != nil {
 		return models.ProjectPageCore{}, utils.ResponseError{
 			Code:    http.StatusInternalServerError,
@@ -183,3 +288,40 @@ func (p ProjectPageGatewayImpl) GetProjectPageById(id uint) (projectPage models.
 	}
 	return projectPage, nil
 }
+
+func (p ProjectPageGatewayImpl) GetProjectPageByProjectId(projectId uint) (projectPage models.ProjectPageCore, err error) {
+	if err = p.postgresClient.Db.Where("project_id = ?", projectId).First(&projectPage).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return projectPage, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return projectPage, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return projectPage, nil
+}
+
+// createProjectPage creates the project and its page in the transaction
+func createProjectPage(tx *gorm.DB, projectPage *models.ProjectPageCore, project models.ProjectCore) error {
+	if err := tx.Create(&project).Clauses(clause.Returning{}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	projectPage.ProjectID = project.ID
+	projectPage.Project = project
+	projectPage.LinkToScratch = viper.GetString("project_page_scratch_link") +
+		"?#" + strconv.FormatUint(uint64(project.ID), 10)
+	if err := tx.Create(projectPage).Clauses(clause.Returning{}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+type RecoveryCodeGateway interface {
+	ReplaceRecoveryCodes(userId uint, codeHashes []string) error
+	UseRecoveryCode(userId uint, codeHash string) (used bool, err error)
+	DeleteRecoveryCodes(userId uint) error
+}
+
+type RecoveryCodeGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (r RecoveryCodeGatewayImpl) ReplaceRecoveryCodes(userId uint, codeHashes []string) error {
+	if err := r.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCodeCore{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCodeCore, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			codes = append(codes, models.RecoveryCodeCore{UserID: userId, CodeHash: codeHash})
+		}
+		return tx.Create(&codes).Error
+	}); err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (r RecoveryCodeGatewayImpl) UseRecoveryCode(userId uint, codeHash string) (used bool, err error) {
+	var code models.RecoveryCodeCore
+	if err := r.postgresClient.Db.Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Take(&code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	result := r.postgresClient.Db.Model(&code).Where("used_at IS NULL").Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r RecoveryCodeGatewayImpl) DeleteRecoveryCodes(userId uint) error {
+	if err := r.postgresClient.Db.Where("user_id = ?", userId).Delete(&models.RecoveryCodeCore{}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
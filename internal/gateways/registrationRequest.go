@@ -0,0 +1,109 @@
+package gateways
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+type RegistrationRequestGateway interface {
+	CreateRegistrationRequest(request models.RegistrationRequestCore) error
+	GetPendingRegistrationRequests(offset, limit int) (requests []models.RegistrationRequestCore, countRows uint, err error)
+	ApproveRegistrationRequests(ids []uint, reason string, decidedById uint) (requests []models.RegistrationRequestCore, err error)
+	RejectRegistrationRequests(ids []uint, reason string, decidedById uint) (requests []models.RegistrationRequestCore, err error)
+}
+
+type RegistrationRequestGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (r RegistrationRequestGatewayImpl) CreateRegistrationRequest(request models.RegistrationRequestCore) error {
+	if err := r.postgresClient.Db.Create(&request).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (r RegistrationRequestGatewayImpl) GetPendingRegistrationRequests(offset, limit int) (
+	requests []models.RegistrationRequestCore, countRows uint, err error) {
+	var count int64
+	result := r.postgresClient.Db.Model(&models.RegistrationRequestCore{}).
+		Where("status = ?", models.RegistrationStatusPending).Count(&count)
+	if result.Error != nil {
+		return []models.RegistrationRequestCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if err := r.postgresClient.Db.Preload("User").Where("status = ?", models.RegistrationStatusPending).
+		Limit(limit).Offset(offset).Order("created_at").Find(&requests).Error; err != nil {
+		return []models.RegistrationRequestCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return requests, uint(count), nil
+}
+
+// ApproveRegistrationRequests activates users of the pending requests. Requests which are not
+// pending anymore are skipped, so only the decided requests are returned.
+func (r RegistrationRequestGatewayImpl) ApproveRegistrationRequests(ids []uint, reason string, decidedById uint) (
+	requests []models.RegistrationRequestCore, err error) {
+	return r.decide(ids, models.RegistrationStatusApproved, reason, decidedById, func(tx *gorm.DB, userIds []uint) error {
+		return tx.Model(&models.UserCore{}).Where("id IN ?", userIds).Updates(map[string]interface{}{
+			"is_active":       true,
+			"activation_link": "",
+		}).Error
+	})
+}
+
+// RejectRegistrationRequests deletes users of the pending requests, so the email can be used again
+func (r RegistrationRequestGatewayImpl) RejectRegistrationRequests(ids []uint, reason string, decidedById uint) (
+	requests []models.RegistrationRequestCore, err error) {
+	return r.decide(ids, models.RegistrationStatusRejected, reason, decidedById, func(tx *gorm.DB, userIds []uint) error {
+		return tx.Where("id IN ?", userIds).Delete(&models.UserCore{}).Error
+	})
+}
+
+func (r RegistrationRequestGatewayImpl) decide(ids []uint, status models.RegistrationStatus, reason string,
+	decidedById uint, updateUsers func(tx *gorm.DB, userIds []uint) error) (requests []models.RegistrationRequestCore, err error) {
+	if err := r.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("User").
+			Where("id IN ? AND status = ?", ids, models.RegistrationStatusPending).
+			Find(&requests).Error; err != nil {
+			return err
+		}
+		if len(requests) == 0 {
+			return nil
+		}
+		requestIds := make([]uint, 0, len(requests))
+		userIds := make([]uint, 0, len(requests))
+		for _, request := range requests {
+			requestIds = append(requestIds, request.ID)
+			userIds = append(userIds, request.UserID)
+		}
+		if err := tx.Model(&models.RegistrationRequestCore{}).Where("id IN ?", requestIds).
+			Updates(map[string]interface{}{
+				"status":        status,
+				"reason":        reason,
+				"decided_by_id": decidedById,
+				"decided_at":    time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+		return updateUsers(tx, userIds)
+	}); err != nil {
+		return []models.RegistrationRequestCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return requests, nil
+}
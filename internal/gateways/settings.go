@@ -4,12 +4,17 @@ import (
 	"github.com/skinnykaen/rpa_clone/internal/db"
 	"github.com/skinnykaen/rpa_clone/internal/models"
 	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm/clause"
 	"net/http"
 )
 
 type SettingsGateway interface {
 	SetActivationByLink(activationByCode bool) error
 	GetActivationByLink() (activationByCode bool, err error)
+	SetTwoFactorRequired(role models.Role, required bool) error
+	GetTwoFactorRequiredRoles() (roles []models.Role, err error)
+	GetPasswordPolicy() (policy models.PasswordPolicyCore, err error)
+	SetPasswordPolicy(policy models.PasswordPolicyCore) error
 }
 
 type SettingsGatewayImpl struct {
@@ -32,3 +37,55 @@ func (s SettingsGatewayImpl) SetActivationByLink(activationByCode bool) error {
 		"activation_by_link": activationByCode,
 	}).Error
 }
+
+func (s SettingsGatewayImpl) SetTwoFactorRequired(role models.Role, required bool) error {
+	if err := s.postgresClient.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"two_factor_required"}),
+	}).Create(&models.RoleSettingsCore{Role: role, TwoFactorRequired: required}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (s SettingsGatewayImpl) GetTwoFactorRequiredRoles() (roles []models.Role, err error) {
+	if err := s.postgresClient.Db.Model(&models.RoleSettingsCore{}).Where("two_factor_required = ?", true).
+		Pluck("role", &roles).Error; err != nil {
+		return []models.Role{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return roles, nil
+}
+
+func (s SettingsGatewayImpl) GetPasswordPolicy() (policy models.PasswordPolicyCore, err error) {
+	var settings models.SettingsCore
+	if err := s.postgresClient.Db.First(&settings, 1).Error; err != nil {
+		return models.PasswordPolicyCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return settings.PasswordPolicy, nil
+}
+
+func (s SettingsGatewayImpl) SetPasswordPolicy(policy models.PasswordPolicyCore) error {
+	if err := s.postgresClient.Db.Model(&models.SettingsCore{ID: 1}).Updates(map[string]interface{}{
+		"password_min_length":        policy.MinLength,
+		"password_require_uppercase": policy.RequireUppercase,
+		"password_require_lowercase": policy.RequireLowercase,
+		"password_require_digit":     policy.RequireDigit,
+		"password_require_special":   policy.RequireSpecial,
+		"password_forbid_common":     policy.ForbidCommon,
+	}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+type TeamGateway interface {
+	CreateTeam(team models.TeamCore, projectPage models.ProjectPageCore, project models.ProjectCore) (models.TeamCore, error)
+	DeleteTeam(id uint) error
+	GetTeamById(id uint) (models.TeamCore, error)
+	GetTeamByMember(contestId, userId uint) (models.TeamCore, error)
+	GetTeamsByContest(contestId uint, offset, limit int) (teams []models.TeamCore, countRows uint, err error)
+	GetTeamsByUser(userId uint, offset, limit int) (teams []models.TeamCore, countRows uint, err error)
+	CreateInvitation(teamId, userId uint) (models.TeamInvitationCore, error)
+	DeleteInvitation(id uint) error
+	GetInvitationById(id uint) (models.TeamInvitationCore, error)
+	GetInvitationsByTeam(teamId uint) ([]models.TeamInvitationCore, error)
+	GetInvitationsByUser(userId uint, offset, limit int) (invitations []models.TeamInvitationCore, countRows uint, err error)
+	AcceptInvitation(invitation models.TeamInvitationCore, maxTeamSize int) (models.TeamCore, error)
+	DeleteMember(teamId, userId uint) error
+	SubmitTeam(id uint) (models.TeamCore, error)
+	SetResult(id uint, score *float64, place *int) (models.TeamCore, error)
+	IsTeamMember(projectId, userId uint) (bool, error)
+	IsSubmittedTeamProject(projectId uint) (bool, error)
+	IsTeamProjectPage(projectPageId uint) (bool, error)
+	GetLargestTeamSize(contestId uint) (size int, err error)
+}
+
+type TeamGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+// CreateTeam creates the team with its shared project, the captain becomes the first member
+// and invitations of the captain to other teams of the contest are dropped
+func (t TeamGatewayImpl) CreateTeam(team models.TeamCore, projectPage models.ProjectPageCore, project models.ProjectCore) (
+	models.TeamCore, error) {
+	if err := t.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := createProjectPage(tx, &projectPage, project); err != nil {
+			return err
+		}
+		team.ProjectPageID = projectPage.ID
+		if err := tx.Omit(clause.Associations).Create(&team).Error; err != nil {
+			return err
+		}
+		return addTeamMember(tx, team, team.CaptainID)
+	}); err != nil {
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return t.GetTeamById(team.ID)
+}
+
+func (t TeamGatewayImpl) DeleteTeam(id uint) error {
+	if err := t.postgresClient.Db.Delete(&models.TeamCore{}, id).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (t TeamGatewayImpl) GetTeamById(id uint) (models.TeamCore, error) {
+	return t.getTeam(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ?", id)
+	})
+}
+
+func (t TeamGatewayImpl) GetTeamByMember(contestId, userId uint) (models.TeamCore, error) {
+	return t.getTeam(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN (?)", t.postgresClient.Db.Model(&models.TeamMemberCore{}).Select("team_id").
+			Where("contest_id = ? AND user_id = ?", contestId, userId))
+	})
+}
+
+func (t TeamGatewayImpl) getTeam(filter func(db *gorm.DB) *gorm.DB) (team models.TeamCore, err error) {
+	if err := t.postgresClient.Db.Scopes(preloadTeam, filter).Take(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.TeamCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return team, nil
+}
+
+// GetTeamsByContest returns teams ordered by their places, teams without results go last
+func (t TeamGatewayImpl) GetTeamsByContest(contestId uint, offset, limit int) (
+	teams []models.TeamCore, countRows uint, err error) {
+	return t.getTeams(func(db *gorm.DB) *gorm.DB {
+		return db.Where("contest_id = ?", contestId)
+	}, "place ASC NULLS LAST, name", offset, limit)
+}
+
+func (t TeamGatewayImpl) GetTeamsByUser(userId uint, offset, limit int) (teams []models.TeamCore, countRows uint, err error) {
+	return t.getTeams(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN (?)",
+			t.postgresClient.Db.Model(&models.TeamMemberCore{}).Select("team_id").Where("user_id = ?", userId))
+	}, "created_at DESC", offset, limit)
+}
+
+func (t TeamGatewayImpl) getTeams(filter func(db *gorm.DB) *gorm.DB, order string, offset, limit int) (
+	teams []models.TeamCore, countRows uint, err error) {
+	var count int64
+	if err := t.postgresClient.Db.Model(&models.TeamCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.TeamCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := t.postgresClient.Db.Scopes(preloadTeam, filter).Order(order).
+		Limit(limit).Offset(offset).Find(&teams).Error; err != nil {
+		return []models.TeamCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return teams, uint(count), nil
+}
+
+func (t TeamGatewayImpl) CreateInvitation(teamId, userId uint) (models.TeamInvitationCore, error) {
+	invitation := models.TeamInvitationCore{
+		TeamID: teamId,
+		UserID: userId,
+	}
+	if err := t.postgresClient.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&invitation).Error; err != nil {
+		return models.TeamInvitationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return t.getInvitation(func(db *gorm.DB) *gorm.DB {
+		return db.Where("team_id = ? AND user_id = ?", teamId, userId)
+	})
+}
+
+func (t TeamGatewayImpl) DeleteInvitation(id uint) error {
+	if err := t.postgresClient.Db.Delete(&models.TeamInvitationCore{}, id).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (t TeamGatewayImpl) GetInvitationById(id uint) (models.TeamInvitationCore, error) {
+	return t.getInvitation(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ?", id)
+	})
+}
+
+func (t TeamGatewayImpl) getInvitation(filter func(db *gorm.DB) *gorm.DB) (invitation models.TeamInvitationCore, err error) {
+	if err := t.postgresClient.Db.Scopes(preloadTeamInvitation, filter).Take(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.TeamInvitationCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.TeamInvitationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return invitation, nil
+}
+
+func (t TeamGatewayImpl) GetInvitationsByTeam(teamId uint) (invitations []models.TeamInvitationCore, err error) {
+	if err := t.postgresClient.Db.Scopes(preloadTeamInvitation).Where("team_id = ?", teamId).
+		Order("created_at").Find(&invitations).Error; err != nil {
+		return []models.TeamInvitationCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return invitations, nil
+}
+
+func (t TeamGatewayImpl) GetInvitationsByUser(userId uint, offset, limit int) (
+	invitations []models.TeamInvitationCore, countRows uint, err error) {
+	var count int64
+	if err := t.postgresClient.Db.Model(&models.TeamInvitationCore{}).Where("user_id = ?", userId).
+		Count(&count).Error; err != nil {
+		return []models.TeamInvitationCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := t.postgresClient.Db.Scopes(preloadTeamInvitation).Where("user_id = ?", userId).
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&invitations).Error; err != nil {
+		return []models.TeamInvitationCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return invitations, uint(count), nil
+}
+
+// AcceptInvitation adds the invited user to the team unless the team is full. The team row is locked,
+// so concurrent acceptances do not exceed the maximum size.
+func (t TeamGatewayImpl) AcceptInvitation(invitation models.TeamInvitationCore, maxTeamSize int) (models.TeamCore, error) {
+	if err := t.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		var team models.TeamCore
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&team, invitation.TeamID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.TeamMemberCore{}).Where("team_id = ?", team.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(maxTeamSize) {
+			return utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrTeamFull,
+			}
+		}
+		return addTeamMember(tx, team, invitation.UserID)
+	}); err != nil {
+		var responseError utils.ResponseError
+		if errors.As(err, &responseError) {
+			return models.TeamCore{}, err
+		}
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return t.GetTeamById(invitation.TeamID)
+}
+
+func (t TeamGatewayImpl) DeleteMember(teamId, userId uint) error {
+	result := t.postgresClient.Db.Where("team_id = ? AND user_id = ?", teamId, userId).Delete(&models.TeamMemberCore{})
+	if result.Error != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if result.RowsAffected == 0 {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrNotFoundInDB,
+		}
+	}
+	return nil
+}
+
+func (t TeamGatewayImpl) SubmitTeam(id uint) (models.TeamCore, error) {
+	if err := t.postgresClient.Db.Model(&models.TeamCore{}).Where("id = ?", id).
+		Update("submitted_at", gorm.Expr("NOW()")).Error; err != nil {
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return t.GetTeamById(id)
+}
+
+func (t TeamGatewayImpl) SetResult(id uint, score *float64, place *int) (models.TeamCore, error) {
+	if err := t.postgresClient.Db.Model(&models.TeamCore{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"score": score,
+			"place": place,
+		}).Error; err != nil {
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return t.GetTeamById(id)
+}
+
+// IsTeamMember reports whether the project is the shared project of a team with the user
+func (t TeamGatewayImpl) IsTeamMember(projectId, userId uint) (bool, error) {
+	var count int64
+	if err := t.teamProjects(projectId).
+		Joins("JOIN team_member_cores ON team_member_cores.team_id = team_cores.id").
+		Where("team_member_cores.user_id = ?", userId).Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+func (t TeamGatewayImpl) IsSubmittedTeamProject(projectId uint) (bool, error) {
+	var count int64
+	if err := t.teamProjects(projectId).Where("team_cores.submitted_at IS NOT NULL").Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+func (t TeamGatewayImpl) IsTeamProjectPage(projectPageId uint) (bool, error) {
+	var count int64
+	if err := t.postgresClient.Db.Model(&models.TeamCore{}).Where("project_page_id = ?", projectPageId).
+		Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+// GetLargestTeamSize returns the number of members of the largest team of the contest,
+// it is 0 if the contest has no teams
+func (t TeamGatewayImpl) GetLargestTeamSize(contestId uint) (size int, err error) {
+	if err := t.postgresClient.Db.Table("(?) AS sizes", t.postgresClient.Db.Model(&models.TeamMemberCore{}).
+		Select("COUNT(*) AS members").Where("contest_id = ?", contestId).Group("team_id")).
+		Select("COALESCE(MAX(members), 0)").Scan(&size).Error; err != nil {
+		return 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return size, nil
+}
+
+func (t TeamGatewayImpl) teamProjects(projectId uint) *gorm.DB {
+	return t.postgresClient.Db.Model(&models.TeamCore{}).
+		Joins("JOIN project_page_cores ON project_page_cores.id = team_cores.project_page_id").
+		Where("project_page_cores.project_id = ?", projectId)
+}
+
+// addTeamMember adds the member and drops invitations of the member to teams of the contest
+func addTeamMember(tx *gorm.DB, team models.TeamCore, userId uint) error {
+	if err := tx.Create(&models.TeamMemberCore{
+		TeamID:    team.ID,
+		UserID:    userId,
+		ContestID: team.ContestID,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ? AND team_id IN (?)", userId,
+		tx.Model(&models.TeamCore{}).Select("id").Where("contest_id = ?", team.ContestID)).
+		Delete(&models.TeamInvitationCore{}).Error
+}
+
+func preloadTeam(db *gorm.DB) *gorm.DB {
+	return db.Preload("Captain").Preload("Members.User")
+}
+
+func preloadTeamInvitation(db *gorm.DB) *gorm.DB {
+	return db.Preload("Team.Captain").Preload("Team.Members.User").Preload("User")
+}
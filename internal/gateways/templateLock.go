@@ -0,0 +1,53 @@
+package gateways
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type TemplateLockGateway interface {
+	GetLocks(projectPageId uint) (locks []models.TemplateLockCore, err error)
+	SetLocks(projectPageId uint, locks []models.TemplateLockCore) ([]models.TemplateLockCore, error)
+}
+
+type TemplateLockGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+// GetLocks returns locks of the template, locks of a deleted template are not returned
+func (t TemplateLockGatewayImpl) GetLocks(projectPageId uint) (locks []models.TemplateLockCore, err error) {
+	if err := t.postgresClient.Db.Where("project_page_id = ? AND project_page_id IN (?)", projectPageId,
+		t.postgresClient.Db.Model(&models.ProjectPageCore{}).Select("id").Where("is_template = ?", true)).
+		Order("id").Find(&locks).Error; err != nil {
+		return []models.TemplateLockCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return locks, nil
+}
+
+// SetLocks replaces all locks of the template
+func (t TemplateLockGatewayImpl) SetLocks(projectPageId uint, locks []models.TemplateLockCore) ([]models.TemplateLockCore, error) {
+	if err := t.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_page_id = ?", projectPageId).Delete(&models.TemplateLockCore{}).Error; err != nil {
+			return err
+		}
+		if len(locks) == 0 {
+			return nil
+		}
+		for i := range locks {
+			locks[i].ProjectPageID = projectPageId
+		}
+		return tx.Create(&locks).Error
+	}); err != nil {
+		return []models.TemplateLockCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return locks, nil
+}
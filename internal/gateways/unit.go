@@ -0,0 +1,217 @@
+package gateways
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/db"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+type UnitGateway interface {
+	CreateUnit(unit models.UnitCore) (models.UnitCore, error)
+	UpdateUnit(unit models.UnitCore) (models.UnitCore, error)
+	DeleteUnit(id uint) error
+	GetUnitById(id uint) (models.UnitCore, error)
+	GetAllUnits(offset, limit int) (units []models.UnitCore, countRows uint, err error)
+	GetUnitsByUser(userId uint, offset, limit int) (units []models.UnitCore, countRows uint, err error)
+	GetUnitIdsByUser(userId uint) (unitIds []uint, err error)
+	GetMembers(unitId uint, offset, limit int) (users []models.UserCore, countRows uint, err error)
+	AddMember(unitId, userId uint) error
+	DeleteMember(unitId, userId uint) error
+	IsMember(unitId, userId uint) (bool, error)
+	DoUsersShareUnit(userId, otherUserId uint) (bool, error)
+}
+
+type UnitGatewayImpl struct {
+	postgresClient db.PostgresClient
+}
+
+func (u UnitGatewayImpl) CreateUnit(unit models.UnitCore) (models.UnitCore, error) {
+	if err := u.postgresClient.Db.Create(&unit).Error; err != nil {
+		return models.UnitCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return unit, nil
+}
+
+func (u UnitGatewayImpl) UpdateUnit(unit models.UnitCore) (models.UnitCore, error) {
+	result := u.postgresClient.Db.Model(&unit).Clauses(clause.Returning{}).
+		Where("id = ?", unit.ID).
+		Updates(map[string]interface{}{
+			"name":        unit.Name,
+			"description": unit.Description,
+		})
+	if result.Error != nil {
+		return models.UnitCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	if result.RowsAffected == 0 {
+		return models.UnitCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrNotFoundInDB,
+		}
+	}
+	return unit, nil
+}
+
+func (u UnitGatewayImpl) DeleteUnit(id uint) error {
+	if err := u.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("unit_id = ?", id).Delete(&models.UnitMemberCore{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("unit_id = ?", id).Delete(&models.GroupCore{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.UnitCore{}, id).Error
+	}); err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (u UnitGatewayImpl) GetUnitById(id uint) (unit models.UnitCore, err error) {
+	if err := u.postgresClient.Db.First(&unit, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.UnitCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrNotFoundInDB,
+			}
+		}
+		return models.UnitCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return unit, nil
+}
+
+func (u UnitGatewayImpl) GetAllUnits(offset, limit int) (units []models.UnitCore, countRows uint, err error) {
+	var count int64
+	if err := u.postgresClient.Db.Model(&models.UnitCore{}).Count(&count).Error; err != nil {
+		return []models.UnitCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := u.postgresClient.Db.Order("name").Limit(limit).Offset(offset).Find(&units).Error; err != nil {
+		return []models.UnitCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return units, uint(count), nil
+}
+
+func (u UnitGatewayImpl) GetUnitsByUser(userId uint, offset, limit int) (units []models.UnitCore, countRows uint, err error) {
+	var count int64
+	members := u.postgresClient.Db.Model(&models.UnitMemberCore{}).Select("unit_id").Where("user_id = ?", userId)
+	if err := u.postgresClient.Db.Model(&models.UnitCore{}).Where("id IN (?)", members).
+		Count(&count).Error; err != nil {
+		return []models.UnitCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := u.postgresClient.Db.Where("id IN (?)", members).Order("name").
+		Limit(limit).Offset(offset).Find(&units).Error; err != nil {
+		return []models.UnitCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return units, uint(count), nil
+}
+
+func (u UnitGatewayImpl) GetUnitIdsByUser(userId uint) (unitIds []uint, err error) {
+	if err := u.postgresClient.Db.Model(&models.UnitMemberCore{}).
+		Joins("JOIN unit_cores ON unit_cores.id = unit_member_cores.unit_id AND unit_cores.deleted_at IS NULL").
+		Where("unit_member_cores.user_id = ?", userId).
+		Pluck("unit_member_cores.unit_id", &unitIds).Error; err != nil {
+		return []uint{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return unitIds, nil
+}
+
+func (u UnitGatewayImpl) GetMembers(unitId uint, offset, limit int) (users []models.UserCore, countRows uint, err error) {
+	var count int64
+	members := u.postgresClient.Db.Model(&models.UnitMemberCore{}).Select("user_id").Where("unit_id = ?", unitId)
+	if err := u.postgresClient.Db.Model(&models.UserCore{}).Where("id IN (?)", members).
+		Count(&count).Error; err != nil {
+		return []models.UserCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := u.postgresClient.Db.Where("id IN (?)", members).Order("lastname, firstname").
+		Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return []models.UserCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return users, uint(count), nil
+}
+
+func (u UnitGatewayImpl) AddMember(unitId, userId uint) error {
+	if err := u.postgresClient.Db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UnitMemberCore{UnitID: unitId, UserID: userId}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (u UnitGatewayImpl) DeleteMember(unitId, userId uint) error {
+	if err := u.postgresClient.Db.Where("unit_id = ? AND user_id = ?", unitId, userId).
+		Delete(&models.UnitMemberCore{}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (u UnitGatewayImpl) IsMember(unitId, userId uint) (bool, error) {
+	var count int64
+	if err := u.postgresClient.Db.Model(&models.UnitMemberCore{}).
+		Where("unit_id = ? AND user_id = ?", unitId, userId).Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+// DoUsersShareUnit reports whether both users are members of at least one existing unit
+func (u UnitGatewayImpl) DoUsersShareUnit(userId, otherUserId uint) (bool, error) {
+	var count int64
+	if err := u.postgresClient.Db.Table("unit_member_cores AS m").
+		Joins("JOIN unit_member_cores AS o ON o.unit_id = m.unit_id").
+		Joins("JOIN unit_cores ON unit_cores.id = m.unit_id AND unit_cores.deleted_at IS NULL").
+		Where("m.user_id = ? AND o.user_id = ?", userId, otherUserId).
+		Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
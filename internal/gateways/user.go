@@ -9,18 +9,28 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"net/http"
+	"time"
 )
 
 type UserGateway interface {
 	CreateUser(user models.UserCore) (newUser models.UserCore, err error)
+	CreateUnitUser(user models.UserCore, unitId uint) (newUser models.UserCore, err error)
 	DeleteUser(id uint) (err error)
 	UpdateUser(user models.UserCore) (updatedUser models.UserCore, err error)
 	GetUserById(id uint) (user models.UserCore, err error)
 	GetUserByActivationLink(link string) (user models.UserCore, err error)
 	GetUserByEmail(email string) (user models.UserCore, err error)
-	GetAllUsers(offset, limit int, isActive bool, role []models.Role) (users []models.UserCore, countRows uint, err error)
+	GetUserByNickname(nickname string) (user models.UserCore, err error)
+	GetAllUsers(offset, limit int, isActive bool, role []models.Role, unitIds []uint) (users []models.UserCore, countRows uint, err error)
+	ExportUsers(isActive bool, role []models.Role, unitIds []uint, batchSize int, write func(users []models.UserCore) error) error
 	DoesExistEmail(id uint, email string) (bool, error)
+	DoesExistNickname(id uint, nickname string) (bool, error)
+	ImportUsers(users []models.ImportedUserCore) ([]models.ImportedUserCore, error)
 	SetIsActive(id uint, isActive bool) error
+	SetTotp(id uint, secret string, enabled bool) error
+	UseTotpStep(id uint, step int64) (bool, error)
+	SetPassword(id uint, passwordHash string) error
+	SetActivationLink(id uint, linkHash string, expiresAt time.Time) error
 }
 
 type UserGatewayImpl struct {
@@ -29,7 +39,16 @@ type UserGatewayImpl struct {
 
 func (u UserGatewayImpl) GetUserByActivationLink(link string) (user models.UserCore, err error) {
 	if err = u.postgresClient.Db.Where("activation_link = ?", link).Take(&user).Error; err != nil {
-		return user, err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrIncorrectActivationLink,
+			}
+		}
+		return user, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
 	}
 	return user, nil
 }
@@ -50,21 +69,83 @@ func (u UserGatewayImpl) GetUserByEmail(email string) (user models.UserCore, err
 	return user, nil
 }
 
-func (u UserGatewayImpl) SetIsActive(id uint, isActive bool) error {
-	var updateStruct map[string]interface{}
-	if isActive {
-		updateStruct = map[string]interface{}{
-			"is_active":       isActive,
-			"activation_link": "",
+func (u UserGatewayImpl) GetUserByNickname(nickname string) (user models.UserCore, err error) {
+	if err = u.postgresClient.Db.Where("nickname = ?", nickname).Take(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrIncorrectPasswordOrEmail,
+			}
 		}
-	} else {
-		updateStruct = map[string]interface{}{
-			"is_active": isActive,
+		return user, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
 		}
 	}
+	return user, nil
+}
+
+// SetIsActive clears the activation link in both cases, so a deactivated user cannot activate
+// the account again with a link sent before
+func (u UserGatewayImpl) SetIsActive(id uint, isActive bool) error {
+	updateStruct := map[string]interface{}{
+		"is_active":                  isActive,
+		"activation_link":            "",
+		"activation_link_expires_at": nil,
+	}
 	return u.postgresClient.Db.First(&models.UserCore{ID: id}).Updates(updateStruct).Error
 }
 
+func (u UserGatewayImpl) SetTotp(id uint, secret string, enabled bool) error {
+	if err := u.postgresClient.Db.Model(&models.UserCore{ID: id}).Updates(map[string]interface{}{
+		"totp_secret":  secret,
+		"totp_enabled": enabled,
+	}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+// UseTotpStep saves the step of the accepted code unless a code of the same or a later step was accepted
+func (u UserGatewayImpl) UseTotpStep(id uint, step int64) (bool, error) {
+	result := u.postgresClient.Db.Model(&models.UserCore{}).Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: result.Error.Error(),
+		}
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (u UserGatewayImpl) SetPassword(id uint, passwordHash string) error {
+	if err := u.postgresClient.Db.Model(&models.UserCore{ID: id}).Update("password", passwordHash).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (u UserGatewayImpl) SetActivationLink(id uint, linkHash string, expiresAt time.Time) error {
+	if err := u.postgresClient.Db.Model(&models.UserCore{ID: id}).Updates(map[string]interface{}{
+		"activation_link":            linkHash,
+		"activation_link_expires_at": expiresAt,
+		"activation_link_sent_at":    time.Now(),
+	}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
 func (u UserGatewayImpl) DoesExistEmail(id uint, email string) (bool, error) {
 	result := u.postgresClient.Db.Where("id != ? AND email = ?", id, email).
 		Take(&models.UserCore{})
@@ -80,6 +161,68 @@ func (u UserGatewayImpl) DoesExistEmail(id uint, email string) (bool, error) {
 	return true, nil
 }
 
+func (u UserGatewayImpl) DoesExistNickname(id uint, nickname string) (bool, error) {
+	var count int64
+	if err := u.postgresClient.Db.Model(&models.UserCore{}).
+		Where("id != ? AND nickname = ?", id, nickname).Count(&count).Error; err != nil {
+		return false, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return count > 0, nil
+}
+
+// ImportUsers creates the users in one transaction, adds them to their units and to the groups
+// named after their classes, missing groups are created
+func (u UserGatewayImpl) ImportUsers(users []models.ImportedUserCore) ([]models.ImportedUserCore, error) {
+	if err := u.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		type groupKey struct {
+			unitId uint
+			name   string
+		}
+		groups := make(map[groupKey]uint)
+		for i := range users {
+			if err := tx.Create(&users[i].User).Error; err != nil {
+				return err
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UnitMemberCore{
+				UnitID: users[i].Unit.ID,
+				UserID: users[i].User.ID,
+			}).Error; err != nil {
+				return err
+			}
+			if users[i].Class == "" {
+				continue
+			}
+			key := groupKey{unitId: users[i].Unit.ID, name: users[i].Class}
+			groupId, ok := groups[key]
+			if !ok {
+				group := models.GroupCore{UnitID: key.unitId, Name: key.name}
+				if err := tx.Where("unit_id = ? AND name = ?", key.unitId, key.name).
+					FirstOrCreate(&group).Error; err != nil {
+					return err
+				}
+				groupId = group.ID
+				groups[key] = groupId
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.GroupStudentCore{
+				GroupID: groupId,
+				UserID:  users[i].User.ID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return []models.ImportedUserCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return users, nil
+}
+
 func (u UserGatewayImpl) CreateUser(user models.UserCore) (newUser models.UserCore, err error) {
 	result := u.postgresClient.Db.Create(&user).Clauses(clause.Returning{})
 	if result.Error != nil {
@@ -91,20 +234,45 @@ func (u UserGatewayImpl) CreateUser(user models.UserCore) (newUser models.UserCo
 	return user, nil
 }
 
+// CreateUnitUser creates the user together with the membership in the unit,
+// so the user is never left without the unit if the membership fails
+func (u UserGatewayImpl) CreateUnitUser(user models.UserCore, unitId uint) (newUser models.UserCore, err error) {
+	if err := u.postgresClient.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UnitMemberCore{UnitID: unitId, UserID: user.ID}).Error
+	}); err != nil {
+		return models.UserCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return user, nil
+}
+
 func (u UserGatewayImpl) DeleteUser(id uint) (err error) {
 	return u.postgresClient.Db.Delete(&models.UserCore{}, id).Error
 }
 
 func (u UserGatewayImpl) UpdateUser(user models.UserCore) (models.UserCore, error) {
+	values := map[string]interface{}{
+		"email":      user.Email,
+		"firstname":  user.Firstname,
+		"lastname":   user.Lastname,
+		"middlename": user.Middlename,
+		"nickname":   user.Nickname,
+	}
+	// birth date and grade are optional in the update and kept when not given
+	if user.BirthDate != nil {
+		values["birth_date"] = user.BirthDate
+	}
+	if user.Grade != nil {
+		values["grade"] = user.Grade
+	}
 	if err := u.postgresClient.Db.Model(&user).Clauses(clause.Returning{}).
 		Take(&models.UserCore{}, user.ID).
-		Updates(map[string]interface{}{
-			"email":      user.Email,
-			"firstname":  user.Firstname,
-			"lastname":   user.Lastname,
-			"middlename": user.Middlename,
-			"nickname":   user.Nickname,
-		}).Error; err != nil {
+		Updates(values).Error; err != nil {
 		return models.UserCore{}, utils.ResponseError{
 			Code:    http.StatusInternalServerError,
 			Message: err.Error(),
@@ -127,8 +295,42 @@ func (u UserGatewayImpl) GetAllUsers(
 	offset, limit int,
 	isActive bool,
 	role []models.Role,
+	unitIds []uint,
 ) (users []models.UserCore, countRows uint, err error) {
 	var count int64
+	filter := u.usersFilter(isActive, role, unitIds)
+	if err := u.postgresClient.Db.Model(&models.UserCore{}).Scopes(filter).Count(&count).Error; err != nil {
+		return []models.UserCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := u.postgresClient.Db.Scopes(filter).Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return []models.UserCore{}, 0, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return users, uint(count), nil
+}
+
+// ExportUsers passes users filtered like in GetAllUsers to the write function in batches
+func (u UserGatewayImpl) ExportUsers(isActive bool, role []models.Role, unitIds []uint, batchSize int,
+	write func(users []models.UserCore) error) error {
+	var users []models.UserCore
+	if err := u.postgresClient.Db.Scopes(u.usersFilter(isActive, role, unitIds)).Order("id").
+		FindInBatches(&users, batchSize, func(tx *gorm.DB, batch int) error {
+			return write(users)
+		}).Error; err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (u UserGatewayImpl) usersFilter(isActive bool, role []models.Role, unitIds []uint) func(db *gorm.DB) *gorm.DB {
 	if len(role) == 0 {
 		role = append(role,
 			models.RoleStudent,
@@ -137,14 +339,13 @@ func (u UserGatewayImpl) GetAllUsers(
 			models.RoleUnitAdmin,
 		)
 	}
-	result := u.postgresClient.Db.Limit(limit).Offset(offset).
-		Where("is_active = ? AND (role) IN ?", isActive, role).Find(&users)
-	if result.Error != nil {
-		return []models.UserCore{}, 0, utils.ResponseError{
-			Code:    http.StatusInternalServerError,
-			Message: result.Error.Error(),
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("is_active = ? AND (role) IN ?", isActive, role)
+		// nil unit ids do not limit users, empty ones match no users
+		if unitIds != nil {
+			db = db.Where("id IN (?)", u.postgresClient.Db.Model(&models.UnitMemberCore{}).
+				Select("user_id").Where("unit_id IN ?", unitIds))
 		}
+		return db
 	}
-	result.Count(&count)
-	return users, uint(count), result.Error
 }
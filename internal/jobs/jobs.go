@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"github.com/skinnykaen/rpa_clone/internal/services"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+	"time"
+)
+
+// StartJobs runs periodic background jobs for the lifetime of the app
+func StartJobs(
+	lifecycle fx.Lifecycle,
+	loggers logger.Loggers,
+	cohortService services.CohortService,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lifecycle.Append(
+		fx.Hook{
+			OnStart: func(context.Context) error {
+				go runEvery(ctx, viper.GetDuration("edx.reconcile_interval"), func() {
+					if err := cohortService.ReconcileCohorts(); err != nil {
+						loggers.Err.Printf("%s", err.Error())
+					}
+				})
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		},
+	)
+}
+
+// runEvery calls job every interval until ctx is done, a non-positive interval disables the job
+func runEvery(ctx context.Context, interval time.Duration, job func()) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job()
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// ActivityCore is an event in the activity of the user shown to the parents of the user
+type ActivityCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint         `gorm:"not null;index"`
+	Kind      ActivityKind `gorm:"not null"`
+	// SubjectID is the id of the project page, contest or another subject of the event
+	SubjectID uint
+	Title     string `gorm:"size:256;not null"`
+}
+
+func (a *ActivityHTTP) FromCore(activityCore ActivityCore) {
+	a.ID = strconv.Itoa(int(activityCore.ID))
+	a.CreatedAt = activityCore.CreatedAt.Format(time.DateTime)
+	a.UserID = strconv.Itoa(int(activityCore.UserID))
+	a.Kind = activityCore.Kind
+	a.SubjectID = strconv.Itoa(int(activityCore.SubjectID))
+	a.Title = activityCore.Title
+}
+
+func FromActivitiesCore(activitiesCore []ActivityCore) (activitiesHttp []*ActivityHTTP) {
+	for _, activityCore := range activitiesCore {
+		var tmpActivityHttp ActivityHTTP
+		tmpActivityHttp.FromCore(activityCore)
+		activitiesHttp = append(activitiesHttp, &tmpActivityHttp)
+	}
+	return
+}
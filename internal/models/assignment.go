@@ -0,0 +1,104 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// AssignmentCore is the homework given to a group. Every student works on a personal copy
+// of the template project page.
+type AssignmentCore struct {
+	ID                    uint `gorm:"primaryKey"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             gorm.DeletedAt       `gorm:"index"`
+	GroupID               uint                 `gorm:"not null;index"`
+	Group                 GroupCore            `gorm:"foreignKey:GroupID"`
+	CreatedByID           uint                 `gorm:"not null"`
+	TemplateProjectPageID uint                 `gorm:"not null"`
+	Title                 string               `gorm:"size:256;not null"`
+	Instruction           string               `gorm:"size:4096;not null"`
+	Deadline              time.Time            `gorm:"not null"`
+	LatePolicy            AssignmentLatePolicy `gorm:"not null"`
+	// LatePenalty is the percent of the grade taken from late submissions
+	LatePenalty int `gorm:"not null;default:0"`
+	MaxGrade    int `gorm:"not null"`
+}
+
+type AssignmentSubmissionCore struct {
+	ID            uint `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	AssignmentID  uint             `gorm:"not null;uniqueIndex:idx_assignment_student"`
+	Assignment    AssignmentCore   `gorm:"foreignKey:AssignmentID;constraint:OnDelete:CASCADE;"`
+	StudentID     uint             `gorm:"not null;uniqueIndex:idx_assignment_student;index"`
+	Student       UserCore         `gorm:"foreignKey:StudentID;constraint:OnDelete:CASCADE;"`
+	ProjectPageID uint             `gorm:"not null;index"`
+	ProjectPage   ProjectPageCore  `gorm:"foreignKey:ProjectPageID"`
+	Status        AssignmentStatus `gorm:"not null"`
+	SubmittedAt   *time.Time
+	IsLate        bool `gorm:"not null;default:false;type:boolean;column:is_late"`
+	// Grade keeps the grade after the late penalty
+	Grade        *int
+	Feedback     string `gorm:"size:4096"`
+	ReviewedByID *uint
+	ReviewedAt   *time.Time
+}
+
+func (a *AssignmentHTTP) FromCore(assignmentCore AssignmentCore) {
+	a.ID = strconv.Itoa(int(assignmentCore.ID))
+	a.CreatedAt = assignmentCore.CreatedAt.Format(time.DateTime)
+	a.UpdatedAt = assignmentCore.UpdatedAt.Format(time.DateTime)
+	a.GroupID = strconv.Itoa(int(assignmentCore.GroupID))
+	a.CreatedByID = strconv.Itoa(int(assignmentCore.CreatedByID))
+	a.TemplateProjectPageID = strconv.Itoa(int(assignmentCore.TemplateProjectPageID))
+	a.Title = assignmentCore.Title
+	a.Instruction = assignmentCore.Instruction
+	a.Deadline = assignmentCore.Deadline.Format(time.DateTime)
+	a.LatePolicy = assignmentCore.LatePolicy
+	a.LatePenalty = assignmentCore.LatePenalty
+	a.MaxGrade = assignmentCore.MaxGrade
+}
+
+func FromAssignmentsCore(assignmentsCore []AssignmentCore) (assignmentsHttp []*AssignmentHTTP) {
+	for _, assignmentCore := range assignmentsCore {
+		var tmpAssignmentHttp AssignmentHTTP
+		tmpAssignmentHttp.FromCore(assignmentCore)
+		assignmentsHttp = append(assignmentsHttp, &tmpAssignmentHttp)
+	}
+	return
+}
+
+func (a *AssignmentSubmissionHTTP) FromCore(submissionCore AssignmentSubmissionCore) {
+	var studentHttp UserHTTP
+	studentHttp.FromCore(submissionCore.Student)
+	a.ID = strconv.Itoa(int(submissionCore.ID))
+	a.AssignmentID = strconv.Itoa(int(submissionCore.AssignmentID))
+	a.AssignmentTitle = submissionCore.Assignment.Title
+	a.Deadline = submissionCore.Assignment.Deadline.Format(time.DateTime)
+	a.Student = &studentHttp
+	a.ProjectPageID = strconv.Itoa(int(submissionCore.ProjectPageID))
+	a.Status = submissionCore.Status
+	if submissionCore.SubmittedAt != nil {
+		submittedAt := submissionCore.SubmittedAt.Format(time.DateTime)
+		a.SubmittedAt = &submittedAt
+	}
+	a.IsLate = submissionCore.IsLate
+	a.Grade = submissionCore.Grade
+	a.MaxGrade = submissionCore.Assignment.MaxGrade
+	a.Feedback = submissionCore.Feedback
+	if submissionCore.ReviewedAt != nil {
+		reviewedAt := submissionCore.ReviewedAt.Format(time.DateTime)
+		a.ReviewedAt = &reviewedAt
+	}
+}
+
+func FromAssignmentSubmissionsCore(submissionsCore []AssignmentSubmissionCore) (submissionsHttp []*AssignmentSubmissionHTTP) {
+	for _, submissionCore := range submissionsCore {
+		var tmpSubmissionHttp AssignmentSubmissionHTTP
+		tmpSubmissionHttp.FromCore(submissionCore)
+		submissionsHttp = append(submissionsHttp, &tmpSubmissionHttp)
+	}
+	return
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CertificateTemplateCore is the layout of diplomas and certificates: the background image,
+// text fields with placeholders and the place of the verification QR code
+type CertificateTemplateCore struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt         `gorm:"index"`
+	CreatedByID uint                   `gorm:"not null;index"`
+	CreatedBy   UserCore               `gorm:"foreignKey:CreatedByID"`
+	Name        string                 `gorm:"size:256;not null"`
+	Background  string                 `gorm:"size:256;not null"`
+	Width       float64                `gorm:"not null"`
+	Height      float64                `gorm:"not null"`
+	QrX         float64                `gorm:"not null"`
+	QrY         float64                `gorm:"not null"`
+	QrSize      float64                `gorm:"not null"`
+	Fields      []CertificateFieldCore `gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE;"`
+}
+
+type CertificateFieldCore struct {
+	ID         uint   `gorm:"primaryKey"`
+	TemplateID uint   `gorm:"not null;index"`
+	Text       string `gorm:"size:1024;not null"`
+	X          float64
+	Y          float64
+	FontSize   float64
+	Align      CertificateTextAlign
+}
+
+// CertificateCore is the certificate issued to the user, the random code confirms its authenticity
+type CertificateCore struct {
+	ID         uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	TemplateID uint                    `gorm:"not null;index"`
+	Template   CertificateTemplateCore `gorm:"foreignKey:TemplateID"`
+	UserID     uint                    `gorm:"not null;index"`
+	User       UserCore                `gorm:"foreignKey:UserID"`
+	IssuedByID uint                    `gorm:"not null"`
+	// ContestID is set for certificates of contests held on the platform
+	ContestID *uint  `gorm:"index"`
+	Contest   string `gorm:"size:256;not null"`
+	Place     *int
+	Score     *float64
+	Code      string `gorm:"size:64;not null;uniqueIndex"`
+	RevokedAt *time.Time
+	// VerificationURL is the address encoded in the QR code, it depends on the configuration
+	VerificationURL string `gorm:"-"`
+}
+
+func (c *CertificateFieldHTTP) FromCore(fieldCore CertificateFieldCore) {
+	c.Text = fieldCore.Text
+	c.X = fieldCore.X
+	c.Y = fieldCore.Y
+	c.FontSize = fieldCore.FontSize
+	c.Align = fieldCore.Align
+}
+
+func (c *CertificateTemplateHTTP) FromCore(templateCore CertificateTemplateCore) {
+	c.ID = strconv.Itoa(int(templateCore.ID))
+	c.CreatedAt = templateCore.CreatedAt.Format(time.DateTime)
+	c.UpdatedAt = templateCore.UpdatedAt.Format(time.DateTime)
+	c.Name = templateCore.Name
+	c.Background = templateCore.Background
+	c.Width = templateCore.Width
+	c.Height = templateCore.Height
+	c.QRX = templateCore.QrX
+	c.QRY = templateCore.QrY
+	c.QRSize = templateCore.QrSize
+	c.Fields = []*CertificateFieldHTTP{}
+	for _, fieldCore := range templateCore.Fields {
+		var tmpFieldHttp CertificateFieldHTTP
+		tmpFieldHttp.FromCore(fieldCore)
+		c.Fields = append(c.Fields, &tmpFieldHttp)
+	}
+}
+
+func FromCertificateTemplatesCore(templatesCore []CertificateTemplateCore) (templatesHttp []*CertificateTemplateHTTP) {
+	for _, templateCore := range templatesCore {
+		var tmpTemplateHttp CertificateTemplateHTTP
+		tmpTemplateHttp.FromCore(templateCore)
+		templatesHttp = append(templatesHttp, &tmpTemplateHttp)
+	}
+	return
+}
+
+func (c *CertificateHTTP) FromCore(certificateCore CertificateCore) {
+	var userHttp UserHTTP
+	userHttp.FromCore(certificateCore.User)
+	c.ID = strconv.Itoa(int(certificateCore.ID))
+	c.CreatedAt = certificateCore.CreatedAt.Format(time.DateTime)
+	c.TemplateID = strconv.Itoa(int(certificateCore.TemplateID))
+	c.User = &userHttp
+	if certificateCore.ContestID != nil {
+		contestId := strconv.Itoa(int(*certificateCore.ContestID))
+		c.ContestID = &contestId
+	}
+	c.Contest = certificateCore.Contest
+	c.Place = certificateCore.Place
+	c.Score = certificateCore.Score
+	c.Code = certificateCore.Code
+	c.VerificationURL = certificateCore.VerificationURL
+	c.IsRevoked = certificateCore.RevokedAt != nil
+}
+
+func (c *CertificatePublicHTTP) FromCore(certificateCore CertificateCore) {
+	user := certificateCore.User
+	c.Holder = strings.TrimSpace(user.Lastname + " " + user.Firstname + " " + user.Middlename)
+	c.Contest = certificateCore.Contest
+	c.Place = certificateCore.Place
+	c.Score = certificateCore.Score
+	c.IssuedAt = certificateCore.CreatedAt.Format(time.DateTime)
+	c.IsRevoked = certificateCore.RevokedAt != nil
+}
+
+func FromCertificatesCore(certificatesCore []CertificateCore) (certificatesHttp []*CertificateHTTP) {
+	for _, certificateCore := range certificatesCore {
+		var tmpCertificateHttp CertificateHTTP
+		tmpCertificateHttp.FromCore(certificateCore)
+		certificatesHttp = append(certificatesHttp, &tmpCertificateHttp)
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// ClarificationCore is a question of a participant about the contest or its task. The jury answers
+// privately to the author or publishes the answer to all participants.
+type ClarificationCore struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	ContestID    uint             `gorm:"not null;index"`
+	Contest      ContestCore      `gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE;"`
+	TaskID       *uint            `gorm:"index"`
+	Task         *ContestTaskCore `gorm:"foreignKey:TaskID;constraint:OnDelete:SET NULL;"`
+	AuthorID     uint             `gorm:"not null;index"`
+	Author       UserCore         `gorm:"foreignKey:AuthorID"`
+	Question     string           `gorm:"size:4096;not null"`
+	Answer       string           `gorm:"size:4096"`
+	AnsweredByID *uint
+	AnsweredAt   *time.Time
+	IsPublic     bool `gorm:"not null;default:false;type:boolean;column:is_public"`
+}
+
+type AnnouncementCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	ContestID uint        `gorm:"not null;index"`
+	Contest   ContestCore `gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE;"`
+	AuthorID  uint        `gorm:"not null"`
+	Text      string      `gorm:"size:4096;not null"`
+}
+
+// ContestEventCore is delivered live to subscribers of the contest, it has either the announcement
+// or the asked or answered clarification
+type ContestEventCore struct {
+	Kind          ContestEventKind
+	Announcement  *AnnouncementCore
+	Clarification *ClarificationCore
+}
+
+func (c *ClarificationHTTP) FromCore(clarificationCore ClarificationCore) {
+	c.ID = strconv.Itoa(int(clarificationCore.ID))
+	c.CreatedAt = clarificationCore.CreatedAt.Format(time.DateTime)
+	c.ContestID = strconv.Itoa(int(clarificationCore.ContestID))
+	if clarificationCore.TaskID != nil {
+		taskId := strconv.Itoa(int(*clarificationCore.TaskID))
+		c.TaskID = &taskId
+	}
+	if clarificationCore.Task != nil {
+		c.TaskTitle = &clarificationCore.Task.Title
+	}
+	c.AuthorID = strconv.Itoa(int(clarificationCore.AuthorID))
+	c.Question = clarificationCore.Question
+	if clarificationCore.AnsweredAt != nil {
+		answeredAt := clarificationCore.AnsweredAt.Format(time.DateTime)
+		c.Answer = &clarificationCore.Answer
+		c.AnsweredAt = &answeredAt
+	}
+	c.IsPublic = clarificationCore.IsPublic
+}
+
+func FromClarificationsCore(clarificationsCore []ClarificationCore) (clarificationsHttp []*ClarificationHTTP) {
+	for _, clarificationCore := range clarificationsCore {
+		var tmpClarificationHttp ClarificationHTTP
+		tmpClarificationHttp.FromCore(clarificationCore)
+		clarificationsHttp = append(clarificationsHttp, &tmpClarificationHttp)
+	}
+	return
+}
+
+func (a *AnnouncementHTTP) FromCore(announcementCore AnnouncementCore) {
+	a.ID = strconv.Itoa(int(announcementCore.ID))
+	a.CreatedAt = announcementCore.CreatedAt.Format(time.DateTime)
+	a.ContestID = strconv.Itoa(int(announcementCore.ContestID))
+	a.Text = announcementCore.Text
+}
+
+func FromAnnouncementsCore(announcementsCore []AnnouncementCore) (announcementsHttp []*AnnouncementHTTP) {
+	for _, announcementCore := range announcementsCore {
+		var tmpAnnouncementHttp AnnouncementHTTP
+		tmpAnnouncementHttp.FromCore(announcementCore)
+		announcementsHttp = append(announcementsHttp, &tmpAnnouncementHttp)
+	}
+	return
+}
+
+func (c *ContestEventHTTP) FromCore(eventCore ContestEventCore) {
+	c.Kind = eventCore.Kind
+	if eventCore.Announcement != nil {
+		var announcementHttp AnnouncementHTTP
+		announcementHttp.FromCore(*eventCore.Announcement)
+		c.Announcement = &announcementHttp
+	}
+	if eventCore.Clarification != nil {
+		var clarificationHttp ClarificationHTTP
+		clarificationHttp.FromCore(*eventCore.Clarification)
+		c.Clarification = &clarificationHttp
+	}
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// CohortCore binds the platform group to the edX cohort of the course,
+// students of the group are expected to be members of the edX cohort
+type CohortCore struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CourseID    string    `gorm:"not null;index"`
+	GroupID     uint      `gorm:"not null;index"`
+	Group       GroupCore `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE;"`
+	Name        string    `gorm:"not null"`
+	EdxCohortID int       `gorm:"not null"`
+	CreatedByID uint      `gorm:"not null"`
+	CreatedBy   UserCore  `gorm:"foreignKey:CreatedByID"`
+}
+
+// CohortMismatchCore is found by the reconciliation of platform groups with edX cohorts,
+// mismatches of a cohort are replaced on every reconciliation
+type CohortMismatchCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	CohortID  uint       `gorm:"not null;index"`
+	Cohort    CohortCore `gorm:"foreignKey:CohortID;constraint:OnDelete:CASCADE;"`
+	Username  string     `gorm:"not null"`
+	UserID    *uint
+	Kind      CohortMismatchKind `gorm:"not null"`
+}
+
+func (c *CohortHTTP) FromCore(cohortCore CohortCore) {
+	c.ID = strconv.Itoa(int(cohortCore.ID))
+	c.CreatedAt = cohortCore.CreatedAt.Format(time.DateTime)
+	c.CourseID = cohortCore.CourseID
+	c.GroupID = strconv.Itoa(int(cohortCore.GroupID))
+	c.Name = cohortCore.Name
+	c.EdxCohortID = cohortCore.EdxCohortID
+}
+
+func FromCohortsCore(cohortsCore []CohortCore) (cohortsHttp []*CohortHTTP) {
+	for _, cohortCore := range cohortsCore {
+		var tmpCohortHttp CohortHTTP
+		tmpCohortHttp.FromCore(cohortCore)
+		cohortsHttp = append(cohortsHttp, &tmpCohortHttp)
+	}
+	return
+}
+
+func (c *CohortMismatchHTTP) FromCore(mismatchCore CohortMismatchCore) {
+	c.ID = strconv.Itoa(int(mismatchCore.ID))
+	c.DetectedAt = mismatchCore.CreatedAt.Format(time.DateTime)
+	c.CohortID = strconv.Itoa(int(mismatchCore.CohortID))
+	c.CourseID = mismatchCore.Cohort.CourseID
+	c.GroupID = strconv.Itoa(int(mismatchCore.Cohort.GroupID))
+	c.CohortName = mismatchCore.Cohort.Name
+	c.Username = mismatchCore.Username
+	if mismatchCore.UserID != nil {
+		userId := strconv.Itoa(int(*mismatchCore.UserID))
+		c.UserID = &userId
+	}
+	c.Kind = mismatchCore.Kind
+}
+
+func FromCohortMismatchesCore(mismatchesCore []CohortMismatchCore) (mismatchesHttp []*CohortMismatchHTTP) {
+	for _, mismatchCore := range mismatchesCore {
+		var tmpMismatchHttp CohortMismatchHTTP
+		tmpMismatchHttp.FromCore(mismatchCore)
+		mismatchesHttp = append(mismatchesHttp, &tmpMismatchHttp)
+	}
+	return
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// ContestCore is a competition with registration of participants. Registration is open until the start
+// and only for users meeting the eligibility rules.
+type ContestCore struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	CreatedByID uint           `gorm:"not null;index"`
+	// UnitID limits registration to members of the unit
+	UnitID         *uint     `gorm:"index"`
+	Title          string    `gorm:"size:256;not null"`
+	Description    string    `gorm:"size:4096"`
+	StartAt        time.Time `gorm:"not null"`
+	EndAt          time.Time `gorm:"not null"`
+	MinAge         *int
+	MaxAge         *int
+	MinGrade       *int
+	MaxGrade       *int
+	InvitationOnly bool `gorm:"not null;default:false;type:boolean;column:invitation_only"`
+	// Capacity limits confirmed registrations and registrations awaiting consent, others are waitlisted
+	Capacity *int
+	// team sizes are set for team contests, where participants work in teams on a shared project
+	MinTeamSize   *int
+	MaxTeamSize   *int
+	AgeCategories []ContestAgeCategoryCore `gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE;"`
+}
+
+type ContestAgeCategoryCore struct {
+	ID        uint   `gorm:"primaryKey"`
+	ContestID uint   `gorm:"not null;index"`
+	Name      string `gorm:"size:256;not null"`
+	MinAge    int    `gorm:"not null"`
+	MaxAge    int    `gorm:"not null"`
+}
+
+// ContestTaskCore is a task of the contest, participants see tasks after the start
+type ContestTaskCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ContestID uint        `gorm:"not null;index"`
+	Contest   ContestCore `gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE;"`
+	Position  int         `gorm:"not null"`
+	Title     string      `gorm:"size:256;not null"`
+	Statement string      `gorm:"size:65535;not null"`
+}
+
+type ContestInvitationCore struct {
+	ContestID uint        `gorm:"primaryKey"`
+	Contest   ContestCore `gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE;"`
+	UserID    uint        `gorm:"primaryKey;index"`
+	User      UserCore    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+type ContestRegistrationCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ContestID uint                      `gorm:"not null;uniqueIndex:idx_contest_user"`
+	Contest   ContestCore               `gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE;"`
+	UserID    uint                      `gorm:"not null;uniqueIndex:idx_contest_user;index"`
+	User      UserCore                  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Status    ContestRegistrationStatus `gorm:"not null"`
+	// Age is the age at the start of the contest, it is unknown for users without a birth date
+	Age           *int
+	AgeCategoryID *uint                   `gorm:"index"`
+	AgeCategory   *ContestAgeCategoryCore `gorm:"foreignKey:AgeCategoryID;constraint:OnDelete:SET NULL;"`
+	// ConsentRequired is set for minors, their registration is confirmed after the consent of a parent
+	ConsentRequired bool `gorm:"not null;default:false;type:boolean;column:consent_required"`
+	ConsentedByID   *uint
+	ConsentedAt     *time.Time
+}
+
+func (c *ContestAgeCategoryHTTP) FromCore(categoryCore ContestAgeCategoryCore) {
+	c.ID = strconv.Itoa(int(categoryCore.ID))
+	c.Name = categoryCore.Name
+	c.MinAge = categoryCore.MinAge
+	c.MaxAge = categoryCore.MaxAge
+}
+
+func (c *ContestHTTP) FromCore(contestCore ContestCore) {
+	c.ID = strconv.Itoa(int(contestCore.ID))
+	c.CreatedAt = contestCore.CreatedAt.Format(time.DateTime)
+	c.UpdatedAt = contestCore.UpdatedAt.Format(time.DateTime)
+	c.CreatedByID = strconv.Itoa(int(contestCore.CreatedByID))
+	if contestCore.UnitID != nil {
+		unitId := strconv.Itoa(int(*contestCore.UnitID))
+		c.UnitID = &unitId
+	}
+	c.Title = contestCore.Title
+	c.Description = contestCore.Description
+	c.StartAt = contestCore.StartAt.Format(time.DateTime)
+	c.EndAt = contestCore.EndAt.Format(time.DateTime)
+	c.MinAge = contestCore.MinAge
+	c.MaxAge = contestCore.MaxAge
+	c.MinGrade = contestCore.MinGrade
+	c.MaxGrade = contestCore.MaxGrade
+	c.InvitationOnly = contestCore.InvitationOnly
+	c.Capacity = contestCore.Capacity
+	c.MinTeamSize = contestCore.MinTeamSize
+	c.MaxTeamSize = contestCore.MaxTeamSize
+	c.AgeCategories = []*ContestAgeCategoryHTTP{}
+	for _, categoryCore := range contestCore.AgeCategories {
+		var tmpCategoryHttp ContestAgeCategoryHTTP
+		tmpCategoryHttp.FromCore(categoryCore)
+		c.AgeCategories = append(c.AgeCategories, &tmpCategoryHttp)
+	}
+}
+
+func FromContestsCore(contestsCore []ContestCore) (contestsHttp []*ContestHTTP) {
+	for _, contestCore := range contestsCore {
+		var tmpContestHttp ContestHTTP
+		tmpContestHttp.FromCore(contestCore)
+		contestsHttp = append(contestsHttp, &tmpContestHttp)
+	}
+	return
+}
+
+func (c *ContestTaskHTTP) FromCore(taskCore ContestTaskCore) {
+	c.ID = strconv.Itoa(int(taskCore.ID))
+	c.CreatedAt = taskCore.CreatedAt.Format(time.DateTime)
+	c.UpdatedAt = taskCore.UpdatedAt.Format(time.DateTime)
+	c.ContestID = strconv.Itoa(int(taskCore.ContestID))
+	c.Position = taskCore.Position
+	c.Title = taskCore.Title
+	c.Statement = taskCore.Statement
+}
+
+func FromContestTasksCore(tasksCore []ContestTaskCore) (tasksHttp []*ContestTaskHTTP) {
+	for _, taskCore := range tasksCore {
+		var tmpTaskHttp ContestTaskHTTP
+		tmpTaskHttp.FromCore(taskCore)
+		tasksHttp = append(tasksHttp, &tmpTaskHttp)
+	}
+	return
+}
+
+func (c *ContestRegistrationHTTP) FromCore(registrationCore ContestRegistrationCore) {
+	c.ID = strconv.Itoa(int(registrationCore.ID))
+	c.CreatedAt = registrationCore.CreatedAt.Format(time.DateTime)
+	c.ContestID = strconv.Itoa(int(registrationCore.ContestID))
+	c.ContestTitle = registrationCore.Contest.Title
+	var userHttp UserHTTP
+	userHttp.FromCore(registrationCore.User)
+	c.User = &userHttp
+	c.Status = registrationCore.Status
+	c.Age = registrationCore.Age
+	if registrationCore.AgeCategory != nil {
+		c.AgeCategory = &registrationCore.AgeCategory.Name
+	}
+	c.ConsentRequired = registrationCore.ConsentRequired
+	if registrationCore.ConsentedAt != nil {
+		consentedAt := registrationCore.ConsentedAt.Format(time.DateTime)
+		c.ConsentedAt = &consentedAt
+	}
+}
+
+func FromContestRegistrationsCore(registrationsCore []ContestRegistrationCore) (registrationsHttp []*ContestRegistrationHTTP) {
+	for _, registrationCore := range registrationsCore {
+		var tmpRegistrationHttp ContestRegistrationHTTP
+		tmpRegistrationHttp.FromCore(registrationCore)
+		registrationsHttp = append(registrationsHttp, &tmpRegistrationHttp)
+	}
+	return
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// CourseCore is the course as returned by edX Course API
+type CourseCore struct {
+	ID               string                       `json:"id"`
+	BlocksUrl        string                       `json:"blocks_url"`
+	Effort           string                       `json:"effort"`
+	EnrollmentStart  *time.Time                   `json:"enrollment_start"`
+	EnrollmentEnd    *time.Time                   `json:"enrollment_end"`
+	End              *time.Time                   `json:"end"`
+	Name             string                       `json:"name"`
+	Number           string                       `json:"number"`
+	Org              string                       `json:"org"`
+	ShortDescription string                       `json:"short_description"`
+	Start            *time.Time                   `json:"start"`
+	StartDisplay     string                       `json:"start_display"`
+	StartType        string                       `json:"start_type"`
+	Pacing           string                       `json:"pacing"`
+	MobileAvailable  bool                         `json:"mobile_available"`
+	Hidden           bool                         `json:"hidden"`
+	InvitationOnly   bool                         `json:"invitation_only"`
+	Overview         *string                      `json:"overview"`
+	CourseID         string                       `json:"course_id"`
+	Media            CourseAPIMediaCollectionCore `json:"media"`
+}
+
+type CourseAPIMediaCollectionCore struct {
+	BannerImage *AbsoluteMediaCore `json:"banner_image"`
+	CourseImage *MediaCore         `json:"course_image"`
+	CourseVideo *MediaCore         `json:"course_video"`
+	Image       *ImageCore         `json:"image"`
+}
+
+type AbsoluteMediaCore struct {
+	Uri         string `json:"uri"`
+	UriAbsolute string `json:"uri_absolute"`
+}
+
+type MediaCore struct {
+	Uri string `json:"uri"`
+}
+
+type ImageCore struct {
+	Raw   string `json:"raw"`
+	Small string `json:"small"`
+	Large string `json:"large"`
+}
+
+// EnrollmentCore is the enrollment of the user as returned by edX Enrollment API
+type EnrollmentCore struct {
+	CourseID string `json:"course_id"`
+	User     string `json:"user"`
+	IsActive bool   `json:"is_active"`
+	Mode     string `json:"mode"`
+}
+
+// EnrollmentResultCore is the result of the enrollment of one user in bulk operations,
+// a failure of one user does not stop the others
+type EnrollmentResultCore struct {
+	UserID uint
+	Err    error
+}
+
+func (c *CourseHTTP) FromCore(courseCore CourseCore) {
+	c.ID = courseCore.ID
+	c.BlocksURL = courseCore.BlocksUrl
+	c.Effort = courseCore.Effort
+	c.EnrollmentStart = formatCourseTime(courseCore.EnrollmentStart)
+	c.EnrollmentEnd = formatCourseTime(courseCore.EnrollmentEnd)
+	c.End = formatCourseTime(courseCore.End)
+	c.Name = courseCore.Name
+	c.Number = courseCore.Number
+	c.Org = courseCore.Org
+	c.ShortDescription = courseCore.ShortDescription
+	c.Start = formatCourseTime(courseCore.Start)
+	c.StartDisplay = courseCore.StartDisplay
+	c.StartType = courseCore.StartType
+	c.Pacing = courseCore.Pacing
+	c.MobileAvailable = courseCore.MobileAvailable
+	c.Hidden = courseCore.Hidden
+	c.InvitationOnly = courseCore.InvitationOnly
+	c.Overview = courseCore.Overview
+	c.CourseID = courseCore.CourseID
+	// media objects have no ids in edX, the course id is used instead
+	c.Media = &CourseAPIMediaCollectionHTTP{ID: courseCore.ID}
+	if media := courseCore.Media.BannerImage; media != nil {
+		c.Media.BannerImage = &AbsoluteMediaHTTP{ID: courseCore.ID, URI: media.Uri, URIAbsolute: media.UriAbsolute}
+	}
+	if media := courseCore.Media.CourseImage; media != nil {
+		c.Media.CourseImage = &MediaHTTP{ID: courseCore.ID, URI: media.Uri}
+	}
+	if media := courseCore.Media.CourseVideo; media != nil {
+		c.Media.CourseVideo = &MediaHTTP{ID: courseCore.ID, URI: media.Uri}
+	}
+	if media := courseCore.Media.Image; media != nil {
+		c.Media.Image = &ImageHTTP{ID: courseCore.ID, Raw: media.Raw, Small: media.Small, Large: media.Large}
+	}
+}
+
+func FromCoursesCore(coursesCore []CourseCore) (coursesHttp []*CourseHTTP) {
+	for _, courseCore := range coursesCore {
+		var tmpCourseHttp CourseHTTP
+		tmpCourseHttp.FromCore(courseCore)
+		coursesHttp = append(coursesHttp, &tmpCourseHttp)
+	}
+	return
+}
+
+func FromEnrollmentResultsCore(resultsCore []EnrollmentResultCore) (resultsHttp []*EnrollmentResultHTTP) {
+	for _, resultCore := range resultsCore {
+		resultHttp := EnrollmentResultHTTP{
+			UserID: strconv.Itoa(int(resultCore.UserID)),
+			Ok:     resultCore.Err == nil,
+		}
+		if resultCore.Err != nil {
+			resultHttp.Error = resultCore.Err.Error()
+		}
+		resultsHttp = append(resultsHttp, &resultHttp)
+	}
+	return
+}
+
+func formatCourseTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.DateTime)
+}
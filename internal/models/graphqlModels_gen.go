@@ -14,6 +14,256 @@ type AbsoluteMediaHTTP struct {
 	URIAbsolute string `json:"uri_absolute"`
 }
 
+type ActivityHTTP struct {
+	ID        string       `json:"id"`
+	CreatedAt string       `json:"createdAt"`
+	UserID    string       `json:"userId"`
+	Kind      ActivityKind `json:"kind"`
+	SubjectID string       `json:"subjectId"`
+	Title     string       `json:"title"`
+}
+
+type AnnouncementHTTP struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"createdAt"`
+	ContestID string `json:"contestId"`
+	Text      string `json:"text"`
+}
+
+type AnnouncementHTTPList struct {
+	Announcements []*AnnouncementHTTP `json:"announcements"`
+	CountRows     int                 `json:"countRows"`
+}
+
+type AssignmentHTTP struct {
+	ID                    string               `json:"id"`
+	CreatedAt             string               `json:"createdAt"`
+	UpdatedAt             string               `json:"updatedAt"`
+	GroupID               string               `json:"groupId"`
+	CreatedByID           string               `json:"createdById"`
+	TemplateProjectPageID string               `json:"templateProjectPageId"`
+	Title                 string               `json:"title"`
+	Instruction           string               `json:"instruction"`
+	Deadline              string               `json:"deadline"`
+	LatePolicy            AssignmentLatePolicy `json:"latePolicy"`
+	LatePenalty           int                  `json:"latePenalty"`
+	MaxGrade              int                  `json:"maxGrade"`
+}
+
+type AssignmentHTTPList struct {
+	Assignments []*AssignmentHTTP `json:"assignments"`
+	CountRows   int               `json:"countRows"`
+}
+
+type AssignmentSubmissionHTTP struct {
+	ID              string           `json:"id"`
+	AssignmentID    string           `json:"assignmentId"`
+	AssignmentTitle string           `json:"assignmentTitle"`
+	Deadline        string           `json:"deadline"`
+	Student         *UserHTTP        `json:"student"`
+	ProjectPageID   string           `json:"projectPageId"`
+	Status          AssignmentStatus `json:"status"`
+	SubmittedAt     *string          `json:"submittedAt,omitempty"`
+	IsLate          bool             `json:"isLate"`
+	Grade           *int             `json:"grade,omitempty"`
+	MaxGrade        int              `json:"maxGrade"`
+	Feedback        string           `json:"feedback"`
+	ReviewedAt      *string          `json:"reviewedAt,omitempty"`
+}
+
+type AssignmentSubmissionHTTPList struct {
+	AssignmentSubmissions []*AssignmentSubmissionHTTP `json:"assignmentSubmissions"`
+	CountRows             int                         `json:"countRows"`
+}
+
+type CertificateFieldHTTP struct {
+	Text     string               `json:"text"`
+	X        float64              `json:"x"`
+	Y        float64              `json:"y"`
+	FontSize float64              `json:"fontSize"`
+	Align    CertificateTextAlign `json:"align"`
+}
+
+type CertificateHTTP struct {
+	ID              string    `json:"id"`
+	CreatedAt       string    `json:"createdAt"`
+	TemplateID      string    `json:"templateId"`
+	User            *UserHTTP `json:"user"`
+	ContestID       *string   `json:"contestId,omitempty"`
+	Contest         string    `json:"contest"`
+	Place           *int      `json:"place,omitempty"`
+	Score           *float64  `json:"score,omitempty"`
+	Code            string    `json:"code"`
+	VerificationURL string    `json:"verificationUrl"`
+	IsRevoked       bool      `json:"isRevoked"`
+}
+
+type CertificateHTTPList struct {
+	Certificates []*CertificateHTTP `json:"certificates"`
+	CountRows    int                `json:"countRows"`
+}
+
+type CertificatePublicHTTP struct {
+	Holder    string   `json:"holder"`
+	Contest   string   `json:"contest"`
+	Place     *int     `json:"place,omitempty"`
+	Score     *float64 `json:"score,omitempty"`
+	IssuedAt  string   `json:"issuedAt"`
+	IsRevoked bool     `json:"isRevoked"`
+}
+
+type CertificateTemplateHTTP struct {
+	ID         string                  `json:"id"`
+	CreatedAt  string                  `json:"createdAt"`
+	UpdatedAt  string                  `json:"updatedAt"`
+	Name       string                  `json:"name"`
+	Background string                  `json:"background"`
+	Width      float64                 `json:"width"`
+	Height     float64                 `json:"height"`
+	QRX        float64                 `json:"qrX"`
+	QRY        float64                 `json:"qrY"`
+	QRSize     float64                 `json:"qrSize"`
+	Fields     []*CertificateFieldHTTP `json:"fields"`
+}
+
+type CertificateTemplateHTTPList struct {
+	CertificateTemplates []*CertificateTemplateHTTP `json:"certificateTemplates"`
+	CountRows            int                        `json:"countRows"`
+}
+
+type ChildContestHTTP struct {
+	Registration *ContestRegistrationHTTP `json:"registration"`
+	TeamName     *string                  `json:"teamName,omitempty"`
+	SubmittedAt  *string                  `json:"submittedAt,omitempty"`
+	Score        *float64                 `json:"score,omitempty"`
+	Place        *int                     `json:"place,omitempty"`
+}
+
+type ChildProgressHTTP struct {
+	Child                 *UserHTTP                   `json:"child"`
+	ProjectPages          []*ProjectPageHTTP          `json:"projectPages"`
+	CountProjectPages     int                         `json:"countProjectPages"`
+	AssignmentSubmissions []*AssignmentSubmissionHTTP `json:"assignmentSubmissions"`
+	Contests              []*ChildContestHTTP         `json:"contests"`
+	Activity              []*ActivityHTTP             `json:"activity"`
+}
+
+type ClarificationHTTP struct {
+	ID         string  `json:"id"`
+	CreatedAt  string  `json:"createdAt"`
+	ContestID  string  `json:"contestId"`
+	TaskID     *string `json:"taskId,omitempty"`
+	TaskTitle  *string `json:"taskTitle,omitempty"`
+	AuthorID   string  `json:"authorId"`
+	Question   string  `json:"question"`
+	Answer     *string `json:"answer,omitempty"`
+	AnsweredAt *string `json:"answeredAt,omitempty"`
+	IsPublic   bool    `json:"isPublic"`
+}
+
+type ClarificationHTTPList struct {
+	Clarifications []*ClarificationHTTP `json:"clarifications"`
+	CountRows      int                  `json:"countRows"`
+}
+
+type CohortHTTP struct {
+	ID          string `json:"id"`
+	CreatedAt   string `json:"createdAt"`
+	CourseID    string `json:"courseId"`
+	GroupID     string `json:"groupId"`
+	Name        string `json:"name"`
+	EdxCohortID int    `json:"edxCohortId"`
+}
+
+type CohortHTTPList struct {
+	Cohorts   []*CohortHTTP `json:"cohorts"`
+	CountRows int           `json:"countRows"`
+}
+
+type CohortMismatchHTTP struct {
+	ID         string             `json:"id"`
+	DetectedAt string             `json:"detectedAt"`
+	CohortID   string             `json:"cohortId"`
+	CourseID   string             `json:"courseId"`
+	GroupID    string             `json:"groupId"`
+	CohortName string             `json:"cohortName"`
+	Username   string             `json:"username"`
+	UserID     *string            `json:"userId,omitempty"`
+	Kind       CohortMismatchKind `json:"kind"`
+}
+
+type CohortMismatchHTTPList struct {
+	CohortMismatches []*CohortMismatchHTTP `json:"cohortMismatches"`
+	CountRows        int                   `json:"countRows"`
+}
+
+type ContestAgeCategoryHTTP struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	MinAge int    `json:"minAge"`
+	MaxAge int    `json:"maxAge"`
+}
+
+type ContestEventHTTP struct {
+	Kind          ContestEventKind   `json:"kind"`
+	Announcement  *AnnouncementHTTP  `json:"announcement,omitempty"`
+	Clarification *ClarificationHTTP `json:"clarification,omitempty"`
+}
+
+type ContestHTTP struct {
+	ID             string                    `json:"id"`
+	CreatedAt      string                    `json:"createdAt"`
+	UpdatedAt      string                    `json:"updatedAt"`
+	CreatedByID    string                    `json:"createdById"`
+	UnitID         *string                   `json:"unitId,omitempty"`
+	Title          string                    `json:"title"`
+	Description    string                    `json:"description"`
+	StartAt        string                    `json:"startAt"`
+	EndAt          string                    `json:"endAt"`
+	MinAge         *int                      `json:"minAge,omitempty"`
+	MaxAge         *int                      `json:"maxAge,omitempty"`
+	MinGrade       *int                      `json:"minGrade,omitempty"`
+	MaxGrade       *int                      `json:"maxGrade,omitempty"`
+	InvitationOnly bool                      `json:"invitation_only"`
+	Capacity       *int                      `json:"capacity,omitempty"`
+	MinTeamSize    *int                      `json:"minTeamSize,omitempty"`
+	MaxTeamSize    *int                      `json:"maxTeamSize,omitempty"`
+	AgeCategories  []*ContestAgeCategoryHTTP `json:"ageCategories"`
+}
+
+type ContestHTTPList struct {
+	Contests  []*ContestHTTP `json:"contests"`
+	CountRows int            `json:"countRows"`
+}
+
+type ContestRegistrationHTTP struct {
+	ID              string                    `json:"id"`
+	CreatedAt       string                    `json:"createdAt"`
+	ContestID       string                    `json:"contestId"`
+	ContestTitle    string                    `json:"contestTitle"`
+	User            *UserHTTP                 `json:"user"`
+	Status          ContestRegistrationStatus `json:"status"`
+	Age             *int                      `json:"age,omitempty"`
+	AgeCategory     *string                   `json:"ageCategory,omitempty"`
+	ConsentRequired bool                      `json:"consentRequired"`
+	ConsentedAt     *string                   `json:"consentedAt,omitempty"`
+}
+
+type ContestRegistrationHTTPList struct {
+	ContestRegistrations []*ContestRegistrationHTTP `json:"contestRegistrations"`
+	CountRows            int                        `json:"countRows"`
+}
+
+type ContestTaskHTTP struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+	ContestID string `json:"contestId"`
+	Position  int    `json:"position"`
+	Title     string `json:"title"`
+	Statement string `json:"statement"`
+}
+
 type CourseAPIMediaCollectionHTTP struct {
 	ID          string             `json:"id"`
 	BannerImage *AbsoluteMediaHTTP `json:"banner_image,omitempty"`
@@ -50,6 +300,31 @@ type CoursesListHTTP struct {
 	CountRows int           `json:"countRows"`
 }
 
+type CreatedParentLinkCode struct {
+	Code           string              `json:"code"`
+	ParentLinkCode *ParentLinkCodeHTTP `json:"parentLinkCode"`
+}
+
+type EnrollmentResultHTTP struct {
+	UserID string `json:"userId"`
+	Ok     bool   `json:"ok"`
+	Error  string `json:"error"`
+}
+
+type GroupHTTP struct {
+	ID          string `json:"id"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+	UnitID      string `json:"unitId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type GroupHTTPList struct {
+	Groups    []*GroupHTTP `json:"groups"`
+	CountRows int          `json:"countRows"`
+}
+
 type ImageHTTP struct {
 	ID    string `json:"id"`
 	Raw   string `json:"raw"`
@@ -57,11 +332,124 @@ type ImageHTTP struct {
 	Large string `json:"large"`
 }
 
+type LockoutEventHTTP struct {
+	ID          string `json:"id"`
+	CreatedAt   string `json:"createdAt"`
+	UserID      string `json:"userId"`
+	Email       string `json:"email"`
+	IP          string `json:"ip"`
+	Reason      string `json:"reason"`
+	LockedUntil string `json:"lockedUntil"`
+}
+
+type LockoutEventHTTPList struct {
+	LockoutEvents []*LockoutEventHTTP `json:"lockoutEvents"`
+	CountRows     int                 `json:"countRows"`
+}
+
+type LtiDeepLinkingFormHTTP struct {
+	URL string `json:"url"`
+	Jwt string `json:"jwt"`
+}
+
+type LtiLaunchHTTP struct {
+	ID            string          `json:"id"`
+	MessageType   LtiMessageType  `json:"messageType"`
+	ProjectPageID *string         `json:"projectPageId,omitempty"`
+	GradeLinkID   *string         `json:"gradeLinkId,omitempty"`
+	SignIn        *SignInResponse `json:"signIn"`
+}
+
 type MediaHTTP struct {
 	ID  string `json:"id"`
 	URI string `json:"uri"`
 }
 
+type NewAssignment struct {
+	GroupID               string               `json:"groupId"`
+	TemplateProjectPageID string               `json:"templateProjectPageId"`
+	Title                 string               `json:"title"`
+	Instruction           string               `json:"instruction"`
+	Deadline              string               `json:"deadline"`
+	LatePolicy            AssignmentLatePolicy `json:"latePolicy"`
+	LatePenalty           *int                 `json:"latePenalty,omitempty"`
+	MaxGrade              int                  `json:"maxGrade"`
+}
+
+type NewCertificate struct {
+	UserID    string   `json:"userId"`
+	ContestID *string  `json:"contestId,omitempty"`
+	Contest   *string  `json:"contest,omitempty"`
+	Place     *int     `json:"place,omitempty"`
+	Score     *float64 `json:"score,omitempty"`
+}
+
+type NewCertificateField struct {
+	Text     string               `json:"text"`
+	X        float64              `json:"x"`
+	Y        float64              `json:"y"`
+	FontSize float64              `json:"fontSize"`
+	Align    CertificateTextAlign `json:"align"`
+}
+
+type NewCertificateTemplate struct {
+	Name       string                 `json:"name"`
+	Background string                 `json:"background"`
+	Width      float64                `json:"width"`
+	Height     float64                `json:"height"`
+	QRX        float64                `json:"qrX"`
+	QRY        float64                `json:"qrY"`
+	QRSize     float64                `json:"qrSize"`
+	Fields     []*NewCertificateField `json:"fields"`
+}
+
+type NewContest struct {
+	UnitID         *string                  `json:"unitId,omitempty"`
+	Title          string                   `json:"title"`
+	Description    *string                  `json:"description,omitempty"`
+	StartAt        string                   `json:"startAt"`
+	EndAt          string                   `json:"endAt"`
+	MinAge         *int                     `json:"minAge,omitempty"`
+	MaxAge         *int                     `json:"maxAge,omitempty"`
+	MinGrade       *int                     `json:"minGrade,omitempty"`
+	MaxGrade       *int                     `json:"maxGrade,omitempty"`
+	InvitationOnly bool                     `json:"invitation_only"`
+	Capacity       *int                     `json:"capacity,omitempty"`
+	MinTeamSize    *int                     `json:"minTeamSize,omitempty"`
+	MaxTeamSize    *int                     `json:"maxTeamSize,omitempty"`
+	AgeCategories  []*NewContestAgeCategory `json:"ageCategories"`
+}
+
+type NewContestAgeCategory struct {
+	Name   string `json:"name"`
+	MinAge int    `json:"minAge"`
+	MaxAge int    `json:"maxAge"`
+}
+
+type NewContestTask struct {
+	ContestID string `json:"contestId"`
+	Position  int    `json:"position"`
+	Title     string `json:"title"`
+	Statement string `json:"statement"`
+}
+
+type NewGroup struct {
+	UnitID      string  `json:"unitId"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+type NewTemplateLock struct {
+	Kind       TemplateLockKind `json:"kind"`
+	TargetName string           `json:"targetName"`
+	BlockID    *string          `json:"blockId,omitempty"`
+}
+
+type NewUnit struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
 type NewUser struct {
 	Email      string  `json:"email"`
 	Password   string  `json:"password"`
@@ -70,6 +458,9 @@ type NewUser struct {
 	Lastname   string  `json:"lastname"`
 	Middlename *string `json:"middlename,omitempty"`
 	Nickname   string  `json:"nickname"`
+	UnitID     *string `json:"unitId,omitempty"`
+	BirthDate  *string `json:"birthDate,omitempty"`
+	Grade      *int    `json:"grade,omitempty"`
 }
 
 type NewUserResponse struct {
@@ -81,19 +472,77 @@ type NewUserResponse struct {
 	Middlename string `json:"middlename"`
 }
 
+type NotificationHTTP struct {
+	ID        string           `json:"id"`
+	CreatedAt string           `json:"createdAt"`
+	Kind      NotificationKind `json:"kind"`
+	Title     string           `json:"title"`
+	Text      string           `json:"text"`
+	Payload   string           `json:"payload"`
+	ReadAt    *string          `json:"readAt,omitempty"`
+}
+
+type NotificationHTTPList struct {
+	Notifications []*NotificationHTTP `json:"notifications"`
+	CountRows     int                 `json:"countRows"`
+	CountUnread   int                 `json:"countUnread"`
+}
+
+type NotificationPreferenceHTTP struct {
+	Kind  NotificationKind `json:"kind"`
+	InApp bool             `json:"inApp"`
+	Email bool             `json:"email"`
+}
+
+type ParentLinkCodeHTTP struct {
+	ID              string               `json:"id"`
+	CreatedAt       string               `json:"createdAt"`
+	ExpiresAt       string               `json:"expiresAt"`
+	Child           *UserHTTP            `json:"child"`
+	CreatedByID     string               `json:"createdById"`
+	RequireApproval bool                 `json:"requireApproval"`
+	Status          ParentLinkCodeStatus `json:"status"`
+	RedeemedBy      *UserHTTP            `json:"redeemedBy,omitempty"`
+}
+
+type ParentLinkCodeHTTPList struct {
+	ParentLinkCodes []*ParentLinkCodeHTTP `json:"parentLinkCodes"`
+	CountRows       int                   `json:"countRows"`
+}
+
+type PasswordPolicy struct {
+	MinLength        int  `json:"minLength"`
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireSpecial   bool `json:"requireSpecial"`
+	ForbidCommon     bool `json:"forbidCommon"`
+}
+
+type PasswordPolicyInput struct {
+	MinLength        int  `json:"minLength"`
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireSpecial   bool `json:"requireSpecial"`
+	ForbidCommon     bool `json:"forbidCommon"`
+}
+
 type ProjectPageHTTP struct {
-	ID               string `json:"id"`
-	CreatedAt        string `json:"createdAt"`
-	UpdatedAt        string `json:"updatedAt"`
-	AuthorID         string `json:"authorId"`
-	ProjectID        string `json:"projectId"`
-	ProjectUpdatedAt string `json:"projectUpdatedAt"`
-	Title            string `json:"title"`
-	Instruction      string `json:"instruction"`
-	Notes            string `json:"notes"`
-	LinkToScratch    string `json:"linkToScratch"`
-	IsShared         bool   `json:"isShared"`
-	IsBanned         bool   `json:"isBanned"`
+	ID               string  `json:"id"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+	AuthorID         string  `json:"authorId"`
+	ProjectID        string  `json:"projectId"`
+	ProjectUpdatedAt string  `json:"projectUpdatedAt"`
+	Title            string  `json:"title"`
+	Instruction      string  `json:"instruction"`
+	Notes            string  `json:"notes"`
+	LinkToScratch    string  `json:"linkToScratch"`
+	IsShared         bool    `json:"isShared"`
+	IsBanned         bool    `json:"isBanned"`
+	IsTemplate       bool    `json:"isTemplate"`
+	TemplateID       *string `json:"templateId,omitempty"`
 }
 
 type ProjectPageHTTPList struct {
@@ -101,12 +550,40 @@ type ProjectPageHTTPList struct {
 	CountRows    int                `json:"countRows"`
 }
 
+type ProjectPageModerationHTTP struct {
+	ProjectPageID string `json:"projectPageId"`
+	Title         string `json:"title"`
+	IsBanned      bool   `json:"isBanned"`
+	ModeratedAt   string `json:"moderatedAt"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type RegistrationRequestHTTP struct {
+	ID        string             `json:"id"`
+	CreatedAt string             `json:"createdAt"`
+	User      *UserHTTP          `json:"user"`
+	IP        string             `json:"ip"`
+	UserAgent string             `json:"userAgent"`
+	Status    RegistrationStatus `json:"status"`
+	Reason    string             `json:"reason"`
+}
+
+type RegistrationRequestHTTPList struct {
+	RegistrationRequests []*RegistrationRequestHTTP `json:"registrationRequests"`
+	CountRows            int                        `json:"countRows"`
+}
+
 type Response struct {
 	Ok bool `json:"ok"`
 }
 
 type Settings struct {
-	ActivationByLink bool `json:"activationByLink"`
+	ActivationByLink       bool            `json:"activationByLink"`
+	TwoFactorRequiredRoles []Role          `json:"twoFactorRequiredRoles"`
+	PasswordPolicy         *PasswordPolicy `json:"passwordPolicy"`
 }
 
 type SignIn struct {
@@ -115,8 +592,11 @@ type SignIn struct {
 }
 
 type SignInResponse struct {
-	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"`
+	AccessToken                 string `json:"accessToken"`
+	RefreshToken                string `json:"refreshToken"`
+	TwoFactorRequired           bool   `json:"twoFactorRequired"`
+	TwoFactorEnrollmentRequired bool   `json:"twoFactorEnrollmentRequired"`
+	ChallengeToken              string `json:"challengeToken"`
 }
 
 type SignUp struct {
@@ -128,6 +608,134 @@ type SignUp struct {
 	Middlename *string `json:"middlename,omitempty"`
 }
 
+type TeamHTTP struct {
+	ID            string            `json:"id"`
+	CreatedAt     string            `json:"createdAt"`
+	ContestID     string            `json:"contestId"`
+	Name          string            `json:"name"`
+	Captain       *TeamMemberHTTP   `json:"captain"`
+	Members       []*TeamMemberHTTP `json:"members"`
+	ProjectPageID string            `json:"projectPageId"`
+	SubmittedAt   *string           `json:"submittedAt,omitempty"`
+	Score         *float64          `json:"score,omitempty"`
+	Place         *int              `json:"place,omitempty"`
+}
+
+type TeamHTTPList struct {
+	Teams     []*TeamHTTP `json:"teams"`
+	CountRows int         `json:"countRows"`
+}
+
+type TeamInvitationHTTP struct {
+	ID        string          `json:"id"`
+	CreatedAt string          `json:"createdAt"`
+	Team      *TeamHTTP       `json:"team"`
+	User      *TeamMemberHTTP `json:"user"`
+}
+
+type TeamInvitationHTTPList struct {
+	TeamInvitations []*TeamInvitationHTTP `json:"teamInvitations"`
+	CountRows       int                   `json:"countRows"`
+}
+
+type TeamMemberHTTP struct {
+	ID         string `json:"id"`
+	Firstname  string `json:"firstname"`
+	Lastname   string `json:"lastname"`
+	Middlename string `json:"middlename"`
+	Nickname   string `json:"nickname"`
+}
+
+type TemplateLockHTTP struct {
+	ID            string           `json:"id"`
+	ProjectPageID string           `json:"projectPageId"`
+	Kind          TemplateLockKind `json:"kind"`
+	TargetName    string           `json:"targetName"`
+	BlockID       string           `json:"blockId"`
+}
+
+type TwoFactorConfirmation struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+	AccessToken   string   `json:"accessToken"`
+	RefreshToken  string   `json:"refreshToken"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type UnitHTTP struct {
+	ID          string `json:"id"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UnitHTTPList struct {
+	Units     []*UnitHTTP `json:"units"`
+	CountRows int         `json:"countRows"`
+}
+
+type UpdateAssignment struct {
+	ID          string               `json:"id"`
+	Title       string               `json:"title"`
+	Instruction string               `json:"instruction"`
+	Deadline    string               `json:"deadline"`
+	LatePolicy  AssignmentLatePolicy `json:"latePolicy"`
+	LatePenalty *int                 `json:"latePenalty,omitempty"`
+	MaxGrade    int                  `json:"maxGrade"`
+}
+
+type UpdateCertificateTemplate struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Background string                 `json:"background"`
+	Width      float64                `json:"width"`
+	Height     float64                `json:"height"`
+	QRX        float64                `json:"qrX"`
+	QRY        float64                `json:"qrY"`
+	QRSize     float64                `json:"qrSize"`
+	Fields     []*NewCertificateField `json:"fields"`
+}
+
+type UpdateContest struct {
+	ID             string                   `json:"id"`
+	Title          string                   `json:"title"`
+	Description    string                   `json:"description"`
+	StartAt        string                   `json:"startAt"`
+	EndAt          string                   `json:"endAt"`
+	MinAge         *int                     `json:"minAge,omitempty"`
+	MaxAge         *int                     `json:"maxAge,omitempty"`
+	MinGrade       *int                     `json:"minGrade,omitempty"`
+	MaxGrade       *int                     `json:"maxGrade,omitempty"`
+	InvitationOnly bool                     `json:"invitation_only"`
+	Capacity       *int                     `json:"capacity,omitempty"`
+	MinTeamSize    *int                     `json:"minTeamSize,omitempty"`
+	MaxTeamSize    *int                     `json:"maxTeamSize,omitempty"`
+	AgeCategories  []*NewContestAgeCategory `json:"ageCategories"`
+}
+
+type UpdateContestTask struct {
+	ID        string `json:"id"`
+	Position  int    `json:"position"`
+	Title     string `json:"title"`
+	Statement string `json:"statement"`
+}
+
+type UpdateGroup struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateNotificationPreference struct {
+	Kind  NotificationKind `json:"kind"`
+	InApp bool             `json:"inApp"`
+	Email bool             `json:"email"`
+}
+
 type UpdateProjectPage struct {
 	ID          string `json:"id"`
 	Title       string `json:"title"`
@@ -136,28 +744,38 @@ type UpdateProjectPage struct {
 	IsShared    bool   `json:"isShared"`
 }
 
+type UpdateUnit struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
 type UpdateUser struct {
-	ID         string `json:"id"`
-	Email      string `json:"email"`
-	Firstname  string `json:"firstname"`
-	Lastname   string `json:"lastname"`
-	Middlename string `json:"middlename"`
-	Nickname   string `json:"nickname"`
+	ID         string  `json:"id"`
+	Email      string  `json:"email"`
+	Firstname  string  `json:"firstname"`
+	Lastname   string  `json:"lastname"`
+	Middlename string  `json:"middlename"`
+	Nickname   string  `json:"nickname"`
+	BirthDate  *string `json:"birthDate,omitempty"`
+	Grade      *int    `json:"grade,omitempty"`
 }
 
 type UserHTTP struct {
-	ID             string `json:"id"`
-	CreatedAt      string `json:"createdAt"`
-	UpdatedAt      string `json:"updatedAt"`
-	Email          string `json:"email"`
-	Password       string `json:"password"`
-	Role           Role   `json:"role"`
-	Firstname      string `json:"firstname"`
-	Lastname       string `json:"lastname"`
-	Middlename     string `json:"middlename"`
-	Nickname       string `json:"nickname"`
-	IsActive       bool   `json:"isActive"`
-	ActivationLink string `json:"activationLink"`
+	ID             string  `json:"id"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+	Email          string  `json:"email"`
+	Password       string  `json:"password"`
+	Role           Role    `json:"role"`
+	Firstname      string  `json:"firstname"`
+	Lastname       string  `json:"lastname"`
+	Middlename     string  `json:"middlename"`
+	Nickname       string  `json:"nickname"`
+	IsActive       bool    `json:"isActive"`
+	ActivationLink string  `json:"activationLink"`
+	BirthDate      *string `json:"birthDate,omitempty"`
+	Grade          *int    `json:"grade,omitempty"`
 }
 
 type UsersList struct {
@@ -165,6 +783,495 @@ type UsersList struct {
 	CountRows int         `json:"countRows"`
 }
 
+type ActivityKind string
+
+const (
+	ActivityKindProjectCreated      ActivityKind = "ProjectCreated"
+	ActivityKindProjectUpdated      ActivityKind = "ProjectUpdated"
+	ActivityKindProjectBanned       ActivityKind = "ProjectBanned"
+	ActivityKindProjectUnbanned     ActivityKind = "ProjectUnbanned"
+	ActivityKindAssignmentSubmitted ActivityKind = "AssignmentSubmitted"
+	ActivityKindAssignmentReviewed  ActivityKind = "AssignmentReviewed"
+)
+
+var AllActivityKind = []ActivityKind{
+	ActivityKindProjectCreated,
+	ActivityKindProjectUpdated,
+	ActivityKindProjectBanned,
+	ActivityKindProjectUnbanned,
+	ActivityKindAssignmentSubmitted,
+	ActivityKindAssignmentReviewed,
+}
+
+func (e ActivityKind) IsValid() bool {
+	switch e {
+	case ActivityKindProjectCreated, ActivityKindProjectUpdated, ActivityKindProjectBanned, ActivityKindProjectUnbanned, ActivityKindAssignmentSubmitted, ActivityKindAssignmentReviewed:
+		return true
+	}
+	return false
+}
+
+func (e ActivityKind) String() string {
+	return string(e)
+}
+
+func (e *ActivityKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ActivityKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ActivityKind", str)
+	}
+	return nil
+}
+
+func (e ActivityKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AssignmentLatePolicy string
+
+const (
+	AssignmentLatePolicyDeny    AssignmentLatePolicy = "Deny"
+	AssignmentLatePolicyAllow   AssignmentLatePolicy = "Allow"
+	AssignmentLatePolicyPenalty AssignmentLatePolicy = "Penalty"
+)
+
+var AllAssignmentLatePolicy = []AssignmentLatePolicy{
+	AssignmentLatePolicyDeny,
+	AssignmentLatePolicyAllow,
+	AssignmentLatePolicyPenalty,
+}
+
+func (e AssignmentLatePolicy) IsValid() bool {
+	switch e {
+	case AssignmentLatePolicyDeny, AssignmentLatePolicyAllow, AssignmentLatePolicyPenalty:
+		return true
+	}
+	return false
+}
+
+func (e AssignmentLatePolicy) String() string {
+	return string(e)
+}
+
+func (e *AssignmentLatePolicy) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AssignmentLatePolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AssignmentLatePolicy", str)
+	}
+	return nil
+}
+
+func (e AssignmentLatePolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AssignmentStatus string
+
+const (
+	AssignmentStatusNotStarted AssignmentStatus = "NotStarted"
+	AssignmentStatusInProgress AssignmentStatus = "InProgress"
+	AssignmentStatusSubmitted  AssignmentStatus = "Submitted"
+	AssignmentStatusReviewed   AssignmentStatus = "Reviewed"
+)
+
+var AllAssignmentStatus = []AssignmentStatus{
+	AssignmentStatusNotStarted,
+	AssignmentStatusInProgress,
+	AssignmentStatusSubmitted,
+	AssignmentStatusReviewed,
+}
+
+func (e AssignmentStatus) IsValid() bool {
+	switch e {
+	case AssignmentStatusNotStarted, AssignmentStatusInProgress, AssignmentStatusSubmitted, AssignmentStatusReviewed:
+		return true
+	}
+	return false
+}
+
+func (e AssignmentStatus) String() string {
+	return string(e)
+}
+
+func (e *AssignmentStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AssignmentStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AssignmentStatus", str)
+	}
+	return nil
+}
+
+func (e AssignmentStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type CertificateTextAlign string
+
+const (
+	CertificateTextAlignLeft   CertificateTextAlign = "Left"
+	CertificateTextAlignCenter CertificateTextAlign = "Center"
+	CertificateTextAlignRight  CertificateTextAlign = "Right"
+)
+
+var AllCertificateTextAlign = []CertificateTextAlign{
+	CertificateTextAlignLeft,
+	CertificateTextAlignCenter,
+	CertificateTextAlignRight,
+}
+
+func (e CertificateTextAlign) IsValid() bool {
+	switch e {
+	case CertificateTextAlignLeft, CertificateTextAlignCenter, CertificateTextAlignRight:
+		return true
+	}
+	return false
+}
+
+func (e CertificateTextAlign) String() string {
+	return string(e)
+}
+
+func (e *CertificateTextAlign) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CertificateTextAlign(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CertificateTextAlign", str)
+	}
+	return nil
+}
+
+func (e CertificateTextAlign) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type CohortMismatchKind string
+
+const (
+	CohortMismatchKindMissingInEdx    CohortMismatchKind = "MissingInEdx"
+	CohortMismatchKindUnexpectedInEdx CohortMismatchKind = "UnexpectedInEdx"
+)
+
+var AllCohortMismatchKind = []CohortMismatchKind{
+	CohortMismatchKindMissingInEdx,
+	CohortMismatchKindUnexpectedInEdx,
+}
+
+func (e CohortMismatchKind) IsValid() bool {
+	switch e {
+	case CohortMismatchKindMissingInEdx, CohortMismatchKindUnexpectedInEdx:
+		return true
+	}
+	return false
+}
+
+func (e CohortMismatchKind) String() string {
+	return string(e)
+}
+
+func (e *CohortMismatchKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CohortMismatchKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CohortMismatchKind", str)
+	}
+	return nil
+}
+
+func (e CohortMismatchKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ContestEventKind string
+
+const (
+	ContestEventKindAnnouncement          ContestEventKind = "Announcement"
+	ContestEventKindClarificationAsked    ContestEventKind = "ClarificationAsked"
+	ContestEventKindClarificationAnswered ContestEventKind = "ClarificationAnswered"
+)
+
+var AllContestEventKind = []ContestEventKind{
+	ContestEventKindAnnouncement,
+	ContestEventKindClarificationAsked,
+	ContestEventKindClarificationAnswered,
+}
+
+func (e ContestEventKind) IsValid() bool {
+	switch e {
+	case ContestEventKindAnnouncement, ContestEventKindClarificationAsked, ContestEventKindClarificationAnswered:
+		return true
+	}
+	return false
+}
+
+func (e ContestEventKind) String() string {
+	return string(e)
+}
+
+func (e *ContestEventKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ContestEventKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ContestEventKind", str)
+	}
+	return nil
+}
+
+func (e ContestEventKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ContestRegistrationStatus string
+
+const (
+	ContestRegistrationStatusWaitlisted      ContestRegistrationStatus = "Waitlisted"
+	ContestRegistrationStatusAwaitingConsent ContestRegistrationStatus = "AwaitingConsent"
+	ContestRegistrationStatusConfirmed       ContestRegistrationStatus = "Confirmed"
+)
+
+var AllContestRegistrationStatus = []ContestRegistrationStatus{
+	ContestRegistrationStatusWaitlisted,
+	ContestRegistrationStatusAwaitingConsent,
+	ContestRegistrationStatusConfirmed,
+}
+
+func (e ContestRegistrationStatus) IsValid() bool {
+	switch e {
+	case ContestRegistrationStatusWaitlisted, ContestRegistrationStatusAwaitingConsent, ContestRegistrationStatusConfirmed:
+		return true
+	}
+	return false
+}
+
+func (e ContestRegistrationStatus) String() string {
+	return string(e)
+}
+
+func (e *ContestRegistrationStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ContestRegistrationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ContestRegistrationStatus", str)
+	}
+	return nil
+}
+
+func (e ContestRegistrationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type LtiMessageType string
+
+const (
+	LtiMessageTypeResourceLink LtiMessageType = "ResourceLink"
+	LtiMessageTypeDeepLinking  LtiMessageType = "DeepLinking"
+)
+
+var AllLtiMessageType = []LtiMessageType{
+	LtiMessageTypeResourceLink,
+	LtiMessageTypeDeepLinking,
+}
+
+func (e LtiMessageType) IsValid() bool {
+	switch e {
+	case LtiMessageTypeResourceLink, LtiMessageTypeDeepLinking:
+		return true
+	}
+	return false
+}
+
+func (e LtiMessageType) String() string {
+	return string(e)
+}
+
+func (e *LtiMessageType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LtiMessageType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LtiMessageType", str)
+	}
+	return nil
+}
+
+func (e LtiMessageType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type NotificationKind string
+
+const (
+	NotificationKindProjectBanned          NotificationKind = "ProjectBanned"
+	NotificationKindSubmissionReviewed     NotificationKind = "SubmissionReviewed"
+	NotificationKindContestResultPublished NotificationKind = "ContestResultPublished"
+	NotificationKindParentLinkRequested    NotificationKind = "ParentLinkRequested"
+	NotificationKindParentLinked           NotificationKind = "ParentLinked"
+	NotificationKindParentLinkRejected     NotificationKind = "ParentLinkRejected"
+	NotificationKindChildEvent             NotificationKind = "ChildEvent"
+)
+
+var AllNotificationKind = []NotificationKind{
+	NotificationKindProjectBanned,
+	NotificationKindSubmissionReviewed,
+	NotificationKindContestResultPublished,
+	NotificationKindParentLinkRequested,
+	NotificationKindParentLinked,
+	NotificationKindParentLinkRejected,
+	NotificationKindChildEvent,
+}
+
+func (e NotificationKind) IsValid() bool {
+	switch e {
+	case NotificationKindProjectBanned, NotificationKindSubmissionReviewed, NotificationKindContestResultPublished, NotificationKindParentLinkRequested, NotificationKindParentLinked, NotificationKindParentLinkRejected, NotificationKindChildEvent:
+		return true
+	}
+	return false
+}
+
+func (e NotificationKind) String() string {
+	return string(e)
+}
+
+func (e *NotificationKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationKind", str)
+	}
+	return nil
+}
+
+func (e NotificationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ParentLinkCodeStatus string
+
+const (
+	ParentLinkCodeStatusActive          ParentLinkCodeStatus = "Active"
+	ParentLinkCodeStatusPendingApproval ParentLinkCodeStatus = "PendingApproval"
+	ParentLinkCodeStatusRedeemed        ParentLinkCodeStatus = "Redeemed"
+	ParentLinkCodeStatusRejected        ParentLinkCodeStatus = "Rejected"
+	ParentLinkCodeStatusRevoked         ParentLinkCodeStatus = "Revoked"
+)
+
+var AllParentLinkCodeStatus = []ParentLinkCodeStatus{
+	ParentLinkCodeStatusActive,
+	ParentLinkCodeStatusPendingApproval,
+	ParentLinkCodeStatusRedeemed,
+	ParentLinkCodeStatusRejected,
+	ParentLinkCodeStatusRevoked,
+}
+
+func (e ParentLinkCodeStatus) IsValid() bool {
+	switch e {
+	case ParentLinkCodeStatusActive, ParentLinkCodeStatusPendingApproval, ParentLinkCodeStatusRedeemed, ParentLinkCodeStatusRejected, ParentLinkCodeStatusRevoked:
+		return true
+	}
+	return false
+}
+
+func (e ParentLinkCodeStatus) String() string {
+	return string(e)
+}
+
+func (e *ParentLinkCodeStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ParentLinkCodeStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ParentLinkCodeStatus", str)
+	}
+	return nil
+}
+
+func (e ParentLinkCodeStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RegistrationStatus string
+
+const (
+	RegistrationStatusPending  RegistrationStatus = "Pending"
+	RegistrationStatusApproved RegistrationStatus = "Approved"
+	RegistrationStatusRejected RegistrationStatus = "Rejected"
+)
+
+var AllRegistrationStatus = []RegistrationStatus{
+	RegistrationStatusPending,
+	RegistrationStatusApproved,
+	RegistrationStatusRejected,
+}
+
+func (e RegistrationStatus) IsValid() bool {
+	switch e {
+	case RegistrationStatusPending, RegistrationStatusApproved, RegistrationStatusRejected:
+		return true
+	}
+	return false
+}
+
+func (e RegistrationStatus) String() string {
+	return string(e)
+}
+
+func (e *RegistrationStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RegistrationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RegistrationStatus", str)
+	}
+	return nil
+}
+
+func (e RegistrationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type Role string
 
 const (
@@ -213,3 +1320,44 @@ func (e *Role) UnmarshalGQL(v interface{}) error {
 func (e Role) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type TemplateLockKind string
+
+const (
+	TemplateLockKindSprite TemplateLockKind = "Sprite"
+	TemplateLockKindScript TemplateLockKind = "Script"
+)
+
+var AllTemplateLockKind = []TemplateLockKind{
+	TemplateLockKindSprite,
+	TemplateLockKindScript,
+}
+
+func (e TemplateLockKind) IsValid() bool {
+	switch e {
+	case TemplateLockKindSprite, TemplateLockKindScript:
+		return true
+	}
+	return false
+}
+
+func (e TemplateLockKind) String() string {
+	return string(e)
+}
+
+func (e *TemplateLockKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TemplateLockKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TemplateLockKind", str)
+	}
+	return nil
+}
+
+func (e TemplateLockKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
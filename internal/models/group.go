@@ -0,0 +1,51 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// GroupCore is a class of students within a unit taught by its teachers
+type GroupCore struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	UnitID      uint           `gorm:"not null;index"`
+	Unit        UnitCore       `gorm:"foreignKey:UnitID"`
+	Name        string         `gorm:"size:256;not null"`
+	Description string         `gorm:"size:1024"`
+}
+
+type GroupTeacherCore struct {
+	GroupID uint      `gorm:"primaryKey"`
+	Group   GroupCore `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE;"`
+	UserID  uint      `gorm:"primaryKey;index"`
+	User    UserCore  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+type GroupStudentCore struct {
+	GroupID uint      `gorm:"primaryKey"`
+	Group   GroupCore `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE;"`
+	UserID  uint      `gorm:"primaryKey;index"`
+	User    UserCore  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (g *GroupHTTP) FromCore(groupCore GroupCore) {
+	g.ID = strconv.Itoa(int(groupCore.ID))
+	g.CreatedAt = groupCore.CreatedAt.Format(time.DateTime)
+	g.UpdatedAt = groupCore.UpdatedAt.Format(time.DateTime)
+	g.UnitID = strconv.Itoa(int(groupCore.UnitID))
+	g.Name = groupCore.Name
+	g.Description = groupCore.Description
+}
+
+func FromGroupsCore(groupsCore []GroupCore) (groupsHttp []*GroupHTTP) {
+	for _, groupCore := range groupsCore {
+		var tmpGroupHttp GroupHTTP
+		tmpGroupHttp.FromCore(groupCore)
+		groupsHttp = append(groupsHttp, &tmpGroupHttp)
+	}
+	return
+}
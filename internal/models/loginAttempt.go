@@ -0,0 +1,46 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// LoginAttemptCore counts failed sign in attempts for an account or an ip address
+type LoginAttemptCore struct {
+	ID            uint   `gorm:"primaryKey"`
+	Key           string `gorm:"not null;uniqueIndex;column:attempt_key"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+	// UnlockToken keeps sha256 of the token sent by email
+	UnlockToken string `gorm:"index"`
+}
+
+type LockoutEventCore struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UserID      uint
+	Email       string
+	Ip          string
+	Reason      string `gorm:"not null"`
+	LockedUntil time.Time
+}
+
+func (l *LockoutEventHTTP) FromCore(eventCore LockoutEventCore) {
+	l.ID = strconv.Itoa(int(eventCore.ID))
+	l.CreatedAt = eventCore.CreatedAt.Format(time.DateTime)
+	l.UserID = strconv.Itoa(int(eventCore.UserID))
+	l.Email = eventCore.Email
+	l.IP = eventCore.Ip
+	l.Reason = eventCore.Reason
+	l.LockedUntil = eventCore.LockedUntil.Format(time.DateTime)
+}
+
+func FromLockoutEventsCore(eventsCore []LockoutEventCore) (eventsHttp []*LockoutEventHTTP) {
+	for _, eventCore := range eventsCore {
+		var tmpEventHttp LockoutEventHTTP
+		tmpEventHttp.FromCore(eventCore)
+		eventsHttp = append(eventsHttp, &tmpEventHttp)
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// LtiLaunchCore keeps the validated LTI launch until the frontend exchanges the one-time
+// code for tokens. Deep linking launches are answered within the lifetime of the record.
+type LtiLaunchCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	// Code keeps sha256 of the code passed to the frontend
+	Code              string         `gorm:"not null;uniqueIndex"`
+	IsUsed            bool           `gorm:"not null;default:false;type:boolean;column:is_used"`
+	UserID            uint           `gorm:"not null;index"`
+	User              UserCore       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Platform          string         `gorm:"not null"`
+	MessageType       LtiMessageType `gorm:"not null"`
+	DeploymentID      string         `gorm:"not null"`
+	ProjectPageID     *uint
+	GradeLinkID       *uint
+	DeepLinkReturnUrl string
+	DeepLinkData      string
+	ExpiresAt         time.Time
+}
+
+// LtiGradeLinkCore is the gradebook column of the LMS resource link, scores are posted to its line item
+type LtiGradeLinkCore struct {
+	ID             uint `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Platform       string `gorm:"not null;uniqueIndex:idx_platform_resource_link"`
+	ResourceLinkID string `gorm:"not null;uniqueIndex:idx_platform_resource_link"`
+	Title          string
+	LineItemUrl    string `gorm:"not null"`
+	ProjectPageID  *uint  `gorm:"index"`
+	// OwnerID is the instructor who opened the resource link first, the owner may post scores to it
+	OwnerID *uint
+}
+
+func (l *LtiLaunchHTTP) FromCore(launchCore LtiLaunchCore) {
+	l.ID = strconv.Itoa(int(launchCore.ID))
+	l.MessageType = launchCore.MessageType
+	if launchCore.ProjectPageID != nil {
+		projectPageId := strconv.Itoa(int(*launchCore.ProjectPageID))
+		l.ProjectPageID = &projectPageId
+	}
+	if launchCore.GradeLinkID != nil {
+		gradeLinkId := strconv.Itoa(int(*launchCore.GradeLinkID))
+		l.GradeLinkID = &gradeLinkId
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// NotificationCore is a message to the user shown in the notification center and delivered live to connected clients
+type NotificationCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint             `gorm:"not null;index"`
+	User      UserCore         `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Kind      NotificationKind `gorm:"not null"`
+	Title     string           `gorm:"size:256;not null"`
+	Text      string           `gorm:"size:4096;not null"`
+	// Payload has ids of the subject of the notification, e.g. projectPageId, for links in the client
+	Payload map[string]string `gorm:"serializer:json"`
+	ReadAt  *time.Time
+}
+
+// NotificationPreferenceCore keeps the choice of the user, kinds without a preference use the defaults
+type NotificationPreferenceCore struct {
+	ID     uint             `gorm:"primaryKey"`
+	UserID uint             `gorm:"not null;uniqueIndex:idx_user_notification_kind"`
+	User   UserCore         `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Kind   NotificationKind `gorm:"not null;uniqueIndex:idx_user_notification_kind"`
+	InApp  bool             `gorm:"not null;type:boolean;column:in_app"`
+	Email  bool             `gorm:"not null;type:boolean;column:email"`
+}
+
+func (n *NotificationHTTP) FromCore(notificationCore NotificationCore) {
+	n.ID = strconv.Itoa(int(notificationCore.ID))
+	n.CreatedAt = notificationCore.CreatedAt.Format(time.DateTime)
+	n.Kind = notificationCore.Kind
+	n.Title = notificationCore.Title
+	n.Text = notificationCore.Text
+	n.Payload = "{}"
+	if payload, err := json.Marshal(notificationCore.Payload); err == nil && notificationCore.Payload != nil {
+		n.Payload = string(payload)
+	}
+	if notificationCore.ReadAt != nil {
+		readAt := notificationCore.ReadAt.Format(time.DateTime)
+		n.ReadAt = &readAt
+	}
+}
+
+func FromNotificationsCore(notificationsCore []NotificationCore) (notificationsHttp []*NotificationHTTP) {
+	for _, notificationCore := range notificationsCore {
+		var tmpNotificationHttp NotificationHTTP
+		tmpNotificationHttp.FromCore(notificationCore)
+		notificationsHttp = append(notificationsHttp, &tmpNotificationHttp)
+	}
+	return
+}
+
+func (n *NotificationPreferenceHTTP) FromCore(preferenceCore NotificationPreferenceCore) {
+	n.Kind = preferenceCore.Kind
+	n.InApp = preferenceCore.InApp
+	n.Email = preferenceCore.Email
+}
+
+func FromNotificationPreferencesCore(preferencesCore []NotificationPreferenceCore) (
+	preferencesHttp []*NotificationPreferenceHTTP) {
+	for _, preferenceCore := range preferencesCore {
+		var tmpPreferenceHttp NotificationPreferenceHTTP
+		tmpPreferenceHttp.FromCore(preferenceCore)
+		preferencesHttp = append(preferencesHttp, &tmpPreferenceHttp)
+	}
+	return
+}
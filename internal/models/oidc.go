@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// OidcStateCore keeps the PKCE verifier and nonce between the redirect
+// to the OIDC provider and the sign in with the returned code
+type OidcStateCore struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	State        string `gorm:"not null;uniqueIndex"`
+	Provider     string `gorm:"not null"`
+	CodeVerifier string `gorm:"not null"`
+	Nonce        string `gorm:"not null"`
+	// BrowserBinding keeps sha256 of the value of the cookie set in the browser that started the sign in
+	BrowserBinding string
+	ExpiresAt      time.Time
+}
+
+// UserIdentityCore links the user to the account of an external identity provider
+type UserIdentityCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint     `gorm:"not null;index"`
+	User      UserCore `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Provider  string   `gorm:"not null;uniqueIndex:idx_provider_subject"`
+	Subject   string   `gorm:"not null;uniqueIndex:idx_provider_subject"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// ChildProgressCore is the progress of the student shown on the dashboard of the parent
+type ChildProgressCore struct {
+	Child             UserCore
+	ProjectPages      []ProjectPageCore
+	CountProjectPages uint
+	Submissions       []AssignmentSubmissionCore
+	Contests          []ChildContestCore
+	Activity          []ActivityCore
+}
+
+// ChildContestCore is the registration of the student for the contest,
+// the team is set for team contests once the student joins a team
+type ChildContestCore struct {
+	Registration ContestRegistrationCore
+	Team         *TeamCore
+}
+
+func (c *ChildProgressHTTP) FromCore(progressCore ChildProgressCore) {
+	var childHttp UserHTTP
+	childHttp.FromCore(progressCore.Child)
+	c.Child = &childHttp
+	c.ProjectPages = FromProjectPagesCore(progressCore.ProjectPages)
+	c.CountProjectPages = int(progressCore.CountProjectPages)
+	c.AssignmentSubmissions = FromAssignmentSubmissionsCore(progressCore.Submissions)
+	c.Contests = []*ChildContestHTTP{}
+	for _, contestCore := range progressCore.Contests {
+		var tmpContestHttp ChildContestHTTP
+		tmpContestHttp.FromCore(contestCore)
+		c.Contests = append(c.Contests, &tmpContestHttp)
+	}
+	c.Activity = FromActivitiesCore(progressCore.Activity)
+}
+
+func (c *ChildContestHTTP) FromCore(contestCore ChildContestCore) {
+	var registrationHttp ContestRegistrationHTTP
+	registrationHttp.FromCore(contestCore.Registration)
+	c.Registration = &registrationHttp
+	if contestCore.Team == nil {
+		return
+	}
+	c.TeamName = &contestCore.Team.Name
+	if contestCore.Team.SubmittedAt != nil {
+		submittedAt := contestCore.Team.SubmittedAt.Format(time.DateTime)
+		c.SubmittedAt = &submittedAt
+	}
+	c.Score = contestCore.Team.Score
+	c.Place = contestCore.Team.Place
+}
+
+func FromChildrenProgressCore(progressesCore []ChildProgressCore) (progressesHttp []*ChildProgressHTTP) {
+	for _, progressCore := range progressesCore {
+		var tmpProgressHttp ChildProgressHTTP
+		tmpProgressHttp.FromCore(progressCore)
+		progressesHttp = append(progressesHttp, &tmpProgressHttp)
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// ParentLinkCodeCore is the one-time code of the student, which a parent redeems
+// to create the parent relationship, optionally after the approval of the student
+type ParentLinkCodeCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// Code keeps sha256 of the code
+	Code            string               `gorm:"not null;uniqueIndex"`
+	ChildID         uint                 `gorm:"not null;index"`
+	Child           UserCore             `gorm:"foreignKey:ChildID;constraint:OnDelete:CASCADE;"`
+	CreatedByID     uint                 `gorm:"not null;index"`
+	RequireApproval bool                 `gorm:"not null;default:false;type:boolean;column:require_approval"`
+	Status          ParentLinkCodeStatus `gorm:"not null"`
+	ExpiresAt       time.Time
+	RedeemedByID    *uint
+	RedeemedBy      *UserCore `gorm:"foreignKey:RedeemedByID"`
+	RedeemedAt      *time.Time
+}
+
+func (p *ParentLinkCodeHTTP) FromCore(codeCore ParentLinkCodeCore) {
+	var childHttp UserHTTP
+	childHttp.FromCore(codeCore.Child)
+	p.ID = strconv.Itoa(int(codeCore.ID))
+	p.CreatedAt = codeCore.CreatedAt.Format(time.DateTime)
+	p.ExpiresAt = codeCore.ExpiresAt.Format(time.DateTime)
+	p.Child = &childHttp
+	p.CreatedByID = strconv.Itoa(int(codeCore.CreatedByID))
+	p.RequireApproval = codeCore.RequireApproval
+	p.Status = codeCore.Status
+	if codeCore.RedeemedBy != nil {
+		var redeemedByHttp UserHTTP
+		redeemedByHttp.FromCore(*codeCore.RedeemedBy)
+		p.RedeemedBy = &redeemedByHttp
+	}
+}
+
+func FromParentLinkCodesCore(codesCore []ParentLinkCodeCore) (codesHttp []*ParentLinkCodeHTTP) {
+	for _, codeCore := range codesCore {
+		var tmpCodeHttp ParentLinkCodeHTTP
+		tmpCodeHttp.FromCore(codeCore)
+		codesHttp = append(codesHttp, &tmpCodeHttp)
+	}
+	return
+}
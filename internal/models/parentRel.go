@@ -12,8 +12,9 @@ type ParentRelCore struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
-	ParentID  uint
-	Parent    UserCore `gorm:"constraint:onUpdate:CASCADE;"`
-	ChildID   uint
-	Child     UserCore `gorm:"constraint:onUpdate:CASCADE;"`
+	// deleted relationships do not prevent linking the same parent and child again
+	ParentID uint     `gorm:"not null;uniqueIndex:idx_parent_child,where:deleted_at IS NULL"`
+	Parent   UserCore `gorm:"constraint:onUpdate:CASCADE;"`
+	ChildID  uint     `gorm:"not null;uniqueIndex:idx_parent_child,where:deleted_at IS NULL;index"`
+	Child    UserCore `gorm:"constraint:onUpdate:CASCADE;"`
 }
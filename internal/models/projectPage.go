@@ -21,6 +21,11 @@ type ProjectPageCore struct {
 	LinkToScratch string      `gorm:"size:256;not null"`
 	IsShared      bool        `gorm:"type:boolean;default:false;column:is_shared"`
 	IsBanned      bool        `gorm:"type:boolean;default:false;column:is_banned"`
+	IsTemplate    bool        `gorm:"type:boolean;default:false;column:is_template"`
+	// TemplateID is the template project page the project was started from
+	TemplateID *uint `gorm:"index"`
+	// LockedBlocks keeps locks of the template and blocks of its locked scripts at the moment the copy was made
+	LockedBlocks string `gorm:"type:text"`
 }
 
 func (p *ProjectPageHTTP) FromCore(projectPage ProjectPageCore) {
@@ -36,6 +41,11 @@ func (p *ProjectPageHTTP) FromCore(projectPage ProjectPageCore) {
 	p.LinkToScratch = projectPage.LinkToScratch
 	p.IsShared = projectPage.IsShared
 	p.IsBanned = projectPage.IsBanned
+	p.IsTemplate = projectPage.IsTemplate
+	if projectPage.TemplateID != nil {
+		templateId := strconv.Itoa(int(*projectPage.TemplateID))
+		p.TemplateID = &templateId
+	}
 }
 
 func FromProjectPagesCore(projectPagesCore []ProjectPageCore) (projectPagesHttp []*ProjectPageHTTP) {
@@ -46,3 +56,18 @@ func FromProjectPagesCore(projectPagesCore []ProjectPageCore) (projectPagesHttp
 	}
 	return
 }
+
+// ModerationEventCore is delivered live to the author of the project page when it is banned or unbanned
+type ModerationEventCore struct {
+	ProjectPageID uint
+	Title         string
+	IsBanned      bool
+	ModeratedAt   time.Time
+}
+
+func (p *ProjectPageModerationHTTP) FromCore(eventCore ModerationEventCore) {
+	p.ProjectPageID = strconv.Itoa(int(eventCore.ProjectPageID))
+	p.Title = eventCore.Title
+	p.IsBanned = eventCore.IsBanned
+	p.ModeratedAt = eventCore.ModeratedAt.Format(time.DateTime)
+}
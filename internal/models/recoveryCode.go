@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+type RecoveryCodeCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint     `gorm:"not null;index"`
+	User      UserCore `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	CodeHash  string   `gorm:"not null;size:64"`
+	UsedAt    *time.Time
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// RegistrationRequestCore is created on sign up when activation by link is off
+// and waits for a decision of an admin
+type RegistrationRequestCore struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uint               `gorm:"not null;index"`
+	User        UserCore           `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Ip          string             `gorm:"not null"`
+	UserAgent   string             `gorm:"not null"`
+	Status      RegistrationStatus `gorm:"not null;index"`
+	Reason      string
+	DecidedByID *uint
+	DecidedAt   *time.Time
+}
+
+func (r *RegistrationRequestHTTP) FromCore(requestCore RegistrationRequestCore) {
+	var userHttp UserHTTP
+	userHttp.FromCore(requestCore.User)
+	r.ID = strconv.Itoa(int(requestCore.ID))
+	r.CreatedAt = requestCore.CreatedAt.Format(time.DateTime)
+	r.User = &userHttp
+	r.IP = requestCore.Ip
+	r.UserAgent = requestCore.UserAgent
+	r.Status = requestCore.Status
+	r.Reason = requestCore.Reason
+}
+
+func FromRegistrationRequestsCore(requestsCore []RegistrationRequestCore) (requestsHttp []*RegistrationRequestHTTP) {
+	for _, requestCore := range requestsCore {
+		var tmpRequestHttp RegistrationRequestHTTP
+		tmpRequestHttp.FromCore(requestCore)
+		requestsHttp = append(requestsHttp, &tmpRequestHttp)
+	}
+	return
+}
@@ -1,10 +1,48 @@
 package models
 
 type SettingsCore struct {
-	ID               uint `gorm:"primaryKey" json:"id"`
-	ActivationByLink bool `gorm:"not null;default:true;type:boolean;column:activation_by_link"`
+	ID               uint               `gorm:"primaryKey" json:"id"`
+	ActivationByLink bool               `gorm:"not null;default:true;type:boolean;column:activation_by_link"`
+	PasswordPolicy   PasswordPolicyCore `gorm:"embedded;embeddedPrefix:password_"`
+}
+
+// PasswordPolicyCore is checked whenever a user sets a new password
+type PasswordPolicyCore struct {
+	MinLength        int  `gorm:"not null;default:6"`
+	RequireUppercase bool `gorm:"not null;default:false;type:boolean"`
+	RequireLowercase bool `gorm:"not null;default:false;type:boolean"`
+	RequireDigit     bool `gorm:"not null;default:false;type:boolean"`
+	RequireSpecial   bool `gorm:"not null;default:false;type:boolean"`
+	// ForbidCommon rejects passwords from the list of common and breached passwords
+	ForbidCommon bool `gorm:"not null;default:true;type:boolean"`
+}
+
+// RoleSettingsCore keeps settings applied to every user with the role
+type RoleSettingsCore struct {
+	Role              Role `gorm:"primaryKey"`
+	TwoFactorRequired bool `gorm:"not null;default:false;type:boolean;column:two_factor_required"`
 }
 
 func (p *Settings) FromCore(settingsCore SettingsCore) {
 	p.ActivationByLink = settingsCore.ActivationByLink
 }
+
+func (p *PasswordPolicy) FromCore(policyCore PasswordPolicyCore) {
+	p.MinLength = policyCore.MinLength
+	p.RequireUppercase = policyCore.RequireUppercase
+	p.RequireLowercase = policyCore.RequireLowercase
+	p.RequireDigit = policyCore.RequireDigit
+	p.RequireSpecial = policyCore.RequireSpecial
+	p.ForbidCommon = policyCore.ForbidCommon
+}
+
+func (p *PasswordPolicyInput) ToCore() PasswordPolicyCore {
+	return PasswordPolicyCore{
+		MinLength:        p.MinLength,
+		RequireUppercase: p.RequireUppercase,
+		RequireLowercase: p.RequireLowercase,
+		RequireDigit:     p.RequireDigit,
+		RequireSpecial:   p.RequireSpecial,
+		ForbidCommon:     p.ForbidCommon,
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// TeamCore is a team of a team contest. All members work on the shared project of the team,
+// its submission and result belong to every member.
+type TeamCore struct {
+	ID            uint `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ContestID     uint             `gorm:"not null;index"`
+	Contest       ContestCore      `gorm:"foreignKey:ContestID;constraint:OnDelete:CASCADE;"`
+	Name          string           `gorm:"size:256;not null"`
+	CaptainID     uint             `gorm:"not null"`
+	Captain       UserCore         `gorm:"foreignKey:CaptainID"`
+	ProjectPageID uint             `gorm:"not null;index"`
+	ProjectPage   ProjectPageCore  `gorm:"foreignKey:ProjectPageID"`
+	Members       []TeamMemberCore `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE;"`
+	SubmittedAt   *time.Time
+	Score         *float64
+	Place         *int
+}
+
+// TeamMemberCore keeps the contest to let a user be a member of only one team of the contest
+type TeamMemberCore struct {
+	TeamID    uint     `gorm:"primaryKey"`
+	UserID    uint     `gorm:"primaryKey;uniqueIndex:idx_contest_member"`
+	User      UserCore `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	ContestID uint     `gorm:"not null;uniqueIndex:idx_contest_member"`
+}
+
+type TeamInvitationCore struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	TeamID    uint     `gorm:"not null;uniqueIndex:idx_team_invitation"`
+	Team      TeamCore `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE;"`
+	UserID    uint     `gorm:"not null;uniqueIndex:idx_team_invitation;index"`
+	User      UserCore `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (t *TeamMemberHTTP) FromCore(userCore UserCore) {
+	t.ID = strconv.Itoa(int(userCore.ID))
+	t.Firstname = userCore.Firstname
+	t.Lastname = userCore.Lastname
+	t.Middlename = userCore.Middlename
+	t.Nickname = userCore.Nickname
+}
+
+func (t *TeamHTTP) FromCore(teamCore TeamCore) {
+	t.ID = strconv.Itoa(int(teamCore.ID))
+	t.CreatedAt = teamCore.CreatedAt.Format(time.DateTime)
+	t.ContestID = strconv.Itoa(int(teamCore.ContestID))
+	t.Name = teamCore.Name
+	var captainHttp TeamMemberHTTP
+	captainHttp.FromCore(teamCore.Captain)
+	t.Captain = &captainHttp
+	t.Members = []*TeamMemberHTTP{}
+	for _, member := range teamCore.Members {
+		var memberHttp TeamMemberHTTP
+		memberHttp.FromCore(member.User)
+		t.Members = append(t.Members, &memberHttp)
+	}
+	t.ProjectPageID = strconv.Itoa(int(teamCore.ProjectPageID))
+	if teamCore.SubmittedAt != nil {
+		submittedAt := teamCore.SubmittedAt.Format(time.DateTime)
+		t.SubmittedAt = &submittedAt
+	}
+	t.Score = teamCore.Score
+	t.Place = teamCore.Place
+}
+
+func FromTeamsCore(teamsCore []TeamCore) (teamsHttp []*TeamHTTP) {
+	for _, teamCore := range teamsCore {
+		var tmpTeamHttp TeamHTTP
+		tmpTeamHttp.FromCore(teamCore)
+		teamsHttp = append(teamsHttp, &tmpTeamHttp)
+	}
+	return
+}
+
+func (t *TeamInvitationHTTP) FromCore(invitationCore TeamInvitationCore) {
+	t.ID = strconv.Itoa(int(invitationCore.ID))
+	t.CreatedAt = invitationCore.CreatedAt.Format(time.DateTime)
+	var teamHttp TeamHTTP
+	teamHttp.FromCore(invitationCore.Team)
+	t.Team = &teamHttp
+	var userHttp TeamMemberHTTP
+	userHttp.FromCore(invitationCore.User)
+	t.User = &userHttp
+}
+
+func FromTeamInvitationsCore(invitationsCore []TeamInvitationCore) (invitationsHttp []*TeamInvitationHTTP) {
+	for _, invitationCore := range invitationsCore {
+		var tmpInvitationHttp TeamInvitationHTTP
+		tmpInvitationHttp.FromCore(invitationCore)
+		invitationsHttp = append(invitationsHttp, &tmpInvitationHttp)
+	}
+	return
+}
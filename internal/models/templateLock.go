@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// TemplateLockCore protects a sprite or a script of the template from being deleted in projects started from it.
+// Script locks are identified by the id of the top block of the script.
+type TemplateLockCore struct {
+	ID            uint `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	ProjectPageID uint            `gorm:"not null;index"`
+	ProjectPage   ProjectPageCore `gorm:"foreignKey:ProjectPageID;constraint:OnDelete:CASCADE;"`
+	Kind          TemplateLockKind
+	TargetName    string `gorm:"size:256;not null"`
+	BlockID       string `gorm:"size:256"`
+}
+
+func (t *TemplateLockHTTP) FromCore(lockCore TemplateLockCore) {
+	t.ID = strconv.Itoa(int(lockCore.ID))
+	t.ProjectPageID = strconv.Itoa(int(lockCore.ProjectPageID))
+	t.Kind = lockCore.Kind
+	t.TargetName = lockCore.TargetName
+	t.BlockID = lockCore.BlockID
+}
+
+func FromTemplateLocksCore(locksCore []TemplateLockCore) (locksHttp []*TemplateLockHTTP) {
+	for _, lockCore := range locksCore {
+		var tmpLockHttp TemplateLockHTTP
+		tmpLockHttp.FromCore(lockCore)
+		locksHttp = append(locksHttp, &tmpLockHttp)
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// UnitCore is a school or a club. Users belong to units and unit admins manage
+// only users of their own units.
+type UnitCore struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Name        string         `gorm:"size:256;not null"`
+	Description string         `gorm:"size:1024"`
+}
+
+type UnitMemberCore struct {
+	UnitID uint     `gorm:"primaryKey"`
+	Unit   UnitCore `gorm:"foreignKey:UnitID;constraint:OnDelete:CASCADE;"`
+	UserID uint     `gorm:"primaryKey;index"`
+	User   UserCore `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (u *UnitHTTP) FromCore(unitCore UnitCore) {
+	u.ID = strconv.Itoa(int(unitCore.ID))
+	u.CreatedAt = unitCore.CreatedAt.Format(time.DateTime)
+	u.UpdatedAt = unitCore.UpdatedAt.Format(time.DateTime)
+	u.Name = unitCore.Name
+	u.Description = unitCore.Description
+}
+
+func FromUnitsCore(unitsCore []UnitCore) (unitsHttp []*UnitHTTP) {
+	for _, unitCore := range unitsCore {
+		var tmpUnitHttp UnitHTTP
+		tmpUnitHttp.FromCore(unitCore)
+		unitsHttp = append(unitsHttp, &tmpUnitHttp)
+	}
+	return
+}
@@ -7,19 +7,29 @@ import (
 )
 
 type UserCore struct {
-	ID             uint `gorm:"primaryKey"`
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	DeletedAt      gorm.DeletedAt `gorm:"index"`
-	Email          string         `gorm:"not null;"`
-	Password       string         `gorm:"not null;"`
-	Role           Role           `gorm:"not null;"`
-	Firstname      string         `gorm:"not null;"`
-	Lastname       string         `gorm:"not null;"`
-	Middlename     string         `gorm:""`
-	Nickname       string         `gorm:"not null;"`
-	IsActive       bool           `gorm:"not null;default:false;type:boolean;column:is_active"`
-	ActivationLink string
+	ID         uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+	Email      string         `gorm:"not null;"`
+	Password   string         `gorm:"not null;"`
+	Role       Role           `gorm:"not null;"`
+	Firstname  string         `gorm:"not null;"`
+	Lastname   string         `gorm:"not null;"`
+	Middlename string         `gorm:""`
+	Nickname   string         `gorm:"not null;uniqueIndex:idx_user_cores_nickname,where:deleted_at IS NULL AND nickname <> ''"`
+	IsActive   bool           `gorm:"not null;default:false;type:boolean;column:is_active"`
+	// ActivationLink keeps sha256 of the link sent by email
+	ActivationLink          string `gorm:"index"`
+	ActivationLinkExpiresAt *time.Time
+	ActivationLinkSentAt    *time.Time
+	TotpSecret              string
+	TotpEnabled             bool `gorm:"not null;default:false;type:boolean;column:totp_enabled"`
+	// TotpLastStep is the time step of the last accepted code, a code is accepted only once
+	TotpLastStep int64 `gorm:"not null;default:0"`
+	// BirthDate and Grade are checked by the eligibility rules of contests
+	BirthDate *time.Time `gorm:"type:date"`
+	Grade     *int
 }
 
 func (u *UserHTTP) ToCore() UserCore {
@@ -49,6 +59,11 @@ func (u *UserHTTP) FromCore(userCore UserCore) {
 	u.Nickname = userCore.Nickname
 	u.IsActive = userCore.IsActive
 	u.Role = userCore.Role
+	if userCore.BirthDate != nil {
+		birthDate := userCore.BirthDate.Format(time.DateOnly)
+		u.BirthDate = &birthDate
+	}
+	u.Grade = userCore.Grade
 }
 
 func FromUsersCore(usersCore []UserCore) (usersHttp []*UserHTTP) {
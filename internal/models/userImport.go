@@ -0,0 +1,17 @@
+package models
+
+// ImportedUserCore is a participant created by the bulk import, the password is kept only
+// to print the credentials sheet and is never stored in plain text
+type ImportedUserCore struct {
+	Row      int
+	User     UserCore
+	Unit     UnitCore
+	Class    string
+	Password string
+}
+
+// ImportRowErrorCore explains why the row of the imported file is rejected, rows are numbered from 1
+type ImportRowErrorCore struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
@@ -2,19 +2,26 @@ package server
 
 import (
 	"context"
+	"errors"
 	"github.com/dgrijalva/jwt-go/v4"
 	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/models"
 	"github.com/skinnykaen/rpa_clone/internal/services"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
 	"github.com/spf13/viper"
 	"log"
+	"net"
 	"net/http"
 	"strings"
 	"time"
 )
 
-func Auth(next http.Handler, errLogger *log.Logger) http.Handler {
+func Auth(next http.Handler, errLogger *log.Logger, keyService services.KeyService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), consts.KeyIp, getClientIp(r)))
+		r = r.WithContext(context.WithValue(r.Context(), consts.KeyUserAgent, r.UserAgent()))
+		r = r.WithContext(context.WithValue(r.Context(), consts.KeyResponseWriter, w))
+		r = r.WithContext(context.WithValue(r.Context(), consts.KeyRequest, r))
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			r = r.WithContext(context.WithValue(r.Context(), consts.KeyId, "0"))
@@ -22,35 +29,12 @@ func Auth(next http.Handler, errLogger *log.Logger) http.Handler {
 			next.ServeHTTP(w, r)
 			return
 		}
-		headerParts := strings.Split(authHeader, " ")
-		if len(headerParts) != 2 {
-			errLogger.Printf("%s", "invalid authorization header format")
-			http.Error(w, "invalid authorization header format", http.StatusBadRequest)
-			return
-		}
-		data, err := jwt.ParseWithClaims(headerParts[1], &services.UserClaims{},
-			func(token *jwt.Token) (interface{}, error) {
-				return []byte(viper.GetString("auth_access_signing_key")), nil
-			})
-		if data == nil {
-			errLogger.Printf("%s", err.Error())
-			http.Error(w, err.Error(), http.StatusUnauthorized)
-			return
-		}
-		claims, ok := data.Claims.(*services.UserClaims)
+		claims, err := parseAccessToken(authHeader, keyService)
 		if err != nil {
-			if claims.ExpiresAt.Unix() < time.Now().Unix() {
-				errLogger.Printf("%s", err.Error())
-				http.Error(w, consts.ErrTokenExpired, http.StatusUnauthorized)
-				return
-			}
-			errLogger.Printf("%s", err.Error())
-			http.Error(w, err.Error(), http.StatusUnauthorized)
-			return
-		}
-		if !ok {
-			errLogger.Printf("%s", consts.ErrNotStandardToken)
-			http.Error(w, consts.ErrNotStandardToken, http.StatusUnauthorized)
+			var responseError utils.ResponseError
+			errors.As(err, &responseError)
+			errLogger.Printf("%s", responseError.Message)
+			http.Error(w, responseError.Message, int(responseError.Code))
 			return
 		}
 		r = r.WithContext(context.WithValue(r.Context(), consts.KeyId, claims.Id))
@@ -58,3 +42,60 @@ func Auth(next http.Handler, errLogger *log.Logger) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// parseAccessToken returns claims of the access token from the authorization header value "Bearer <token>"
+func parseAccessToken(authHeader string, keyService services.KeyService) (*services.UserClaims, error) {
+	headerParts := strings.Split(authHeader, " ")
+	if len(headerParts) != 2 {
+		return nil, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: "invalid authorization header format",
+		}
+	}
+	data, err := jwt.ParseWithClaims(headerParts[1], &services.UserClaims{}, keyService.Keyfunc(services.KeyPurposeAccess))
+	if data == nil {
+		return nil, utils.ResponseError{
+			Code:    http.StatusUnauthorized,
+			Message: err.Error(),
+		}
+	}
+	claims, ok := data.Claims.(*services.UserClaims)
+	if err != nil {
+		if claims.ExpiresAt != nil && claims.ExpiresAt.Unix() < time.Now().Unix() {
+			return nil, utils.ResponseError{
+				Code:    http.StatusUnauthorized,
+				Message: consts.ErrTokenExpired,
+			}
+		}
+		return nil, utils.ResponseError{
+			Code:    http.StatusUnauthorized,
+			Message: err.Error(),
+		}
+	}
+	// tokens issued for a special purpose (e.g. 2FA challenge) are not access tokens
+	if !ok || claims.Purpose != "" {
+		return nil, utils.ResponseError{
+			Code:    http.StatusUnauthorized,
+			Message: consts.ErrNotStandardToken,
+		}
+	}
+	return claims, nil
+}
+
+// getClientIp returns the client address. Forwarded headers are used
+// only if the server is configured to run behind a trusted proxy.
+func getClientIp(r *http.Request) string {
+	if viper.GetBool("trust_proxy_headers") {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+		if realIp := r.Header.Get("X-Real-IP"); realIp != "" {
+			return realIp
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
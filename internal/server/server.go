@@ -2,12 +2,12 @@ package server
 
 import (
 	"context"
-	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/rs/cors"
 	"github.com/skinnykaen/rpa_clone/graph"
 	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/graphql/directives"
+	"github.com/skinnykaen/rpa_clone/internal/services"
 	resolvers "github.com/skinnykaen/rpa_clone/internal/transports/graphql"
 	http2 "github.com/skinnykaen/rpa_clone/internal/transports/http"
 	"github.com/skinnykaen/rpa_clone/pkg/logger"
@@ -22,6 +22,7 @@ func NewServer(
 	loggers logger.Loggers,
 	resolver resolvers.Resolver,
 	handlers http2.Handlers,
+	keyService services.KeyService,
 ) {
 	lifecycle.Append(
 		fx.Hook{
@@ -30,17 +31,25 @@ func NewServer(
 				c := graph.Config{Resolvers: &resolver}
 				c.Directives.HasRole = directives.HasRole(loggers.Err)
 				mux := http.NewServeMux()
-				srv := handler.NewDefaultServer(graph.NewExecutableSchema(c))
+				srv := NewGraphqlServer(graph.NewExecutableSchema(c), loggers.Err, keyService)
 				switch m {
 				case consts.Production:
-					mux.Handle("/query", Auth(srv, loggers.Err))
-					mux.Handle("/project", Auth(handlers.ProjectHandler, loggers.Err))
+					mux.Handle("/query", Auth(srv, loggers.Err, keyService))
+					mux.Handle("/project", Auth(handlers.ProjectHandler, loggers.Err, keyService))
+					mux.Handle("/users/import", Auth(handlers.UserImportHandler, loggers.Err, keyService))
+					mux.Handle("/export/", Auth(handlers.ExportHandler, loggers.Err, keyService))
+					mux.Handle("/certificates/", Auth(handlers.CertificateHandler, loggers.Err, keyService))
 				case consts.Development:
 					mux.Handle("/", playground.Handler("GraphQL playground", "/query"))
-					mux.Handle("/query", Auth(srv, loggers.Err))
-					mux.Handle("/project", Auth(handlers.ProjectHandler, loggers.Err))
-					mux.Handle("/avatar", Auth(handlers.AvatarHandler, loggers.Err))
+					mux.Handle("/query", Auth(srv, loggers.Err, keyService))
+					mux.Handle("/project", Auth(handlers.ProjectHandler, loggers.Err, keyService))
+					mux.Handle("/avatar", Auth(handlers.AvatarHandler, loggers.Err, keyService))
+					mux.Handle("/users/import", Auth(handlers.UserImportHandler, loggers.Err, keyService))
+					mux.Handle("/export/", Auth(handlers.ExportHandler, loggers.Err, keyService))
+					mux.Handle("/certificates/", Auth(handlers.CertificateHandler, loggers.Err, keyService))
 				}
+				mux.Handle("/.well-known/jwks.json", handlers.JwksHandler)
+				mux.Handle("/lti/", handlers.LtiHandler)
 				loggers.Info.Printf(
 					"Connect to %s:%s/ for GraphQL playground",
 					viper.GetString("server_host"),
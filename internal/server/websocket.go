@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/services"
+	"github.com/spf13/viper"
+	"log"
+	"net/http"
+	"time"
+)
+
+// NewGraphqlServer is handler.NewDefaultServer with subscriptions authorized by the token of the connection.
+// Browsers cannot set headers of websocket requests, so clients send "Authorization": "Bearer <token>"
+// in the payload of connection_init.
+func NewGraphqlServer(es graphql.ExecutableSchema, errLogger *log.Logger, keyService services.KeyService) *handler.Server {
+	srv := handler.New(es)
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: checkOrigin,
+		},
+		InitFunc: websocketInit(errLogger, keyService),
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+	srv.SetQueryCache(lru.New(1000))
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New(100),
+	})
+	return srv
+}
+
+// websocketInit replaces the client of the upgrade request with the owner of the token from the payload.
+// The connection is closed when the token expires, the client reconnects with a refreshed token.
+func websocketInit(errLogger *log.Logger, keyService services.KeyService) transport.WebsocketInitFunc {
+	return func(ctx context.Context, initPayload transport.InitPayload) (context.Context, error) {
+		authHeader := initPayload.Authorization()
+		if authHeader == "" {
+			return ctx, nil
+		}
+		claims, err := parseAccessToken(authHeader, keyService)
+		if err != nil {
+			errLogger.Printf("%s", err.Error())
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, consts.KeyId, claims.Id)
+		ctx = context.WithValue(ctx, consts.KeyRole, claims.Role)
+		if claims.ExpiresAt == nil {
+			return ctx, nil
+		}
+		ctx, cancel := context.WithDeadline(transport.AppendCloseReason(ctx, consts.ErrTokenExpired), claims.ExpiresAt.Time)
+		go func() {
+			<-ctx.Done()
+			cancel()
+		}()
+		return ctx, nil
+	}
+}
+
+// checkOrigin allows websocket connections from the origins allowed by cors
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowedOrigin := range viper.GetStringSlice("cors.allowed_origins") {
+		if allowedOrigin == "*" || allowedOrigin == origin {
+			return true
+		}
+	}
+	return false
+}
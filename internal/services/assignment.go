@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/pubsub"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AssignmentService gives homework to groups. Teachers of the group create and review
+// assignments, students of the group work on personal copies of the template.
+type AssignmentService interface {
+	CreateAssignment(assignment models.AssignmentCore, clientId uint, clientRole models.Role) (models.AssignmentCore, error)
+	UpdateAssignment(assignment models.AssignmentCore, clientId uint, clientRole models.Role) (models.AssignmentCore, error)
+	DeleteAssignment(id, clientId uint, clientRole models.Role) error
+	GetAssignmentById(id, clientId uint, clientRole models.Role) (models.AssignmentCore, error)
+	GetAssignmentsByGroup(groupId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		assignments []models.AssignmentCore, countRows uint, err error)
+	GetSubmissionsByAssignment(assignmentId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		submissions []models.AssignmentSubmissionCore, countRows uint, err error)
+	GetSubmissionsByStudent(page, pageSize *int, clientId uint) (
+		submissions []models.AssignmentSubmissionCore, countRows uint, err error)
+	StartAssignment(assignmentId, clientId uint) (models.AssignmentSubmissionCore, error)
+	SubmitAssignment(assignmentId, clientId uint) (models.AssignmentSubmissionCore, error)
+	ReviewSubmission(ctx context.Context, id uint, grade int, feedback string, clientId uint, clientRole models.Role) (
+		models.AssignmentSubmissionCore, error)
+	SubscribeSubmissions(assignmentId, clientId uint, clientRole models.Role) (
+		submissions <-chan models.AssignmentSubmissionCore, unsubscribe func(), err error)
+}
+
+type AssignmentServiceImpl struct {
+	loggers                logger.Loggers
+	assignmentGateway      gateways.AssignmentGateway
+	groupGateway           gateways.GroupGateway
+	projectPageGateway     gateways.ProjectPageGateway
+	activityGateway        gateways.ActivityGateway
+	groupService           GroupService
+	projectTemplateService ProjectTemplateService
+	parentDashboardService ParentDashboardService
+	notificationService    NotificationService
+	ltiService             LtiService
+	submissionBroker       *pubsub.Broker[models.AssignmentSubmissionCore]
+}
+
+func (a AssignmentServiceImpl) CreateAssignment(assignment models.AssignmentCore, clientId uint, clientRole models.Role) (
+	models.AssignmentCore, error) {
+	if _, err := a.groupService.GetGroupById(assignment.GroupID, clientId, clientRole); err != nil {
+		return models.AssignmentCore{}, err
+	}
+	if err := validateAssignment(assignment); err != nil {
+		return models.AssignmentCore{}, err
+	}
+	template, err := a.projectPageGateway.GetProjectPageById(assignment.TemplateProjectPageID)
+	if err != nil {
+		return models.AssignmentCore{}, err
+	}
+	if template.AuthorID != clientId || template.IsBanned {
+		return models.AssignmentCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectAssignmentTemplate,
+		}
+	}
+	studentIds, err := a.groupGateway.GetStudentIds(assignment.GroupID)
+	if err != nil {
+		return models.AssignmentCore{}, err
+	}
+	lockedBlocks, err := a.projectTemplateService.GetLockedBlocks(template)
+	if err != nil {
+		return models.AssignmentCore{}, err
+	}
+	assignment.CreatedByID = clientId
+	return a.assignmentGateway.CreateAssignment(assignment, studentIds, template, lockedBlocks)
+}
+
+func (a AssignmentServiceImpl) UpdateAssignment(assignment models.AssignmentCore, clientId uint, clientRole models.Role) (
+	models.AssignmentCore, error) {
+	getAssignment, err := a.getTaughtAssignment(assignment.ID, clientId, clientRole)
+	if err != nil {
+		return models.AssignmentCore{}, err
+	}
+	if err := validateAssignment(assignment); err != nil {
+		return models.AssignmentCore{}, err
+	}
+	assignment.GroupID = getAssignment.GroupID
+	assignment.CreatedByID = getAssignment.CreatedByID
+	assignment.TemplateProjectPageID = getAssignment.TemplateProjectPageID
+	return a.assignmentGateway.UpdateAssignment(assignment)
+}
+
+func (a AssignmentServiceImpl) DeleteAssignment(id, clientId uint, clientRole models.Role) error {
+	if _, err := a.getTaughtAssignment(id, clientId, clientRole); err != nil {
+		return err
+	}
+	return a.assignmentGateway.DeleteAssignment(id)
+}
+
+func (a AssignmentServiceImpl) GetAssignmentById(id, clientId uint, clientRole models.Role) (models.AssignmentCore, error) {
+	assignment, err := a.assignmentGateway.GetAssignmentById(id)
+	if err != nil {
+		return models.AssignmentCore{}, err
+	}
+	if err := a.checkGroupAccess(assignment.GroupID, clientId, clientRole); err != nil {
+		return models.AssignmentCore{}, err
+	}
+	return assignment, nil
+}
+
+func (a AssignmentServiceImpl) GetAssignmentsByGroup(groupId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+	assignments []models.AssignmentCore, countRows uint, err error) {
+	if err := a.checkGroupAccess(groupId, clientId, clientRole); err != nil {
+		return []models.AssignmentCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return a.assignmentGateway.GetAssignmentsByGroup(groupId, offset, limit)
+}
+
+func (a AssignmentServiceImpl) GetSubmissionsByAssignment(assignmentId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+	submissions []models.AssignmentSubmissionCore, countRows uint, err error) {
+	if _, err := a.getTaughtAssignment(assignmentId, clientId, clientRole); err != nil {
+		return []models.AssignmentSubmissionCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return a.assignmentGateway.GetSubmissionsByAssignment(assignmentId, offset, limit)
+}
+
+func (a AssignmentServiceImpl) GetSubmissionsByStudent(page, pageSize *int, clientId uint) (
+	submissions []models.AssignmentSubmissionCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return a.assignmentGateway.GetSubmissionsByStudent(clientId, offset, limit)
+}
+
+// StartAssignment returns the submission of the student. Students joined the group after
+// the assignment was given get their personal copy of the template here.
+func (a AssignmentServiceImpl) StartAssignment(assignmentId, clientId uint) (models.AssignmentSubmissionCore, error) {
+	assignment, err := a.GetAssignmentById(assignmentId, clientId, models.RoleStudent)
+	if err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	submission, err := a.assignmentGateway.GetSubmission(assignmentId, clientId)
+	var responseError utils.ResponseError
+	if errors.As(err, &responseError) && responseError.Message == consts.ErrNotFoundInDB {
+		template, err := a.projectPageGateway.GetProjectPageById(assignment.TemplateProjectPageID)
+		if err != nil {
+			return models.AssignmentSubmissionCore{}, err
+		}
+		lockedBlocks, err := a.projectTemplateService.GetLockedBlocks(template)
+		if err != nil {
+			return models.AssignmentSubmissionCore{}, err
+		}
+		submission, err = a.assignmentGateway.CreateSubmission(assignment, clientId, template, lockedBlocks)
+		if err != nil {
+			return models.AssignmentSubmissionCore{}, err
+		}
+	} else if err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	if submission.Status != models.AssignmentStatusNotStarted {
+		return submission, nil
+	}
+	submission.Status = models.AssignmentStatusInProgress
+	submission, err = a.assignmentGateway.UpdateSubmission(submission)
+	if err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	a.publishSubmission(submission)
+	return submission, nil
+}
+
+// SubmitAssignment submits the personal copy for the review, the copy cannot be changed after that
+func (a AssignmentServiceImpl) SubmitAssignment(assignmentId, clientId uint) (models.AssignmentSubmissionCore, error) {
+	submission, err := a.StartAssignment(assignmentId, clientId)
+	if err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	if submission.Status == models.AssignmentStatusReviewed {
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrAssignmentAlreadyReviewed,
+		}
+	}
+	if submission.Status == models.AssignmentStatusSubmitted {
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrAssignmentAlreadySubmitted,
+		}
+	}
+	now := time.Now()
+	isLate := now.After(submission.Assignment.Deadline)
+	if isLate && submission.Assignment.LatePolicy == models.AssignmentLatePolicyDeny {
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrAssignmentDeadlinePassed,
+		}
+	}
+	submission.Status = models.AssignmentStatusSubmitted
+	submission.SubmittedAt = &now
+	submission.IsLate = isLate
+	submission, err = a.assignmentGateway.UpdateSubmission(submission)
+	if err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	a.addActivity(submission, models.ActivityKindAssignmentSubmitted)
+	a.publishSubmission(submission)
+	return submission, nil
+}
+
+// ReviewSubmission grades the submission, the late penalty is taken from the grade of late submissions
+func (a AssignmentServiceImpl) ReviewSubmission(ctx context.Context, id uint, grade int, feedback string, clientId uint, clientRole models.Role) (
+	models.AssignmentSubmissionCore, error) {
+	submission, err := a.assignmentGateway.GetSubmissionById(id)
+	if err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	if _, err := a.groupService.GetGroupById(submission.Assignment.GroupID, clientId, clientRole); err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	if submission.Status != models.AssignmentStatusSubmitted && submission.Status != models.AssignmentStatusReviewed {
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrAssignmentNotSubmitted,
+		}
+	}
+	if grade < 0 || grade > submission.Assignment.MaxGrade {
+		return models.AssignmentSubmissionCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectAssignmentGrade,
+		}
+	}
+	if submission.IsLate && submission.Assignment.LatePolicy == models.AssignmentLatePolicyPenalty {
+		grade -= grade * submission.Assignment.LatePenalty / 100
+	}
+	now := time.Now()
+	submission.Status = models.AssignmentStatusReviewed
+	submission.Grade = &grade
+	submission.Feedback = feedback
+	submission.ReviewedByID = &clientId
+	submission.ReviewedAt = &now
+	submission, err = a.assignmentGateway.UpdateSubmission(submission)
+	if err != nil {
+		return models.AssignmentSubmissionCore{}, err
+	}
+	a.addActivity(submission, models.ActivityKindAssignmentReviewed)
+	text := "Задание «" + submission.Assignment.Title + "» проверено. Оценка: " +
+		strconv.Itoa(grade) + " из " + strconv.Itoa(submission.Assignment.MaxGrade) + "."
+	a.notificationService.Notify(models.NotificationCore{
+		UserID: submission.StudentID,
+		Kind:   models.NotificationKindSubmissionReviewed,
+		Title:  "Задание проверено",
+		Text:   text,
+		Payload: map[string]string{
+			"assignmentId": strconv.Itoa(int(submission.AssignmentID)),
+			"submissionId": strconv.Itoa(int(submission.ID)),
+		},
+	})
+	a.parentDashboardService.NotifyParents(submission.StudentID, "Задание ребёнка проверено", text, map[string]string{
+		"assignmentId": strconv.Itoa(int(submission.AssignmentID)),
+	})
+	// the review is kept when the lms does not accept the grade, the teacher may push it again
+	if err := a.ltiService.PushAssignmentGrade(ctx, submission); err != nil {
+		a.loggers.Err.Printf("assignment submission %d lti grade: %s", submission.ID, err.Error())
+	}
+	a.publishSubmission(submission)
+	return submission, nil
+}
+
+// SubscribeSubmissions delivers status changes of submissions of the assignment to its teachers
+// and of the own submission to students of the group
+func (a AssignmentServiceImpl) SubscribeSubmissions(assignmentId, clientId uint, clientRole models.Role) (
+	submissions <-chan models.AssignmentSubmissionCore, unsubscribe func(), err error) {
+	var filter func(submission models.AssignmentSubmissionCore) bool
+	if clientRole == models.RoleStudent {
+		if _, err := a.GetAssignmentById(assignmentId, clientId, clientRole); err != nil {
+			return nil, nil, err
+		}
+		filter = func(submission models.AssignmentSubmissionCore) bool {
+			return submission.StudentID == clientId
+		}
+	} else if _, err := a.getTaughtAssignment(assignmentId, clientId, clientRole); err != nil {
+		return nil, nil, err
+	}
+	submissions, unsubscribe = a.submissionBroker.Subscribe(strconv.Itoa(int(assignmentId)), filter)
+	return submissions, unsubscribe, nil
+}
+
+// getTaughtAssignment returns the assignment if the client teaches the group of the assignment
+func (a AssignmentServiceImpl) getTaughtAssignment(id, clientId uint, clientRole models.Role) (models.AssignmentCore, error) {
+	assignment, err := a.assignmentGateway.GetAssignmentById(id)
+	if err != nil {
+		return models.AssignmentCore{}, err
+	}
+	if _, err := a.groupService.GetGroupById(assignment.GroupID, clientId, clientRole); err != nil {
+		return models.AssignmentCore{}, err
+	}
+	return assignment, nil
+}
+
+// checkGroupAccess allows students of the group, its teachers and admins of its unit
+func (a AssignmentServiceImpl) checkGroupAccess(groupId, clientId uint, clientRole models.Role) error {
+	if clientRole != models.RoleStudent {
+		_, err := a.groupService.GetGroupById(groupId, clientId, clientRole)
+		return err
+	}
+	isStudent, err := a.groupGateway.IsStudent(groupId, clientId)
+	if err != nil {
+		return err
+	}
+	if !isStudent {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return nil
+}
+
+func (a AssignmentServiceImpl) publishSubmission(submission models.AssignmentSubmissionCore) {
+	a.submissionBroker.Publish(strconv.Itoa(int(submission.AssignmentID)), submission)
+}
+
+// addActivity records the event for the dashboard of parents, a failed record is only logged
+func (a AssignmentServiceImpl) addActivity(submission models.AssignmentSubmissionCore, kind models.ActivityKind) {
+	if err := a.activityGateway.CreateActivity(models.ActivityCore{
+		UserID:    submission.StudentID,
+		Kind:      kind,
+		SubjectID: submission.AssignmentID,
+		Title:     submission.Assignment.Title,
+	}); err != nil {
+		a.loggers.Err.Printf("assignment submission %d activity: %s", submission.ID, err.Error())
+	}
+}
+
+func validateAssignment(assignment models.AssignmentCore) error {
+	if !assignment.Deadline.After(time.Now()) || assignment.MaxGrade <= 0 ||
+		assignment.LatePenalty < 0 || assignment.LatePenalty > 100 {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectAssignment,
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"github.com/dgrijalva/jwt-go/v4"
 	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/gateways"
@@ -8,6 +9,7 @@ import (
 	"github.com/skinnykaen/rpa_clone/pkg/utils"
 	"github.com/spf13/viper"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -16,78 +18,96 @@ type Tokens struct {
 	Refresh string
 }
 
+// SignInResult contains either tokens or a challenge token for the second
+// authentication step if the user must confirm the sign in with 2FA code
+type SignInResult struct {
+	Tokens                      Tokens
+	TwoFactorRequired           bool
+	TwoFactorEnrollmentRequired bool
+	ChallengeToken              string
+}
+
 type UserClaims struct {
 	jwt.StandardClaims
 	Id   uint
 	Role models.Role
+	// Purpose is empty for access and refresh tokens
+	Purpose string `json:",omitempty"`
 }
 
 type AuthService interface {
-	SignUp(newUser models.UserCore) error
-	SignIn(email, password string) (Tokens, error)
+	SignUp(newUser models.UserCore, ip, userAgent string) error
+	SignIn(email, password, ip string) (SignInResult, error)
 	Refresh(token string) (string, error)
-	ConfirmActivation(link string) (Tokens, error)
+	ConfirmActivation(link string) (SignInResult, error)
+	ResendActivation(email string) error
 }
 
 type AuthServiceImpl struct {
-	userGateway     gateways.UserGateway
-	settingsGateway gateways.SettingsGateway
+	userGateway                gateways.UserGateway
+	settingsGateway            gateways.SettingsGateway
+	keyService                 KeyService
+	loginGuardService          LoginGuardService
+	passwordService            PasswordService
+	registrationRequestGateway gateways.RegistrationRequestGateway
 }
 
-func (a AuthServiceImpl) ConfirmActivation(link string) (Tokens, error) {
+func (a AuthServiceImpl) ConfirmActivation(link string) (SignInResult, error) {
 	activationByLink, err := a.settingsGateway.GetActivationByLink()
 	if err != nil {
-		return Tokens{Access: "", Refresh: ""}, utils.ResponseError{
+		return SignInResult{}, utils.ResponseError{
 			Code:    http.StatusInternalServerError,
 			Message: err.Error(),
 		}
 	}
 	if !activationByLink {
-		return Tokens{Access: "", Refresh: ""}, utils.ResponseError{
+		return SignInResult{}, utils.ResponseError{
 			Code:    http.StatusServiceUnavailable,
 			Message: consts.ErrActivationLinkUnavailable,
 		}
 	}
-	user, err := a.userGateway.GetUserByActivationLink(link)
-	if err != nil {
-		return Tokens{Access: "", Refresh: ""}, utils.ResponseError{
-			Code:    http.StatusInternalServerError,
-			Message: err.Error(),
-		}
-	}
-	if err := a.userGateway.SetIsActive(user.ID, true); err != nil {
-		return Tokens{Access: "", Refresh: ""}, utils.ResponseError{
-			Code:    http.StatusInternalServerError,
-			Message: err.Error(),
+	if link == "" {
+		return SignInResult{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectActivationLink,
 		}
 	}
-	access, err := generateToken(user, viper.GetDuration("auth_access_token_ttl"), []byte(viper.GetString("auth_access_signing_key")))
+	user, err := a.userGateway.GetUserByActivationLink(utils.GetSha256String(link))
 	if err != nil {
-		return Tokens{}, utils.ResponseError{
-			Code:    http.StatusInternalServerError,
-			Message: err.Error(),
+		return SignInResult{}, err
+	}
+	if user.ActivationLinkExpiresAt == nil || time.Now().After(*user.ActivationLinkExpiresAt) {
+		return SignInResult{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrActivationLinkExpired,
 		}
 	}
-	refresh, err := generateToken(user, viper.GetDuration("auth_refresh_token_ttl"), []byte(viper.GetString("auth_refresh_signing_key")))
-	if err != nil {
-		return Tokens{}, utils.ResponseError{
+	// SetIsActive clears the link, so it can not be used again
+	if err := a.userGateway.SetIsActive(user.ID, true); err != nil {
+		return SignInResult{}, utils.ResponseError{
 			Code:    http.StatusInternalServerError,
 			Message: err.Error(),
 		}
 	}
-	return Tokens{Access: access, Refresh: refresh}, nil
+	return completeSignIn(a.keyService, a.settingsGateway, user)
 }
 
 func (a AuthServiceImpl) Refresh(token string) (string, error) {
-	claims, err := parseToken(token, []byte(viper.GetString("auth_refresh_signing_key")))
+	claims, err := parseToken(a.keyService, KeyPurposeRefresh, token)
 	if err != nil {
 		return "", err
 	}
+	if claims.Purpose != "" {
+		return "", utils.ResponseError{
+			Code:    http.StatusUnauthorized,
+			Message: consts.ErrNotStandardToken,
+		}
+	}
 	user := models.UserCore{
 		ID:   claims.Id,
 		Role: claims.Role,
 	}
-	newAccessToken, err := generateToken(user, viper.GetDuration("auth_access_token_ttl"), []byte(viper.GetString("auth_access_signing_key")))
+	newAccessToken, err := generateToken(a.keyService, KeyPurposeAccess, user, viper.GetDuration("auth_access_token_ttl"))
 	if err != nil {
 		return "", utils.ResponseError{
 			Code:    http.StatusInternalServerError,
@@ -97,42 +117,72 @@ func (a AuthServiceImpl) Refresh(token string) (string, error) {
 	return newAccessToken, nil
 }
 
-func (a AuthServiceImpl) SignIn(email, password string) (Tokens, error) {
-	user, err := a.userGateway.GetUserByEmail(email)
+// SignIn accepts the email or, for imported participants without email, the nickname
+func (a AuthServiceImpl) SignIn(email, password, ip string) (SignInResult, error) {
+	if err := a.loginGuardService.Check(0, ip); err != nil {
+		return SignInResult{}, err
+	}
+	var user models.UserCore
+	var err error
+	if strings.Contains(email, "@") {
+		user, err = a.userGateway.GetUserByEmail(email)
+	} else {
+		user, err = a.userGateway.GetUserByNickname(email)
+	}
 	if err != nil {
-		return Tokens{}, err
+		var responseError utils.ResponseError
+		if errors.As(err, &responseError) && responseError.Code == http.StatusBadRequest {
+			if err := a.loginGuardService.RegisterFailure(0, email, ip); err != nil {
+				return SignInResult{}, err
+			}
+		}
+		return SignInResult{}, err
 	}
-	if err = utils.ComparePassword(user.Password, password); err != nil {
-		return Tokens{}, utils.ResponseError{
+	if err := a.loginGuardService.Check(user.ID, ""); err != nil {
+		return SignInResult{}, err
+	}
+	needsRehash, err := a.passwordService.ComparePassword(user.Password, password)
+	if err != nil {
+		if err := a.loginGuardService.RegisterFailure(user.ID, user.Email, ip); err != nil {
+			return SignInResult{}, err
+		}
+		return SignInResult{}, utils.ResponseError{
 			Code:    http.StatusBadRequest,
 			Message: consts.ErrIncorrectPasswordOrEmail,
 		}
 	}
+	// the plain password is available only here, so hashes are upgraded on sign in
+	if needsRehash {
+		passwordHash, err := a.passwordService.HashPassword(password)
+		if err != nil {
+			return SignInResult{}, err
+		}
+		if err := a.userGateway.SetPassword(user.ID, passwordHash); err != nil {
+			return SignInResult{}, err
+		}
+	}
 	if !user.IsActive {
-		return Tokens{},
+		return SignInResult{},
 			utils.ResponseError{
 				Code:    http.StatusForbidden,
 				Message: consts.ErrUserIsNotActive,
 			}
 	}
-	access, err := generateToken(user, viper.GetDuration("auth_access_token_ttl"), []byte(viper.GetString("auth_access_signing_key")))
+	result, err := completeSignIn(a.keyService, a.settingsGateway, user)
 	if err != nil {
-		return Tokens{}, utils.ResponseError{
-			Code:    http.StatusInternalServerError,
-			Message: err.Error(),
-		}
+		return SignInResult{}, err
 	}
-	refresh, err := generateToken(user, viper.GetDuration("auth_refresh_token_ttl"), []byte(viper.GetString("auth_refresh_signing_key")))
-	if err != nil {
-		return Tokens{}, utils.ResponseError{
-			Code:    http.StatusInternalServerError,
-			Message: err.Error(),
+	// with the second factor failures are reset only after the code is checked, otherwise
+	// the password would reset the lockout of guessing codes
+	if result.ChallengeToken == "" {
+		if err := a.loginGuardService.RegisterSuccess(user.ID); err != nil {
+			return SignInResult{}, err
 		}
 	}
-	return Tokens{Access: access, Refresh: refresh}, nil
+	return result, nil
 }
 
-func (a AuthServiceImpl) SignUp(newUser models.UserCore) error {
+func (a AuthServiceImpl) SignUp(newUser models.UserCore, ip, userAgent string) error {
 	if !utils.IsValidEmail(newUser.Email) {
 		return utils.ResponseError{
 			Code:    http.StatusBadRequest,
@@ -149,14 +199,17 @@ func (a AuthServiceImpl) SignUp(newUser models.UserCore) error {
 			Message: consts.ErrEmailAlreadyInUse,
 		}
 	}
-	if len(newUser.Password) < 6 {
-		return utils.ResponseError{
-			Code:    http.StatusBadRequest,
-			Message: consts.ErrShortPassword,
-		}
+	if err := checkNickname(a.userGateway, 0, newUser.Nickname); err != nil {
+		return err
+	}
+	if err := a.passwordService.ValidatePassword(newUser.Password); err != nil {
+		return err
 	}
 
-	passwordHash := utils.HashPassword(newUser.Password)
+	passwordHash, err := a.passwordService.HashPassword(newUser.Password)
+	if err != nil {
+		return err
+	}
 	newUser.Password = passwordHash
 	newUser, err = a.userGateway.CreateUser(newUser)
 	if err != nil {
@@ -173,15 +226,19 @@ func (a AuthServiceImpl) SignUp(newUser models.UserCore) error {
 			Message: err.Error(),
 		}
 	}
-	var subject, body string
 	if activationByLink {
-		subject = "Ваша ссылка активации аккаунта"
-		body = "<p>Перейдите по ссылке" + viper.GetString("activation_path") +
-			newUser.ActivationLink + " для активации вашего аккаунта.</p>"
-	} else {
-		subject = "Активация аккаунта"
-		body = "<p>На данный момент активация по ссылке недоступна. Ждите активации от администратора.</p>"
+		return a.sendActivationLink(newUser)
 	}
+	if err := a.registrationRequestGateway.CreateRegistrationRequest(models.RegistrationRequestCore{
+		UserID:    newUser.ID,
+		Ip:        ip,
+		UserAgent: userAgent,
+		Status:    models.RegistrationStatusPending,
+	}); err != nil {
+		return err
+	}
+	subject := "Активация аккаунта"
+	body := "<p>На данный момент активация по ссылке недоступна. Ждите активации от администратора.</p>"
 	if err := utils.SendEmail(subject, newUser.Email, body); err != nil {
 		return utils.ResponseError{
 			Code:    http.StatusInternalServerError,
@@ -191,7 +248,137 @@ func (a AuthServiceImpl) SignUp(newUser models.UserCore) error {
 	return nil
 }
 
-func generateToken(user models.UserCore, duration time.Duration, signingKey []byte) (token string, err error) {
+func (a AuthServiceImpl) ResendActivation(email string) error {
+	activationByLink, err := a.settingsGateway.GetActivationByLink()
+	if err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if !activationByLink {
+		return utils.ResponseError{
+			Code:    http.StatusServiceUnavailable,
+			Message: consts.ErrActivationLinkUnavailable,
+		}
+	}
+	user, err := a.userGateway.GetUserByEmail(email)
+	if err != nil {
+		// do not reveal whether the email is registered
+		var responseError utils.ResponseError
+		if errors.As(err, &responseError) && responseError.Code == http.StatusBadRequest {
+			return nil
+		}
+		return err
+	}
+	// only accounts waiting for the activation get a new link, accounts deactivated by admins have none
+	if user.IsActive || user.ActivationLink == "" {
+		return nil
+	}
+	if user.ActivationLinkSentAt != nil &&
+		time.Now().Before(user.ActivationLinkSentAt.Add(viper.GetDuration("activation.resend_interval"))) {
+		return utils.ResponseError{
+			Code:    http.StatusTooManyRequests,
+			Message: consts.ErrActivationResendTooOften,
+		}
+	}
+	return a.sendActivationLink(user)
+}
+
+// sendActivationLink replaces the previous activation link of the user with
+// a new random one and sends it by email. Only the hash of the link is stored.
+func (a AuthServiceImpl) sendActivationLink(user models.UserCore) error {
+	link, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	expiresAt := time.Now().Add(viper.GetDuration("activation.link_ttl"))
+	if err := a.userGateway.SetActivationLink(user.ID, utils.GetSha256String(link), expiresAt); err != nil {
+		return err
+	}
+	subject := "Ваша ссылка активации аккаунта"
+	body := "<p>Перейдите по ссылке " + viper.GetString("activation_path") + link +
+		" для активации вашего аккаунта. Ссылка действительна до " + expiresAt.Format(time.DateTime) + ".</p>"
+	if err := utils.SendEmail(subject, user.Email, body); err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+// completeSignIn issues tokens for the authenticated user or a challenge token
+// if the user has 2FA enabled or it is mandatory for the user role
+func completeSignIn(keyService KeyService, settingsGateway gateways.SettingsGateway, user models.UserCore) (SignInResult, error) {
+	required, err := isTwoFactorRequired(settingsGateway, user.Role)
+	if err != nil {
+		return SignInResult{}, err
+	}
+	if !user.TotpEnabled && !required {
+		tokens, err := generateTokens(keyService, user)
+		if err != nil {
+			return SignInResult{}, err
+		}
+		return SignInResult{Tokens: tokens}, nil
+	}
+	claims := UserClaims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: jwt.At(time.Now().Add(viper.GetDuration("auth.two_factor.challenge_ttl") * time.Second)),
+		},
+		Id:      user.ID,
+		Role:    user.Role,
+		Purpose: consts.TokenPurposeTwoFactor,
+	}
+	challenge, err := keyService.Sign(KeyPurposeChallenge, claims)
+	if err != nil {
+		return SignInResult{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return SignInResult{
+		TwoFactorRequired:           user.TotpEnabled,
+		TwoFactorEnrollmentRequired: !user.TotpEnabled,
+		ChallengeToken:              challenge,
+	}, nil
+}
+
+func isTwoFactorRequired(settingsGateway gateways.SettingsGateway, role models.Role) (bool, error) {
+	roles, err := settingsGateway.GetTwoFactorRequiredRoles()
+	if err != nil {
+		return false, err
+	}
+	for _, r := range roles {
+		if r.String() == role.String() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func generateTokens(keyService KeyService, user models.UserCore) (Tokens, error) {
+	access, err := generateToken(keyService, KeyPurposeAccess, user, viper.GetDuration("auth_access_token_ttl"))
+	if err != nil {
+		return Tokens{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	refresh, err := generateToken(keyService, KeyPurposeRefresh, user, viper.GetDuration("auth_refresh_token_ttl"))
+	if err != nil {
+		return Tokens{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return Tokens{Access: access, Refresh: refresh}, nil
+}
+
+func generateToken(keyService KeyService, purpose KeyPurpose, user models.UserCore, duration time.Duration) (token string, err error) {
 	claims := UserClaims{
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: jwt.At(time.Now().Add(duration * time.Second)),
@@ -199,19 +386,20 @@ func generateToken(user models.UserCore, duration time.Duration, signingKey []by
 		Id:   user.ID,
 		Role: user.Role,
 	}
-	ss := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err = ss.SignedString(signingKey)
-	return token, err
+	return keyService.Sign(purpose, claims)
 }
 
-func parseToken(token string, key []byte) (*UserClaims, error) {
-	data, err := jwt.ParseWithClaims(token, &UserClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return key, nil
-		})
+func parseToken(keyService KeyService, purpose KeyPurpose, token string) (*UserClaims, error) {
+	data, err := jwt.ParseWithClaims(token, &UserClaims{}, keyService.Keyfunc(purpose))
+	if data == nil {
+		return &UserClaims{}, utils.ResponseError{
+			Code:    http.StatusUnauthorized,
+			Message: err.Error(),
+		}
+	}
 	claims, ok := data.Claims.(*UserClaims)
 	if err != nil {
-		if claims.ExpiresAt.Unix() < time.Now().Unix() {
+		if claims.ExpiresAt != nil && claims.ExpiresAt.Unix() < time.Now().Unix() {
 			return &UserClaims{}, utils.ResponseError{
 				Code:    http.StatusUnauthorized,
 				Message: consts.ErrTokenExpired,
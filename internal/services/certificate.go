@@ -0,0 +1,398 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/pdf"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/skip2/go-qrcode"
+	"github.com/spf13/viper"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type CertificateService interface {
+	CreateTemplate(template models.CertificateTemplateCore, clientId uint, clientRole models.Role) (
+		models.CertificateTemplateCore, error)
+	UpdateTemplate(template models.CertificateTemplateCore, clientId uint, clientRole models.Role) (
+		models.CertificateTemplateCore, error)
+	DeleteTemplate(id, clientId uint, clientRole models.Role) error
+	GetTemplateById(id, clientId uint, clientRole models.Role) (models.CertificateTemplateCore, error)
+	GetTemplates(page, pageSize *int, clientId uint, clientRole models.Role) (
+		templates []models.CertificateTemplateCore, countRows uint, err error)
+	UploadBackground(filename string, data []byte) (string, error)
+	IssueCertificates(templateId uint, certificates []models.CertificateCore, clientId uint, clientRole models.Role) (
+		[]models.CertificateCore, error)
+	RevokeCertificate(id, clientId uint, clientRole models.Role) error
+	GetCertificatesByTemplate(templateId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		certificates []models.CertificateCore, countRows uint, err error)
+	GetCertificatesByUser(page, pageSize *int, clientId uint) (
+		certificates []models.CertificateCore, countRows uint, err error)
+	GetCertificatesForPrint(ids []uint, clientId uint, clientRole models.Role) ([]models.CertificateCore, error)
+	VerifyCertificate(code string) (models.CertificateCore, error)
+	WritePdf(w io.Writer, certificates []models.CertificateCore) error
+}
+
+type CertificateServiceImpl struct {
+	certificateGateway     gateways.CertificateGateway
+	userGateway            gateways.UserGateway
+	teamGateway            gateways.TeamGateway
+	unitService            UnitService
+	contestService         ContestService
+	parentDashboardService ParentDashboardService
+}
+
+func (c CertificateServiceImpl) CreateTemplate(template models.CertificateTemplateCore, clientId uint,
+	clientRole models.Role) (models.CertificateTemplateCore, error) {
+	if err := validateCertificateTemplate(template); err != nil {
+		return models.CertificateTemplateCore{}, err
+	}
+	template.CreatedByID = clientId
+	return c.certificateGateway.CreateTemplate(template)
+}
+
+func (c CertificateServiceImpl) UpdateTemplate(template models.CertificateTemplateCore, clientId uint,
+	clientRole models.Role) (models.CertificateTemplateCore, error) {
+	if _, err := c.GetTemplateById(template.ID, clientId, clientRole); err != nil {
+		return models.CertificateTemplateCore{}, err
+	}
+	if err := validateCertificateTemplate(template); err != nil {
+		return models.CertificateTemplateCore{}, err
+	}
+	return c.certificateGateway.UpdateTemplate(template)
+}
+
+func (c CertificateServiceImpl) DeleteTemplate(id, clientId uint, clientRole models.Role) error {
+	if _, err := c.GetTemplateById(id, clientId, clientRole); err != nil {
+		return err
+	}
+	return c.certificateGateway.DeleteTemplate(id)
+}
+
+// GetTemplateById returns the template, unit admins manage only templates they created
+func (c CertificateServiceImpl) GetTemplateById(id, clientId uint, clientRole models.Role) (
+	models.CertificateTemplateCore, error) {
+	template, err := c.certificateGateway.GetTemplateById(id)
+	if err != nil {
+		return models.CertificateTemplateCore{}, err
+	}
+	if clientRole != models.RoleSuperAdmin && template.CreatedByID != clientId {
+		return models.CertificateTemplateCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return template, nil
+}
+
+func (c CertificateServiceImpl) GetTemplates(page, pageSize *int, clientId uint, clientRole models.Role) (
+	templates []models.CertificateTemplateCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	if clientRole != models.RoleSuperAdmin {
+		return c.certificateGateway.GetTemplatesByCreator(clientId, offset, limit)
+	}
+	return c.certificateGateway.GetTemplates(offset, limit)
+}
+
+// UploadBackground stores the png or jpeg image under a random name and returns the name
+func (c CertificateServiceImpl) UploadBackground(filename string, data []byte) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") || (ext != ".png" && ext != ".jpg" && ext != ".jpeg") {
+		return "", utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectCertificateBackground,
+		}
+	}
+	name, err := utils.GenerateRandomString(24)
+	if err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	storagePath := viper.GetString("certificates.storage_path")
+	if err := os.MkdirAll(storagePath, 0755); err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := os.WriteFile(filepath.Join(storagePath, name+ext), data, 0644); err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return name + ext, nil
+}
+
+// IssueCertificates issues certificates of the template to users available to the client
+func (c CertificateServiceImpl) IssueCertificates(templateId uint, certificates []models.CertificateCore,
+	clientId uint, clientRole models.Role) ([]models.CertificateCore, error) {
+	if _, err := c.GetTemplateById(templateId, clientId, clientRole); err != nil {
+		return []models.CertificateCore{}, err
+	}
+	for i, certificate := range certificates {
+		if certificate.ContestID != nil {
+			filled, err := c.fillContestResult(certificate, clientId, clientRole)
+			if err != nil {
+				return []models.CertificateCore{}, err
+			}
+			certificates[i], certificate = filled, filled
+		}
+		if strings.TrimSpace(certificate.Contest) == "" {
+			return []models.CertificateCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrIncorrectCertificate,
+			}
+		}
+		if _, err := c.userGateway.GetUserById(certificate.UserID); err != nil {
+			return []models.CertificateCore{}, err
+		}
+		if err := c.unitService.CheckUserAccess(certificate.UserID, clientId, clientRole); err != nil {
+			return []models.CertificateCore{}, err
+		}
+		code, err := utils.GenerateRandomString(16)
+		if err != nil {
+			return []models.CertificateCore{}, utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		certificates[i].TemplateID = templateId
+		certificates[i].IssuedByID = clientId
+		certificates[i].Code = code
+	}
+	created, err := c.certificateGateway.CreateCertificates(certificates)
+	if err != nil {
+		return []models.CertificateCore{}, err
+	}
+	for _, certificate := range created {
+		c.parentDashboardService.NotifyParents(certificate.UserID, "Ребёнок получил сертификат",
+			"Ребёнку выдан сертификат конкурса «"+certificate.Contest+"».",
+			map[string]string{"certificateId": strconv.Itoa(int(certificate.ID))})
+	}
+	return withVerificationUrls(created), nil
+}
+
+// fillContestResult takes the title of the platform contest and the place and score of the team of the user,
+// the typed values are kept only when the result is not published
+func (c CertificateServiceImpl) fillContestResult(certificate models.CertificateCore, clientId uint,
+	clientRole models.Role) (models.CertificateCore, error) {
+	contest, err := c.contestService.GetContestById(*certificate.ContestID, clientId, clientRole)
+	if err != nil {
+		return models.CertificateCore{}, err
+	}
+	if err := c.contestService.CheckContestManager(contest, clientId, clientRole); err != nil {
+		return models.CertificateCore{}, err
+	}
+	certificate.Contest = contest.Title
+	team, err := c.teamGateway.GetTeamByMember(contest.ID, certificate.UserID)
+	if err != nil {
+		var responseError utils.ResponseError
+		if errors.As(err, &responseError) && responseError.Message == consts.ErrNotFoundInDB {
+			return certificate, nil
+		}
+		return models.CertificateCore{}, err
+	}
+	if team.Place != nil {
+		certificate.Place = team.Place
+	}
+	if team.Score != nil {
+		certificate.Score = team.Score
+	}
+	return certificate, nil
+}
+
+func (c CertificateServiceImpl) RevokeCertificate(id, clientId uint, clientRole models.Role) error {
+	certificate, err := c.certificateGateway.GetCertificateById(id)
+	if err != nil {
+		return err
+	}
+	if err := c.unitService.CheckUserAccess(certificate.UserID, clientId, clientRole); err != nil {
+		return err
+	}
+	return c.certificateGateway.RevokeCertificate(id)
+}
+
+func (c CertificateServiceImpl) GetCertificatesByTemplate(templateId uint, page, pageSize *int, clientId uint,
+	clientRole models.Role) (certificates []models.CertificateCore, countRows uint, err error) {
+	if _, err := c.GetTemplateById(templateId, clientId, clientRole); err != nil {
+		return []models.CertificateCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	certificates, countRows, err = c.certificateGateway.GetCertificatesByTemplate(templateId, offset, limit)
+	return withVerificationUrls(certificates), countRows, err
+}
+
+func (c CertificateServiceImpl) GetCertificatesByUser(page, pageSize *int, clientId uint) (
+	certificates []models.CertificateCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	certificates, countRows, err = c.certificateGateway.GetCertificatesByUser(clientId, offset, limit)
+	return withVerificationUrls(certificates), countRows, err
+}
+
+// GetCertificatesForPrint returns not revoked certificates of the client or of users available to the admin
+func (c CertificateServiceImpl) GetCertificatesForPrint(ids []uint, clientId uint, clientRole models.Role) (
+	[]models.CertificateCore, error) {
+	certificates := make([]models.CertificateCore, 0, len(ids))
+	for _, id := range ids {
+		certificate, err := c.certificateGateway.GetCertificateById(id)
+		if err != nil {
+			return []models.CertificateCore{}, err
+		}
+		if certificate.UserID != clientId {
+			if clientRole != models.RoleSuperAdmin && clientRole != models.RoleUnitAdmin {
+				return []models.CertificateCore{}, utils.ResponseError{
+					Code:    http.StatusForbidden,
+					Message: consts.ErrAccessDenied,
+				}
+			}
+			if err := c.unitService.CheckUserAccess(certificate.UserID, clientId, clientRole); err != nil {
+				return []models.CertificateCore{}, err
+			}
+		}
+		if certificate.RevokedAt != nil {
+			return []models.CertificateCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrCertificateRevoked,
+			}
+		}
+		certificates = append(certificates, certificate)
+	}
+	return withVerificationUrls(certificates), nil
+}
+
+// VerifyCertificate is public, anyone with the code from the QR code sees whom and for what the certificate is issued
+func (c CertificateServiceImpl) VerifyCertificate(code string) (models.CertificateCore, error) {
+	certificate, err := c.certificateGateway.GetCertificateByCode(code)
+	if err != nil {
+		return models.CertificateCore{}, err
+	}
+	return withVerificationUrls([]models.CertificateCore{certificate})[0], nil
+}
+
+// WritePdf writes certificates as one document with a page for every certificate
+func (c CertificateServiceImpl) WritePdf(w io.Writer, certificates []models.CertificateCore) error {
+	fontData, err := os.ReadFile(viper.GetString("certificates.font_path"))
+	if err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	font, err := pdf.ParseFont(fontData)
+	if err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	doc := pdf.NewDocument(font)
+	// backgrounds are embedded once for all certificates of the template
+	backgrounds := make(map[string]*pdf.Image)
+	for _, certificate := range certificates {
+		template := certificate.Template
+		background, ok := backgrounds[template.Background]
+		if !ok {
+			data, err := os.ReadFile(filepath.Join(viper.GetString("certificates.storage_path"), template.Background))
+			if err != nil {
+				return utils.ResponseError{
+					Code:    http.StatusInternalServerError,
+					Message: err.Error(),
+				}
+			}
+			if background, err = doc.AddImage(data); err != nil {
+				return utils.ResponseError{
+					Code:    http.StatusInternalServerError,
+					Message: err.Error(),
+				}
+			}
+			backgrounds[template.Background] = background
+		}
+		page := doc.AddPage(template.Width, template.Height)
+		page.DrawImage(background, 0, 0, template.Width, template.Height)
+		for _, field := range template.Fields {
+			page.Text(fillCertificatePlaceholders(field.Text, certificate), field.X, field.Y, field.FontSize,
+				certificateAligns[field.Align])
+		}
+		qr, err := qrcode.New(certificate.VerificationURL, qrcode.Medium)
+		if err != nil {
+			return utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		bitmap := qr.Bitmap()
+		module := template.QrSize / float64(len(bitmap))
+		for row, modules := range bitmap {
+			for column, isDark := range modules {
+				if isDark {
+					page.Rect(template.QrX+float64(column)*module, template.QrY+template.QrSize-float64(row+1)*module,
+						module, module)
+				}
+			}
+		}
+	}
+	return doc.Write(w)
+}
+
+var certificateAligns = map[models.CertificateTextAlign]pdf.Align{
+	models.CertificateTextAlignLeft:   pdf.AlignLeft,
+	models.CertificateTextAlignCenter: pdf.AlignCenter,
+	models.CertificateTextAlignRight:  pdf.AlignRight,
+}
+
+func fillCertificatePlaceholders(text string, certificate models.CertificateCore) string {
+	var place, score string
+	if certificate.Place != nil {
+		place = strconv.Itoa(*certificate.Place)
+	}
+	if certificate.Score != nil {
+		score = strconv.FormatFloat(*certificate.Score, 'f', -1, 64)
+	}
+	user := certificate.User
+	return strings.NewReplacer(
+		"{{name}}", strings.TrimSpace(user.Lastname+" "+user.Firstname+" "+user.Middlename),
+		"{{contest}}", certificate.Contest,
+		"{{place}}", place,
+		"{{score}}", score,
+		"{{date}}", certificate.CreatedAt.Format("02.01.2006"),
+	).Replace(text)
+}
+
+func withVerificationUrls(certificates []models.CertificateCore) []models.CertificateCore {
+	for i := range certificates {
+		certificates[i].VerificationURL = viper.GetString("certificates.verification_url") + certificates[i].Code
+	}
+	return certificates
+}
+
+func validateCertificateTemplate(template models.CertificateTemplateCore) error {
+	incorrect := utils.ResponseError{
+		Code:    http.StatusBadRequest,
+		Message: consts.ErrIncorrectCertificateTemplate,
+	}
+	if strings.TrimSpace(template.Name) == "" || template.Width <= 0 || template.Height <= 0 || template.QrSize <= 0 ||
+		template.Background != filepath.Base(template.Background) {
+		return incorrect
+	}
+	if _, err := os.Stat(filepath.Join(viper.GetString("certificates.storage_path"), template.Background)); err != nil {
+		return incorrect
+	}
+	for _, field := range template.Fields {
+		if field.FontSize <= 0 || !field.Align.IsValid() {
+			return incorrect
+		}
+	}
+	return nil
+}
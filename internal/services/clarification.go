@@ -0,0 +1,233 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/pubsub"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ClarificationService interface {
+	AskClarification(contestId uint, taskId *uint, question string, clientId uint) (models.ClarificationCore, error)
+	AnswerClarification(id uint, answer string, isPublic bool, clientId uint, clientRole models.Role) (
+		models.ClarificationCore, error)
+	GetClarifications(contestId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		clarifications []models.ClarificationCore, countRows uint, err error)
+	PostAnnouncement(contestId uint, text string, clientId uint, clientRole models.Role) (models.AnnouncementCore, error)
+	GetAnnouncements(contestId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		announcements []models.AnnouncementCore, countRows uint, err error)
+	SubscribeContestEvents(contestId, clientId uint, clientRole models.Role) (
+		events <-chan models.ContestEventCore, unsubscribe func(), err error)
+}
+
+type ClarificationServiceImpl struct {
+	clarificationGateway gateways.ClarificationGateway
+	contestGateway       gateways.ContestGateway
+	contestService       ContestService
+	broker               *pubsub.Broker[models.ContestEventCore]
+}
+
+func NewClarificationService(
+	clarificationGateway gateways.ClarificationGateway,
+	contestGateway gateways.ContestGateway,
+	contestService ContestService,
+) *ClarificationServiceImpl {
+	return &ClarificationServiceImpl{
+		clarificationGateway: clarificationGateway,
+		contestGateway:       contestGateway,
+		contestService:       contestService,
+		broker:               pubsub.NewBroker[models.ContestEventCore](liveEventsBufferSize),
+	}
+}
+
+// AskClarification is available to confirmed participants while the contest is running
+func (c ClarificationServiceImpl) AskClarification(contestId uint, taskId *uint, question string, clientId uint) (
+	models.ClarificationCore, error) {
+	contest, err := c.contestGateway.GetContestById(contestId)
+	if err != nil {
+		return models.ClarificationCore{}, err
+	}
+	if err := c.contestService.CheckContestParticipant(contest, clientId); err != nil {
+		return models.ClarificationCore{}, err
+	}
+	now := time.Now()
+	if now.Before(contest.StartAt) || !now.Before(contest.EndAt) {
+		return models.ClarificationCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrClarificationsClosed,
+		}
+	}
+	incorrect := utils.ResponseError{
+		Code:    http.StatusBadRequest,
+		Message: consts.ErrIncorrectClarification,
+	}
+	if strings.TrimSpace(question) == "" {
+		return models.ClarificationCore{}, incorrect
+	}
+	if taskId != nil {
+		task, err := c.contestGateway.GetTaskById(*taskId)
+		if err != nil {
+			return models.ClarificationCore{}, err
+		}
+		if task.ContestID != contestId {
+			return models.ClarificationCore{}, incorrect
+		}
+	}
+	clarification, err := c.clarificationGateway.CreateClarification(models.ClarificationCore{
+		ContestID: contestId,
+		TaskID:    taskId,
+		AuthorID:  clientId,
+		Question:  question,
+	})
+	if err != nil {
+		return models.ClarificationCore{}, err
+	}
+	c.publish(contestId, models.ContestEventCore{
+		Kind:          models.ContestEventKindClarificationAsked,
+		Clarification: &clarification,
+	})
+	return clarification, nil
+}
+
+// AnswerClarification answers privately to the author or publishes the answer to all participants.
+// The answer may be changed later, subscribers get the clarification again.
+func (c ClarificationServiceImpl) AnswerClarification(id uint, answer string, isPublic bool, clientId uint,
+	clientRole models.Role) (models.ClarificationCore, error) {
+	clarification, err := c.clarificationGateway.GetClarificationById(id)
+	if err != nil {
+		return models.ClarificationCore{}, err
+	}
+	if _, err := c.getManagedContest(clarification.ContestID, clientId, clientRole); err != nil {
+		return models.ClarificationCore{}, err
+	}
+	if strings.TrimSpace(answer) == "" {
+		return models.ClarificationCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectClarificationAnswer,
+		}
+	}
+	clarification, err = c.clarificationGateway.AnswerClarification(id, answer, isPublic, clientId)
+	if err != nil {
+		return models.ClarificationCore{}, err
+	}
+	c.publish(clarification.ContestID, models.ContestEventCore{
+		Kind:          models.ContestEventKindClarificationAnswered,
+		Clarification: &clarification,
+	})
+	return clarification, nil
+}
+
+// GetClarifications returns all clarifications to managers of the contest,
+// participants get public clarifications and their own questions
+func (c ClarificationServiceImpl) GetClarifications(contestId uint, page, pageSize *int, clientId uint,
+	clientRole models.Role) (clarifications []models.ClarificationCore, countRows uint, err error) {
+	isManager, err := c.checkContestEventsAccess(contestId, clientId, clientRole)
+	if err != nil {
+		return []models.ClarificationCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	if isManager {
+		return c.clarificationGateway.GetClarificationsByContest(contestId, offset, limit)
+	}
+	return c.clarificationGateway.GetClarificationsForUser(contestId, clientId, offset, limit)
+}
+
+func (c ClarificationServiceImpl) PostAnnouncement(contestId uint, text string, clientId uint, clientRole models.Role) (
+	models.AnnouncementCore, error) {
+	if _, err := c.getManagedContest(contestId, clientId, clientRole); err != nil {
+		return models.AnnouncementCore{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return models.AnnouncementCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectAnnouncement,
+		}
+	}
+	announcement, err := c.clarificationGateway.CreateAnnouncement(models.AnnouncementCore{
+		ContestID: contestId,
+		AuthorID:  clientId,
+		Text:      text,
+	})
+	if err != nil {
+		return models.AnnouncementCore{}, err
+	}
+	c.publish(contestId, models.ContestEventCore{
+		Kind:         models.ContestEventKindAnnouncement,
+		Announcement: &announcement,
+	})
+	return announcement, nil
+}
+
+func (c ClarificationServiceImpl) GetAnnouncements(contestId uint, page, pageSize *int, clientId uint,
+	clientRole models.Role) (announcements []models.AnnouncementCore, countRows uint, err error) {
+	if _, err := c.checkContestEventsAccess(contestId, clientId, clientRole); err != nil {
+		return []models.AnnouncementCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return c.clarificationGateway.GetAnnouncementsByContest(contestId, offset, limit)
+}
+
+// SubscribeContestEvents delivers announcements and clarifications of the contest as they are posted.
+// Participants get only the events they could read with GetClarifications and GetAnnouncements.
+// The channel is closed after unsubscribe.
+func (c ClarificationServiceImpl) SubscribeContestEvents(contestId, clientId uint, clientRole models.Role) (
+	events <-chan models.ContestEventCore, unsubscribe func(), err error) {
+	isManager, err := c.checkContestEventsAccess(contestId, clientId, clientRole)
+	if err != nil {
+		return nil, nil, err
+	}
+	var filter func(event models.ContestEventCore) bool
+	if !isManager {
+		filter = func(event models.ContestEventCore) bool {
+			return isVisibleToParticipant(event, clientId)
+		}
+	}
+	events, unsubscribe = c.broker.Subscribe(contestEventsTopic(contestId), filter)
+	return events, unsubscribe, nil
+}
+
+func (c ClarificationServiceImpl) publish(contestId uint, event models.ContestEventCore) {
+	c.broker.Publish(contestEventsTopic(contestId), event)
+}
+
+func (c ClarificationServiceImpl) getManagedContest(contestId, clientId uint, clientRole models.Role) (
+	models.ContestCore, error) {
+	contest, err := c.contestGateway.GetContestById(contestId)
+	if err != nil {
+		return models.ContestCore{}, err
+	}
+	if err := c.contestService.CheckContestManager(contest, clientId, clientRole); err != nil {
+		return models.ContestCore{}, err
+	}
+	return contest, nil
+}
+
+// checkContestEventsAccess allows students with a confirmed registration and managers of the contest
+func (c ClarificationServiceImpl) checkContestEventsAccess(contestId, clientId uint, clientRole models.Role) (
+	isManager bool, err error) {
+	contest, err := c.contestGateway.GetContestById(contestId)
+	if err != nil {
+		return false, err
+	}
+	if clientRole != models.RoleStudent {
+		return true, c.contestService.CheckContestManager(contest, clientId, clientRole)
+	}
+	return false, c.contestService.CheckContestParticipant(contest, clientId)
+}
+
+func isVisibleToParticipant(event models.ContestEventCore, userId uint) bool {
+	if event.Clarification == nil {
+		return true
+	}
+	return event.Clarification.AuthorID == userId ||
+		(event.Clarification.IsPublic && event.Clarification.AnsweredAt != nil)
+}
+
+func contestEventsTopic(contestId uint) string {
+	return strconv.Itoa(int(contestId))
+}
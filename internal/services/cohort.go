@@ -0,0 +1,189 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+)
+
+type CohortService interface {
+	CreateCohort(courseId string, groupId uint, name string, clientId uint, clientRole models.Role) (models.CohortCore, error)
+	GetCohortsByCourse(courseId string, clientId uint, clientRole models.Role) (
+		cohorts []models.CohortCore, countRows uint, err error)
+	AddUsersToCohort(cohortId uint, userIds []uint, clientId uint, clientRole models.Role) ([]models.EnrollmentResultCore, error)
+	ReconcileCohorts() error
+	GetCohortMismatches(page, pageSize *int, clientId uint, clientRole models.Role) (
+		mismatches []models.CohortMismatchCore, countRows uint, err error)
+}
+
+type CohortServiceImpl struct {
+	loggers       logger.Loggers
+	cohortGateway gateways.CohortGateway
+	edxGateway    gateways.EdxGateway
+	groupGateway  gateways.GroupGateway
+	courseService CourseService
+	groupService  GroupService
+	unitService   UnitService
+}
+
+// CreateCohort binds the group to a new edX cohort. The cohort is created in edX first,
+// so the platform never keeps cohorts unknown to edX.
+func (c CohortServiceImpl) CreateCohort(courseId string, groupId uint, name string, clientId uint, clientRole models.Role) (
+	models.CohortCore, error) {
+	if _, err := c.groupService.GetGroupById(groupId, clientId, clientRole); err != nil {
+		return models.CohortCore{}, err
+	}
+	edxCohortId, err := c.edxGateway.PostCohort(courseId, name)
+	if err != nil {
+		return models.CohortCore{}, err
+	}
+	return c.cohortGateway.CreateCohort(models.CohortCore{
+		CourseID:    courseId,
+		GroupID:     groupId,
+		Name:        name,
+		EdxCohortID: edxCohortId,
+		CreatedByID: clientId,
+	})
+}
+
+// GetCohortsByCourse returns cohorts of groups of the unit admin units or of the teacher groups
+func (c CohortServiceImpl) GetCohortsByCourse(courseId string, clientId uint, clientRole models.Role) (
+	cohorts []models.CohortCore, countRows uint, err error) {
+	unitIds, teacherId, err := c.getClientFilter(clientId, clientRole)
+	if err != nil {
+		return []models.CohortCore{}, 0, err
+	}
+	return c.cohortGateway.GetCohortsByCourse(courseId, unitIds, teacherId)
+}
+
+// AddUsersToCohort enrolls students of the cohort group on the course and adds the enrolled ones to the edX cohort
+func (c CohortServiceImpl) AddUsersToCohort(cohortId uint, userIds []uint, clientId uint, clientRole models.Role) (
+	[]models.EnrollmentResultCore, error) {
+	cohort, err := c.cohortGateway.GetCohortById(cohortId)
+	if err != nil {
+		return []models.EnrollmentResultCore{}, err
+	}
+	if _, err := c.groupService.GetGroupById(cohort.GroupID, clientId, clientRole); err != nil {
+		return []models.EnrollmentResultCore{}, err
+	}
+	results := make([]models.EnrollmentResultCore, 0, len(userIds))
+	var studentIds []uint
+	for _, userId := range userIds {
+		isStudent, err := c.groupGateway.IsStudent(cohort.GroupID, userId)
+		if err != nil {
+			return []models.EnrollmentResultCore{}, err
+		}
+		if !isStudent {
+			results = append(results, models.EnrollmentResultCore{
+				UserID: userId,
+				Err: utils.ResponseError{
+					Code:    http.StatusBadRequest,
+					Message: consts.ErrIncorrectGroupMember,
+				},
+			})
+			continue
+		}
+		studentIds = append(studentIds, userId)
+	}
+	results = append(results, c.courseService.EnrollUsers(cohort.CourseID, studentIds, clientId, clientRole)...)
+	var usernames []string
+	for _, result := range results {
+		if result.Err == nil {
+			usernames = append(usernames, getEdxUsername(result.UserID))
+		}
+	}
+	if len(usernames) == 0 {
+		return results, nil
+	}
+	if err := c.edxGateway.PostCohortUsers(cohort.CourseID, cohort.EdxCohortID, usernames); err != nil {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = err
+			}
+		}
+	}
+	return results, nil
+}
+
+// ReconcileCohorts compares students of the group of every cohort with the edX cohort and
+// replaces the stored mismatches. A failed cohort does not stop the others.
+func (c CohortServiceImpl) ReconcileCohorts() error {
+	cohorts, err := c.cohortGateway.GetAllCohorts()
+	if err != nil {
+		return err
+	}
+	for _, cohort := range cohorts {
+		if err := c.reconcileCohort(cohort); err != nil {
+			c.loggers.Err.Printf("cohort %d reconciliation: %s", cohort.ID, err.Error())
+		}
+	}
+	return nil
+}
+
+func (c CohortServiceImpl) reconcileCohort(cohort models.CohortCore) error {
+	memberIds, err := c.groupGateway.GetStudentIds(cohort.GroupID)
+	if err != nil {
+		return err
+	}
+	edxUsernames, err := c.edxGateway.GetCohortUsers(cohort.CourseID, cohort.EdxCohortID)
+	if err != nil {
+		return err
+	}
+	inEdx := make(map[string]bool, len(edxUsernames))
+	for _, username := range edxUsernames {
+		inEdx[username] = true
+	}
+	var mismatches []models.CohortMismatchCore
+	inPlatform := make(map[string]bool, len(memberIds))
+	for _, memberId := range memberIds {
+		userId := memberId
+		username := getEdxUsername(userId)
+		inPlatform[username] = true
+		if !inEdx[username] {
+			mismatches = append(mismatches, models.CohortMismatchCore{
+				CohortID: cohort.ID,
+				Username: username,
+				UserID:   &userId,
+				Kind:     models.CohortMismatchKindMissingInEdx,
+			})
+		}
+	}
+	for _, username := range edxUsernames {
+		if inPlatform[username] {
+			continue
+		}
+		mismatch := models.CohortMismatchCore{
+			CohortID: cohort.ID,
+			Username: username,
+			Kind:     models.CohortMismatchKindUnexpectedInEdx,
+		}
+		if userId, ok := getUserIdByEdxUsername(username); ok {
+			mismatch.UserID = &userId
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	return c.cohortGateway.ReplaceMismatches(cohort.ID, mismatches)
+}
+
+func (c CohortServiceImpl) GetCohortMismatches(page, pageSize *int, clientId uint, clientRole models.Role) (
+	mismatches []models.CohortMismatchCore, countRows uint, err error) {
+	unitIds, teacherId, err := c.getClientFilter(clientId, clientRole)
+	if err != nil {
+		return []models.CohortMismatchCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return c.cohortGateway.GetMismatches(offset, limit, unitIds, teacherId)
+}
+
+// getClientFilter limits unit admins to cohorts of their units and teachers to cohorts of their groups
+func (c CohortServiceImpl) getClientFilter(clientId uint, clientRole models.Role) (
+	unitIds []uint, teacherId *uint, err error) {
+	if clientRole == models.RoleTeacher {
+		return nil, &clientId, nil
+	}
+	unitIds, err = c.unitService.GetClientUnitIds(clientId, clientRole)
+	return unitIds, nil, err
+}
@@ -0,0 +1,501 @@
+package services
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type ContestService interface {
+	CreateContest(contest models.ContestCore, clientId uint, clientRole models.Role) (models.ContestCore, error)
+	UpdateContest(contest models.ContestCore, clientId uint, clientRole models.Role) (models.ContestCore, error)
+	DeleteContest(id, clientId uint, clientRole models.Role) error
+	GetContestById(id, clientId uint, clientRole models.Role) (models.ContestCore, error)
+	GetContests(page, pageSize *int, clientId uint, clientRole models.Role) (contests []models.ContestCore, countRows uint, err error)
+	CreateTask(task models.ContestTaskCore, clientId uint, clientRole models.Role) (models.ContestTaskCore, error)
+	UpdateTask(task models.ContestTaskCore, clientId uint, clientRole models.Role) (models.ContestTaskCore, error)
+	DeleteTask(id, clientId uint, clientRole models.Role) error
+	GetTasks(contestId, clientId uint, clientRole models.Role) ([]models.ContestTaskCore, error)
+	InviteToContest(contestId uint, userIds []uint, clientId uint, clientRole models.Role) error
+	RemoveInvitation(contestId, userId, clientId uint, clientRole models.Role) error
+	Register(contestId, clientId uint) (models.ContestRegistrationCore, error)
+	CancelRegistration(contestId, userId, clientId uint, clientRole models.Role) error
+	GiveConsent(registrationId, clientId uint, clientRole models.Role) (models.ContestRegistrationCore, error)
+	GetRegistrationsByContest(contestId uint, status *models.ContestRegistrationStatus, page, pageSize *int,
+		clientId uint, clientRole models.Role) (registrations []models.ContestRegistrationCore, countRows uint, err error)
+	GetRegistrationsByUser(page, pageSize *int, clientId uint) (
+		registrations []models.ContestRegistrationCore, countRows uint, err error)
+	GetRegistrationsAwaitingConsent(page, pageSize *int, clientId uint) (
+		registrations []models.ContestRegistrationCore, countRows uint, err error)
+	CheckContestManager(contest models.ContestCore, clientId uint, clientRole models.Role) error
+	CheckContestParticipant(contest models.ContestCore, clientId uint) error
+}
+
+type ContestServiceImpl struct {
+	contestGateway   gateways.ContestGateway
+	userGateway      gateways.UserGateway
+	unitGateway      gateways.UnitGateway
+	parentRelGateway gateways.ParentRel
+	teamGateway      gateways.TeamGateway
+	unitService      UnitService
+}
+
+func (c ContestServiceImpl) CreateContest(contest models.ContestCore, clientId uint, clientRole models.Role) (
+	models.ContestCore, error) {
+	if contest.UnitID == nil && clientRole == models.RoleUnitAdmin {
+		return models.ContestCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrUnitRequired,
+		}
+	}
+	if contest.UnitID != nil {
+		if _, err := c.unitGateway.GetUnitById(*contest.UnitID); err != nil {
+			return models.ContestCore{}, err
+		}
+		if err := c.unitService.CheckUnitAccess(*contest.UnitID, clientId, clientRole); err != nil {
+			return models.ContestCore{}, err
+		}
+	}
+	if err := validateContest(contest); err != nil {
+		return models.ContestCore{}, err
+	}
+	contest.CreatedByID = clientId
+	return c.contestGateway.CreateContest(contest)
+}
+
+func (c ContestServiceImpl) UpdateContest(contest models.ContestCore, clientId uint, clientRole models.Role) (
+	models.ContestCore, error) {
+	if _, err := c.getManagedContest(contest.ID, clientId, clientRole); err != nil {
+		return models.ContestCore{}, err
+	}
+	if err := validateContest(contest); err != nil {
+		return models.ContestCore{}, err
+	}
+	// existing teams must stay valid, so the maximum size is not cleared or reduced below them
+	largestTeamSize, err := c.teamGateway.GetLargestTeamSize(contest.ID)
+	if err != nil {
+		return models.ContestCore{}, err
+	}
+	if largestTeamSize > 0 && (contest.MaxTeamSize == nil || *contest.MaxTeamSize < largestTeamSize) {
+		return models.ContestCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamSizeBelowTeams,
+		}
+	}
+	return c.contestGateway.UpdateContest(contest)
+}
+
+func (c ContestServiceImpl) DeleteContest(id, clientId uint, clientRole models.Role) error {
+	if _, err := c.getManagedContest(id, clientId, clientRole); err != nil {
+		return err
+	}
+	return c.contestGateway.DeleteContest(id)
+}
+
+func (c ContestServiceImpl) GetContestById(id, clientId uint, clientRole models.Role) (models.ContestCore, error) {
+	if clientRole == models.RoleSuperAdmin || clientRole == models.RoleUnitAdmin {
+		return c.contestGateway.GetContestById(id)
+	}
+	contest, err := c.contestGateway.GetContestById(id)
+	if err != nil {
+		return models.ContestCore{}, err
+	}
+	if err := c.checkContestAccess(contest, clientId); err != nil {
+		return models.ContestCore{}, err
+	}
+	return contest, nil
+}
+
+func (c ContestServiceImpl) GetContests(page, pageSize *int, clientId uint, clientRole models.Role) (
+	contests []models.ContestCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	if clientRole == models.RoleSuperAdmin || clientRole == models.RoleUnitAdmin {
+		return c.contestGateway.GetContests(offset, limit)
+	}
+	return c.contestGateway.GetContestsForUser(clientId, offset, limit)
+}
+
+func (c ContestServiceImpl) CreateTask(task models.ContestTaskCore, clientId uint, clientRole models.Role) (
+	models.ContestTaskCore, error) {
+	if _, err := c.getManagedContest(task.ContestID, clientId, clientRole); err != nil {
+		return models.ContestTaskCore{}, err
+	}
+	if err := validateContestTask(task); err != nil {
+		return models.ContestTaskCore{}, err
+	}
+	return c.contestGateway.CreateTask(task)
+}
+
+func (c ContestServiceImpl) UpdateTask(task models.ContestTaskCore, clientId uint, clientRole models.Role) (
+	models.ContestTaskCore, error) {
+	taskCore, err := c.contestGateway.GetTaskById(task.ID)
+	if err != nil {
+		return models.ContestTaskCore{}, err
+	}
+	if _, err := c.getManagedContest(taskCore.ContestID, clientId, clientRole); err != nil {
+		return models.ContestTaskCore{}, err
+	}
+	if err := validateContestTask(task); err != nil {
+		return models.ContestTaskCore{}, err
+	}
+	return c.contestGateway.UpdateTask(task)
+}
+
+func (c ContestServiceImpl) DeleteTask(id, clientId uint, clientRole models.Role) error {
+	task, err := c.contestGateway.GetTaskById(id)
+	if err != nil {
+		return err
+	}
+	if _, err := c.getManagedContest(task.ContestID, clientId, clientRole); err != nil {
+		return err
+	}
+	return c.contestGateway.DeleteTask(id)
+}
+
+// GetTasks returns tasks to managers of the contest at any time and to confirmed participants after the start
+func (c ContestServiceImpl) GetTasks(contestId, clientId uint, clientRole models.Role) ([]models.ContestTaskCore, error) {
+	contest, err := c.contestGateway.GetContestById(contestId)
+	if err != nil {
+		return []models.ContestTaskCore{}, err
+	}
+	if clientRole != models.RoleStudent {
+		if err := c.CheckContestManager(contest, clientId, clientRole); err != nil {
+			return []models.ContestTaskCore{}, err
+		}
+		return c.contestGateway.GetTasksByContest(contestId)
+	}
+	if err := c.CheckContestParticipant(contest, clientId); err != nil {
+		return []models.ContestTaskCore{}, err
+	}
+	if time.Now().Before(contest.StartAt) {
+		return []models.ContestTaskCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestNotStarted,
+		}
+	}
+	return c.contestGateway.GetTasksByContest(contestId)
+}
+
+func (c ContestServiceImpl) InviteToContest(contestId uint, userIds []uint, clientId uint, clientRole models.Role) error {
+	if _, err := c.getManagedContest(contestId, clientId, clientRole); err != nil {
+		return err
+	}
+	for _, userId := range userIds {
+		user, err := c.userGateway.GetUserById(userId)
+		if err != nil {
+			return err
+		}
+		if user.Role != models.RoleStudent {
+			return utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrIncorrectContestParticipant,
+			}
+		}
+		if err := c.unitService.CheckUserAccess(userId, clientId, clientRole); err != nil {
+			return err
+		}
+	}
+	return c.contestGateway.CreateInvitations(contestId, userIds)
+}
+
+func (c ContestServiceImpl) RemoveInvitation(contestId, userId, clientId uint, clientRole models.Role) error {
+	if _, err := c.getManagedContest(contestId, clientId, clientRole); err != nil {
+		return err
+	}
+	return c.contestGateway.DeleteInvitation(contestId, userId)
+}
+
+// Register checks the eligibility of the student, assigns the age category by the age at the start of the contest
+// and waits for the consent of a parent for minors. The registration is waitlisted when there are no free places.
+func (c ContestServiceImpl) Register(contestId, clientId uint) (models.ContestRegistrationCore, error) {
+	contest, err := c.contestGateway.GetContestById(contestId)
+	if err != nil {
+		return models.ContestRegistrationCore{}, err
+	}
+	if err := c.checkContestAccess(contest, clientId); err != nil {
+		return models.ContestRegistrationCore{}, err
+	}
+	if !time.Now().Before(contest.StartAt) {
+		return models.ContestRegistrationCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestRegistrationClosed,
+		}
+	}
+	_, err = c.contestGateway.GetRegistration(contestId, clientId)
+	var responseError utils.ResponseError
+	if err == nil {
+		return models.ContestRegistrationCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestAlreadyRegistered,
+		}
+	} else if !errors.As(err, &responseError) || responseError.Message != consts.ErrNotFoundInDB {
+		return models.ContestRegistrationCore{}, err
+	}
+	user, err := c.userGateway.GetUserById(clientId)
+	if err != nil {
+		return models.ContestRegistrationCore{}, err
+	}
+	registration := models.ContestRegistrationCore{
+		ContestID: contestId,
+		UserID:    clientId,
+		Status:    models.ContestRegistrationStatusConfirmed,
+	}
+	if user.BirthDate != nil {
+		age := getAge(*user.BirthDate, contest.StartAt)
+		registration.Age = &age
+	}
+	needsAge := contest.MinAge != nil || contest.MaxAge != nil || len(contest.AgeCategories) > 0
+	if needsAge && registration.Age == nil {
+		return models.ContestRegistrationCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestBirthDateRequired,
+		}
+	}
+	if (contest.MinAge != nil && *registration.Age < *contest.MinAge) ||
+		(contest.MaxAge != nil && *registration.Age > *contest.MaxAge) {
+		return models.ContestRegistrationCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestAgeNotEligible,
+		}
+	}
+	if contest.MinGrade != nil || contest.MaxGrade != nil {
+		if user.Grade == nil {
+			return models.ContestRegistrationCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrContestGradeRequired,
+			}
+		}
+		if (contest.MinGrade != nil && *user.Grade < *contest.MinGrade) ||
+			(contest.MaxGrade != nil && *user.Grade > *contest.MaxGrade) {
+			return models.ContestRegistrationCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrContestGradeNotEligible,
+			}
+		}
+	}
+	if len(contest.AgeCategories) > 0 {
+		for _, category := range contest.AgeCategories {
+			if *registration.Age >= category.MinAge && *registration.Age <= category.MaxAge {
+				registration.AgeCategoryID = &category.ID
+				break
+			}
+		}
+		if registration.AgeCategoryID == nil {
+			return models.ContestRegistrationCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrContestAgeNotEligible,
+			}
+		}
+	}
+	// users without a birth date are treated as minors
+	if registration.Age == nil || *registration.Age < viper.GetInt("contests.adult_age") {
+		registration.ConsentRequired = true
+		registration.Status = models.ContestRegistrationStatusAwaitingConsent
+	}
+	return c.contestGateway.CreateRegistration(registration, contest.Capacity)
+}
+
+// CancelRegistration cancels the registration of the student, admins managing the contest may cancel any
+func (c ContestServiceImpl) CancelRegistration(contestId, userId, clientId uint, clientRole models.Role) error {
+	if clientRole == models.RoleStudent {
+		if userId != clientId {
+			return utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+	} else if _, err := c.getManagedContest(contestId, clientId, clientRole); err != nil {
+		return err
+	}
+	_, err := c.teamGateway.GetTeamByMember(contestId, userId)
+	var responseError utils.ResponseError
+	if err == nil {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestRegistrationInTeam,
+		}
+	} else if !errors.As(err, &responseError) || responseError.Message != consts.ErrNotFoundInDB {
+		return err
+	}
+	return c.contestGateway.DeleteRegistration(contestId, userId)
+}
+
+// GiveConsent is given by a parent of the participant or by an admin having the consent on paper
+func (c ContestServiceImpl) GiveConsent(registrationId, clientId uint, clientRole models.Role) (
+	models.ContestRegistrationCore, error) {
+	registration, err := c.contestGateway.GetRegistrationById(registrationId)
+	if err != nil {
+		return models.ContestRegistrationCore{}, err
+	}
+	switch clientRole {
+	case models.RoleParent:
+		isChild, err := c.parentRelGateway.DoesExistRel(clientId, registration.UserID)
+		if err != nil {
+			return models.ContestRegistrationCore{}, err
+		}
+		if !isChild {
+			return models.ContestRegistrationCore{}, utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+	default:
+		if err := c.unitService.CheckUserAccess(registration.UserID, clientId, clientRole); err != nil {
+			return models.ContestRegistrationCore{}, err
+		}
+	}
+	if !registration.ConsentRequired || registration.ConsentedAt != nil {
+		return models.ContestRegistrationCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestConsentNotRequired,
+		}
+	}
+	return c.contestGateway.SetConsent(registrationId, clientId)
+}
+
+func (c ContestServiceImpl) GetRegistrationsByContest(contestId uint, status *models.ContestRegistrationStatus,
+	page, pageSize *int, clientId uint, clientRole models.Role) (
+	registrations []models.ContestRegistrationCore, countRows uint, err error) {
+	if _, err := c.getManagedContest(contestId, clientId, clientRole); err != nil {
+		return []models.ContestRegistrationCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return c.contestGateway.GetRegistrationsByContest(contestId, status, offset, limit)
+}
+
+func (c ContestServiceImpl) GetRegistrationsByUser(page, pageSize *int, clientId uint) (
+	registrations []models.ContestRegistrationCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return c.contestGateway.GetRegistrationsByUser(clientId, offset, limit)
+}
+
+func (c ContestServiceImpl) GetRegistrationsAwaitingConsent(page, pageSize *int, clientId uint) (
+	registrations []models.ContestRegistrationCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return c.contestGateway.GetRegistrationsAwaitingConsent(clientId, offset, limit)
+}
+
+// CheckContestManager denies access to the contest to everyone except super admins and the creator of the contest
+func (c ContestServiceImpl) CheckContestManager(contest models.ContestCore, clientId uint, clientRole models.Role) error {
+	if clientRole != models.RoleSuperAdmin && contest.CreatedByID != clientId {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return nil
+}
+
+// CheckContestParticipant denies access to the contest to everyone without a confirmed registration
+func (c ContestServiceImpl) CheckContestParticipant(contest models.ContestCore, clientId uint) error {
+	registration, err := c.contestGateway.GetRegistration(contest.ID, clientId)
+	var responseError utils.ResponseError
+	if errors.As(err, &responseError) && responseError.Message == consts.ErrNotFoundInDB {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrContestParticipantOnly,
+		}
+	} else if err != nil {
+		return err
+	}
+	if registration.Status != models.ContestRegistrationStatusConfirmed {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrContestParticipantOnly,
+		}
+	}
+	return nil
+}
+
+func (c ContestServiceImpl) getManagedContest(id, clientId uint, clientRole models.Role) (models.ContestCore, error) {
+	contest, err := c.contestGateway.GetContestById(id)
+	if err != nil {
+		return models.ContestCore{}, err
+	}
+	if err := c.CheckContestManager(contest, clientId, clientRole); err != nil {
+		return models.ContestCore{}, err
+	}
+	return contest, nil
+}
+
+// checkContestAccess denies access to contests of other units and to invitation only contests without an invitation
+func (c ContestServiceImpl) checkContestAccess(contest models.ContestCore, clientId uint) error {
+	if contest.UnitID != nil {
+		isMember, err := c.unitGateway.IsMember(*contest.UnitID, clientId)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+	}
+	if contest.InvitationOnly {
+		isInvited, err := c.contestGateway.IsInvited(contest.ID, clientId)
+		if err != nil {
+			return err
+		}
+		if !isInvited {
+			return utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrContestInvitationOnly,
+			}
+		}
+	}
+	return nil
+}
+
+// getAge returns full years since the birth date at the moment
+func getAge(birthDate, at time.Time) int {
+	age := at.Year() - birthDate.Year()
+	if at.Month() < birthDate.Month() || (at.Month() == birthDate.Month() && at.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+func validateContest(contest models.ContestCore) error {
+	incorrect := utils.ResponseError{
+		Code:    http.StatusBadRequest,
+		Message: consts.ErrIncorrectContest,
+	}
+	if strings.TrimSpace(contest.Title) == "" || !contest.EndAt.After(contest.StartAt) ||
+		(contest.Capacity != nil && *contest.Capacity <= 0) {
+		return incorrect
+	}
+	if !isCorrectRange(contest.MinAge, contest.MaxAge, 0) || !isCorrectRange(contest.MinGrade, contest.MaxGrade, minGrade) ||
+		(contest.MaxGrade != nil && *contest.MaxGrade > maxGrade) ||
+		!isCorrectRange(contest.MinTeamSize, contest.MaxTeamSize, 1) ||
+		(contest.MinTeamSize != nil && contest.MaxTeamSize == nil) {
+		return incorrect
+	}
+	for _, category := range contest.AgeCategories {
+		if strings.TrimSpace(category.Name) == "" || category.MinAge < 0 || category.MinAge > category.MaxAge {
+			return incorrect
+		}
+	}
+	return nil
+}
+
+func validateContestTask(task models.ContestTaskCore) error {
+	if strings.TrimSpace(task.Title) == "" || strings.TrimSpace(task.Statement) == "" || task.Position < 0 {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectContestTask,
+		}
+	}
+	return nil
+}
+
+// isCorrectRange checks optional bounds of a range
+func isCorrectRange(from, to *int, min int) bool {
+	return (from == nil || *from >= min) && (to == nil || *to >= min) && (from == nil || to == nil || *from <= *to)
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CourseService interface {
+	GetCourseById(courseId string) (models.CourseCore, error)
+	GetCoursesByUser(userId uint) (courses []models.CourseCore, countRows uint, err error)
+	EnrollUser(userId uint, courseId string) error
+	EnrollUsers(courseId string, userIds []uint, clientId uint, clientRole models.Role) []models.EnrollmentResultCore
+}
+
+type CourseServiceImpl struct {
+	edxGateway   gateways.EdxGateway
+	userGateway  gateways.UserGateway
+	groupGateway gateways.GroupGateway
+	unitService  UnitService
+	cache        *courseCache
+}
+
+// courseCache keeps courses loaded from edX for edx.cache_ttl,
+// courses change rarely and every user request would query them otherwise
+type courseCache struct {
+	mu      sync.RWMutex
+	courses map[string]cachedCourse
+}
+
+type cachedCourse struct {
+	course    models.CourseCore
+	expiresAt time.Time
+}
+
+func (c CourseServiceImpl) GetCourseById(courseId string) (models.CourseCore, error) {
+	c.cache.mu.RLock()
+	cached, ok := c.cache.courses[courseId]
+	c.cache.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.course, nil
+	}
+	course, err := c.edxGateway.GetCourse(courseId)
+	if err != nil {
+		return models.CourseCore{}, err
+	}
+	c.cache.mu.Lock()
+	c.cache.courses[courseId] = cachedCourse{
+		course:    course,
+		expiresAt: time.Now().Add(viper.GetDuration("edx.cache_ttl")),
+	}
+	c.cache.mu.Unlock()
+	return course, nil
+}
+
+func (c CourseServiceImpl) GetCoursesByUser(userId uint) (courses []models.CourseCore, countRows uint, err error) {
+	enrollments, err := c.edxGateway.GetEnrollments(getEdxUsername(userId))
+	if err != nil {
+		return []models.CourseCore{}, 0, err
+	}
+	for _, enrollment := range enrollments {
+		if !enrollment.IsActive {
+			continue
+		}
+		course, err := c.GetCourseById(enrollment.CourseID)
+		if err != nil {
+			return []models.CourseCore{}, 0, err
+		}
+		courses = append(courses, course)
+	}
+	return courses, uint(len(courses)), nil
+}
+
+// EnrollUser enrolls the user on the course by themselves, the edX account is registered on the first
+// enrollment. Courses by invitation only and courses outside of the enrollment period are refused.
+func (c CourseServiceImpl) EnrollUser(userId uint, courseId string) error {
+	course, err := c.GetCourseById(courseId)
+	if err != nil {
+		return err
+	}
+	if course.InvitationOnly {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrCourseInvitationOnly,
+		}
+	}
+	now := time.Now()
+	if (course.EnrollmentStart != nil && now.Before(*course.EnrollmentStart)) ||
+		(course.EnrollmentEnd != nil && now.After(*course.EnrollmentEnd)) {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrCourseEnrollmentClosed,
+		}
+	}
+	user, err := c.userGateway.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	username, err := c.provisionEdxUser(user)
+	if err != nil {
+		return err
+	}
+	return c.edxGateway.PostEnrollment(username, courseId)
+}
+
+// EnrollUsers enrolls every user on the course separately. Teachers may enroll only students
+// of their groups, unit admins only users of their units.
+func (c CourseServiceImpl) EnrollUsers(courseId string, userIds []uint, clientId uint, clientRole models.Role) []models.EnrollmentResultCore {
+	results := make([]models.EnrollmentResultCore, 0, len(userIds))
+	for _, userId := range userIds {
+		results = append(results, models.EnrollmentResultCore{
+			UserID: userId,
+			Err:    c.enrollUserByClient(userId, courseId, clientId, clientRole),
+		})
+	}
+	return results
+}
+
+func (c CourseServiceImpl) enrollUserByClient(userId uint, courseId string, clientId uint, clientRole models.Role) error {
+	user, err := c.userGateway.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	if err := c.unitService.CheckUserAccess(userId, clientId, clientRole); err != nil {
+		return err
+	}
+	if clientRole == models.RoleTeacher {
+		doesTeach, err := c.groupGateway.DoesTeachUser(clientId, userId)
+		if err != nil {
+			return err
+		}
+		if user.Role != models.RoleStudent || !doesTeach {
+			return utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+	}
+	username, err := c.provisionEdxUser(user)
+	if err != nil {
+		return err
+	}
+	return c.edxGateway.PostEnrollment(username, courseId)
+}
+
+// provisionEdxUser registers the edX account of the user if it does not exist yet
+func (c CourseServiceImpl) provisionEdxUser(user models.UserCore) (username string, err error) {
+	// users sign in to edX through the platform, so the edX password is never used
+	password, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	username = getEdxUsername(user.ID)
+	if err := c.edxGateway.PostRegistration(user, username, password); err != nil {
+		return "", err
+	}
+	return username, nil
+}
+
+// getEdxUsername returns edX username of the platform user. Nicknames are not unique
+// and may contain symbols forbidden in edX, so the user id is used.
+func getEdxUsername(userId uint) string {
+	return viper.GetString("edx.username_prefix") + strconv.Itoa(int(userId))
+}
+
+// getUserIdByEdxUsername is the reverse of getEdxUsername, ok is false for edX accounts
+// not created by the platform
+func getUserIdByEdxUsername(username string) (userId uint, ok bool) {
+	id, found := strings.CutPrefix(username, viper.GetString("edx.username_prefix"))
+	if !found {
+		return 0, false
+	}
+	atoi, err := strconv.Atoi(id)
+	if err != nil || atoi <= 0 {
+		return 0, false
+	}
+	return uint(atoi), true
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+)
+
+// ExportService passes the exported records to the write function in batches,
+// so large exports are streamed instead of being loaded at once
+type ExportService interface {
+	ExportUsers(isActive bool, roles []models.Role, clientId uint, clientRole models.Role,
+		write func(users []models.UserCore) error) error
+	ExportProjectPages(clientId uint, clientRole models.Role,
+		write func(projectPages []models.ProjectPageCore) error) error
+	ExportContestResults(contestId, clientId uint, clientRole models.Role, write func(teams []models.TeamCore) error) error
+}
+
+type ExportServiceImpl struct {
+	userGateway        gateways.UserGateway
+	projectPageGateway gateways.ProjectPageGateway
+	contestGateway     gateways.ContestGateway
+	teamGateway        gateways.TeamGateway
+	unitService        UnitService
+	contestService     ContestService
+}
+
+// ExportUsers exports users filtered like GetAllUsers, unit admins export only users of their units
+func (e ExportServiceImpl) ExportUsers(isActive bool, roles []models.Role, clientId uint, clientRole models.Role,
+	write func(users []models.UserCore) error) error {
+	if clientRole == models.RoleUnitAdmin {
+		for _, role := range roles {
+			if role == models.RoleSuperAdmin || role == models.RoleUnitAdmin {
+				return utils.ResponseError{
+					Code:    http.StatusForbidden,
+					Message: consts.ErrAccessDenied,
+				}
+			}
+		}
+	}
+	unitIds, err := e.unitService.GetClientUnitIds(clientId, clientRole)
+	if err != nil {
+		return err
+	}
+	return e.userGateway.ExportUsers(isActive, roles, unitIds, viper.GetInt("export.batch_size"), write)
+}
+
+// ExportProjectPages exports project pages, unit admins export only project pages of users of their units
+func (e ExportServiceImpl) ExportProjectPages(clientId uint, clientRole models.Role,
+	write func(projectPages []models.ProjectPageCore) error) error {
+	unitIds, err := e.unitService.GetClientUnitIds(clientId, clientRole)
+	if err != nil {
+		return err
+	}
+	return e.projectPageGateway.ExportProjectPages(unitIds, viper.GetInt("export.batch_size"), write)
+}
+
+// ExportContestResults exports teams of the contest ordered by their places to admins managing the contest
+func (e ExportServiceImpl) ExportContestResults(contestId, clientId uint, clientRole models.Role,
+	write func(teams []models.TeamCore) error) error {
+	contest, err := e.contestGateway.GetContestById(contestId)
+	if err != nil {
+		return err
+	}
+	if err := e.contestService.CheckContestManager(contest, clientId, clientRole); err != nil {
+		return err
+	}
+	batchSize := viper.GetInt("export.batch_size")
+	for offset := 0; ; offset += batchSize {
+		teams, _, err := e.teamGateway.GetTeamsByContest(contestId, offset, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(teams) == 0 {
+			return nil
+		}
+		if err := write(teams); err != nil {
+			return err
+		}
+		if len(teams) < batchSize {
+			return nil
+		}
+	}
+}
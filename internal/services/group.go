@@ -0,0 +1,215 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+)
+
+// GroupService manages groups of units. Admins manage groups and assign teachers,
+// teachers manage students of their groups.
+type GroupService interface {
+	CreateGroup(group models.GroupCore, clientId uint, clientRole models.Role) (models.GroupCore, error)
+	UpdateGroup(group models.GroupCore, clientId uint, clientRole models.Role) (models.GroupCore, error)
+	DeleteGroup(id, clientId uint, clientRole models.Role) error
+	GetGroupById(id, clientId uint, clientRole models.Role) (models.GroupCore, error)
+	GetGroupsByUnit(unitId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		groups []models.GroupCore, countRows uint, err error)
+	GetGroupsByClient(page, pageSize *int, clientId uint, clientRole models.Role) (
+		groups []models.GroupCore, countRows uint, err error)
+	GetGroupTeachers(groupId, clientId uint, clientRole models.Role) (teachers []models.UserCore, countRows uint, err error)
+	GetGroupStudents(groupId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		students []models.UserCore, countRows uint, err error)
+	GetGroupProjectPages(groupId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		projectPages []models.ProjectPageCore, countRows uint, err error)
+	AddTeacher(groupId, teacherId, clientId uint, clientRole models.Role) error
+	DeleteTeacher(groupId, teacherId, clientId uint, clientRole models.Role) error
+	AddStudents(groupId uint, studentIds []uint, clientId uint, clientRole models.Role) error
+	DeleteStudent(groupId, studentId, clientId uint, clientRole models.Role) error
+}
+
+type GroupServiceImpl struct {
+	userGateway        gateways.UserGateway
+	unitGateway        gateways.UnitGateway
+	groupGateway       gateways.GroupGateway
+	projectPageGateway gateways.ProjectPageGateway
+	unitService        UnitService
+}
+
+func (g GroupServiceImpl) CreateGroup(group models.GroupCore, clientId uint, clientRole models.Role) (models.GroupCore, error) {
+	if _, err := g.unitGateway.GetUnitById(group.UnitID); err != nil {
+		return models.GroupCore{}, err
+	}
+	if err := g.unitService.CheckUnitAccess(group.UnitID, clientId, clientRole); err != nil {
+		return models.GroupCore{}, err
+	}
+	return g.groupGateway.CreateGroup(group)
+}
+
+func (g GroupServiceImpl) UpdateGroup(group models.GroupCore, clientId uint, clientRole models.Role) (models.GroupCore, error) {
+	getGroup, err := g.getManagedGroup(group.ID, clientId, clientRole)
+	if err != nil {
+		return models.GroupCore{}, err
+	}
+	group.UnitID = getGroup.UnitID
+	return g.groupGateway.UpdateGroup(group)
+}
+
+func (g GroupServiceImpl) DeleteGroup(id, clientId uint, clientRole models.Role) error {
+	if _, err := g.getManagedGroup(id, clientId, clientRole); err != nil {
+		return err
+	}
+	return g.groupGateway.DeleteGroup(id)
+}
+
+func (g GroupServiceImpl) GetGroupById(id, clientId uint, clientRole models.Role) (models.GroupCore, error) {
+	return g.getTaughtGroup(id, clientId, clientRole)
+}
+
+func (g GroupServiceImpl) GetGroupsByUnit(unitId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+	groups []models.GroupCore, countRows uint, err error) {
+	if err := g.unitService.CheckUnitAccess(unitId, clientId, clientRole); err != nil {
+		return []models.GroupCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return g.groupGateway.GetGroupsByUnit(unitId, offset, limit)
+}
+
+// GetGroupsByClient returns groups taught by the teacher or groups of the student
+func (g GroupServiceImpl) GetGroupsByClient(page, pageSize *int, clientId uint, clientRole models.Role) (
+	groups []models.GroupCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	if clientRole == models.RoleStudent {
+		return g.groupGateway.GetGroupsByStudent(clientId, offset, limit)
+	}
+	return g.groupGateway.GetGroupsByTeacher(clientId, offset, limit)
+}
+
+func (g GroupServiceImpl) GetGroupTeachers(groupId, clientId uint, clientRole models.Role) (
+	teachers []models.UserCore, countRows uint, err error) {
+	if _, err := g.getTaughtGroup(groupId, clientId, clientRole); err != nil {
+		return []models.UserCore{}, 0, err
+	}
+	teachers, err = g.groupGateway.GetTeachers(groupId)
+	if err != nil {
+		return []models.UserCore{}, 0, err
+	}
+	return teachers, uint(len(teachers)), nil
+}
+
+func (g GroupServiceImpl) GetGroupStudents(groupId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+	students []models.UserCore, countRows uint, err error) {
+	if _, err := g.getTaughtGroup(groupId, clientId, clientRole); err != nil {
+		return []models.UserCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return g.groupGateway.GetStudents(groupId, offset, limit)
+}
+
+func (g GroupServiceImpl) GetGroupProjectPages(groupId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+	projectPages []models.ProjectPageCore, countRows uint, err error) {
+	if _, err := g.getTaughtGroup(groupId, clientId, clientRole); err != nil {
+		return []models.ProjectPageCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return g.projectPageGateway.GetProjectPagesByGroupId(groupId, offset, limit)
+}
+
+func (g GroupServiceImpl) AddTeacher(groupId, teacherId, clientId uint, clientRole models.Role) error {
+	group, err := g.getManagedGroup(groupId, clientId, clientRole)
+	if err != nil {
+		return err
+	}
+	if err := g.checkMember(group, teacherId, models.RoleTeacher); err != nil {
+		return err
+	}
+	return g.groupGateway.AddTeacher(groupId, teacherId)
+}
+
+func (g GroupServiceImpl) DeleteTeacher(groupId, teacherId, clientId uint, clientRole models.Role) error {
+	if _, err := g.getManagedGroup(groupId, clientId, clientRole); err != nil {
+		return err
+	}
+	return g.groupGateway.DeleteTeacher(groupId, teacherId)
+}
+
+func (g GroupServiceImpl) AddStudents(groupId uint, studentIds []uint, clientId uint, clientRole models.Role) error {
+	group, err := g.getTaughtGroup(groupId, clientId, clientRole)
+	if err != nil {
+		return err
+	}
+	for _, studentId := range studentIds {
+		if err := g.checkMember(group, studentId, models.RoleStudent); err != nil {
+			return err
+		}
+	}
+	return g.groupGateway.AddStudents(groupId, studentIds)
+}
+
+func (g GroupServiceImpl) DeleteStudent(groupId, studentId, clientId uint, clientRole models.Role) error {
+	if _, err := g.getTaughtGroup(groupId, clientId, clientRole); err != nil {
+		return err
+	}
+	return g.groupGateway.DeleteStudent(groupId, studentId)
+}
+
+// getManagedGroup returns the group if the client is an admin of the unit of the group
+func (g GroupServiceImpl) getManagedGroup(id, clientId uint, clientRole models.Role) (models.GroupCore, error) {
+	group, err := g.groupGateway.GetGroupById(id)
+	if err != nil {
+		return models.GroupCore{}, err
+	}
+	if !isAdmin(clientRole) {
+		return models.GroupCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	if err := g.unitService.CheckUnitAccess(group.UnitID, clientId, clientRole); err != nil {
+		return models.GroupCore{}, err
+	}
+	return group, nil
+}
+
+// getTaughtGroup returns the group if the client is an admin of the unit of the group or a teacher of the group
+func (g GroupServiceImpl) getTaughtGroup(id, clientId uint, clientRole models.Role) (models.GroupCore, error) {
+	if clientRole != models.RoleTeacher {
+		return g.getManagedGroup(id, clientId, clientRole)
+	}
+	group, err := g.groupGateway.GetGroupById(id)
+	if err != nil {
+		return models.GroupCore{}, err
+	}
+	isTeacher, err := g.groupGateway.IsTeacher(id, clientId)
+	if err != nil {
+		return models.GroupCore{}, err
+	}
+	if !isTeacher {
+		return models.GroupCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return group, nil
+}
+
+// checkMember requires the user to have the role and to be a member of the unit of the group
+func (g GroupServiceImpl) checkMember(group models.GroupCore, userId uint, role models.Role) error {
+	user, err := g.userGateway.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	isMember, err := g.unitGateway.IsMember(group.UnitID, userId)
+	if err != nil {
+		return err
+	}
+	if user.Role != role || !isMember {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectGroupMember,
+		}
+	}
+	return nil
+}
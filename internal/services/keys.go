@@ -0,0 +1,320 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/spf13/viper"
+	"math/big"
+	"os"
+	"time"
+)
+
+type KeyPurpose string
+
+const (
+	KeyPurposeAccess  KeyPurpose = "access"
+	KeyPurposeRefresh KeyPurpose = "refresh"
+	// KeyPurposeChallenge signs two factor challenges, its keys are never published,
+	// so services verifying access tokens by the JWKS do not accept challenges
+	KeyPurposeChallenge KeyPurpose = "challenge"
+	// KeyPurposeLti signs messages of the LTI tool, the key is optional
+	KeyPurposeLti KeyPurpose = "lti"
+)
+
+// legacyKid is used for tokens issued before key ids were introduced
+// and for the key ring built from the old single signing key settings.
+const legacyKid = "default"
+
+type KeyService interface {
+	Sign(purpose KeyPurpose, claims jwt.Claims) (string, error)
+	Keyfunc(purpose KeyPurpose) jwt.Keyfunc
+	JWKS(purpose KeyPurpose) models.JWKS
+}
+
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	private   interface{}
+	public    interface{}
+	retiredAt time.Time
+}
+
+type keyRing struct {
+	active string
+	keys   map[string]signingKey
+}
+
+type KeyServiceImpl struct {
+	rings       map[KeyPurpose]keyRing
+	gracePeriod time.Duration
+}
+
+type keyConfig struct {
+	Kid            string `mapstructure:"kid"`
+	Alg            string `mapstructure:"alg"`
+	Secret         string `mapstructure:"secret"`
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	PublicKeyPath  string `mapstructure:"public_key_path"`
+	RetiredAt      string `mapstructure:"retired_at"`
+}
+
+func NewKeyService() (KeyService, error) {
+	k := KeyServiceImpl{
+		rings:       map[KeyPurpose]keyRing{},
+		gracePeriod: viper.GetDuration("auth.keys.grace_period"),
+	}
+	legacySecrets := map[KeyPurpose]string{
+		KeyPurposeAccess:    "auth_access_signing_key",
+		KeyPurposeRefresh:   "auth_refresh_signing_key",
+		KeyPurposeChallenge: "auth_challenge_signing_key",
+	}
+	for purpose, legacySecret := range legacySecrets {
+		ring, err := loadKeyRing(purpose, legacySecret)
+		if err != nil {
+			return nil, err
+		}
+		k.rings[purpose] = ring
+	}
+	// LTI platforms accept only RS256 signatures of the tool
+	if viper.IsSet("auth.keys." + string(KeyPurposeLti) + ".keys") {
+		ring, err := loadKeyRing(KeyPurposeLti, "")
+		if err != nil {
+			return nil, err
+		}
+		if ring.keys[ring.active].method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("%s key %q: RS256 is required", KeyPurposeLti, ring.active)
+		}
+		k.rings[KeyPurposeLti] = ring
+	}
+	return k, nil
+}
+
+func (k KeyServiceImpl) Sign(purpose KeyPurpose, claims jwt.Claims) (string, error) {
+	ring, ok := k.rings[purpose]
+	if !ok {
+		return "", errors.New(consts.ErrUnknownSigningKey)
+	}
+	key := ring.keys[ring.active]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// Keyfunc returns the key matching the token kid. Keys removed from signing
+// keep validating tokens until their retirement time plus the grace period.
+func (k KeyServiceImpl) Keyfunc(purpose KeyPurpose) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		ring, ok := k.rings[purpose]
+		if !ok {
+			return nil, errors.New(consts.ErrUnknownSigningKey)
+		}
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = legacyKid
+		}
+		key, ok := ring.keys[kid]
+		if !ok || !k.isValid(key) {
+			return nil, errors.New(consts.ErrUnknownSigningKey)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+		}
+		return key.public, nil
+	}
+}
+
+// JWKS returns public parts of the asymmetric keys of the purpose, so other services
+// can verify access tokens and LTI platforms can verify tool messages by themselves.
+func (k KeyServiceImpl) JWKS(purpose KeyPurpose) models.JWKS {
+	jwks := models.JWKS{Keys: []models.JWK{}}
+	for _, key := range k.rings[purpose].keys {
+		if !k.isValid(key) {
+			continue
+		}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, models.JWK{
+				Kty: "RSA",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, models.JWK{
+				Kty: "OKP",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return jwks
+}
+
+func (k KeyServiceImpl) isValid(key signingKey) bool {
+	return key.retiredAt.IsZero() || time.Now().Before(key.retiredAt.Add(k.gracePeriod))
+}
+
+func loadKeyRing(purpose KeyPurpose, legacySecret string) (keyRing, error) {
+	var configs []keyConfig
+	if err := viper.UnmarshalKey("auth.keys."+string(purpose)+".keys", &configs); err != nil {
+		return keyRing{}, err
+	}
+	// without configured keys fall back to the single hmac secret
+	if len(configs) == 0 {
+		configs = append(configs, keyConfig{Kid: legacyKid, Alg: jwt.SigningMethodHS256.Alg(), Secret: legacySecret})
+	}
+	ring := keyRing{
+		active: viper.GetString("auth.keys." + string(purpose) + ".active"),
+		keys:   map[string]signingKey{},
+	}
+	if ring.active == "" {
+		ring.active = configs[0].Kid
+	}
+	for _, config := range configs {
+		key, err := loadSigningKey(config)
+		if err != nil {
+			return keyRing{}, fmt.Errorf("%s key %q: %w", purpose, config.Kid, err)
+		}
+		ring.keys[key.kid] = key
+	}
+	active, ok := ring.keys[ring.active]
+	if !ok || active.private == nil || !active.retiredAt.IsZero() {
+		return keyRing{}, fmt.Errorf("%s key %q: %s", purpose, ring.active, consts.ErrUnknownSigningKey)
+	}
+	return ring, nil
+}
+
+func loadSigningKey(config keyConfig) (key signingKey, err error) {
+	key.kid = config.Kid
+	if config.RetiredAt != "" {
+		if key.retiredAt, err = time.Parse(time.RFC3339, config.RetiredAt); err != nil {
+			return signingKey{}, err
+		}
+	}
+	switch config.Alg {
+	case jwt.SigningMethodHS256.Alg():
+		secret := []byte(viper.GetString(config.Secret))
+		if len(secret) == 0 {
+			return signingKey{}, errors.New("empty hmac secret")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.private, key.public = secret, secret
+	case jwt.SigningMethodRS256.Alg():
+		key.method = jwt.SigningMethodRS256
+		if config.PrivateKeyPath != "" {
+			data, err := os.ReadFile(config.PrivateKeyPath)
+			if err != nil {
+				return signingKey{}, err
+			}
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return signingKey{}, err
+			}
+			key.private, key.public = private, &private.PublicKey
+		} else {
+			data, err := os.ReadFile(config.PublicKeyPath)
+			if err != nil {
+				return signingKey{}, err
+			}
+			if key.public, err = jwt.ParseRSAPublicKeyFromPEM(data); err != nil {
+				return signingKey{}, err
+			}
+		}
+	case SigningMethodEdDSA.Alg():
+		key.method = SigningMethodEdDSA
+		if config.PrivateKeyPath != "" {
+			parsed, err := parsePemKey(config.PrivateKeyPath, x509.ParsePKCS8PrivateKey)
+			if err != nil {
+				return signingKey{}, err
+			}
+			private, ok := parsed.(ed25519.PrivateKey)
+			if !ok {
+				return signingKey{}, errors.New("not an ed25519 private key")
+			}
+			key.private, key.public = private, private.Public()
+		} else {
+			parsed, err := parsePemKey(config.PublicKeyPath, x509.ParsePKIXPublicKey)
+			if err != nil {
+				return signingKey{}, err
+			}
+			if _, ok := parsed.(ed25519.PublicKey); !ok {
+				return signingKey{}, errors.New("not an ed25519 public key")
+			}
+			key.public = parsed
+		}
+	default:
+		return signingKey{}, fmt.Errorf("unsupported signing algorithm %q", config.Alg)
+	}
+	return key, nil
+}
+
+func parsePemKey(path string, parse func([]byte) (interface{}, error)) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid pem file")
+	}
+	return parse(block.Bytes)
+}
+
+// SigningMethodEdDSA implements the EdDSA (Ed25519) signing method,
+// which is not provided by jwt-go. Expects ed25519.PrivateKey for signing
+// and ed25519.PublicKey for validation.
+var SigningMethodEdDSA = &signingMethodEd25519{}
+
+type signingMethodEd25519 struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEd25519) Alg() string {
+	return "EdDSA"
+}
+
+func (m *signingMethodEd25519) Verify(signingString, signature string, key interface{}) error {
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.NewInvalidKeyTypeError("ed25519.PublicKey", key)
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(public, []byte(signingString), sig) {
+		return errors.New("ed25519 verification error")
+	}
+	return nil
+}
+
+func (m *signingMethodEd25519) Sign(signingString string, key interface{}) (string, error) {
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.NewInvalidKeyTypeError("ed25519.PrivateKey", key)
+	}
+	sig, err := private.Sign(rand.Reader, []byte(signingString), crypto.Hash(0))
+	if err != nil {
+		return "", err
+	}
+	return jwt.EncodeSegment(sig), nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	lockoutReasonAccount = "account"
+	lockoutReasonIp      = "ip"
+)
+
+// LoginGuardService protects sign in from password guessing. Failed attempts are counted
+// per account and per ip address: after a few failures every next attempt is delayed
+// exponentially, after the threshold the account or the ip address is locked for a while.
+type LoginGuardService interface {
+	Check(userId uint, ip string) error
+	RegisterFailure(userId uint, email, ip string) error
+	RegisterSuccess(userId uint) error
+	UnlockAccount(unlockToken string) error
+	GetLockoutEvents(page, pageSize *int) (events []models.LockoutEventCore, countRows uint, err error)
+}
+
+type LoginGuardServiceImpl struct {
+	loggers             logger.Loggers
+	loginAttemptGateway gateways.LoginAttemptGateway
+}
+
+// Check returns an error if the account (userId != 0) or the ip address may not try to sign in now
+func (l LoginGuardServiceImpl) Check(userId uint, ip string) error {
+	for _, key := range getAttemptKeys(userId, ip) {
+		attempt, err := l.loginAttemptGateway.GetAttempt(key)
+		if err != nil {
+			return err
+		}
+		if attempt.LockedUntil != nil && time.Now().Before(*attempt.LockedUntil) {
+			return utils.ResponseError{
+				Code:    http.StatusTooManyRequests,
+				Message: consts.ErrAccountLocked,
+			}
+		}
+		if time.Now().Before(attempt.LastFailureAt.Add(getBackoff(attempt.Failures))) {
+			return utils.ResponseError{
+				Code:    http.StatusTooManyRequests,
+				Message: consts.ErrTooManyAttempts,
+			}
+		}
+	}
+	return nil
+}
+
+func (l LoginGuardServiceImpl) RegisterFailure(userId uint, email, ip string) error {
+	window := viper.GetDuration("auth.lockout.window")
+	lockedUntil := time.Now().Add(viper.GetDuration("auth.lockout.duration"))
+	if ip != "" {
+		attempt, err := l.loginAttemptGateway.RegisterFailure(getIpAttemptKey(ip), window)
+		if err != nil {
+			return err
+		}
+		if attempt.Failures >= viper.GetInt("auth.lockout.ip_threshold") {
+			if err := l.lock(attempt.Key, "", models.LockoutEventCore{
+				UserID:      userId,
+				Email:       email,
+				Ip:          ip,
+				Reason:      lockoutReasonIp,
+				LockedUntil: lockedUntil,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	if userId == 0 {
+		return nil
+	}
+	attempt, err := l.loginAttemptGateway.RegisterFailure(getUserAttemptKey(userId), window)
+	if err != nil {
+		return err
+	}
+	if attempt.Failures < viper.GetInt("auth.lockout.account_threshold") {
+		return nil
+	}
+	unlockToken, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	if err := l.lock(attempt.Key, utils.GetSha256String(unlockToken), models.LockoutEventCore{
+		UserID:      userId,
+		Email:       email,
+		Ip:          ip,
+		Reason:      lockoutReasonAccount,
+		LockedUntil: lockedUntil,
+	}); err != nil {
+		return err
+	}
+	// imported students may have no email, they wait for the end of the lockout
+	if email == "" {
+		return nil
+	}
+	subject := "Ваш аккаунт временно заблокирован"
+	body := "<p>Зафиксировано несколько неудачных попыток входа в ваш аккаунт, вход заблокирован до " +
+		lockedUntil.Format(time.DateTime) + ". Перейдите по ссылке " + viper.GetString("auth.lockout.unlock_path") +
+		unlockToken + " чтобы разблокировать аккаунт.</p>"
+	// the lockout is already saved, a failed email must not turn the failed sign in into a server error
+	if err := utils.SendEmail(subject, email, body); err != nil {
+		l.loggers.Err.Printf("lockout email of user %d: %s", userId, err.Error())
+	}
+	return nil
+}
+
+func (l LoginGuardServiceImpl) RegisterSuccess(userId uint) error {
+	return l.loginAttemptGateway.ResetAttempts(getUserAttemptKey(userId))
+}
+
+func (l LoginGuardServiceImpl) UnlockAccount(unlockToken string) error {
+	attempt, err := l.loginAttemptGateway.GetAttemptByUnlockToken(utils.GetSha256String(unlockToken))
+	if err != nil {
+		return err
+	}
+	return l.loginAttemptGateway.ResetAttempts(attempt.Key)
+}
+
+func (l LoginGuardServiceImpl) GetLockoutEvents(page, pageSize *int) (events []models.LockoutEventCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return l.loginAttemptGateway.GetLockoutEvents(offset, limit)
+}
+
+func (l LoginGuardServiceImpl) lock(key, unlockTokenHash string, event models.LockoutEventCore) error {
+	if err := l.loginAttemptGateway.Lock(key, event.LockedUntil, unlockTokenHash); err != nil {
+		return err
+	}
+	l.loggers.Err.Printf("sign in locked by %s: user id %d, email %s, ip %s, until %s",
+		event.Reason, event.UserID, event.Email, event.Ip, event.LockedUntil.Format(time.DateTime))
+	return l.loginAttemptGateway.CreateLockoutEvent(event)
+}
+
+// getBackoff returns the delay required after the last failed attempt
+func getBackoff(failures int) time.Duration {
+	exponent := failures - viper.GetInt("auth.lockout.backoff_after")
+	if exponent < 0 {
+		return 0
+	}
+	maxBackoff := viper.GetDuration("auth.lockout.backoff_max")
+	if exponent > 30 {
+		return maxBackoff
+	}
+	backoff := viper.GetDuration("auth.lockout.backoff_base") << exponent
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+func getAttemptKeys(userId uint, ip string) (keys []string) {
+	if userId != 0 {
+		keys = append(keys, getUserAttemptKey(userId))
+	}
+	if ip != "" {
+		keys = append(keys, getIpAttemptKey(ip))
+	}
+	return keys
+}
+
+func getUserAttemptKey(userId uint) string {
+	return "user:" + strconv.Itoa(int(userId))
+}
+
+func getIpAttemptKey(ip string) string {
+	return "ip:" + ip
+}
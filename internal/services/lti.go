@@ -0,0 +1,516 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/lti"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ltiProviderPrefix marks LTI platforms among providers of oidc states and user identities
+const ltiProviderPrefix = "lti:"
+
+// ltiCustomProjectPage is the custom parameter of resource links created by deep linking
+const ltiCustomProjectPage = "project_page_id"
+
+// LtiService is the LTI 1.3 tool. The platform posts the launch to the backend,
+// the frontend receives a one-time code and exchanges it for tokens.
+type LtiService interface {
+	Login(issuer, clientId, loginHint, ltiMessageHint string) (redirectUrl, browserBinding string, err error)
+	Launch(ctx context.Context, idToken, state, browserBinding string) (redirectUrl string, err error)
+	SignIn(code string) (models.LtiLaunchCore, SignInResult, error)
+	CreateDeepLinkingResponse(launchId uint, projectPageIds []uint, clientId uint, clientRole models.Role) (
+		returnUrl, message string, err error)
+	PushScore(ctx context.Context, gradeLinkId, userId uint, scoreGiven, scoreMaximum float64, comment string,
+		clientId uint, clientRole models.Role) error
+	PushAssignmentGrade(ctx context.Context, submission models.AssignmentSubmissionCore) error
+}
+
+type LtiServiceImpl struct {
+	platforms          map[string]ltiPlatform
+	userGateway        gateways.UserGateway
+	settingsGateway    gateways.SettingsGateway
+	oidcGateway        gateways.OidcGateway
+	ltiGateway         gateways.LtiGateway
+	groupGateway       gateways.GroupGateway
+	keyService         KeyService
+	passwordService    PasswordService
+	projectPageService ProjectPageService
+}
+
+type ltiPlatform struct {
+	client      *lti.Platform
+	linkByEmail bool
+}
+
+type ltiPlatformConfig struct {
+	Name          string   `mapstructure:"name"`
+	Issuer        string   `mapstructure:"issuer"`
+	ClientId      string   `mapstructure:"client_id"`
+	DeploymentIds []string `mapstructure:"deployment_ids"`
+	AuthLoginUrl  string   `mapstructure:"auth_login_url"`
+	AuthTokenUrl  string   `mapstructure:"auth_token_url"`
+	KeysetUrl     string   `mapstructure:"keyset_url"`
+	LinkByEmail   bool     `mapstructure:"link_by_email"`
+}
+
+func NewLtiService(
+	userGateway gateways.UserGateway,
+	settingsGateway gateways.SettingsGateway,
+	oidcGateway gateways.OidcGateway,
+	ltiGateway gateways.LtiGateway,
+	groupGateway gateways.GroupGateway,
+	keyService KeyService,
+	passwordService PasswordService,
+	projectPageService ProjectPageService,
+) (LtiService, error) {
+	var configs []ltiPlatformConfig
+	if err := viper.UnmarshalKey("lti.platforms", &configs); err != nil {
+		return nil, err
+	}
+	platforms := map[string]ltiPlatform{}
+	for _, config := range configs {
+		if config.Name == "" || config.Issuer == "" || config.ClientId == "" || config.AuthLoginUrl == "" ||
+			config.AuthTokenUrl == "" || config.KeysetUrl == "" {
+			return nil, fmt.Errorf("lti platform %q: name, issuer, client_id, auth_login_url, auth_token_url "+
+				"and keyset_url are required", config.Name)
+		}
+		platforms[config.Name] = ltiPlatform{
+			client: lti.NewPlatform(lti.Config{
+				Issuer:        config.Issuer,
+				ClientID:      config.ClientId,
+				DeploymentIDs: config.DeploymentIds,
+				AuthLoginURL:  config.AuthLoginUrl,
+				AuthTokenURL:  config.AuthTokenUrl,
+				KeysetURL:     config.KeysetUrl,
+			}, nil),
+			linkByEmail: config.LinkByEmail,
+		}
+	}
+	return LtiServiceImpl{
+		platforms:          platforms,
+		userGateway:        userGateway,
+		settingsGateway:    settingsGateway,
+		oidcGateway:        oidcGateway,
+		ltiGateway:         ltiGateway,
+		groupGateway:       groupGateway,
+		keyService:         keyService,
+		passwordService:    passwordService,
+		projectPageService: projectPageService,
+	}, nil
+}
+
+// Login answers the third party login initiation of the platform
+func (l LtiServiceImpl) Login(issuer, clientId, loginHint, ltiMessageHint string) (
+	redirectUrl, browserBinding string, err error) {
+	name, p, ok := l.findPlatform(issuer, clientId)
+	if !ok || loginHint == "" {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrUnknownLtiPlatform,
+		}
+	}
+	values := make([]string, 3)
+	for i := range values {
+		value, err := utils.GenerateRandomString(43)
+		if err != nil {
+			return "", "", utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		values[i] = value
+	}
+	state := models.OidcStateCore{
+		State:          values[0],
+		Provider:       ltiProviderPrefix + name,
+		Nonce:          values[1],
+		BrowserBinding: utils.GetSha256String(values[2]),
+		ExpiresAt:      time.Now().Add(viper.GetDuration("oidc.state_ttl")),
+	}
+	if err := l.oidcGateway.CreateState(state); err != nil {
+		return "", "", err
+	}
+	redirectUrl = p.client.LoginURL(loginHint, ltiMessageHint, viper.GetString("lti.launch_url"), state.State, state.Nonce)
+	return redirectUrl, values[2], nil
+}
+
+// Launch validates the launch and returns the frontend url with the one-time code
+func (l LtiServiceImpl) Launch(ctx context.Context, idToken, state, browserBinding string) (redirectUrl string, err error) {
+	stateCore, err := l.oidcGateway.TakeState(state)
+	if err != nil {
+		return "", err
+	}
+	// a login initiated in another browser must not sign this browser in
+	if stateCore.BrowserBinding == "" || browserBinding == "" ||
+		subtle.ConstantTimeCompare([]byte(stateCore.BrowserBinding), []byte(utils.GetSha256String(browserBinding))) != 1 {
+		return "", utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectOidcState,
+		}
+	}
+	name, isLti := strings.CutPrefix(stateCore.Provider, ltiProviderPrefix)
+	p, ok := l.platforms[name]
+	if !isLti || !ok {
+		return "", utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrUnknownLtiPlatform,
+		}
+	}
+	claims, err := p.client.VerifyLaunch(ctx, idToken, stateCore.Nonce)
+	if err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusUnauthorized,
+			Message: err.Error(),
+		}
+	}
+	user, err := l.getOrCreateUser(name, p, claims)
+	if err != nil {
+		return "", err
+	}
+	code, err := utils.GenerateRandomString(43)
+	if err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	launch := models.LtiLaunchCore{
+		Code:         utils.GetSha256String(code),
+		UserID:       user.ID,
+		Platform:     name,
+		DeploymentID: claims.DeploymentID,
+		ExpiresAt:    time.Now().Add(viper.GetDuration("lti.launch_ttl")),
+	}
+	switch claims.MessageType {
+	case lti.MessageTypeDeepLinking:
+		if !claims.HasRole(lti.RoleInstructor, lti.RoleAdministrator) {
+			return "", utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+		launch.MessageType = models.LtiMessageTypeDeepLinking
+		launch.DeepLinkReturnUrl = claims.DeepLinkingSettings.DeepLinkReturnURL
+		launch.DeepLinkData = claims.DeepLinkingSettings.Data
+	case lti.MessageTypeResourceLink:
+		launch.MessageType = models.LtiMessageTypeResourceLink
+		if projectPageId, err := strconv.Atoi(claims.Custom[ltiCustomProjectPage]); err == nil && projectPageId > 0 {
+			id := uint(projectPageId)
+			launch.ProjectPageID = &id
+		}
+		if claims.CanPostScore() {
+			gradeLink := models.LtiGradeLinkCore{
+				Platform:       name,
+				ResourceLinkID: claims.ResourceLink.ID,
+				Title:          claims.ResourceLink.Title,
+				LineItemUrl:    claims.Endpoint.LineItem,
+				ProjectPageID:  launch.ProjectPageID,
+			}
+			if claims.HasRole(lti.RoleInstructor, lti.RoleAdministrator) {
+				gradeLink.OwnerID = &user.ID
+			}
+			gradeLink, err := l.ltiGateway.UpsertGradeLink(gradeLink)
+			if err != nil {
+				return "", err
+			}
+			launch.GradeLinkID = &gradeLink.ID
+		}
+	}
+	if _, err := l.ltiGateway.CreateLaunch(launch); err != nil {
+		return "", err
+	}
+	return viper.GetString("lti.frontend_launch_url") + "?code=" + url.QueryEscape(code), nil
+}
+
+func (l LtiServiceImpl) SignIn(code string) (models.LtiLaunchCore, SignInResult, error) {
+	launch, err := l.ltiGateway.TakeLaunch(utils.GetSha256String(code))
+	if err != nil {
+		return models.LtiLaunchCore{}, SignInResult{}, err
+	}
+	user, err := l.userGateway.GetUserById(launch.UserID)
+	if err != nil {
+		return models.LtiLaunchCore{}, SignInResult{}, err
+	}
+	if !user.IsActive {
+		return models.LtiLaunchCore{}, SignInResult{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrUserIsNotActive,
+		}
+	}
+	result, err := completeSignIn(l.keyService, l.settingsGateway, user)
+	if err != nil {
+		return models.LtiLaunchCore{}, SignInResult{}, err
+	}
+	return launch, result, nil
+}
+
+// CreateDeepLinkingResponse returns the signed message with project pages as resource links,
+// the frontend posts it to the return url of the platform
+func (l LtiServiceImpl) CreateDeepLinkingResponse(launchId uint, projectPageIds []uint, clientId uint,
+	clientRole models.Role) (returnUrl, message string, err error) {
+	launch, err := l.ltiGateway.GetLaunchById(launchId)
+	if err != nil {
+		return "", "", err
+	}
+	if launch.UserID != clientId || launch.MessageType != models.LtiMessageTypeDeepLinking {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	p, ok := l.platforms[launch.Platform]
+	if !ok {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrUnknownLtiPlatform,
+		}
+	}
+	items := make([]lti.ContentItem, 0, len(projectPageIds))
+	for _, projectPageId := range projectPageIds {
+		projectPage, err := l.projectPageService.GetProjectPageById(projectPageId, clientId, clientRole)
+		if err != nil {
+			return "", "", err
+		}
+		// moderators see banned pages, they must not be published to the platform
+		if projectPage.IsBanned {
+			return "", "", utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrProjectPageIsBanned,
+			}
+		}
+		id := strconv.Itoa(int(projectPage.ID))
+		items = append(items, lti.ContentItem{
+			Type:   lti.ContentItemTypeLtiResourceLink,
+			Title:  projectPage.Title,
+			URL:    viper.GetString("lti.launch_url"),
+			Custom: map[string]string{ltiCustomProjectPage: id},
+			LineItem: &lti.LineItem{
+				ScoreMaximum: 100,
+				Label:        projectPage.Title,
+				ResourceID:   id,
+			},
+		})
+	}
+	claims, err := p.client.NewDeepLinkingResponse(launch.DeploymentID, launch.DeepLinkData, items)
+	if err != nil {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	message, err = l.keyService.Sign(KeyPurposeLti, claims)
+	if err != nil {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return launch.DeepLinkReturnUrl, message, nil
+}
+
+// PushScore sends the score of the user to the gradebook column of the resource link. It is allowed
+// to the owner of the resource link and to teachers of groups of the user.
+func (l LtiServiceImpl) PushScore(ctx context.Context, gradeLinkId, userId uint, scoreGiven, scoreMaximum float64,
+	comment string, clientId uint, clientRole models.Role) error {
+	gradeLink, err := l.ltiGateway.GetGradeLinkById(gradeLinkId)
+	if err != nil {
+		return err
+	}
+	if clientRole != models.RoleSuperAdmin && (gradeLink.OwnerID == nil || *gradeLink.OwnerID != clientId) {
+		doesTeach, err := l.groupGateway.DoesTeachUser(clientId, userId)
+		if err != nil {
+			return err
+		}
+		if !doesTeach {
+			return utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+	}
+	return l.postScore(ctx, gradeLink, userId, scoreGiven, scoreMaximum, comment)
+}
+
+// PushAssignmentGrade sends the grade of the reviewed submission to every resource link of the template
+// project page of the assignment, links the student has never opened are skipped
+func (l LtiServiceImpl) PushAssignmentGrade(ctx context.Context, submission models.AssignmentSubmissionCore) error {
+	if submission.Grade == nil {
+		return nil
+	}
+	gradeLinks, err := l.ltiGateway.GetGradeLinksByProjectPage(submission.Assignment.TemplateProjectPageID)
+	if err != nil {
+		return err
+	}
+	for _, gradeLink := range gradeLinks {
+		err := l.postScore(ctx, gradeLink, submission.StudentID, float64(*submission.Grade),
+			float64(submission.Assignment.MaxGrade), submission.Feedback)
+		var responseError utils.ResponseError
+		if errors.As(err, &responseError) && responseError.Message == consts.ErrLtiUserNotLinked {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l LtiServiceImpl) postScore(ctx context.Context, gradeLink models.LtiGradeLinkCore, userId uint,
+	scoreGiven, scoreMaximum float64, comment string) error {
+	if scoreMaximum <= 0 || scoreGiven < 0 || scoreGiven > scoreMaximum {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectLtiScore,
+		}
+	}
+	p, ok := l.platforms[gradeLink.Platform]
+	if !ok {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrLtiScoreUnavailable,
+		}
+	}
+	identity, exist, err := l.oidcGateway.GetIdentityByUser(ltiProviderPrefix+gradeLink.Platform, userId)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrLtiUserNotLinked,
+		}
+	}
+	if err := p.client.PostScore(ctx, l.sign, gradeLink.LineItemUrl, lti.Score{
+		UserID:           identity.Subject,
+		ScoreGiven:       scoreGiven,
+		ScoreMaximum:     scoreMaximum,
+		Comment:          comment,
+		ActivityProgress: lti.ActivityProgressCompleted,
+		GradingProgress:  lti.GradingProgressFullyGraded,
+	}); err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusBadGateway,
+			Message: fmt.Sprintf("%s: %s", consts.ErrLtiUnavailable, err.Error()),
+		}
+	}
+	return nil
+}
+
+func (l LtiServiceImpl) sign(claims jwt.Claims) (string, error) {
+	return l.keyService.Sign(KeyPurposeLti, claims)
+}
+
+// findPlatform finds the platform by issuer, the client id is optional in the login initiation
+func (l LtiServiceImpl) findPlatform(issuer, clientId string) (string, ltiPlatform, bool) {
+	for name, p := range l.platforms {
+		if p.client.Issuer() == issuer && (clientId == "" || p.client.ClientID() == clientId) {
+			return name, p, true
+		}
+	}
+	return "", ltiPlatform{}, false
+}
+
+// getOrCreateUser links the LMS user by subject. New LMS users are created active, the platform
+// is registered by admins and trusted, instructors become teachers and others students.
+func (l LtiServiceImpl) getOrCreateUser(name string, p ltiPlatform, claims *lti.LaunchClaims) (models.UserCore, error) {
+	provider := ltiProviderPrefix + name
+	identity, exist, err := l.oidcGateway.GetIdentity(provider, claims.Subject)
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	if exist {
+		// the linked user was deleted
+		if identity.User.ID == 0 {
+			return models.UserCore{}, utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+		return identity.User, nil
+	}
+	if claims.Email == "" {
+		return models.UserCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrLtiEmailRequired,
+		}
+	}
+	exist, err = l.userGateway.DoesExistEmail(0, claims.Email)
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	var user models.UserCore
+	if exist {
+		if !p.linkByEmail {
+			return models.UserCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrEmailAlreadyInUse,
+			}
+		}
+		if user, err = l.userGateway.GetUserByEmail(claims.Email); err != nil {
+			return models.UserCore{}, err
+		}
+		if user.Role == models.RoleSuperAdmin || user.Role == models.RoleUnitAdmin {
+			return models.UserCore{}, utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrOidcAdminLinkDenied,
+			}
+		}
+	} else {
+		if user, err = l.createUser(claims); err != nil {
+			return models.UserCore{}, err
+		}
+	}
+	if err := l.oidcGateway.CreateIdentity(models.UserIdentityCore{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+	}); err != nil {
+		return models.UserCore{}, err
+	}
+	return user, nil
+}
+
+func (l LtiServiceImpl) createUser(claims *lti.LaunchClaims) (models.UserCore, error) {
+	password, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return models.UserCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	passwordHash, err := l.passwordService.HashPassword(password)
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	role := models.RoleStudent
+	if claims.HasRole(lti.RoleInstructor) {
+		role = models.RoleTeacher
+	}
+	nickname, err := getUniqueNickname(l.userGateway, strings.Split(claims.Email, "@")[0])
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	return l.userGateway.CreateUser(models.UserCore{
+		Email:      claims.Email,
+		Password:   passwordHash,
+		Role:       role,
+		Firstname:  claims.GivenName,
+		Lastname:   claims.FamilyName,
+		Middlename: claims.MiddleName,
+		Nickname:   nickname,
+		IsActive:   true,
+	})
+}
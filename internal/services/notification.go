@@ -0,0 +1,159 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/pubsub"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"html"
+	"strconv"
+)
+
+// emailedNotificationKinds were sent by email before the notification center, so email stays on by default
+var emailedNotificationKinds = map[models.NotificationKind]bool{
+	models.NotificationKindSubmissionReviewed:  true,
+	models.NotificationKindParentLinkRequested: true,
+	models.NotificationKindParentLinked:        true,
+	models.NotificationKindParentLinkRejected:  true,
+	models.NotificationKindChildEvent:          true,
+}
+
+type NotificationService interface {
+	Notify(notification models.NotificationCore)
+	SubscribeNotifications(clientId uint) (notifications <-chan models.NotificationCore, unsubscribe func())
+	GetNotifications(unreadOnly bool, page, pageSize *int, clientId uint) (
+		notifications []models.NotificationCore, countRows, countUnread uint, err error)
+	MarkRead(ids []uint, clientId uint) error
+	MarkAllRead(clientId uint) error
+	GetPreferences(clientId uint) ([]models.NotificationPreferenceCore, error)
+	UpdatePreference(preference models.NotificationPreferenceCore, clientId uint) (models.NotificationPreferenceCore, error)
+}
+
+type NotificationServiceImpl struct {
+	loggers             logger.Loggers
+	notificationGateway gateways.NotificationGateway
+	userGateway         gateways.UserGateway
+	broker              *pubsub.Broker[models.NotificationCore]
+}
+
+func NewNotificationService(
+	loggers logger.Loggers,
+	notificationGateway gateways.NotificationGateway,
+	userGateway gateways.UserGateway,
+) *NotificationServiceImpl {
+	return &NotificationServiceImpl{
+		loggers:             loggers,
+		notificationGateway: notificationGateway,
+		userGateway:         userGateway,
+		broker:              pubsub.NewBroker[models.NotificationCore](liveEventsBufferSize),
+	}
+}
+
+// Notify saves the notification for the notification center and delivers it live, or emails it,
+// as the user prefers for the kind. Notifications follow saved changes, so failures are only logged.
+func (n NotificationServiceImpl) Notify(notification models.NotificationCore) {
+	preference, err := n.getPreference(notification.UserID, notification.Kind)
+	if err != nil {
+		n.loggers.Err.Printf("notification for user %d: %s", notification.UserID, err.Error())
+		return
+	}
+	if preference.InApp {
+		notification, err = n.notificationGateway.CreateNotification(notification)
+		if err != nil {
+			n.loggers.Err.Printf("notification for user %d: %s", notification.UserID, err.Error())
+		} else {
+			n.broker.Publish(strconv.Itoa(int(notification.UserID)), notification)
+		}
+	}
+	if preference.Email {
+		user, err := n.userGateway.GetUserById(notification.UserID)
+		if err != nil {
+			n.loggers.Err.Printf("notification for user %d: %s", notification.UserID, err.Error())
+			return
+		}
+		// imported participants may have no email
+		if user.Email == "" {
+			return
+		}
+		body := "<p>" + html.EscapeString(notification.Text) + "</p>"
+		if err := utils.SendEmail(notification.Title, user.Email, body); err != nil {
+			n.loggers.Err.Printf("notification for user %d: %s", notification.UserID, err.Error())
+		}
+	}
+}
+
+func (n NotificationServiceImpl) SubscribeNotifications(clientId uint) (
+	notifications <-chan models.NotificationCore, unsubscribe func()) {
+	return n.broker.Subscribe(strconv.Itoa(int(clientId)), nil)
+}
+
+func (n NotificationServiceImpl) GetNotifications(unreadOnly bool, page, pageSize *int, clientId uint) (
+	notifications []models.NotificationCore, countRows, countUnread uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	notifications, countRows, err = n.notificationGateway.GetNotificationsByUser(clientId, unreadOnly, offset, limit)
+	if err != nil {
+		return []models.NotificationCore{}, 0, 0, err
+	}
+	countUnread, err = n.notificationGateway.CountUnread(clientId)
+	if err != nil {
+		return []models.NotificationCore{}, 0, 0, err
+	}
+	return notifications, countRows, countUnread, nil
+}
+
+func (n NotificationServiceImpl) MarkRead(ids []uint, clientId uint) error {
+	return n.notificationGateway.MarkRead(clientId, ids)
+}
+
+func (n NotificationServiceImpl) MarkAllRead(clientId uint) error {
+	return n.notificationGateway.MarkAllRead(clientId)
+}
+
+// GetPreferences returns preferences for all kinds, the defaults included
+func (n NotificationServiceImpl) GetPreferences(clientId uint) ([]models.NotificationPreferenceCore, error) {
+	saved, err := n.notificationGateway.GetPreferences(clientId)
+	if err != nil {
+		return []models.NotificationPreferenceCore{}, err
+	}
+	savedByKind := make(map[models.NotificationKind]models.NotificationPreferenceCore, len(saved))
+	for _, preference := range saved {
+		savedByKind[preference.Kind] = preference
+	}
+	preferences := make([]models.NotificationPreferenceCore, 0, len(models.AllNotificationKind))
+	for _, kind := range models.AllNotificationKind {
+		preference, ok := savedByKind[kind]
+		if !ok {
+			preference = getDefaultNotificationPreference(clientId, kind)
+		}
+		preferences = append(preferences, preference)
+	}
+	return preferences, nil
+}
+
+func (n NotificationServiceImpl) UpdatePreference(preference models.NotificationPreferenceCore, clientId uint) (
+	models.NotificationPreferenceCore, error) {
+	preference.UserID = clientId
+	return n.notificationGateway.SetPreference(preference)
+}
+
+func (n NotificationServiceImpl) getPreference(userId uint, kind models.NotificationKind) (
+	models.NotificationPreferenceCore, error) {
+	preference, ok, err := n.notificationGateway.GetPreference(userId, kind)
+	if err != nil {
+		return models.NotificationPreferenceCore{}, err
+	}
+	if !ok {
+		return getDefaultNotificationPreference(userId, kind), nil
+	}
+	return preference, nil
+}
+
+func getDefaultNotificationPreference(userId uint, kind models.NotificationKind) models.NotificationPreferenceCore {
+	return models.NotificationPreferenceCore{
+		UserID: userId,
+		Kind:   kind,
+		InApp:  true,
+		Email:  emailedNotificationKinds[kind],
+	}
+}
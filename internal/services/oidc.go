@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/oidc"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OidcService signs users in with external OpenID Connect providers (authorization code flow
+// with PKCE). Users are linked by provider subject, or by verified email on the first sign in.
+// The sign in is finished only in the browser that started it, the browser keeps the binding in a cookie.
+type OidcService interface {
+	GetProviders() []string
+	GetAuthUrl(ctx context.Context, provider string) (authUrl, browserBinding string, err error)
+	SignIn(ctx context.Context, state, code, browserBinding, ip, userAgent string) (SignInResult, error)
+}
+
+type OidcServiceImpl struct {
+	providers                  map[string]oidcProvider
+	userGateway                gateways.UserGateway
+	settingsGateway            gateways.SettingsGateway
+	oidcGateway                gateways.OidcGateway
+	registrationRequestGateway gateways.RegistrationRequestGateway
+	keyService                 KeyService
+	passwordService            PasswordService
+}
+
+type oidcProvider struct {
+	client      *oidc.Provider
+	role        models.Role
+	createUsers bool
+}
+
+type oidcProviderConfig struct {
+	Name         string   `mapstructure:"name"`
+	Issuer       string   `mapstructure:"issuer"`
+	ClientId     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectUri  string   `mapstructure:"redirect_uri"`
+	Scopes       []string `mapstructure:"scopes"`
+	Role         string   `mapstructure:"role"`
+	CreateUsers  bool     `mapstructure:"create_users"`
+}
+
+func NewOidcService(
+	userGateway gateways.UserGateway,
+	settingsGateway gateways.SettingsGateway,
+	oidcGateway gateways.OidcGateway,
+	registrationRequestGateway gateways.RegistrationRequestGateway,
+	keyService KeyService,
+	passwordService PasswordService,
+) (OidcService, error) {
+	var configs []oidcProviderConfig
+	if err := viper.UnmarshalKey("oidc.providers", &configs); err != nil {
+		return nil, err
+	}
+	providers := map[string]oidcProvider{}
+	for _, config := range configs {
+		if config.Name == "" || config.Issuer == "" || config.ClientId == "" || config.RedirectUri == "" {
+			return nil, fmt.Errorf("oidc provider %q: name, issuer, client_id and redirect_uri are required", config.Name)
+		}
+		role := models.Role(config.Role)
+		if role == "" {
+			role = models.RoleStudent
+		}
+		// admins are never created by an external provider
+		if role != models.RoleStudent && role != models.RoleParent && role != models.RoleTeacher {
+			return nil, fmt.Errorf("oidc provider %q: unsupported role %q", config.Name, config.Role)
+		}
+		scopes := config.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+		providers[config.Name] = oidcProvider{
+			client: oidc.NewProvider(oidc.Config{
+				Issuer:   config.Issuer,
+				ClientID: config.ClientId,
+				// client_secret is the name of the secret in the env file
+				ClientSecret: viper.GetString(config.ClientSecret),
+				RedirectURI:  config.RedirectUri,
+				Scopes:       scopes,
+			}, nil),
+			role:        role,
+			createUsers: config.CreateUsers,
+		}
+	}
+	return OidcServiceImpl{
+		providers:                  providers,
+		userGateway:                userGateway,
+		settingsGateway:            settingsGateway,
+		oidcGateway:                oidcGateway,
+		registrationRequestGateway: registrationRequestGateway,
+		keyService:                 keyService,
+		passwordService:            passwordService,
+	}, nil
+}
+
+func (o OidcServiceImpl) GetProviders() []string {
+	providers := make([]string, 0, len(o.providers))
+	for name := range o.providers {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+func (o OidcServiceImpl) GetAuthUrl(ctx context.Context, provider string) (authUrl, browserBinding string, err error) {
+	p, ok := o.providers[provider]
+	if !ok {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrUnknownOidcProvider,
+		}
+	}
+	values := make([]string, 4)
+	for i := range values {
+		value, err := utils.GenerateRandomString(43)
+		if err != nil {
+			return "", "", utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		values[i] = value
+	}
+	state := models.OidcStateCore{
+		State:          values[0],
+		Provider:       provider,
+		CodeVerifier:   values[1],
+		Nonce:          values[2],
+		BrowserBinding: utils.GetSha256String(values[3]),
+		ExpiresAt:      time.Now().Add(viper.GetDuration("oidc.state_ttl")),
+	}
+	authUrl, err = p.client.AuthCodeURL(ctx, state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusBadGateway,
+			Message: err.Error(),
+		}
+	}
+	if err := o.oidcGateway.CreateState(state); err != nil {
+		return "", "", err
+	}
+	return authUrl, values[3], nil
+}
+
+func (o OidcServiceImpl) SignIn(ctx context.Context, state, code, browserBinding, ip, userAgent string) (SignInResult, error) {
+	stateCore, err := o.oidcGateway.TakeState(state)
+	if err != nil {
+		return SignInResult{}, err
+	}
+	// a state started in another browser must not sign this browser in
+	if stateCore.BrowserBinding == "" || browserBinding == "" ||
+		subtle.ConstantTimeCompare([]byte(stateCore.BrowserBinding), []byte(utils.GetSha256String(browserBinding))) != 1 {
+		return SignInResult{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectOidcState,
+		}
+	}
+	p, ok := o.providers[stateCore.Provider]
+	if !ok {
+		return SignInResult{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrUnknownOidcProvider,
+		}
+	}
+	claims, err := p.client.Exchange(ctx, code, stateCore.CodeVerifier, stateCore.Nonce)
+	if err != nil {
+		return SignInResult{}, utils.ResponseError{
+			Code:    http.StatusUnauthorized,
+			Message: err.Error(),
+		}
+	}
+	user, err := o.getOrCreateUser(stateCore.Provider, p, claims, ip, userAgent)
+	if err != nil {
+		return SignInResult{}, err
+	}
+	if !user.IsActive {
+		return SignInResult{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrUserIsNotActive,
+		}
+	}
+	return completeSignIn(o.keyService, o.settingsGateway, user)
+}
+
+func (o OidcServiceImpl) getOrCreateUser(providerName string, p oidcProvider, claims *oidc.Claims,
+	ip, userAgent string) (models.UserCore, error) {
+	identity, exist, err := o.oidcGateway.GetIdentity(providerName, claims.Subject)
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	if exist {
+		// the linked user was deleted
+		if identity.User.ID == 0 {
+			return models.UserCore{}, utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+		return identity.User, nil
+	}
+	// only an email confirmed by the provider may be trusted to link or create accounts
+	if claims.Email == "" || !claims.EmailVerified {
+		return models.UserCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrOidcEmailNotVerified,
+		}
+	}
+	exist, err = o.userGateway.DoesExistEmail(0, claims.Email)
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	var user models.UserCore
+	if exist {
+		if user, err = o.userGateway.GetUserByEmail(claims.Email); err != nil {
+			return models.UserCore{}, err
+		}
+		// a provider must not get access to admin accounts only by asserting their email
+		if user.Role == models.RoleSuperAdmin || user.Role == models.RoleUnitAdmin {
+			return models.UserCore{}, utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrOidcAdminLinkDenied,
+			}
+		}
+	} else {
+		if user, err = o.createUser(p, claims, ip, userAgent); err != nil {
+			return models.UserCore{}, err
+		}
+	}
+	if err := o.oidcGateway.CreateIdentity(models.UserIdentityCore{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+	}); err != nil {
+		return models.UserCore{}, err
+	}
+	return user, nil
+}
+
+// createUser creates the user with a random password. The email is verified by the provider,
+// so the user is active at once, unless new accounts must be approved by an admin.
+func (o OidcServiceImpl) createUser(p oidcProvider, claims *oidc.Claims, ip, userAgent string) (models.UserCore, error) {
+	if !p.createUsers {
+		return models.UserCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrOidcSignUpDisabled,
+		}
+	}
+	activationByLink, err := o.settingsGateway.GetActivationByLink()
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	password, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return models.UserCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	passwordHash, err := o.passwordService.HashPassword(password)
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	nickname := claims.PreferredUsername
+	if nickname == "" {
+		nickname = strings.Split(claims.Email, "@")[0]
+	}
+	nickname, err = getUniqueNickname(o.userGateway, nickname)
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	user, err := o.userGateway.CreateUser(models.UserCore{
+		Email:      claims.Email,
+		Password:   passwordHash,
+		Role:       p.role,
+		Firstname:  claims.GivenName,
+		Lastname:   claims.FamilyName,
+		Middlename: claims.MiddleName,
+		Nickname:   nickname,
+		IsActive:   activationByLink,
+	})
+	if err != nil {
+		return models.UserCore{}, err
+	}
+	if !activationByLink {
+		if err := o.registrationRequestGateway.CreateRegistrationRequest(models.RegistrationRequestCore{
+			UserID:    user.ID,
+			Ip:        ip,
+			UserAgent: userAgent,
+			Status:    models.RegistrationStatusPending,
+		}); err != nil {
+			return models.UserCore{}, err
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"strconv"
+)
+
+// ParentDashboardService shows parents the progress of their children
+// and notifies them about important events of the children
+type ParentDashboardService interface {
+	GetDashboard(parentId uint) ([]models.ChildProgressCore, error)
+	NotifyParents(childId uint, title, text string, payload map[string]string)
+}
+
+type ParentDashboardServiceImpl struct {
+	loggers             logger.Loggers
+	parentRelGateway    gateways.ParentRel
+	projectPageGateway  gateways.ProjectPageGateway
+	activityGateway     gateways.ActivityGateway
+	assignmentGateway   gateways.AssignmentGateway
+	contestGateway      gateways.ContestGateway
+	teamGateway         gateways.TeamGateway
+	notificationService NotificationService
+}
+
+func (p ParentDashboardServiceImpl) GetDashboard(parentId uint) ([]models.ChildProgressCore, error) {
+	rels, err := p.parentRelGateway.GetRelsByParentId(parentId)
+	if err != nil {
+		return []models.ChildProgressCore{}, err
+	}
+	var dashboard []models.ChildProgressCore
+	for _, rel := range rels {
+		// deleted users are not preloaded
+		if rel.Child.ID == 0 {
+			continue
+		}
+		projectPages, countProjectPages, err := p.projectPageGateway.GetLatestProjectPagesByAuthorId(
+			rel.ChildID, viper.GetInt("parent_dashboard.projects_limit"))
+		if err != nil {
+			return []models.ChildProgressCore{}, err
+		}
+		submissions, _, err := p.assignmentGateway.GetSubmissionsByStudent(
+			rel.ChildID, 0, viper.GetInt("parent_dashboard.assignments_limit"))
+		if err != nil {
+			return []models.ChildProgressCore{}, err
+		}
+		contests, err := p.getChildContests(rel.ChildID)
+		if err != nil {
+			return []models.ChildProgressCore{}, err
+		}
+		activity, err := p.activityGateway.GetLatestActivity(rel.ChildID, viper.GetInt("parent_dashboard.activity_limit"))
+		if err != nil {
+			return []models.ChildProgressCore{}, err
+		}
+		dashboard = append(dashboard, models.ChildProgressCore{
+			Child:             rel.Child,
+			ProjectPages:      projectPages,
+			CountProjectPages: countProjectPages,
+			Submissions:       submissions,
+			Contests:          contests,
+			Activity:          activity,
+		})
+	}
+	return dashboard, nil
+}
+
+// getChildContests returns registrations of the child with the team of the child in team contests,
+// the team keeps the submission time and the published score and place
+func (p ParentDashboardServiceImpl) getChildContests(childId uint) ([]models.ChildContestCore, error) {
+	registrations, _, err := p.contestGateway.GetRegistrationsByUser(childId, 0,
+		viper.GetInt("parent_dashboard.contests_limit"))
+	if err != nil {
+		return []models.ChildContestCore{}, err
+	}
+	contests := make([]models.ChildContestCore, 0, len(registrations))
+	for _, registration := range registrations {
+		childContest := models.ChildContestCore{Registration: registration}
+		if registration.Contest.MaxTeamSize != nil {
+			team, err := p.teamGateway.GetTeamByMember(registration.ContestID, childId)
+			var responseError utils.ResponseError
+			if err == nil {
+				childContest.Team = &team
+			} else if !errors.As(err, &responseError) || responseError.Message != consts.ErrNotFoundInDB {
+				return []models.ChildContestCore{}, err
+			}
+		}
+		contests = append(contests, childContest)
+	}
+	return contests, nil
+}
+
+// NotifyParents notifies all parents of the student as they prefer for events of children.
+// Failures are only logged, the notification never fails the change it reports.
+func (p ParentDashboardServiceImpl) NotifyParents(childId uint, title, text string, payload map[string]string) {
+	rels, err := p.parentRelGateway.GetRelsByChildId(childId)
+	if err != nil {
+		p.loggers.Err.Printf("parents of user %d: %s", childId, err.Error())
+		return
+	}
+	for _, rel := range rels {
+		if rel.Parent.ID == 0 {
+			continue
+		}
+		parentPayload := map[string]string{"childId": strconv.Itoa(int(childId))}
+		for key, value := range payload {
+			parentPayload[key] = value
+		}
+		p.notificationService.Notify(models.NotificationCore{
+			UserID:  rel.ParentID,
+			Kind:    models.NotificationKindChildEvent,
+			Title:   title,
+			Text:    text,
+			Payload: parentPayload,
+		})
+	}
+}
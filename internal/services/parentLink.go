@@ -0,0 +1,203 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parentLinkCodeLength gives 50 random bits, enough for a code living a few days
+const parentLinkCodeLength = 10
+
+// ParentLinkService lets a student or a teacher generate a one-time code,
+// which a parent redeems to link with the student
+type ParentLinkService interface {
+	CreateCode(childId *uint, requireApproval bool, clientId uint, clientRole models.Role) (
+		code string, codeCore models.ParentLinkCodeCore, err error)
+	GetCodes(page, pageSize *int, clientId uint) (codes []models.ParentLinkCodeCore, countRows uint, err error)
+	RevokeCode(id, clientId uint, clientRole models.Role) error
+	RedeemCode(code string, parentId uint) (models.ParentLinkCodeCore, error)
+	ApproveLink(id, clientId uint) error
+	RejectLink(id, clientId uint) error
+}
+
+type ParentLinkServiceImpl struct {
+	loggers               logger.Loggers
+	userGateway           gateways.UserGateway
+	parentLinkCodeGateway gateways.ParentLinkCodeGateway
+	groupGateway          gateways.GroupGateway
+	notificationService   NotificationService
+}
+
+// CreateCode creates the code of the student. Students create codes for themselves,
+// teachers for students of their groups.
+func (p ParentLinkServiceImpl) CreateCode(childId *uint, requireApproval bool, clientId uint, clientRole models.Role) (
+	code string, codeCore models.ParentLinkCodeCore, err error) {
+	id := clientId
+	if clientRole == models.RoleTeacher {
+		if childId == nil {
+			return "", models.ParentLinkCodeCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrIncorrectParentRel,
+			}
+		}
+		doesTeach, err := p.groupGateway.DoesTeachUser(clientId, *childId)
+		if err != nil {
+			return "", models.ParentLinkCodeCore{}, err
+		}
+		if !doesTeach {
+			return "", models.ParentLinkCodeCore{}, utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+		id = *childId
+	} else if childId != nil && *childId != clientId {
+		return "", models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	child, err := p.userGateway.GetUserById(id)
+	if err != nil {
+		return "", models.ParentLinkCodeCore{}, err
+	}
+	if child.Role != models.RoleStudent {
+		return "", models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectParentRel,
+		}
+	}
+	code, err = utils.GenerateRandomString(parentLinkCodeLength)
+	if err != nil {
+		return "", models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	codeCore, err = p.parentLinkCodeGateway.CreateCode(models.ParentLinkCodeCore{
+		Code:            utils.GetSha256String(code),
+		ChildID:         child.ID,
+		CreatedByID:     clientId,
+		RequireApproval: requireApproval,
+		Status:          models.ParentLinkCodeStatusActive,
+		ExpiresAt:       time.Now().Add(viper.GetDuration("parent_link.code_ttl")),
+	})
+	if err != nil {
+		return "", models.ParentLinkCodeCore{}, err
+	}
+	return code, codeCore, nil
+}
+
+func (p ParentLinkServiceImpl) GetCodes(page, pageSize *int, clientId uint) (
+	codes []models.ParentLinkCodeCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return p.parentLinkCodeGateway.GetCodesByUser(clientId, offset, limit)
+}
+
+func (p ParentLinkServiceImpl) RevokeCode(id, clientId uint, clientRole models.Role) error {
+	code, err := p.parentLinkCodeGateway.GetCodeById(id)
+	if err != nil {
+		return err
+	}
+	if !isAdmin(clientRole) && code.ChildID != clientId && code.CreatedByID != clientId {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return p.parentLinkCodeGateway.RevokeCode(id)
+}
+
+func (p ParentLinkServiceImpl) RedeemCode(code string, parentId uint) (models.ParentLinkCodeCore, error) {
+	codeHash := utils.GetSha256String(strings.ToLower(strings.TrimSpace(code)))
+	codeCore, err := p.parentLinkCodeGateway.RedeemCode(codeHash, parentId)
+	if err != nil {
+		return models.ParentLinkCodeCore{}, err
+	}
+	if codeCore.Status == models.ParentLinkCodeStatusPendingApproval {
+		p.notify(codeCore, codeCore.ChildID, models.NotificationKindParentLinkRequested, "Запрос на привязку родителя",
+			getFullName(*codeCore.RedeemedBy)+
+				" хочет привязать ваш аккаунт как родитель. Подтвердите или отклоните запрос в личном кабинете.")
+		return codeCore, nil
+	}
+	p.notifyLinked(codeCore)
+	return codeCore, nil
+}
+
+func (p ParentLinkServiceImpl) ApproveLink(id, clientId uint) error {
+	code, err := p.getPendingCode(id, clientId)
+	if err != nil {
+		return err
+	}
+	if err := p.parentLinkCodeGateway.ApproveCode(id); err != nil {
+		return err
+	}
+	p.notifyLinked(code)
+	return nil
+}
+
+func (p ParentLinkServiceImpl) RejectLink(id, clientId uint) error {
+	code, err := p.getPendingCode(id, clientId)
+	if err != nil {
+		return err
+	}
+	if err := p.parentLinkCodeGateway.RejectCode(id); err != nil {
+		return err
+	}
+	p.notify(code, code.RedeemedBy.ID, models.NotificationKindParentLinkRejected, "Запрос на привязку отклонён",
+		getFullName(code.Child)+" отклонил(а) запрос на привязку аккаунта.")
+	return nil
+}
+
+// getPendingCode returns the code waiting for the approval of the student
+func (p ParentLinkServiceImpl) getPendingCode(id, clientId uint) (models.ParentLinkCodeCore, error) {
+	code, err := p.parentLinkCodeGateway.GetCodeById(id)
+	if err != nil {
+		return models.ParentLinkCodeCore{}, err
+	}
+	if code.ChildID != clientId {
+		return models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	if code.Status != models.ParentLinkCodeStatusPendingApproval || code.RedeemedBy == nil {
+		return models.ParentLinkCodeCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrParentLinkCodeUnavailable,
+		}
+	}
+	return code, nil
+}
+
+// notifyLinked confirms the new relationship to both the parent and the student
+func (p ParentLinkServiceImpl) notifyLinked(code models.ParentLinkCodeCore) {
+	p.notify(code, code.RedeemedBy.ID, models.NotificationKindParentLinked, "Аккаунт ребёнка привязан",
+		"К вашему аккаунту привязан аккаунт "+getFullName(code.Child)+".")
+	p.notify(code, code.ChildID, models.NotificationKindParentLinked, "Аккаунт родителя привязан",
+		"К вашему аккаунту привязан аккаунт родителя "+getFullName(*code.RedeemedBy)+".")
+}
+
+// notify tells about the change of the link, the code is in the payload
+func (p ParentLinkServiceImpl) notify(code models.ParentLinkCodeCore, userId uint, kind models.NotificationKind,
+	title, text string) {
+	p.notificationService.Notify(models.NotificationCore{
+		UserID:  userId,
+		Kind:    kind,
+		Title:   title,
+		Text:    text,
+		Payload: map[string]string{"parentLinkCodeId": strconv.Itoa(int(code.ID))},
+	})
+}
+
+func getFullName(user models.UserCore) string {
+	return strings.TrimSpace(user.Lastname + " " + user.Firstname + " " + user.Middlename)
+}
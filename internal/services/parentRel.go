@@ -0,0 +1,129 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+)
+
+// ParentRelService links parents with students. Relationships are created by admins
+// and may be removed by admins or by any of the linked users. Unit admins manage
+// relationships of users of their units only.
+type ParentRelService interface {
+	CreateParentRel(parentId, childId, clientId uint, clientRole models.Role) error
+	DeleteParentRel(parentId, childId, clientId uint, clientRole models.Role) error
+	GetChildrenByParent(parentId, clientId uint, clientRole models.Role) (children []models.UserCore, countRows uint, err error)
+	GetParentsByChild(childId, clientId uint, clientRole models.Role) (parents []models.UserCore, countRows uint, err error)
+}
+
+type ParentRelServiceImpl struct {
+	userGateway      gateways.UserGateway
+	parentRelGateway gateways.ParentRel
+	unitService      UnitService
+}
+
+func (p ParentRelServiceImpl) CreateParentRel(parentId, childId, clientId uint, clientRole models.Role) error {
+	if err := p.checkUnitAccess(parentId, childId, clientId, clientRole); err != nil {
+		return err
+	}
+	parent, err := p.userGateway.GetUserById(parentId)
+	if err != nil {
+		return err
+	}
+	child, err := p.userGateway.GetUserById(childId)
+	if err != nil {
+		return err
+	}
+	if parent.Role != models.RoleParent || child.Role != models.RoleStudent {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectParentRel,
+		}
+	}
+	exist, err := p.parentRelGateway.DoesExistRel(parentId, childId)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrParentRelAlreadyExists,
+		}
+	}
+	_, err = p.parentRelGateway.CreateRel(parentId, childId)
+	return err
+}
+
+func (p ParentRelServiceImpl) DeleteParentRel(parentId, childId, clientId uint, clientRole models.Role) error {
+	if !isAdmin(clientRole) && clientId != parentId && clientId != childId {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	if err := p.checkUnitAccess(parentId, childId, clientId, clientRole); err != nil {
+		return err
+	}
+	return p.parentRelGateway.DeleteRel(parentId, childId)
+}
+
+func (p ParentRelServiceImpl) GetChildrenByParent(parentId, clientId uint, clientRole models.Role) (
+	children []models.UserCore, countRows uint, err error) {
+	if !isAdmin(clientRole) && clientId != parentId {
+		return []models.UserCore{}, 0, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	if err := p.unitService.CheckUserAccess(parentId, clientId, clientRole); err != nil {
+		return []models.UserCore{}, 0, err
+	}
+	rels, err := p.parentRelGateway.GetRelsByParentId(parentId)
+	if err != nil {
+		return []models.UserCore{}, 0, err
+	}
+	for _, rel := range rels {
+		// deleted users are not preloaded
+		if rel.Child.ID != 0 {
+			children = append(children, rel.Child)
+		}
+	}
+	return children, uint(len(children)), nil
+}
+
+func (p ParentRelServiceImpl) GetParentsByChild(childId, clientId uint, clientRole models.Role) (
+	parents []models.UserCore, countRows uint, err error) {
+	if !isAdmin(clientRole) && clientId != childId {
+		return []models.UserCore{}, 0, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	if err := p.unitService.CheckUserAccess(childId, clientId, clientRole); err != nil {
+		return []models.UserCore{}, 0, err
+	}
+	rels, err := p.parentRelGateway.GetRelsByChildId(childId)
+	if err != nil {
+		return []models.UserCore{}, 0, err
+	}
+	for _, rel := range rels {
+		if rel.Parent.ID != 0 {
+			parents = append(parents, rel.Parent)
+		}
+	}
+	return parents, uint(len(parents)), nil
+}
+
+// checkUnitAccess requires both the parent and the child to be in units of the unit admin
+func (p ParentRelServiceImpl) checkUnitAccess(parentId, childId, clientId uint, clientRole models.Role) error {
+	if err := p.unitService.CheckUserAccess(parentId, clientId, clientRole); err != nil {
+		return err
+	}
+	return p.unitService.CheckUserAccess(childId, clientId, clientRole)
+}
+
+func isAdmin(role models.Role) bool {
+	return role == models.RoleUnitAdmin || role == models.RoleSuperAdmin
+}
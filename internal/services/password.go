@@ -0,0 +1,146 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// PasswordService checks new passwords against the password policy from settings
+// and hashes them with the algorithm configured in password.hashing.
+type PasswordService interface {
+	ValidatePassword(password string) error
+	HashPassword(password string) (string, error)
+	// ComparePassword reports whether the hash must be upgraded to the current algorithm or cost
+	ComparePassword(hashed, password string) (needsRehash bool, err error)
+}
+
+type PasswordServiceImpl struct {
+	settingsGateway gateways.SettingsGateway
+	hashParams      utils.PasswordHashParams
+	commonPasswords map[string]struct{}
+}
+
+func NewPasswordService(settingsGateway gateways.SettingsGateway) (PasswordService, error) {
+	hashParams := utils.PasswordHashParams{
+		Algorithm:         viper.GetString("password.hashing.algorithm"),
+		BcryptCost:        viper.GetInt("password.hashing.bcrypt_cost"),
+		Argon2Memory:      viper.GetUint32("password.hashing.argon2id.memory"),
+		Argon2Iterations:  viper.GetUint32("password.hashing.argon2id.iterations"),
+		Argon2Parallelism: uint8(viper.GetUint("password.hashing.argon2id.parallelism")),
+		Argon2SaltLength:  viper.GetUint32("password.hashing.argon2id.salt_length"),
+		Argon2KeyLength:   viper.GetUint32("password.hashing.argon2id.key_length"),
+	}
+	if err := hashParams.Validate(); err != nil {
+		return nil, err
+	}
+	commonPasswords, err := loadCommonPasswords(viper.GetString("password.common_passwords_path"))
+	if err != nil {
+		return nil, err
+	}
+	return PasswordServiceImpl{
+		settingsGateway: settingsGateway,
+		hashParams:      hashParams,
+		commonPasswords: commonPasswords,
+	}, nil
+}
+
+func (p PasswordServiceImpl) ValidatePassword(password string) error {
+	policy, err := p.settingsGateway.GetPasswordPolicy()
+	if err != nil {
+		return err
+	}
+	if len([]rune(password)) < policy.MinLength {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf(consts.ErrShortPassword, policy.MinLength),
+		}
+	}
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSpecial = true
+		}
+	}
+	var message string
+	switch {
+	case policy.RequireUppercase && !hasUpper:
+		message = consts.ErrPasswordNoUppercase
+	case policy.RequireLowercase && !hasLower:
+		message = consts.ErrPasswordNoLowercase
+	case policy.RequireDigit && !hasDigit:
+		message = consts.ErrPasswordNoDigit
+	case policy.RequireSpecial && !hasSpecial:
+		message = consts.ErrPasswordNoSpecial
+	}
+	if message != "" {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: message,
+		}
+	}
+	if policy.ForbidCommon {
+		if _, ok := p.commonPasswords[strings.ToLower(password)]; ok {
+			return utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrCommonPassword,
+			}
+		}
+	}
+	return nil
+}
+
+func (p PasswordServiceImpl) HashPassword(password string) (string, error) {
+	hashed, err := utils.HashPassword(password, p.hashParams)
+	if err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return hashed, nil
+}
+
+func (p PasswordServiceImpl) ComparePassword(hashed, password string) (needsRehash bool, err error) {
+	if err := utils.ComparePassword(hashed, password); err != nil {
+		return false, err
+	}
+	return utils.NeedsRehash(hashed, p.hashParams), nil
+}
+
+// loadCommonPasswords reads the file with one password per line, empty lines and lines
+// starting with # are skipped. Without the configured path the check is disabled.
+func loadCommonPasswords(path string) (map[string]struct{}, error) {
+	passwords := map[string]struct{}{}
+	if path == "" {
+		return passwords, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords, scanner.Err()
+}
@@ -5,22 +5,74 @@ import (
 	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/gateways"
 	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
 	"github.com/skinnykaen/rpa_clone/pkg/utils"
 	"net/http"
 )
 
 type ProjectService interface {
-	UpdateProject(project models.ProjectCore) (updatedProject models.ProjectCore, err error)
+	UpdateProject(project models.ProjectCore, clientId uint) (updatedProject models.ProjectCore, err error)
 	GetProjectById(id, clientId uint, clientRole models.Role) (project models.ProjectCore, err error)
 }
 
 type ProjectServiceImpl struct {
-	projectGateway gateways.ProjectGateway
+	loggers           logger.Loggers
+	projectGateway    gateways.ProjectGateway
+	assignmentGateway gateways.AssignmentGateway
+	teamGateway       gateways.TeamGateway
+	templateService   ProjectTemplateService
 }
 
-func (p ProjectServiceImpl) UpdateProject(project models.ProjectCore) (updatedProject models.ProjectCore, err error) {
-	//TODO check is author?
-	return p.projectGateway.UpdateProject(project)
+// UpdateProject is allowed to the author of the project and to members of the team sharing it
+func (p ProjectServiceImpl) UpdateProject(project models.ProjectCore, clientId uint) (updatedProject models.ProjectCore, err error) {
+	currentProject, err := p.projectGateway.GetProjectById(project.ID)
+	if err != nil {
+		return models.ProjectCore{}, err
+	}
+	if currentProject.AuthorID != clientId {
+		isMember, err := p.teamGateway.IsTeamMember(project.ID, clientId)
+		if err != nil {
+			return models.ProjectCore{}, err
+		}
+		if !isMember {
+			return models.ProjectCore{}, utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+	}
+	isReadOnly, err := p.assignmentGateway.IsReadOnlySubmissionProject(project.ID)
+	if err != nil {
+		return models.ProjectCore{}, err
+	}
+	if isReadOnly {
+		return models.ProjectCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrAssignmentProjectReadOnly,
+		}
+	}
+	if err := p.templateService.CheckLocks(project); err != nil {
+		return models.ProjectCore{}, err
+	}
+	isSubmitted, err := p.teamGateway.IsSubmittedTeamProject(project.ID)
+	if err != nil {
+		return models.ProjectCore{}, err
+	}
+	if isSubmitted {
+		return models.ProjectCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamAlreadySubmitted,
+		}
+	}
+	updatedProject, err = p.projectGateway.UpdateProject(project)
+	if err != nil {
+		return models.ProjectCore{}, err
+	}
+	// the first save of a personal copy starts the assignment
+	if err := p.assignmentGateway.StartSubmissionsByProject(project.ID); err != nil {
+		p.loggers.Err.Printf("project %d: %s", project.ID, err.Error())
+	}
+	return updatedProject, nil
 }
 
 func (p ProjectServiceImpl) GetProjectById(id, clientId uint, clientRole models.Role) (project models.ProjectCore, err error) {
@@ -38,9 +90,16 @@ func (p ProjectServiceImpl) GetProjectById(id, clientId uint, clientRole models.
 		return project, nil
 	} else {
 		if project.AuthorID != clientId {
-			return models.ProjectCore{}, utils.ResponseError{
-				Code:    http.StatusForbidden,
-				Message: consts.ErrAccessDenied,
+			// members of a team share the project of the team
+			isMember, err := p.teamGateway.IsTeamMember(project.ID, clientId)
+			if err != nil {
+				return models.ProjectCore{}, err
+			}
+			if !isMember {
+				return models.ProjectCore{}, utils.ResponseError{
+					Code:    http.StatusForbidden,
+					Message: consts.ErrAccessDenied,
+				}
 			}
 		}
 	}
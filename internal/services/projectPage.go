@@ -4,27 +4,120 @@ import (
 	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/gateways"
 	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/pubsub"
 	"github.com/skinnykaen/rpa_clone/pkg/utils"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type ProjectPageService interface {
-	CreateProjectPage(authorId uint) (newProjectPage models.ProjectPageCore, err error)
+	CreateProjectPage(authorId uint, templateId *uint, clientRole models.Role) (newProjectPage models.ProjectPageCore, err error)
 	DeleteProjectPage(id, clientId uint) error
 	GetAllProjectPages(page, pageSize *int, userId uint, clientRole models.Role) (projectPages []models.ProjectPageCore, countRows uint, err error)
 	UpdateProjectPage(projectPage models.ProjectPageCore, clientId uint) (models.ProjectPageCore, error)
 	GetProjectPageById(id, clientId uint, clientRole models.Role) (projectPage models.ProjectPageCore, err error)
-	GetProjectsPageByAuthorId(id uint, page, pageSize *int) (projectPages []models.ProjectPageCore, countRows uint, err error)
-	SetIsBanned(id uint, isBanned bool) error
+	GetProjectsPageByAuthorId(id uint, page, pageSize *int, clientId uint, clientRole models.Role) (projectPages []models.ProjectPageCore, countRows uint, err error)
+	SetIsBanned(id uint, isBanned bool, clientId uint, clientRole models.Role) error
+	SubscribeModeration(clientId uint) (events <-chan models.ModerationEventCore, unsubscribe func())
 }
 
 type ProjectPageServiceImpl struct {
-	projectGateway     gateways.ProjectGateway
-	projectPageGateway gateways.ProjectPageGateway
+	loggers                logger.Loggers
+	projectGateway         gateways.ProjectGateway
+	projectPageGateway     gateways.ProjectPageGateway
+	userGateway            gateways.UserGateway
+	activityGateway        gateways.ActivityGateway
+	groupGateway           gateways.GroupGateway
+	assignmentGateway      gateways.AssignmentGateway
+	teamGateway            gateways.TeamGateway
+	parentDashboardService ParentDashboardService
+	notificationService    NotificationService
+	unitService            UnitService
+	projectTemplateService ProjectTemplateService
+	moderationBroker       *pubsub.Broker[models.ModerationEventCore]
 }
 
-func (p ProjectPageServiceImpl) SetIsBanned(id uint, isBanned bool) error {
-	return p.projectPageGateway.SetIsBanned(id, isBanned)
+// SetIsBanned bans the project page. Unit admins moderate only projects of other users of their units
+// who are not admins themselves.
+func (p ProjectPageServiceImpl) SetIsBanned(id uint, isBanned bool, clientId uint, clientRole models.Role) error {
+	projectPage, err := p.projectPageGateway.GetProjectPageById(id)
+	if err != nil {
+		return err
+	}
+	if err := p.checkModerationAccess(projectPage.AuthorID, clientId, clientRole); err != nil {
+		return err
+	}
+	if err := p.projectPageGateway.SetIsBanned(id, isBanned); err != nil {
+		return err
+	}
+	if projectPage.IsBanned == isBanned {
+		return nil
+	}
+	kind := models.ActivityKindProjectUnbanned
+	if isBanned {
+		kind = models.ActivityKindProjectBanned
+		p.parentDashboardService.NotifyParents(projectPage.AuthorID, "Проект заблокирован",
+			"Проект вашего ребёнка «"+projectPage.Title+"» заблокирован администратором платформы.",
+			map[string]string{"projectPageId": strconv.Itoa(int(projectPage.ID))})
+		p.notificationService.Notify(models.NotificationCore{
+			UserID:  projectPage.AuthorID,
+			Kind:    models.NotificationKindProjectBanned,
+			Title:   "Проект заблокирован",
+			Text:    "Проект «" + projectPage.Title + "» заблокирован администратором платформы.",
+			Payload: map[string]string{"projectPageId": strconv.Itoa(int(projectPage.ID))},
+		})
+	}
+	p.addActivity(projectPage.AuthorID, kind, projectPage)
+	p.moderationBroker.Publish(strconv.Itoa(int(projectPage.AuthorID)), models.ModerationEventCore{
+		ProjectPageID: projectPage.ID,
+		Title:         projectPage.Title,
+		IsBanned:      isBanned,
+		ModeratedAt:   time.Now(),
+	})
+	return nil
+}
+
+func (p ProjectPageServiceImpl) checkModerationAccess(authorId, clientId uint, clientRole models.Role) error {
+	if clientRole != models.RoleUnitAdmin {
+		return nil
+	}
+	if authorId == clientId {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	author, err := p.userGateway.GetUserById(authorId)
+	if err != nil {
+		return err
+	}
+	if author.Role == models.RoleUnitAdmin || author.Role == models.RoleSuperAdmin {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return p.unitService.CheckUserAccess(authorId, clientId, clientRole)
+}
+
+// SubscribeModeration delivers bans and unbans of project pages of the client
+func (p ProjectPageServiceImpl) SubscribeModeration(clientId uint) (
+	events <-chan models.ModerationEventCore, unsubscribe func()) {
+	return p.moderationBroker.Subscribe(strconv.Itoa(int(clientId)), nil)
+}
+
+// addActivity records the event for the dashboard of parents, a failed record is only logged
+func (p ProjectPageServiceImpl) addActivity(userId uint, kind models.ActivityKind, projectPage models.ProjectPageCore) {
+	if err := p.activityGateway.CreateActivity(models.ActivityCore{
+		UserID:    userId,
+		Kind:      kind,
+		SubjectID: projectPage.ID,
+		Title:     projectPage.Title,
+	}); err != nil {
+		p.loggers.Err.Printf("project page %d activity: %s", projectPage.ID, err.Error())
+	}
 }
 
 func (p ProjectPageServiceImpl) GetAllProjectPages(page, pageSize *int, userId uint, clientRole models.Role) (projectPages []models.ProjectPageCore, countRows uint, err error) {
@@ -35,27 +128,80 @@ func (p ProjectPageServiceImpl) GetAllProjectPages(page, pageSize *int, userId u
 	return p.projectPageGateway.GetAllProjectPages(offset, limit)
 }
 
-func (p ProjectPageServiceImpl) GetProjectsPageByAuthorId(id uint, page, pageSize *int) (projectPages []models.ProjectPageCore, countRows uint, err error) {
+func (p ProjectPageServiceImpl) GetProjectsPageByAuthorId(id uint, page, pageSize *int, clientId uint, clientRole models.Role) (projectPages []models.ProjectPageCore, countRows uint, err error) {
+	if err := p.unitService.CheckUserAccess(id, clientId, clientRole); err != nil {
+		return []models.ProjectPageCore{}, 0, err
+	}
+	if err := p.checkTeacherAccess(id, clientId, clientRole); err != nil {
+		return []models.ProjectPageCore{}, 0, err
+	}
 	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
 	return p.projectPageGateway.GetProjectPagesByAuthorId(id, offset, limit)
 }
 
-func (p ProjectPageServiceImpl) CreateProjectPage(authorId uint) (newProjectPage models.ProjectPageCore, err error) {
-	return p.projectPageGateway.CreateProjectPage(
-		models.ProjectPageCore{
-			AuthorID:    authorId,
-			Title:       "Untitled",
-			Instruction: "",
-			Notes:       "",
-			IsShared:    false,
-		},
-		models.ProjectCore{
-			AuthorID: authorId,
-			Json:     consts.EmptyProjectJson,
-		})
+// CreateProjectPage creates an empty project or a copy of the template available to the author
+func (p ProjectPageServiceImpl) CreateProjectPage(authorId uint, templateId *uint, clientRole models.Role) (
+	newProjectPage models.ProjectPageCore, err error) {
+	projectPage := models.ProjectPageCore{
+		AuthorID:    authorId,
+		Title:       "Untitled",
+		Instruction: "",
+		Notes:       "",
+		IsShared:    false,
+	}
+	project := models.ProjectCore{
+		AuthorID: authorId,
+		Json:     consts.EmptyProjectJson,
+	}
+	if templateId != nil {
+		template, err := p.GetProjectPageById(*templateId, authorId, clientRole)
+		if err != nil {
+			return models.ProjectPageCore{}, err
+		}
+		if !template.IsTemplate {
+			return models.ProjectPageCore{}, utils.ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrIncorrectProjectTemplate,
+			}
+		}
+		if projectPage.LockedBlocks, err = p.projectTemplateService.GetLockedBlocks(template); err != nil {
+			return models.ProjectPageCore{}, err
+		}
+		projectPage.TemplateID = &template.ID
+		projectPage.Title = template.Title
+		projectPage.Instruction = template.Instruction
+		projectPage.Notes = template.Notes
+		project.Json = template.Project.Json
+	}
+	newProjectPage, err = p.projectPageGateway.CreateProjectPage(projectPage, project)
+	if err != nil {
+		return models.ProjectPageCore{}, err
+	}
+	p.addActivity(authorId, models.ActivityKindProjectCreated, newProjectPage)
+	return newProjectPage, nil
 }
 
 func (p ProjectPageServiceImpl) DeleteProjectPage(id, clientId uint) error {
+	isSubmission, err := p.assignmentGateway.IsSubmissionProjectPage(id)
+	if err != nil {
+		return err
+	}
+	if isSubmission {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrProjectPageInAssignment,
+		}
+	}
+	isTeamProject, err := p.teamGateway.IsTeamProjectPage(id)
+	if err != nil {
+		return err
+	}
+	if isTeamProject {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrProjectPageInTeam,
+		}
+	}
 	return p.projectPageGateway.DeleteProjectPage(id, clientId)
 }
 
@@ -73,7 +219,12 @@ func (p ProjectPageServiceImpl) UpdateProjectPage(projectPage models.ProjectPage
 			Message: consts.ErrAccessDenied,
 		}
 	}
-	return p.projectPageGateway.UpdateProjectPage(projectPage)
+	updatedProjectPage, err := p.projectPageGateway.UpdateProjectPage(projectPage)
+	if err != nil {
+		return models.ProjectPageCore{}, err
+	}
+	p.addActivity(clientId, models.ActivityKindProjectUpdated, updatedProjectPage)
+	return updatedProjectPage, nil
 }
 
 func (p ProjectPageServiceImpl) GetProjectPageById(id, clientId uint, clientRole models.Role) (projectPage models.ProjectPageCore, err error) {
@@ -81,6 +232,11 @@ func (p ProjectPageServiceImpl) GetProjectPageById(id, clientId uint, clientRole
 	if err != nil {
 		return projectPage, err
 	}
+	// админ организации модерирует проекты пользователей своих организаций
+	if clientRole == models.RoleUnitAdmin && projectPage.AuthorID != clientId &&
+		p.unitService.CheckUserAccess(projectPage.AuthorID, clientId, clientRole) == nil {
+		return projectPage, nil
+	}
 	// если проект забанен по решению супер админа, то доступ имеет только супер админ
 	if projectPage.IsBanned && clientRole.String() == models.RoleSuperAdmin.String() {
 		return projectPage, nil
@@ -93,13 +249,43 @@ func (p ProjectPageServiceImpl) GetProjectPageById(id, clientId uint, clientRole
 	// проверка доступа к проекту. супер админу всегда имеет доступ к проекту
 	if projectPage.IsShared || clientRole.String() == models.RoleSuperAdmin.String() {
 		return projectPage, nil
+	} else if clientRole == models.RoleTeacher && projectPage.AuthorID != clientId {
+		// учитель видит проекты учеников своих групп
+		if err := p.checkTeacherAccess(projectPage.AuthorID, clientId, clientRole); err != nil {
+			return models.ProjectPageCore{}, err
+		}
 	} else {
 		if projectPage.AuthorID != clientId {
-			return models.ProjectPageCore{}, utils.ResponseError{
-				Code:    http.StatusForbidden,
-				Message: consts.ErrAccessDenied,
+			// members of a team share the project of the team
+			isMember, err := p.teamGateway.IsTeamMember(projectPage.ProjectID, clientId)
+			if err != nil {
+				return models.ProjectPageCore{}, err
+			}
+			if !isMember {
+				return models.ProjectPageCore{}, utils.ResponseError{
+					Code:    http.StatusForbidden,
+					Message: consts.ErrAccessDenied,
+				}
 			}
 		}
 	}
 	return projectPage, nil
 }
+
+// checkTeacherAccess allows teachers to access only their own projects and projects of students of their groups
+func (p ProjectPageServiceImpl) checkTeacherAccess(authorId, clientId uint, clientRole models.Role) error {
+	if clientRole != models.RoleTeacher || authorId == clientId {
+		return nil
+	}
+	isTaught, err := p.groupGateway.DoesTeachUser(clientId, authorId)
+	if err != nil {
+		return err
+	}
+	if !isTaught {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return nil
+}
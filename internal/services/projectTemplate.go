@@ -0,0 +1,357 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+)
+
+type ProjectTemplateService interface {
+	SetIsTemplate(id uint, isTemplate bool, clientId uint, clientRole models.Role) error
+	GetTemplates(page, pageSize *int, clientId uint) (projectPages []models.ProjectPageCore, countRows uint, err error)
+	GetLocks(projectPageId, clientId uint, clientRole models.Role) ([]models.TemplateLockCore, error)
+	SetLocks(projectPageId uint, locks []models.TemplateLockCore, clientId uint, clientRole models.Role) (
+		[]models.TemplateLockCore, error)
+	GetLockedBlocks(template models.ProjectPageCore) (string, error)
+	CheckLocks(project models.ProjectCore) error
+}
+
+type ProjectTemplateServiceImpl struct {
+	projectPageGateway  gateways.ProjectPageGateway
+	templateLockGateway gateways.TemplateLockGateway
+}
+
+// scratchTarget is the part of a sprite or the stage of the scratch project needed to check locks
+type scratchTarget struct {
+	IsStage bool                       `json:"isStage"`
+	Name    string                     `json:"name"`
+	Blocks  map[string]json.RawMessage `json:"blocks"`
+}
+
+type scratchBlock struct {
+	Opcode   string  `json:"opcode"`
+	Parent   *string `json:"parent"`
+	TopLevel bool    `json:"topLevel"`
+}
+
+type scratchSprite struct {
+	isStage bool
+	blocks  map[string]scratchBlock
+}
+
+// lockedBlocks is saved in the copy of the template, later changes of the template and its locks
+// do not affect copies made before
+type lockedBlocks struct {
+	Locks []lockedTarget `json:"locks"`
+	// Blocks keeps blocks of locked scripts by names of sprites
+	Blocks map[string]map[string]scratchBlock `json:"blocks"`
+}
+
+type lockedTarget struct {
+	Kind       models.TemplateLockKind `json:"kind"`
+	TargetName string                  `json:"targetName"`
+	BlockID    string                  `json:"blockId"`
+}
+
+func (p ProjectTemplateServiceImpl) SetIsTemplate(id uint, isTemplate bool, clientId uint, clientRole models.Role) error {
+	if _, err := p.getOwnProjectPage(id, clientId, clientRole); err != nil {
+		return err
+	}
+	return p.projectPageGateway.SetIsTemplate(id, isTemplate)
+}
+
+func (p ProjectTemplateServiceImpl) GetTemplates(page, pageSize *int, clientId uint) (
+	projectPages []models.ProjectPageCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return p.projectPageGateway.GetTemplates(clientId, offset, limit)
+}
+
+// GetLocks returns locks of the template or of the template the project page was started from
+func (p ProjectTemplateServiceImpl) GetLocks(projectPageId, clientId uint, clientRole models.Role) (
+	[]models.TemplateLockCore, error) {
+	projectPage, err := p.projectPageGateway.GetProjectPageById(projectPageId)
+	if err != nil {
+		return []models.TemplateLockCore{}, err
+	}
+	if !projectPage.IsShared && projectPage.AuthorID != clientId && clientRole != models.RoleSuperAdmin {
+		return []models.TemplateLockCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	if projectPage.IsTemplate {
+		return p.templateLockGateway.GetLocks(projectPage.ID)
+	}
+	if projectPage.TemplateID == nil {
+		return []models.TemplateLockCore{}, nil
+	}
+	snapshot, err := p.getCopyLocks(projectPage)
+	if err != nil {
+		return []models.TemplateLockCore{}, err
+	}
+	locks := make([]models.TemplateLockCore, 0, len(snapshot.Locks))
+	for _, lock := range snapshot.Locks {
+		locks = append(locks, models.TemplateLockCore{
+			ProjectPageID: *projectPage.TemplateID,
+			Kind:          lock.Kind,
+			TargetName:    lock.TargetName,
+			BlockID:       lock.BlockID,
+		})
+	}
+	return locks, nil
+}
+
+// SetLocks replaces locks of the template, every lock must point to a sprite or a script of the template
+func (p ProjectTemplateServiceImpl) SetLocks(projectPageId uint, locks []models.TemplateLockCore, clientId uint,
+	clientRole models.Role) ([]models.TemplateLockCore, error) {
+	template, err := p.getOwnProjectPage(projectPageId, clientId, clientRole)
+	if err != nil {
+		return []models.TemplateLockCore{}, err
+	}
+	if !template.IsTemplate {
+		return []models.TemplateLockCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectProjectTemplate,
+		}
+	}
+	sprites, err := parseScratchProject(template.Project.Json)
+	if err != nil {
+		return []models.TemplateLockCore{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	for i, lock := range locks {
+		sprite, ok := sprites[lock.TargetName]
+		switch {
+		case !ok:
+		case lock.Kind == models.TemplateLockKindSprite && !sprite.isStage:
+			locks[i].BlockID = ""
+			continue
+		case lock.Kind == models.TemplateLockKindScript && sprite.blocks[lock.BlockID].TopLevel:
+			continue
+		}
+		return []models.TemplateLockCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectTemplateLock,
+		}
+	}
+	return p.templateLockGateway.SetLocks(projectPageId, locks)
+}
+
+// GetLockedBlocks returns locks of the template to be saved in a new copy of the template
+func (p ProjectTemplateServiceImpl) GetLockedBlocks(template models.ProjectPageCore) (string, error) {
+	locks, err := p.templateLockGateway.GetLocks(template.ID)
+	if err != nil {
+		return "", err
+	}
+	snapshot, err := newLockedBlocks(locks, template.Project.Json)
+	if err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return string(data), nil
+}
+
+// CheckLocks checks that the project started from a template keeps locked sprites and scripts the template
+// had when the copy was made. Blocks may be appended to a locked script, but its own blocks cannot be deleted or moved.
+func (p ProjectTemplateServiceImpl) CheckLocks(project models.ProjectCore) error {
+	projectPage, err := p.projectPageGateway.GetProjectPageByProjectId(project.ID)
+	if err != nil {
+		var responseError utils.ResponseError
+		if errors.As(err, &responseError) && responseError.Message == consts.ErrNotFoundInDB {
+			return nil
+		}
+		return err
+	}
+	if projectPage.TemplateID == nil {
+		return nil
+	}
+	snapshot, err := p.getCopyLocks(projectPage)
+	if err != nil || len(snapshot.Locks) == 0 {
+		return err
+	}
+	sprites, err := parseScratchProject(project.Json)
+	if err != nil {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	}
+	for _, lock := range snapshot.Locks {
+		sprite, ok := sprites[lock.TargetName]
+		if lock.Kind == models.TemplateLockKindSprite {
+			if !ok || sprite.isStage {
+				return utils.ResponseError{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf(consts.ErrTemplateLockViolated, lock.TargetName),
+				}
+			}
+			continue
+		}
+		templateBlocks := snapshot.Blocks[lock.TargetName]
+		for id, templateBlock := range templateBlocks {
+			if !isInScript(templateBlocks, id, lock.BlockID) {
+				continue
+			}
+			block, ok := sprite.blocks[id]
+			if !ok || block.Opcode != templateBlock.Opcode || !sameParent(block.Parent, templateBlock.Parent) {
+				return utils.ResponseError{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf(consts.ErrTemplateLockViolated, "script of "+lock.TargetName),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getCopyLocks returns locks saved in the copy, copies made before locks were saved are checked
+// against the current template
+func (p ProjectTemplateServiceImpl) getCopyLocks(projectPage models.ProjectPageCore) (lockedBlocks, error) {
+	var snapshot lockedBlocks
+	if projectPage.LockedBlocks != "" {
+		if err := json.Unmarshal([]byte(projectPage.LockedBlocks), &snapshot); err != nil {
+			return lockedBlocks{}, utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		return snapshot, nil
+	}
+	locks, err := p.templateLockGateway.GetLocks(*projectPage.TemplateID)
+	if err != nil || len(locks) == 0 {
+		return lockedBlocks{}, err
+	}
+	template, err := p.projectPageGateway.GetProjectPageById(*projectPage.TemplateID)
+	if err != nil {
+		return lockedBlocks{}, err
+	}
+	if snapshot, err = newLockedBlocks(locks, template.Project.Json); err != nil {
+		return lockedBlocks{}, utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	return snapshot, nil
+}
+
+// newLockedBlocks keeps the locks and blocks of locked scripts of the template project
+func newLockedBlocks(locks []models.TemplateLockCore, templateJson string) (lockedBlocks, error) {
+	snapshot := lockedBlocks{
+		Locks:  make([]lockedTarget, 0, len(locks)),
+		Blocks: map[string]map[string]scratchBlock{},
+	}
+	if len(locks) == 0 {
+		return snapshot, nil
+	}
+	sprites, err := parseScratchProject(templateJson)
+	if err != nil {
+		return lockedBlocks{}, err
+	}
+	for _, lock := range locks {
+		snapshot.Locks = append(snapshot.Locks, lockedTarget{
+			Kind:       lock.Kind,
+			TargetName: lock.TargetName,
+			BlockID:    lock.BlockID,
+		})
+		if lock.Kind != models.TemplateLockKindScript {
+			continue
+		}
+		templateBlocks := sprites[lock.TargetName].blocks
+		if snapshot.Blocks[lock.TargetName] == nil {
+			snapshot.Blocks[lock.TargetName] = map[string]scratchBlock{}
+		}
+		for id, block := range templateBlocks {
+			if isInScript(templateBlocks, id, lock.BlockID) {
+				snapshot.Blocks[lock.TargetName][id] = block
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// getOwnProjectPage returns the not banned project page of the client, super admins manage all project pages
+func (p ProjectTemplateServiceImpl) getOwnProjectPage(id, clientId uint, clientRole models.Role) (
+	models.ProjectPageCore, error) {
+	projectPage, err := p.projectPageGateway.GetProjectPageById(id)
+	if err != nil {
+		return models.ProjectPageCore{}, err
+	}
+	if projectPage.IsBanned {
+		return models.ProjectPageCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrProjectPageIsBanned,
+		}
+	}
+	if projectPage.AuthorID != clientId && clientRole != models.RoleSuperAdmin {
+		return models.ProjectPageCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return projectPage, nil
+}
+
+// parseScratchProject returns sprites and the stage of the scratch project by their names
+func parseScratchProject(projectJson string) (map[string]scratchSprite, error) {
+	var project struct {
+		Targets []scratchTarget `json:"targets"`
+	}
+	if err := json.Unmarshal([]byte(projectJson), &project); err != nil {
+		return nil, err
+	}
+	sprites := make(map[string]scratchSprite, len(project.Targets))
+	for _, target := range project.Targets {
+		blocks := make(map[string]scratchBlock, len(target.Blocks))
+		for id, rawBlock := range target.Blocks {
+			var block scratchBlock
+			// top level variables and lists are stored as arrays and cannot be locked
+			if err := json.Unmarshal(rawBlock, &block); err != nil {
+				continue
+			}
+			blocks[id] = block
+		}
+		sprites[target.Name] = scratchSprite{
+			isStage: target.IsStage,
+			blocks:  blocks,
+		}
+	}
+	return sprites, nil
+}
+
+// isInScript reports whether the block belongs to the script with the top block
+func isInScript(blocks map[string]scratchBlock, id, topBlockId string) bool {
+	for i := 0; i <= len(blocks); i++ {
+		if id == topBlockId {
+			return true
+		}
+		block, ok := blocks[id]
+		if !ok || block.Parent == nil {
+			return false
+		}
+		id = *block.Parent
+	}
+	return false
+}
+
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
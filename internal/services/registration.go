@@ -0,0 +1,64 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"html"
+)
+
+// RegistrationService is the queue of sign ups waiting for admin approval
+// while activation by link is off
+type RegistrationService interface {
+	GetPendingRegistrations(page, pageSize *int) (requests []models.RegistrationRequestCore, countRows uint, err error)
+	ApproveRegistrations(ids []uint, reason string, clientId uint) error
+	RejectRegistrations(ids []uint, reason string, clientId uint) error
+}
+
+type RegistrationServiceImpl struct {
+	loggers                    logger.Loggers
+	registrationRequestGateway gateways.RegistrationRequestGateway
+}
+
+func (r RegistrationServiceImpl) GetPendingRegistrations(page, pageSize *int) (
+	requests []models.RegistrationRequestCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return r.registrationRequestGateway.GetPendingRegistrationRequests(offset, limit)
+}
+
+func (r RegistrationServiceImpl) ApproveRegistrations(ids []uint, reason string, clientId uint) error {
+	requests, err := r.registrationRequestGateway.ApproveRegistrationRequests(ids, reason, clientId)
+	if err != nil {
+		return err
+	}
+	body := "<p>Ваш аккаунт активирован администратором. Теперь вы можете войти на платформу.</p>"
+	if reason != "" {
+		body += "<p>Комментарий администратора: " + html.EscapeString(reason) + "</p>"
+	}
+	r.notify(requests, "Ваш аккаунт активирован", body)
+	return nil
+}
+
+func (r RegistrationServiceImpl) RejectRegistrations(ids []uint, reason string, clientId uint) error {
+	requests, err := r.registrationRequestGateway.RejectRegistrationRequests(ids, reason, clientId)
+	if err != nil {
+		return err
+	}
+	body := "<p>Администратор отклонил вашу заявку на регистрацию.</p>"
+	if reason != "" {
+		body += "<p>Причина: " + html.EscapeString(reason) + "</p>"
+	}
+	r.notify(requests, "Заявка на регистрацию отклонена", body)
+	return nil
+}
+
+// notify sends the decision to every user. The decisions are already saved,
+// so a failed email is only logged and does not fail the whole batch.
+func (r RegistrationServiceImpl) notify(requests []models.RegistrationRequestCore, subject, body string) {
+	for _, request := range requests {
+		if err := utils.SendEmail(subject, request.User.Email, body); err != nil {
+			r.loggers.Err.Printf("registration request %d: %s", request.ID, err.Error())
+		}
+	}
+}
@@ -2,41 +2,279 @@ package services
 
 import (
 	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/pubsub"
 	"go.uber.org/fx"
 )
 
+// liveEventsBufferSize is the number of live events kept for a subscriber not reading them yet
+const liveEventsBufferSize = 16
+
 type Services struct {
 	fx.Out
-	UserService        UserService
-	AuthService        AuthService
-	ProjectService     ProjectService
-	ProjectPageService ProjectPageService
-	SettingsService    SettingsService
+	UserService            UserService
+	AuthService            AuthService
+	ProjectService         ProjectService
+	ProjectPageService     ProjectPageService
+	ProjectTemplateService ProjectTemplateService
+	UserImportService      UserImportService
+	ExportService          ExportService
+	CertificateService     CertificateService
+	ContestService         ContestService
+	TeamService            TeamService
+	ClarificationService   ClarificationService
+	SettingsService        SettingsService
+	KeyService             KeyService
+	TwoFactorService       TwoFactorService
+	LoginGuardService      LoginGuardService
+	PasswordService        PasswordService
+	RegistrationService    RegistrationService
+	OidcService            OidcService
+	CourseService          CourseService
+	CohortService          CohortService
+	LtiService             LtiService
+	ParentRelService       ParentRelService
+	ParentLinkService      ParentLinkService
+	ParentDashboardService ParentDashboardService
+	UnitService            UnitService
+	GroupService           GroupService
+	AssignmentService      AssignmentService
+	NotificationService    NotificationService
 }
 
 func SetupServices(
+	loggers logger.Loggers,
 	userGateway gateways.UserGateway,
+	parentRelGateway gateways.ParentRel,
 	projectGateway gateways.ProjectGateway,
 	projectPageGateway gateways.ProjectPageGateway,
 	settingsGateway gateways.SettingsGateway,
-) Services {
+	recoveryCodeGateway gateways.RecoveryCodeGateway,
+	loginAttemptGateway gateways.LoginAttemptGateway,
+	registrationRequestGateway gateways.RegistrationRequestGateway,
+	oidcGateway gateways.OidcGateway,
+	edxGateway gateways.EdxGateway,
+	cohortGateway gateways.CohortGateway,
+	ltiGateway gateways.LtiGateway,
+	parentLinkCodeGateway gateways.ParentLinkCodeGateway,
+	activityGateway gateways.ActivityGateway,
+	unitGateway gateways.UnitGateway,
+	groupGateway gateways.GroupGateway,
+	assignmentGateway gateways.AssignmentGateway,
+	templateLockGateway gateways.TemplateLockGateway,
+	certificateGateway gateways.CertificateGateway,
+	contestGateway gateways.ContestGateway,
+	teamGateway gateways.TeamGateway,
+	clarificationGateway gateways.ClarificationGateway,
+	notificationGateway gateways.NotificationGateway,
+) (Services, error) {
+	keyService, err := NewKeyService()
+	if err != nil {
+		return Services{}, err
+	}
+	passwordService, err := NewPasswordService(settingsGateway)
+	if err != nil {
+		return Services{}, err
+	}
+	oidcService, err := NewOidcService(
+		userGateway,
+		settingsGateway,
+		oidcGateway,
+		registrationRequestGateway,
+		keyService,
+		passwordService,
+	)
+	if err != nil {
+		return Services{}, err
+	}
+	unitService := &UnitServiceImpl{
+		userGateway: userGateway,
+		unitGateway: unitGateway,
+	}
+	courseService := &CourseServiceImpl{
+		edxGateway:   edxGateway,
+		userGateway:  userGateway,
+		groupGateway: groupGateway,
+		unitService:  unitService,
+		cache:        &courseCache{courses: map[string]cachedCourse{}},
+	}
+	projectTemplateService := &ProjectTemplateServiceImpl{
+		projectPageGateway:  projectPageGateway,
+		templateLockGateway: templateLockGateway,
+	}
+	groupService := &GroupServiceImpl{
+		userGateway:        userGateway,
+		unitGateway:        unitGateway,
+		groupGateway:       groupGateway,
+		projectPageGateway: projectPageGateway,
+		unitService:        unitService,
+	}
+	notificationService := NewNotificationService(loggers, notificationGateway, userGateway)
+	parentDashboardService := &ParentDashboardServiceImpl{
+		loggers:             loggers,
+		parentRelGateway:    parentRelGateway,
+		projectPageGateway:  projectPageGateway,
+		activityGateway:     activityGateway,
+		assignmentGateway:   assignmentGateway,
+		contestGateway:      contestGateway,
+		teamGateway:         teamGateway,
+		notificationService: notificationService,
+	}
+	contestService := &ContestServiceImpl{
+		contestGateway:   contestGateway,
+		userGateway:      userGateway,
+		unitGateway:      unitGateway,
+		parentRelGateway: parentRelGateway,
+		teamGateway:      teamGateway,
+		unitService:      unitService,
+	}
+	projectPageService := &ProjectPageServiceImpl{
+		loggers:                loggers,
+		projectGateway:         projectGateway,
+		projectPageGateway:     projectPageGateway,
+		userGateway:            userGateway,
+		activityGateway:        activityGateway,
+		groupGateway:           groupGateway,
+		assignmentGateway:      assignmentGateway,
+		teamGateway:            teamGateway,
+		parentDashboardService: parentDashboardService,
+		notificationService:    notificationService,
+		unitService:            unitService,
+		projectTemplateService: projectTemplateService,
+		moderationBroker:       pubsub.NewBroker[models.ModerationEventCore](liveEventsBufferSize),
+	}
+	ltiService, err := NewLtiService(
+		userGateway,
+		settingsGateway,
+		oidcGateway,
+		ltiGateway,
+		groupGateway,
+		keyService,
+		passwordService,
+		projectPageService,
+	)
+	if err != nil {
+		return Services{}, err
+	}
+	loginGuardService := &LoginGuardServiceImpl{
+		loggers:             loggers,
+		loginAttemptGateway: loginAttemptGateway,
+	}
 	return Services{
 		UserService: &UserServiceImpl{
-			userGateway: userGateway,
+			userGateway:      userGateway,
+			parentRelGateway: parentRelGateway,
+			unitGateway:      unitGateway,
+			groupGateway:     groupGateway,
+			passwordService:  passwordService,
+			unitService:      unitService,
 		},
 		AuthService: &AuthServiceImpl{
-			userGateway:     userGateway,
-			settingsGateway: settingsGateway,
+			userGateway:                userGateway,
+			settingsGateway:            settingsGateway,
+			keyService:                 keyService,
+			loginGuardService:          loginGuardService,
+			passwordService:            passwordService,
+			registrationRequestGateway: registrationRequestGateway,
 		},
 		ProjectService: &ProjectServiceImpl{
-			projectGateway: projectGateway,
+			loggers:           loggers,
+			projectGateway:    projectGateway,
+			assignmentGateway: assignmentGateway,
+			teamGateway:       teamGateway,
+			templateService:   projectTemplateService,
+		},
+		ProjectPageService:     projectPageService,
+		ProjectTemplateService: projectTemplateService,
+		UserImportService: &UserImportServiceImpl{
+			userGateway:     userGateway,
+			unitGateway:     unitGateway,
+			settingsGateway: settingsGateway,
+			passwordService: passwordService,
 		},
-		ProjectPageService: &ProjectPageServiceImpl{
-			projectGateway:     projectGateway,
+		ExportService: &ExportServiceImpl{
+			userGateway:        userGateway,
 			projectPageGateway: projectPageGateway,
+			contestGateway:     contestGateway,
+			teamGateway:        teamGateway,
+			unitService:        unitService,
+			contestService:     contestService,
 		},
+		CertificateService: &CertificateServiceImpl{
+			certificateGateway:     certificateGateway,
+			userGateway:            userGateway,
+			teamGateway:            teamGateway,
+			unitService:            unitService,
+			contestService:         contestService,
+			parentDashboardService: parentDashboardService,
+		},
+		ContestService: contestService,
+		TeamService: &TeamServiceImpl{
+			teamGateway:            teamGateway,
+			contestGateway:         contestGateway,
+			contestService:         contestService,
+			notificationService:    notificationService,
+			parentDashboardService: parentDashboardService,
+		},
+		ClarificationService: NewClarificationService(clarificationGateway, contestGateway, contestService),
 		SettingsService: &SettingsServiceImpl{
 			settingsGateway: settingsGateway,
 		},
-	}
+		KeyService: keyService,
+		TwoFactorService: &TwoFactorServiceImpl{
+			userGateway:         userGateway,
+			settingsGateway:     settingsGateway,
+			recoveryCodeGateway: recoveryCodeGateway,
+			keyService:          keyService,
+			loginGuardService:   loginGuardService,
+		},
+		LoginGuardService: loginGuardService,
+		PasswordService:   passwordService,
+		RegistrationService: &RegistrationServiceImpl{
+			loggers:                    loggers,
+			registrationRequestGateway: registrationRequestGateway,
+		},
+		OidcService:   oidcService,
+		CourseService: courseService,
+		CohortService: &CohortServiceImpl{
+			loggers:       loggers,
+			cohortGateway: cohortGateway,
+			edxGateway:    edxGateway,
+			groupGateway:  groupGateway,
+			courseService: courseService,
+			groupService:  groupService,
+			unitService:   unitService,
+		},
+		LtiService: ltiService,
+		ParentRelService: &ParentRelServiceImpl{
+			userGateway:      userGateway,
+			parentRelGateway: parentRelGateway,
+			unitService:      unitService,
+		},
+		ParentLinkService: &ParentLinkServiceImpl{
+			loggers:               loggers,
+			userGateway:           userGateway,
+			parentLinkCodeGateway: parentLinkCodeGateway,
+			groupGateway:          groupGateway,
+			notificationService:   notificationService,
+		},
+		ParentDashboardService: parentDashboardService,
+		UnitService:            unitService,
+		GroupService:           groupService,
+		AssignmentService: &AssignmentServiceImpl{
+			loggers:                loggers,
+			assignmentGateway:      assignmentGateway,
+			groupGateway:           groupGateway,
+			projectPageGateway:     projectPageGateway,
+			activityGateway:        activityGateway,
+			groupService:           groupService,
+			projectTemplateService: projectTemplateService,
+			parentDashboardService: parentDashboardService,
+			notificationService:    notificationService,
+			ltiService:             ltiService,
+			submissionBroker:       pubsub.NewBroker[models.AssignmentSubmissionCore](liveEventsBufferSize),
+		},
+		NotificationService: notificationService,
+	}, nil
 }
@@ -1,10 +1,20 @@
 package services
 
-import "github.com/skinnykaen/rpa_clone/internal/gateways"
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+)
 
 type SettingsService interface {
 	SetActivationByLink(activationByCode bool) error
 	GetActivationByLink() (activationByCode bool, err error)
+	SetTwoFactorRequired(role models.Role, required bool) error
+	GetTwoFactorRequiredRoles() (roles []models.Role, err error)
+	GetPasswordPolicy() (policy models.PasswordPolicyCore, err error)
+	SetPasswordPolicy(policy models.PasswordPolicyCore) error
 }
 
 type SettingsServiceImpl struct {
@@ -18,3 +28,25 @@ func (s SettingsServiceImpl) SetActivationByLink(activationByCode bool) error {
 func (s SettingsServiceImpl) GetActivationByLink() (activationByCode bool, err error) {
 	return s.settingsGateway.GetActivationByLink()
 }
+
+func (s SettingsServiceImpl) SetTwoFactorRequired(role models.Role, required bool) error {
+	return s.settingsGateway.SetTwoFactorRequired(role, required)
+}
+
+func (s SettingsServiceImpl) GetTwoFactorRequiredRoles() (roles []models.Role, err error) {
+	return s.settingsGateway.GetTwoFactorRequiredRoles()
+}
+
+func (s SettingsServiceImpl) GetPasswordPolicy() (policy models.PasswordPolicyCore, err error) {
+	return s.settingsGateway.GetPasswordPolicy()
+}
+
+func (s SettingsServiceImpl) SetPasswordPolicy(policy models.PasswordPolicyCore) error {
+	if policy.MinLength < 1 {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectPasswordPolicy,
+		}
+	}
+	return s.settingsGateway.SetPasswordPolicy(policy)
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TeamService interface {
+	CreateTeam(contestId uint, name string, clientId uint) (models.TeamCore, error)
+	DeleteTeam(id, clientId uint, clientRole models.Role) error
+	GetTeamById(id, clientId uint, clientRole models.Role) (models.TeamCore, error)
+	GetTeamsByContest(contestId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		teams []models.TeamCore, countRows uint, err error)
+	GetTeamsByUser(page, pageSize *int, clientId uint) (teams []models.TeamCore, countRows uint, err error)
+	InviteToTeam(teamId, userId, clientId uint) (models.TeamInvitationCore, error)
+	AcceptInvitation(id, clientId uint) (models.TeamCore, error)
+	DeleteInvitation(id, clientId uint) error
+	GetInvitationsByTeam(teamId, clientId uint) ([]models.TeamInvitationCore, error)
+	GetInvitationsByUser(page, pageSize *int, clientId uint) (
+		invitations []models.TeamInvitationCore, countRows uint, err error)
+	RemoveMember(teamId, userId, clientId uint, clientRole models.Role) error
+	SubmitTeam(teamId, clientId uint) (models.TeamCore, error)
+	SetResult(teamId uint, score *float64, place *int, clientId uint, clientRole models.Role) (models.TeamCore, error)
+}
+
+type TeamServiceImpl struct {
+	teamGateway            gateways.TeamGateway
+	contestGateway         gateways.ContestGateway
+	contestService         ContestService
+	notificationService    NotificationService
+	parentDashboardService ParentDashboardService
+}
+
+// CreateTeam creates the team of the contest with the client as the captain and an empty shared project
+func (t TeamServiceImpl) CreateTeam(contestId uint, name string, clientId uint) (models.TeamCore, error) {
+	contest, err := t.contestService.GetContestById(contestId, clientId, models.RoleStudent)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	if strings.TrimSpace(name) == "" {
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectTeam,
+		}
+	}
+	if err := t.checkCanJoin(contest, clientId); err != nil {
+		return models.TeamCore{}, err
+	}
+	team := models.TeamCore{
+		ContestID: contestId,
+		Name:      strings.TrimSpace(name),
+		CaptainID: clientId,
+	}
+	projectPage := models.ProjectPageCore{
+		AuthorID: clientId,
+		Title:    team.Name,
+	}
+	project := models.ProjectCore{
+		AuthorID: clientId,
+		Json:     consts.EmptyProjectJson,
+	}
+	return t.teamGateway.CreateTeam(team, projectPage, project)
+}
+
+// DeleteTeam is allowed to the captain before the submission and to admins managing the contest
+func (t TeamServiceImpl) DeleteTeam(id, clientId uint, clientRole models.Role) error {
+	team, err := t.teamGateway.GetTeamById(id)
+	if err != nil {
+		return err
+	}
+	if clientRole == models.RoleStudent {
+		if err := checkTeamCaptain(team, clientId); err != nil {
+			return err
+		}
+		if err := checkTeamNotSubmitted(team); err != nil {
+			return err
+		}
+	} else if err := t.checkContestManager(team.ContestID, clientId, clientRole); err != nil {
+		return err
+	}
+	return t.teamGateway.DeleteTeam(id)
+}
+
+func (t TeamServiceImpl) GetTeamById(id, clientId uint, clientRole models.Role) (models.TeamCore, error) {
+	team, err := t.teamGateway.GetTeamById(id)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	if clientRole == models.RoleStudent {
+		if !isTeamMember(team, clientId) {
+			return models.TeamCore{}, utils.ResponseError{
+				Code:    http.StatusForbidden,
+				Message: consts.ErrAccessDenied,
+			}
+		}
+	} else if err := t.checkContestManager(team.ContestID, clientId, clientRole); err != nil {
+		return models.TeamCore{}, err
+	}
+	return team, nil
+}
+
+// GetTeamsByContest returns teams with their results to everyone having access to the contest
+func (t TeamServiceImpl) GetTeamsByContest(contestId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+	teams []models.TeamCore, countRows uint, err error) {
+	if _, err := t.contestService.GetContestById(contestId, clientId, clientRole); err != nil {
+		return []models.TeamCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return t.teamGateway.GetTeamsByContest(contestId, offset, limit)
+}
+
+func (t TeamServiceImpl) GetTeamsByUser(page, pageSize *int, clientId uint) (
+	teams []models.TeamCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return t.teamGateway.GetTeamsByUser(clientId, offset, limit)
+}
+
+// InviteToTeam invites a participant of the contest without a team, only the captain invites
+func (t TeamServiceImpl) InviteToTeam(teamId, userId, clientId uint) (models.TeamInvitationCore, error) {
+	team, err := t.teamGateway.GetTeamById(teamId)
+	if err != nil {
+		return models.TeamInvitationCore{}, err
+	}
+	if err := checkTeamCaptain(team, clientId); err != nil {
+		return models.TeamInvitationCore{}, err
+	}
+	if err := checkTeamNotSubmitted(team); err != nil {
+		return models.TeamInvitationCore{}, err
+	}
+	contest, err := t.contestGateway.GetContestById(team.ContestID)
+	if err != nil {
+		return models.TeamInvitationCore{}, err
+	}
+	// checkCanJoin also refuses contests without teams, so the team size is checked after it
+	if err := t.checkCanJoin(contest, userId); err != nil {
+		return models.TeamInvitationCore{}, err
+	}
+	if len(team.Members) >= *contest.MaxTeamSize {
+		return models.TeamInvitationCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamFull,
+		}
+	}
+	return t.teamGateway.CreateInvitation(teamId, userId)
+}
+
+func (t TeamServiceImpl) AcceptInvitation(id, clientId uint) (models.TeamCore, error) {
+	invitation, err := t.teamGateway.GetInvitationById(id)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	if invitation.UserID != clientId {
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	if err := checkTeamNotSubmitted(invitation.Team); err != nil {
+		return models.TeamCore{}, err
+	}
+	contest, err := t.contestGateway.GetContestById(invitation.Team.ContestID)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	if err := t.checkCanJoin(contest, clientId); err != nil {
+		return models.TeamCore{}, err
+	}
+	return t.teamGateway.AcceptInvitation(invitation, *contest.MaxTeamSize)
+}
+
+// DeleteInvitation declines the invitation by the invited user or cancels it by the captain
+func (t TeamServiceImpl) DeleteInvitation(id, clientId uint) error {
+	invitation, err := t.teamGateway.GetInvitationById(id)
+	if err != nil {
+		return err
+	}
+	if invitation.UserID != clientId {
+		if err := checkTeamCaptain(invitation.Team, clientId); err != nil {
+			return err
+		}
+	}
+	return t.teamGateway.DeleteInvitation(id)
+}
+
+func (t TeamServiceImpl) GetInvitationsByTeam(teamId, clientId uint) ([]models.TeamInvitationCore, error) {
+	if _, err := t.GetTeamById(teamId, clientId, models.RoleStudent); err != nil {
+		return []models.TeamInvitationCore{}, err
+	}
+	return t.teamGateway.GetInvitationsByTeam(teamId)
+}
+
+func (t TeamServiceImpl) GetInvitationsByUser(page, pageSize *int, clientId uint) (
+	invitations []models.TeamInvitationCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return t.teamGateway.GetInvitationsByUser(clientId, offset, limit)
+}
+
+// RemoveMember lets members leave the team and the captain or admins managing the contest remove members.
+// The captain stays in the team until the team is deleted.
+func (t TeamServiceImpl) RemoveMember(teamId, userId, clientId uint, clientRole models.Role) error {
+	team, err := t.teamGateway.GetTeamById(teamId)
+	if err != nil {
+		return err
+	}
+	if clientRole == models.RoleStudent {
+		if userId != clientId {
+			if err := checkTeamCaptain(team, clientId); err != nil {
+				return err
+			}
+		}
+		if err := checkTeamNotSubmitted(team); err != nil {
+			return err
+		}
+	} else if err := t.checkContestManager(team.ContestID, clientId, clientRole); err != nil {
+		return err
+	}
+	if userId == team.CaptainID {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamCaptainCannotLeave,
+		}
+	}
+	return t.teamGateway.DeleteMember(teamId, userId)
+}
+
+// SubmitTeam submits the shared project while the contest is running, the project is read only afterwards
+func (t TeamServiceImpl) SubmitTeam(teamId, clientId uint) (models.TeamCore, error) {
+	team, err := t.teamGateway.GetTeamById(teamId)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	if err := checkTeamCaptain(team, clientId); err != nil {
+		return models.TeamCore{}, err
+	}
+	if err := checkTeamNotSubmitted(team); err != nil {
+		return models.TeamCore{}, err
+	}
+	contest, err := t.contestGateway.GetContestById(team.ContestID)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	now := time.Now()
+	if now.Before(contest.StartAt) || !now.Before(contest.EndAt) {
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestNotRunning,
+		}
+	}
+	if contest.MinTeamSize != nil && len(team.Members) < *contest.MinTeamSize {
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamTooSmall,
+		}
+	}
+	return t.teamGateway.SubmitTeam(teamId)
+}
+
+func (t TeamServiceImpl) SetResult(teamId uint, score *float64, place *int, clientId uint, clientRole models.Role) (
+	models.TeamCore, error) {
+	team, err := t.teamGateway.GetTeamById(teamId)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	contest, err := t.contestGateway.GetContestById(team.ContestID)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	if err := t.contestService.CheckContestManager(contest, clientId, clientRole); err != nil {
+		return models.TeamCore{}, err
+	}
+	if (score != nil && *score < 0) || (place != nil && *place < 1) {
+		return models.TeamCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectTeamResult,
+		}
+	}
+	team, err = t.teamGateway.SetResult(teamId, score, place)
+	if err != nil {
+		return models.TeamCore{}, err
+	}
+	if score != nil || place != nil {
+		t.notifyResult(contest, team)
+	}
+	return team, nil
+}
+
+// notifyResult tells members of the team and their parents the published result
+func (t TeamServiceImpl) notifyResult(contest models.ContestCore, team models.TeamCore) {
+	var result []string
+	if team.Place != nil {
+		result = append(result, "место "+strconv.Itoa(*team.Place))
+	}
+	if team.Score != nil {
+		result = append(result, "баллы "+strconv.FormatFloat(*team.Score, 'f', -1, 64))
+	}
+	text := "Результат команды «" + team.Name + "» в конкурсе «" + contest.Title + "»: " + strings.Join(result, ", ") + "."
+	payload := map[string]string{
+		"contestId": strconv.Itoa(int(contest.ID)),
+		"teamId":    strconv.Itoa(int(team.ID)),
+	}
+	for _, member := range team.Members {
+		t.notificationService.Notify(models.NotificationCore{
+			UserID:  member.UserID,
+			Kind:    models.NotificationKindContestResultPublished,
+			Title:   "Опубликован результат конкурса",
+			Text:    text,
+			Payload: payload,
+		})
+		t.parentDashboardService.NotifyParents(member.UserID, "Опубликован результат конкурса ребёнка", text, payload)
+	}
+}
+
+func (t TeamServiceImpl) checkContestManager(contestId, clientId uint, clientRole models.Role) error {
+	contest, err := t.contestGateway.GetContestById(contestId)
+	if err != nil {
+		return err
+	}
+	return t.contestService.CheckContestManager(contest, clientId, clientRole)
+}
+
+// checkCanJoin checks that the team contest is not finished and the user is its confirmed participant without a team
+func (t TeamServiceImpl) checkCanJoin(contest models.ContestCore, userId uint) error {
+	if contest.MaxTeamSize == nil {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestNotTeamBased,
+		}
+	}
+	if !time.Now().Before(contest.EndAt) {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrContestFinished,
+		}
+	}
+	registration, err := t.contestGateway.GetRegistration(contest.ID, userId)
+	var responseError utils.ResponseError
+	if errors.As(err, &responseError) && responseError.Message == consts.ErrNotFoundInDB {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamRegistrationRequired,
+		}
+	} else if err != nil {
+		return err
+	}
+	if registration.Status != models.ContestRegistrationStatusConfirmed {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamRegistrationRequired,
+		}
+	}
+	_, err = t.teamGateway.GetTeamByMember(contest.ID, userId)
+	if err == nil {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamMemberAlreadyInTeam,
+		}
+	} else if !errors.As(err, &responseError) || responseError.Message != consts.ErrNotFoundInDB {
+		return err
+	}
+	return nil
+}
+
+func checkTeamCaptain(team models.TeamCore, clientId uint) error {
+	if team.CaptainID != clientId {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return nil
+}
+
+func checkTeamNotSubmitted(team models.TeamCore) error {
+	if team.SubmittedAt != nil {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTeamAlreadySubmitted,
+		}
+	}
+	return nil
+}
+
+func isTeamMember(team models.TeamCore, userId uint) bool {
+	for _, member := range team.Members {
+		if member.UserID == userId {
+			return true
+		}
+	}
+	return false
+}
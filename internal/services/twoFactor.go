@@ -0,0 +1,258 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"strings"
+)
+
+const recoveryCodesCount = 10
+
+type TwoFactorService interface {
+	VerifyChallenge(challengeToken, code, ip string) (Tokens, error)
+	ParseChallenge(challengeToken string) (userId uint, err error)
+	Enroll(userId uint) (secret, provisioningUri string, err error)
+	ConfirmEnrollment(userId uint, code string, signIn bool) (recoveryCodes []string, tokens Tokens, err error)
+	Disable(userId uint, code, ip string) error
+	RegenerateRecoveryCodes(userId uint, code, ip string) (recoveryCodes []string, err error)
+}
+
+type TwoFactorServiceImpl struct {
+	userGateway         gateways.UserGateway
+	settingsGateway     gateways.SettingsGateway
+	recoveryCodeGateway gateways.RecoveryCodeGateway
+	keyService          KeyService
+	loginGuardService   LoginGuardService
+}
+
+func (t TwoFactorServiceImpl) ParseChallenge(challengeToken string) (userId uint, err error) {
+	claims, err := parseToken(t.keyService, KeyPurposeChallenge, challengeToken)
+	if err != nil {
+		return 0, err
+	}
+	if claims.Purpose != consts.TokenPurposeTwoFactor {
+		return 0, utils.ResponseError{
+			Code:    http.StatusUnauthorized,
+			Message: consts.ErrNotStandardToken,
+		}
+	}
+	return claims.Id, nil
+}
+
+func (t TwoFactorServiceImpl) VerifyChallenge(challengeToken, code, ip string) (Tokens, error) {
+	userId, err := t.ParseChallenge(challengeToken)
+	if err != nil {
+		return Tokens{}, err
+	}
+	user, err := t.userGateway.GetUserById(userId)
+	if err != nil {
+		return Tokens{}, err
+	}
+	if !user.TotpEnabled {
+		return Tokens{}, utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrTwoFactorNotEnabled,
+		}
+	}
+	if err := t.guard(user.ID, user.Email, ip, func() error {
+		return t.checkCode(userId, user.TotpSecret, code)
+	}); err != nil {
+		return Tokens{}, err
+	}
+	return generateTokens(t.keyService, user)
+}
+
+func (t TwoFactorServiceImpl) Enroll(userId uint) (secret, provisioningUri string, err error) {
+	user, err := t.userGateway.GetUserById(userId)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TotpEnabled {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTwoFactorAlreadyEnabled,
+		}
+	}
+	secret, err = utils.GenerateTotpSecret()
+	if err != nil {
+		return "", "", utils.ResponseError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+	// the secret is stored disabled until the user confirms it with the first code
+	if err := t.userGateway.SetTotp(userId, secret, false); err != nil {
+		return "", "", err
+	}
+	return secret, utils.GetTotpProvisioningUri(viper.GetString("auth.two_factor.issuer"), user.Email, secret), nil
+}
+
+func (t TwoFactorServiceImpl) ConfirmEnrollment(userId uint, code string, signIn bool) (recoveryCodes []string, tokens Tokens, err error) {
+	user, err := t.userGateway.GetUserById(userId)
+	if err != nil {
+		return nil, Tokens{}, err
+	}
+	if user.TotpEnabled {
+		return nil, Tokens{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTwoFactorAlreadyEnabled,
+		}
+	}
+	if user.TotpSecret == "" {
+		return nil, Tokens{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectTwoFactorCode,
+		}
+	}
+	if err := t.useTotp(userId, user.TotpSecret, code); err != nil {
+		return nil, Tokens{}, err
+	}
+	if err := t.userGateway.SetTotp(userId, user.TotpSecret, true); err != nil {
+		return nil, Tokens{}, err
+	}
+	recoveryCodes, err = t.replaceRecoveryCodes(userId)
+	if err != nil {
+		return nil, Tokens{}, err
+	}
+	if signIn {
+		if err := t.loginGuardService.RegisterSuccess(userId); err != nil {
+			return nil, Tokens{}, err
+		}
+		user.TotpEnabled = true
+		if tokens, err = generateTokens(t.keyService, user); err != nil {
+			return nil, Tokens{}, err
+		}
+	}
+	return recoveryCodes, tokens, nil
+}
+
+func (t TwoFactorServiceImpl) Disable(userId uint, code, ip string) error {
+	user, err := t.userGateway.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	if !user.TotpEnabled {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTwoFactorNotEnabled,
+		}
+	}
+	required, err := isTwoFactorRequired(t.settingsGateway, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrTwoFactorRequired,
+		}
+	}
+	if err := t.guard(user.ID, user.Email, ip, func() error {
+		return t.checkCode(userId, user.TotpSecret, code)
+	}); err != nil {
+		return err
+	}
+	if err := t.userGateway.SetTotp(userId, "", false); err != nil {
+		return err
+	}
+	return t.recoveryCodeGateway.DeleteRecoveryCodes(userId)
+}
+
+func (t TwoFactorServiceImpl) RegenerateRecoveryCodes(userId uint, code, ip string) (recoveryCodes []string, err error) {
+	user, err := t.userGateway.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TotpEnabled {
+		return nil, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrTwoFactorNotEnabled,
+		}
+	}
+	if err := t.guard(user.ID, user.Email, ip, func() error {
+		return t.useTotp(userId, user.TotpSecret, code)
+	}); err != nil {
+		return nil, err
+	}
+	return t.replaceRecoveryCodes(userId)
+}
+
+// guard counts failed codes like failed passwords, so codes cannot be guessed
+// by a locked out user or through a stolen session
+func (t TwoFactorServiceImpl) guard(userId uint, email, ip string, verify func() error) error {
+	if err := t.loginGuardService.Check(userId, ip); err != nil {
+		return err
+	}
+	if err := verify(); err != nil {
+		if err := t.loginGuardService.RegisterFailure(userId, email, ip); err != nil {
+			return err
+		}
+		return err
+	}
+	return t.loginGuardService.RegisterSuccess(userId)
+}
+
+// checkCode accepts either a current TOTP code or an unused recovery code
+func (t TwoFactorServiceImpl) checkCode(userId uint, secret, code string) error {
+	if _, ok := utils.ValidateTotp(secret, code); ok {
+		return t.useTotp(userId, secret, code)
+	}
+	used, err := t.recoveryCodeGateway.UseRecoveryCode(userId, utils.GetSha256String(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectTwoFactorCode,
+		}
+	}
+	return nil
+}
+
+// useTotp accepts the TOTP code once, a code of the same or an earlier step is rejected even within the skew
+func (t TwoFactorServiceImpl) useTotp(userId uint, secret, code string) error {
+	incorrect := utils.ResponseError{
+		Code:    http.StatusBadRequest,
+		Message: consts.ErrIncorrectTwoFactorCode,
+	}
+	step, ok := utils.ValidateTotp(secret, code)
+	if !ok {
+		return incorrect
+	}
+	used, err := t.userGateway.UseTotpStep(userId, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return incorrect
+	}
+	return nil
+}
+
+func (t TwoFactorServiceImpl) replaceRecoveryCodes(userId uint) (recoveryCodes []string, err error) {
+	codeHashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := utils.GenerateRandomString(10)
+		if err != nil {
+			return nil, utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		code = code[:5] + "-" + code[5:]
+		recoveryCodes = append(recoveryCodes, code)
+		codeHashes = append(codeHashes, utils.GetSha256String(normalizeRecoveryCode(code)))
+	}
+	if err := t.recoveryCodeGateway.ReplaceRecoveryCodes(userId, codeHashes); err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
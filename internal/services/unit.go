@@ -0,0 +1,171 @@
+package services
+
+import (
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"net/http"
+)
+
+// UnitService manages units. Super admins manage all units, unit admins
+// see only their own units and manage only users of them.
+type UnitService interface {
+	CreateUnit(unit models.UnitCore) (models.UnitCore, error)
+	UpdateUnit(unit models.UnitCore) (models.UnitCore, error)
+	DeleteUnit(id uint) error
+	GetUnitById(id, clientId uint, clientRole models.Role) (models.UnitCore, error)
+	GetAllUnits(page, pageSize *int, clientId uint, clientRole models.Role) (units []models.UnitCore, countRows uint, err error)
+	GetUnitsByUser(userId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		units []models.UnitCore, countRows uint, err error)
+	GetUnitMembers(unitId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+		users []models.UserCore, countRows uint, err error)
+	AddMember(unitId, userId, clientId uint, clientRole models.Role) error
+	DeleteMember(unitId, userId, clientId uint, clientRole models.Role) error
+	CheckUserAccess(userId, clientId uint, clientRole models.Role) error
+	CheckUnitAccess(unitId, clientId uint, clientRole models.Role) error
+	GetClientUnitIds(clientId uint, clientRole models.Role) ([]uint, error)
+}
+
+type UnitServiceImpl struct {
+	userGateway gateways.UserGateway
+	unitGateway gateways.UnitGateway
+}
+
+func (u UnitServiceImpl) CreateUnit(unit models.UnitCore) (models.UnitCore, error) {
+	return u.unitGateway.CreateUnit(unit)
+}
+
+func (u UnitServiceImpl) UpdateUnit(unit models.UnitCore) (models.UnitCore, error) {
+	return u.unitGateway.UpdateUnit(unit)
+}
+
+func (u UnitServiceImpl) DeleteUnit(id uint) error {
+	return u.unitGateway.DeleteUnit(id)
+}
+
+func (u UnitServiceImpl) GetUnitById(id, clientId uint, clientRole models.Role) (models.UnitCore, error) {
+	if err := u.CheckUnitAccess(id, clientId, clientRole); err != nil {
+		return models.UnitCore{}, err
+	}
+	return u.unitGateway.GetUnitById(id)
+}
+
+func (u UnitServiceImpl) GetAllUnits(page, pageSize *int, clientId uint, clientRole models.Role) (
+	units []models.UnitCore, countRows uint, err error) {
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	if clientRole == models.RoleUnitAdmin {
+		return u.unitGateway.GetUnitsByUser(clientId, offset, limit)
+	}
+	return u.unitGateway.GetAllUnits(offset, limit)
+}
+
+func (u UnitServiceImpl) GetUnitsByUser(userId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+	units []models.UnitCore, countRows uint, err error) {
+	if err := u.CheckUserAccess(userId, clientId, clientRole); err != nil {
+		return []models.UnitCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return u.unitGateway.GetUnitsByUser(userId, offset, limit)
+}
+
+func (u UnitServiceImpl) GetUnitMembers(unitId uint, page, pageSize *int, clientId uint, clientRole models.Role) (
+	users []models.UserCore, countRows uint, err error) {
+	if err := u.CheckUnitAccess(unitId, clientId, clientRole); err != nil {
+		return []models.UserCore{}, 0, err
+	}
+	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
+	return u.unitGateway.GetMembers(unitId, offset, limit)
+}
+
+// AddMember adds the user to the unit. Unit admins may add to their units only users
+// already sharing a unit with them, users without units are assigned by super admins.
+func (u UnitServiceImpl) AddMember(unitId, userId, clientId uint, clientRole models.Role) error {
+	if _, err := u.unitGateway.GetUnitById(unitId); err != nil {
+		return err
+	}
+	if err := u.checkMemberChange(unitId, userId, clientId, clientRole); err != nil {
+		return err
+	}
+	if err := u.CheckUserAccess(userId, clientId, clientRole); err != nil {
+		return err
+	}
+	return u.unitGateway.AddMember(unitId, userId)
+}
+
+func (u UnitServiceImpl) DeleteMember(unitId, userId, clientId uint, clientRole models.Role) error {
+	if err := u.checkMemberChange(unitId, userId, clientId, clientRole); err != nil {
+		return err
+	}
+	return u.unitGateway.DeleteMember(unitId, userId)
+}
+
+// checkMemberChange allows unit admins to change members of their units except admins
+func (u UnitServiceImpl) checkMemberChange(unitId, userId, clientId uint, clientRole models.Role) error {
+	user, err := u.userGateway.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	if clientRole != models.RoleUnitAdmin {
+		return nil
+	}
+	if isAdmin(user.Role) {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return u.CheckUnitAccess(unitId, clientId, clientRole)
+}
+
+// CheckUserAccess denies unit admins access to users outside of their units.
+// Access of other roles is checked by the callers.
+func (u UnitServiceImpl) CheckUserAccess(userId, clientId uint, clientRole models.Role) error {
+	if clientRole != models.RoleUnitAdmin || userId == clientId {
+		return nil
+	}
+	shared, err := u.unitGateway.DoUsersShareUnit(clientId, userId)
+	if err != nil {
+		return err
+	}
+	if !shared {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return nil
+}
+
+// CheckUnitAccess denies unit admins access to units they are not members of
+func (u UnitServiceImpl) CheckUnitAccess(unitId, clientId uint, clientRole models.Role) error {
+	if clientRole != models.RoleUnitAdmin {
+		return nil
+	}
+	isMember, err := u.unitGateway.IsMember(unitId, clientId)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return nil
+}
+
+// GetClientUnitIds returns units of the unit admin, nil ids mean that the client is not limited by units
+func (u UnitServiceImpl) GetClientUnitIds(clientId uint, clientRole models.Role) ([]uint, error) {
+	if clientRole != models.RoleUnitAdmin {
+		return nil, nil
+	}
+	unitIds, err := u.unitGateway.GetUnitIdsByUser(clientId)
+	if err != nil {
+		return nil, err
+	}
+	if unitIds == nil {
+		unitIds = []uint{}
+	}
+	return unitIds, nil
+}
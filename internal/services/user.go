@@ -6,28 +6,63 @@ import (
 	"github.com/skinnykaen/rpa_clone/internal/models"
 	"github.com/skinnykaen/rpa_clone/pkg/utils"
 	"net/http"
+	"time"
+)
+
+// school grades allowed in user profiles
+const (
+	minGrade = 1
+	maxGrade = 11
 )
 
 type UserService interface {
-	CreateUser(user models.UserCore, clientRole models.Role) (newUser models.UserCore, err error)
-	DeleteUser(id uint) error
-	UpdateUser(user models.UserCore, clientRole models.Role) (updatedUser models.UserCore, err error)
-	GetUserById(id uint, clientRole models.Role) (models.UserCore, error)
-	GetAllUsers(page, pageSize *int, isActive bool, role []models.Role, clientRole models.Role) (users []models.UserCore, countRows uint, err error)
-	SetIsActive(id uint, isActive bool) error
+	CreateUser(user models.UserCore, unitId *uint, clientId uint, clientRole models.Role) (newUser models.UserCore, err error)
+	DeleteUser(id, clientId uint, clientRole models.Role) error
+	UpdateUser(user models.UserCore, clientId uint, clientRole models.Role) (updatedUser models.UserCore, err error)
+	GetUserById(id, clientId uint, clientRole models.Role) (models.UserCore, error)
+	GetAllUsers(page, pageSize *int, isActive bool, role []models.Role, clientId uint, clientRole models.Role) (users []models.UserCore, countRows uint, err error)
+	SetIsActive(id uint, isActive bool, clientId uint, clientRole models.Role) error
 }
 
 type UserServiceImpl struct {
-	userGateway gateways.UserGateway
+	userGateway      gateways.UserGateway
+	parentRelGateway gateways.ParentRel
+	unitGateway      gateways.UnitGateway
+	groupGateway     gateways.GroupGateway
+	passwordService  PasswordService
+	unitService      UnitService
 }
 
-func (u UserServiceImpl) SetIsActive(id uint, isActive bool) error {
+func (u UserServiceImpl) SetIsActive(id uint, isActive bool, clientId uint, clientRole models.Role) error {
+	if err := u.checkUnitAdminAccess(id, clientId, clientRole); err != nil {
+		return err
+	}
 	return u.userGateway.SetIsActive(id, isActive)
 }
 
+// checkUnitAdminAccess allows unit admins to manage only themselves and non-admin users of their units
+func (u UserServiceImpl) checkUnitAdminAccess(id, clientId uint, clientRole models.Role) error {
+	if clientRole != models.RoleUnitAdmin || id == clientId {
+		return nil
+	}
+	user, err := u.userGateway.GetUserById(id)
+	if err != nil {
+		return err
+	}
+	if isAdmin(user.Role) {
+		return utils.ResponseError{
+			Code:    http.StatusForbidden,
+			Message: consts.ErrAccessDenied,
+		}
+	}
+	return u.unitService.CheckUserAccess(id, clientId, clientRole)
+}
+
 // TODO use gorm hooks beforeCreate for example for check client role
 
-func (u UserServiceImpl) CreateUser(user models.UserCore, clientRole models.Role) (newUser models.UserCore, err error) {
+// CreateUser creates the user and adds the user to the unit. Unit admins must choose one of their units.
+func (u UserServiceImpl) CreateUser(user models.UserCore, unitId *uint, clientId uint, clientRole models.Role) (
+	newUser models.UserCore, err error) {
 	// TODO сразу активен? надо ли высылать код
 	// checking the client role for the possibility of creating a user
 	if (clientRole == models.RoleUnitAdmin && user.Role.String() == models.RoleUnitAdmin.String()) ||
@@ -37,6 +72,23 @@ func (u UserServiceImpl) CreateUser(user models.UserCore, clientRole models.Role
 			Message: consts.ErrAccessDenied,
 		}
 	}
+	if unitId == nil && clientRole == models.RoleUnitAdmin {
+		return models.UserCore{}, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrUnitRequired,
+		}
+	}
+	if unitId != nil {
+		if _, err := u.unitGateway.GetUnitById(*unitId); err != nil {
+			return models.UserCore{}, err
+		}
+		if err := u.unitService.CheckUnitAccess(*unitId, clientId, clientRole); err != nil {
+			return models.UserCore{}, err
+		}
+	}
+	if err := validateUserProfile(user); err != nil {
+		return models.UserCore{}, err
+	}
 
 	exist, err := u.userGateway.DoesExistEmail(0, user.Email)
 	if err != nil {
@@ -48,59 +100,76 @@ func (u UserServiceImpl) CreateUser(user models.UserCore, clientRole models.Role
 			Message: consts.ErrEmailAlreadyInUse,
 		}
 	}
-	if len(user.Password) < 6 {
-		return models.UserCore{}, utils.ResponseError{
-			Code:    http.StatusBadRequest,
-			Message: consts.ErrShortPassword,
-		}
+	if err := checkNickname(u.userGateway, 0, user.Nickname); err != nil {
+		return models.UserCore{}, err
+	}
+	if err := u.passwordService.ValidatePassword(user.Password); err != nil {
+		return models.UserCore{}, err
+	}
+	passwordHash, err := u.passwordService.HashPassword(user.Password)
+	if err != nil {
+		return models.UserCore{}, err
 	}
-	passwordHash := utils.HashPassword(user.Password)
 	user.Password = passwordHash
+	if unitId != nil {
+		return u.userGateway.CreateUnitUser(user, *unitId)
+	}
 	return u.userGateway.CreateUser(user)
 }
 
-func (u UserServiceImpl) DeleteUser(id uint) error {
+func (u UserServiceImpl) DeleteUser(id, clientId uint, clientRole models.Role) error {
+	if err := u.checkUnitAdminAccess(id, clientId, clientRole); err != nil {
+		return err
+	}
 	// TODO maybe check userById and role
 	// TODO delete projects
 	// delete all rels (projectPage, project, etc.)
 	return u.userGateway.DeleteUser(id)
 }
 
-func (u UserServiceImpl) UpdateUser(user models.UserCore, clientRole models.Role) (updatedUser models.UserCore, err error) {
+func (u UserServiceImpl) UpdateUser(user models.UserCore, clientId uint, clientRole models.Role) (updatedUser models.UserCore, err error) {
 	// TODO check client role
 	// какие роли кого могут обновлять
 	exist, err := u.userGateway.DoesExistEmail(user.ID, user.Email)
 	if err != nil {
 		return models.UserCore{}, err
 	}
+	// the role of the updated user is not a part of the input and is taken from the database
+	target, err := u.userGateway.GetUserById(user.ID)
+	if err != nil {
+		return models.UserCore{}, err
+	}
 	// checking the client role for the possibility of updating a user
 	switch clientRole {
 	case models.RoleUnitAdmin:
-		if user.Role.String() == models.RoleSuperAdmin.String() {
+		if target.Role.String() == models.RoleSuperAdmin.String() {
 			return models.UserCore{}, utils.ResponseError{
 				Code:    http.StatusForbidden,
 				Message: consts.ErrAccessDenied,
 			}
 		}
-	case models.RoleTeacher:
-		if user.Role.String() != models.RoleTeacher.String() {
+		if err := u.checkUnitAdminAccess(user.ID, clientId, clientRole); err != nil {
+			return models.UserCore{}, err
+		}
+	case models.RoleTeacher, models.RoleStudent:
+		if target.ID != clientId {
 			return models.UserCore{}, utils.ResponseError{
 				Code:    http.StatusForbidden,
 				Message: consts.ErrAccessDenied,
 			}
 		}
 	case models.RoleParent:
-		if user.Role.String() != models.RoleParent.String() {
-			return models.UserCore{}, utils.ResponseError{
-				Code:    http.StatusForbidden,
-				Message: consts.ErrAccessDenied,
+		// parents fill in profiles of their children, e.g. the birth date and the grade
+		if target.ID != clientId {
+			isChild, err := u.parentRelGateway.DoesExistRel(clientId, target.ID)
+			if err != nil {
+				return models.UserCore{}, err
 			}
-		}
-	case models.RoleStudent:
-		if user.Role.String() != models.RoleStudent.String() {
-			return models.UserCore{}, utils.ResponseError{
-				Code:    http.StatusForbidden,
-				Message: consts.ErrAccessDenied,
+			if !isChild {
+				return models.UserCore{}, utils.ResponseError{
+					Code:    http.StatusForbidden,
+					Message: consts.ErrAccessDenied,
+				}
 			}
 		}
 	}
@@ -110,10 +179,16 @@ func (u UserServiceImpl) UpdateUser(user models.UserCore, clientRole models.Role
 			Message: consts.ErrEmailAlreadyInUse,
 		}
 	}
+	if err := checkNickname(u.userGateway, user.ID, user.Nickname); err != nil {
+		return models.UserCore{}, err
+	}
+	if err := validateUserProfile(user); err != nil {
+		return models.UserCore{}, err
+	}
 	return u.userGateway.UpdateUser(user)
 }
 
-func (u UserServiceImpl) GetUserById(id uint, clientRole models.Role) (models.UserCore, error) {
+func (u UserServiceImpl) GetUserById(id, clientId uint, clientRole models.Role) (models.UserCore, error) {
 	user, err := u.userGateway.GetUserById(id)
 	if err != nil {
 		return models.UserCore{}, err
@@ -121,15 +196,30 @@ func (u UserServiceImpl) GetUserById(id uint, clientRole models.Role) (models.Us
 	// checking the client role for the possibility of getting a user
 	switch clientRole {
 	case models.RoleParent:
-		if user.Role.String() != models.RoleStudent.String() {
+		// parents may get only themselves and their own children
+		if id == clientId {
+			break
+		}
+		isChild, err := u.parentRelGateway.DoesExistRel(clientId, id)
+		if err != nil {
+			return models.UserCore{}, err
+		}
+		if !isChild {
 			return models.UserCore{}, utils.ResponseError{
 				Code:    http.StatusForbidden,
 				Message: consts.ErrAccessDenied,
 			}
 		}
 	case models.RoleTeacher:
-		if user.Role.String() == models.RoleUnitAdmin.String() ||
-			user.Role.String() == models.RoleSuperAdmin.String() {
+		// teachers may get only themselves, students of their groups and parents of the students
+		if id == clientId {
+			break
+		}
+		isTaught, err := u.groupGateway.DoesTeachUser(clientId, id)
+		if err != nil {
+			return models.UserCore{}, err
+		}
+		if !isTaught {
 			return models.UserCore{}, utils.ResponseError{
 				Code:    http.StatusForbidden,
 				Message: consts.ErrAccessDenied,
@@ -142,6 +232,9 @@ func (u UserServiceImpl) GetUserById(id uint, clientRole models.Role) (models.Us
 				Message: consts.ErrAccessDenied,
 			}
 		}
+		if err := u.unitService.CheckUserAccess(id, clientId, clientRole); err != nil {
+			return models.UserCore{}, err
+		}
 	}
 	return user, nil
 }
@@ -150,6 +243,7 @@ func (u UserServiceImpl) GetAllUsers(
 	page, pageSize *int,
 	isActive bool,
 	roles []models.Role,
+	clientId uint,
 	clientRole models.Role,
 ) (users []models.UserCore, countRows uint, err error) {
 	// checking the client role for the possibility of getting a users
@@ -164,6 +258,62 @@ func (u UserServiceImpl) GetAllUsers(
 			}
 		}
 	}
+	unitIds, err := u.unitService.GetClientUnitIds(clientId, clientRole)
+	if err != nil {
+		return []models.UserCore{}, 0, err
+	}
 	offset, limit := utils.GetOffsetAndLimit(page, pageSize)
-	return u.userGateway.GetAllUsers(offset, limit, isActive, roles)
+	return u.userGateway.GetAllUsers(offset, limit, isActive, roles, unitIds)
+}
+
+// checkNickname refuses nicknames of other users, users sign in by nickname
+func checkNickname(userGateway gateways.UserGateway, id uint, nickname string) error {
+	if nickname == "" {
+		return nil
+	}
+	exist, err := userGateway.DoesExistNickname(id, nickname)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrNicknameAlreadyInUse,
+		}
+	}
+	return nil
+}
+
+// getUniqueNickname returns the nickname if it is free, otherwise the nickname with a random suffix
+func getUniqueNickname(userGateway gateways.UserGateway, nickname string) (string, error) {
+	candidate := nickname
+	for length := 3; ; length++ {
+		exist, err := userGateway.DoesExistNickname(0, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exist {
+			return candidate, nil
+		}
+		suffix, err := utils.GenerateRandomString(length)
+		if err != nil {
+			return "", utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		candidate = nickname + suffix
+	}
+}
+
+// validateUserProfile checks the birth date and the school grade used by contest eligibility rules
+func validateUserProfile(user models.UserCore) error {
+	if (user.BirthDate != nil && user.BirthDate.After(time.Now())) ||
+		(user.Grade != nil && (*user.Grade < minGrade || *user.Grade > maxGrade)) {
+		return utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectUserProfile,
+		}
+	}
+	return nil
 }
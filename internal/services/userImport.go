@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/gateways"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type UserImportService interface {
+	ImportUsers(rows [][]string, clientId uint, clientRole models.Role) (
+		users []models.ImportedUserCore, rowErrors []models.ImportRowErrorCore, err error)
+}
+
+type UserImportServiceImpl struct {
+	userGateway     gateways.UserGateway
+	unitGateway     gateways.UnitGateway
+	settingsGateway gateways.SettingsGateway
+	passwordService PasswordService
+}
+
+const (
+	importColumnName  = "name"
+	importColumnClass = "class"
+	importColumnUnit  = "unit"
+	importColumnEmail = "email"
+)
+
+// importColumns maps accepted column names of the first row to the columns
+var importColumns = map[string]string{
+	"name":        importColumnName,
+	"фио":         importColumnName,
+	"class":       importColumnClass,
+	"класс":       importColumnClass,
+	"unit":        importColumnUnit,
+	"организация": importColumnUnit,
+	"email":       importColumnEmail,
+	"e-mail":      importColumnEmail,
+	"почта":       importColumnEmail,
+}
+
+var notNicknameSymbols = regexp.MustCompile("[^a-z0-9]")
+
+// ImportUsers creates active students from the rows of the file, the first row names the columns.
+// Nothing is created if any row is incorrect, all incorrect rows are reported.
+// Unit admins import only into their units and may omit the unit if they have only one.
+func (u UserImportServiceImpl) ImportUsers(rows [][]string, clientId uint, clientRole models.Role) (
+	users []models.ImportedUserCore, rowErrors []models.ImportRowErrorCore, err error) {
+	if len(rows) < 2 {
+		return nil, nil, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrEmptyUserImport,
+		}
+	}
+	if maxRows := viper.GetInt("user_import.max_rows"); len(rows)-1 > maxRows {
+		return nil, nil, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf(consts.ErrTooManyImportRows, maxRows),
+		}
+	}
+	columns := make(map[string]int)
+	for i, cell := range rows[0] {
+		if column, ok := importColumns[strings.ToLower(strings.TrimSpace(cell))]; ok {
+			columns[column] = i
+		}
+	}
+	if _, ok := columns[importColumnName]; !ok {
+		return nil, nil, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectImportHeader,
+		}
+	}
+	units, err := u.getClientUnits(clientId, clientRole)
+	if err != nil {
+		return nil, nil, err
+	}
+	emails := make(map[string]struct{})
+	nicknames := make(map[string]struct{})
+	for i, row := range rows[1:] {
+		cell := func(column string) string {
+			index, ok := columns[column]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		// rows are numbered like in the spreadsheet, the first row is the header
+		rowNumber := i + 2
+		user, message, err := u.parseRow(cell(importColumnName), cell(importColumnEmail), emails)
+		if err != nil {
+			return nil, nil, err
+		}
+		unit, unitMessage := findImportUnit(units, cell(importColumnUnit))
+		if message == "" {
+			message = unitMessage
+		}
+		if message != "" {
+			rowErrors = append(rowErrors, models.ImportRowErrorCore{Row: rowNumber, Message: message})
+			continue
+		}
+		user.Nickname, err = u.generateNickname(user, nicknames)
+		if err != nil {
+			return nil, nil, err
+		}
+		users = append(users, models.ImportedUserCore{
+			Row:   rowNumber,
+			User:  user,
+			Unit:  unit,
+			Class: cell(importColumnClass),
+		})
+	}
+	if len(rowErrors) > 0 {
+		return nil, rowErrors, nil
+	}
+	if len(users) == 0 {
+		return nil, nil, utils.ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrEmptyUserImport,
+		}
+	}
+	policy, err := u.settingsGateway.GetPasswordPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+	// the policy may require longer passwords than configured for the import
+	passwordLength := viper.GetInt("user_import.password_length")
+	if passwordLength < policy.MinLength {
+		passwordLength = policy.MinLength
+	}
+	for i := range users {
+		password, err := utils.GenerateRandomPassword(passwordLength)
+		if err != nil {
+			return nil, nil, utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		if err := u.passwordService.ValidatePassword(password); err != nil {
+			return nil, nil, err
+		}
+		users[i].Password = password
+		users[i].User.Password, err = u.passwordService.HashPassword(password)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	users, err = u.userGateway.ImportUsers(users)
+	if err != nil {
+		return nil, nil, err
+	}
+	return users, nil, nil
+}
+
+// parseRow returns the student or the message explaining why the row is incorrect
+func (u UserImportServiceImpl) parseRow(name, email string, emails map[string]struct{}) (
+	user models.UserCore, message string, err error) {
+	names := strings.Fields(name)
+	if len(names) < 2 {
+		return models.UserCore{}, consts.ErrIncorrectImportName, nil
+	}
+	user = models.UserCore{
+		Role:       models.RoleStudent,
+		Lastname:   names[0],
+		Firstname:  names[1],
+		Middlename: strings.Join(names[2:], " "),
+		IsActive:   true,
+	}
+	if email == "" {
+		return user, "", nil
+	}
+	if !utils.IsValidEmail(email) {
+		return models.UserCore{}, consts.ErrIncorrectImportEmail, nil
+	}
+	if _, ok := emails[strings.ToLower(email)]; ok {
+		return models.UserCore{}, consts.ErrEmailAlreadyInUse, nil
+	}
+	exist, err := u.userGateway.DoesExistEmail(0, email)
+	if err != nil {
+		return models.UserCore{}, "", err
+	}
+	if exist {
+		return models.UserCore{}, consts.ErrEmailAlreadyInUse, nil
+	}
+	emails[strings.ToLower(email)] = struct{}{}
+	user.Email = email
+	return user, "", nil
+}
+
+// generateNickname returns a nickname made of the transliterated name and a random suffix,
+// the nickname is unique among existing users and users of the import
+func (u UserImportServiceImpl) generateNickname(user models.UserCore, nicknames map[string]struct{}) (string, error) {
+	base := strings.ToLower(utils.Transliterate(user.Lastname))
+	if firstname := []rune(strings.ToLower(utils.Transliterate(user.Firstname))); len(firstname) > 0 {
+		base += string(firstname[0])
+	}
+	base = notNicknameSymbols.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+	for length := 3; ; length++ {
+		suffix, err := utils.GenerateRandomString(length)
+		if err != nil {
+			return "", utils.ResponseError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		nickname := base + suffix
+		if _, ok := nicknames[nickname]; ok {
+			continue
+		}
+		exist, err := u.userGateway.DoesExistNickname(0, nickname)
+		if err != nil {
+			return "", err
+		}
+		if !exist {
+			nicknames[nickname] = struct{}{}
+			return nickname, nil
+		}
+	}
+}
+
+func (u UserImportServiceImpl) getClientUnits(clientId uint, clientRole models.Role) ([]models.UnitCore, error) {
+	if clientRole == models.RoleUnitAdmin {
+		units, _, err := u.unitGateway.GetUnitsByUser(clientId, 0, -1)
+		return units, err
+	}
+	units, _, err := u.unitGateway.GetAllUnits(0, -1)
+	return units, err
+}
+
+// findImportUnit finds the unit by its name or id, the only unit available to the client may be omitted
+func findImportUnit(units []models.UnitCore, unit string) (models.UnitCore, string) {
+	if unit == "" {
+		if len(units) == 1 {
+			return units[0], ""
+		}
+		return models.UnitCore{}, consts.ErrUnitRequired
+	}
+	for _, u := range units {
+		if strings.EqualFold(u.Name, unit) || strconv.Itoa(int(u.ID)) == unit {
+			return u, ""
+		}
+	}
+	return models.UnitCore{}, consts.ErrImportUnitNotFound
+}
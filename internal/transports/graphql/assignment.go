@@ -0,0 +1,317 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CreateAssignment is the resolver for the CreateAssignment field.
+func (r *mutationResolver) CreateAssignment(ctx context.Context, input models.NewAssignment) (*models.AssignmentHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.GroupID, input.TemplateProjectPageID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	deadline, err := utils.ParseTimestamp(input.Deadline)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	assignment, err := r.assignmentService.CreateAssignment(models.AssignmentCore{
+		GroupID:               ids[0],
+		TemplateProjectPageID: ids[1],
+		Title:                 input.Title,
+		Instruction:           input.Instruction,
+		Deadline:              deadline,
+		LatePolicy:            input.LatePolicy,
+		LatePenalty:           utils.IntPointerToInt(input.LatePenalty),
+		MaxGrade:              input.MaxGrade,
+	}, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	assignmentHttp := models.AssignmentHTTP{}
+	assignmentHttp.FromCore(assignment)
+	return &assignmentHttp, nil
+}
+
+// UpdateAssignment is the resolver for the UpdateAssignment field.
+func (r *mutationResolver) UpdateAssignment(ctx context.Context, input models.UpdateAssignment) (*models.AssignmentHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.ID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	deadline, err := utils.ParseTimestamp(input.Deadline)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	assignment, err := r.assignmentService.UpdateAssignment(models.AssignmentCore{
+		ID:          ids[0],
+		Title:       input.Title,
+		Instruction: input.Instruction,
+		Deadline:    deadline,
+		LatePolicy:  input.LatePolicy,
+		LatePenalty: utils.IntPointerToInt(input.LatePenalty),
+		MaxGrade:    input.MaxGrade,
+	}, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	assignmentHttp := models.AssignmentHTTP{}
+	assignmentHttp.FromCore(assignment)
+	return &assignmentHttp, nil
+}
+
+// DeleteAssignment is the resolver for the DeleteAssignment field.
+func (r *mutationResolver) DeleteAssignment(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.assignmentService.DeleteAssignment(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// StartAssignment is the resolver for the StartAssignment field.
+func (r *mutationResolver) StartAssignment(ctx context.Context, assignmentID string) (*models.AssignmentSubmissionHTTP, error) {
+	ids, err := utils.ParseIds([]string{assignmentID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	submission, err := r.assignmentService.StartAssignment(ids[0], ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	submissionHttp := models.AssignmentSubmissionHTTP{}
+	submissionHttp.FromCore(submission)
+	return &submissionHttp, nil
+}
+
+// SubmitAssignment is the resolver for the SubmitAssignment field.
+func (r *mutationResolver) SubmitAssignment(ctx context.Context, assignmentID string) (*models.AssignmentSubmissionHTTP, error) {
+	ids, err := utils.ParseIds([]string{assignmentID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	submission, err := r.assignmentService.SubmitAssignment(ids[0], ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	submissionHttp := models.AssignmentSubmissionHTTP{}
+	submissionHttp.FromCore(submission)
+	return &submissionHttp, nil
+}
+
+// ReviewAssignmentSubmission is the resolver for the ReviewAssignmentSubmission field.
+func (r *mutationResolver) ReviewAssignmentSubmission(ctx context.Context, id string, grade int, feedback string) (*models.AssignmentSubmissionHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	submission, err := r.assignmentService.ReviewSubmission(ctx, ids[0], grade, feedback, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	submissionHttp := models.AssignmentSubmissionHTTP{}
+	submissionHttp.FromCore(submission)
+	return &submissionHttp, nil
+}
+
+// GetAssignmentByID is the resolver for the GetAssignmentById field.
+func (r *queryResolver) GetAssignmentByID(ctx context.Context, id string) (*models.AssignmentHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	assignment, err := r.assignmentService.GetAssignmentById(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	assignmentHttp := models.AssignmentHTTP{}
+	assignmentHttp.FromCore(assignment)
+	return &assignmentHttp, nil
+}
+
+// GetAssignmentsByGroup is the resolver for the GetAssignmentsByGroup field.
+func (r *queryResolver) GetAssignmentsByGroup(ctx context.Context, groupID string, page *int, pageSize *int) (*models.AssignmentHTTPList, error) {
+	ids, err := utils.ParseIds([]string{groupID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	assignments, countRows, err := r.assignmentService.GetAssignmentsByGroup(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.AssignmentHTTPList{
+		Assignments: models.FromAssignmentsCore(assignments),
+		CountRows:   int(countRows),
+	}, nil
+}
+
+// GetAssignmentSubmissions is the resolver for the GetAssignmentSubmissions field.
+func (r *queryResolver) GetAssignmentSubmissions(ctx context.Context, assignmentID string, page *int, pageSize *int) (*models.AssignmentSubmissionHTTPList, error) {
+	ids, err := utils.ParseIds([]string{assignmentID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	submissions, countRows, err := r.assignmentService.GetSubmissionsByAssignment(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.AssignmentSubmissionHTTPList{
+		AssignmentSubmissions: models.FromAssignmentSubmissionsCore(submissions),
+		CountRows:             int(countRows),
+	}, nil
+}
+
+// GetAssignmentSubmissionsByAccessToken is the resolver for the GetAssignmentSubmissionsByAccessToken field.
+func (r *queryResolver) GetAssignmentSubmissionsByAccessToken(ctx context.Context, page *int, pageSize *int) (*models.AssignmentSubmissionHTTPList, error) {
+	submissions, countRows, err := r.assignmentService.GetSubmissionsByStudent(page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.AssignmentSubmissionHTTPList{
+		AssignmentSubmissions: models.FromAssignmentSubmissionsCore(submissions),
+		CountRows:             int(countRows),
+	}, nil
+}
+
+// SubmissionStatusChanged is the resolver for the SubmissionStatusChanged field.
+func (r *subscriptionResolver) SubmissionStatusChanged(ctx context.Context, assignmentID string) (<-chan *models.AssignmentSubmissionHTTP, error) {
+	ids, err := utils.ParseIds([]string{assignmentID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	submissions, unsubscribe, err := r.assignmentService.SubscribeSubmissions(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return forwardEvents(ctx, submissions, unsubscribe, func(submission models.AssignmentSubmissionCore) *models.AssignmentSubmissionHTTP {
+		var submissionHttp models.AssignmentSubmissionHTTP
+		submissionHttp.FromCore(submission)
+		return &submissionHttp
+	}), nil
+}
@@ -6,11 +6,9 @@ package resolvers
 
 import (
 	"context"
-	"time"
 
 	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/models"
-	"github.com/skinnykaen/rpa_clone/pkg/utils"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
@@ -22,17 +20,16 @@ func (r *mutationResolver) SignUp(ctx context.Context, input models.SignUp) (*mo
 		middlename = *input.Middlename
 	}
 	newUser := models.UserCore{
-		Email:          input.Email,
-		Password:       input.Password,
-		Firstname:      input.Firstname,
-		Lastname:       input.Lastname,
-		Middlename:     middlename,
-		Nickname:       input.Nickname,
-		Role:           models.RoleStudent,
-		IsActive:       false,
-		ActivationLink: utils.GetHashString(time.Now().String()),
+		Email:      input.Email,
+		Password:   input.Password,
+		Firstname:  input.Firstname,
+		Lastname:   input.Lastname,
+		Middlename: middlename,
+		Nickname:   input.Nickname,
+		Role:       models.RoleStudent,
+		IsActive:   false,
 	}
-	err := r.authService.SignUp(newUser)
+	err := r.authService.SignUp(newUser, ctx.Value(consts.KeyIp).(string), ctx.Value(consts.KeyUserAgent).(string))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return &models.Response{Ok: false}, &gqlerror.Error{
@@ -46,7 +43,7 @@ func (r *mutationResolver) SignUp(ctx context.Context, input models.SignUp) (*mo
 
 // SignIn is the resolver for the SignIn field.
 func (r *mutationResolver) SignIn(ctx context.Context, input models.SignIn) (*models.SignInResponse, error) {
-	tokens, err := r.authService.SignIn(input.Email, input.Password)
+	result, err := r.authService.SignIn(input.Email, input.Password, ctx.Value(consts.KeyIp).(string))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return &models.SignInResponse{}, &gqlerror.Error{
@@ -56,8 +53,11 @@ func (r *mutationResolver) SignIn(ctx context.Context, input models.SignIn) (*mo
 		}
 	}
 	return &models.SignInResponse{
-		AccessToken:  tokens.Access,
-		RefreshToken: tokens.Refresh,
+		AccessToken:                 result.Tokens.Access,
+		RefreshToken:                result.Tokens.Refresh,
+		TwoFactorRequired:           result.TwoFactorRequired,
+		TwoFactorEnrollmentRequired: result.TwoFactorEnrollmentRequired,
+		ChallengeToken:              result.ChallengeToken,
 	}, nil
 }
 
@@ -79,7 +79,7 @@ func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string
 
 // ConfirmActivation is the resolver for the ConfirmActivation field.
 func (r *mutationResolver) ConfirmActivation(ctx context.Context, activationLink string) (*models.SignInResponse, error) {
-	tokens, err := r.authService.ConfirmActivation(activationLink)
+	result, err := r.authService.ConfirmActivation(activationLink)
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return &models.SignInResponse{}, &gqlerror.Error{
@@ -89,14 +89,43 @@ func (r *mutationResolver) ConfirmActivation(ctx context.Context, activationLink
 		}
 	}
 	return &models.SignInResponse{
-		AccessToken:  tokens.Access,
-		RefreshToken: tokens.Refresh,
+		AccessToken:                 result.Tokens.Access,
+		RefreshToken:                result.Tokens.Refresh,
+		TwoFactorRequired:           result.TwoFactorRequired,
+		TwoFactorEnrollmentRequired: result.TwoFactorEnrollmentRequired,
+		ChallengeToken:              result.ChallengeToken,
 	}, nil
 }
 
+// ResendActivation is the resolver for the ResendActivation field.
+func (r *mutationResolver) ResendActivation(ctx context.Context, email string) (*models.Response, error) {
+	if err := r.authService.ResendActivation(email); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return &models.Response{Ok: false}, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// UnlockAccount is the resolver for the UnlockAccount field.
+func (r *mutationResolver) UnlockAccount(ctx context.Context, unlockToken string) (*models.Response, error) {
+	if err := r.loginGuardService.UnlockAccount(unlockToken); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return &models.Response{Ok: false}, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
 // Me is the resolver for the Me field.
 func (r *queryResolver) Me(ctx context.Context) (*models.UserHTTP, error) {
-	user, err := r.userService.GetUserById(ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	user, err := r.userService.GetUserById(ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
@@ -109,3 +138,20 @@ func (r *queryResolver) Me(ctx context.Context) (*models.UserHTTP, error) {
 	userHttp.FromCore(user)
 	return &userHttp, nil
 }
+
+// GetLockoutEvents is the resolver for the GetLockoutEvents field.
+func (r *queryResolver) GetLockoutEvents(ctx context.Context, page *int, pageSize *int) (*models.LockoutEventHTTPList, error) {
+	events, countRows, err := r.loginGuardService.GetLockoutEvents(page, pageSize)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.LockoutEventHTTPList{
+		LockoutEvents: models.FromLockoutEventsCore(events),
+		CountRows:     int(countRows),
+	}, nil
+}
@@ -0,0 +1,297 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CreateCertificateTemplate is the resolver for the CreateCertificateTemplate field.
+func (r *mutationResolver) CreateCertificateTemplate(ctx context.Context, input models.NewCertificateTemplate) (*models.CertificateTemplateHTTP, error) {
+	fieldsCore := make([]models.CertificateFieldCore, 0, len(input.Fields))
+	for _, field := range input.Fields {
+		fieldsCore = append(fieldsCore, models.CertificateFieldCore{
+			Text:     field.Text,
+			X:        field.X,
+			Y:        field.Y,
+			FontSize: field.FontSize,
+			Align:    field.Align,
+		})
+	}
+	templateCore := models.CertificateTemplateCore{
+		Name:       input.Name,
+		Background: input.Background,
+		Width:      input.Width,
+		Height:     input.Height,
+		QrX:        input.QRX,
+		QrY:        input.QRY,
+		QrSize:     input.QRSize,
+		Fields:     fieldsCore,
+	}
+	newTemplate, err := r.certificateService.CreateTemplate(templateCore, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var templateHttp models.CertificateTemplateHTTP
+	templateHttp.FromCore(newTemplate)
+	return &templateHttp, nil
+}
+
+// UpdateCertificateTemplate is the resolver for the UpdateCertificateTemplate field.
+func (r *mutationResolver) UpdateCertificateTemplate(ctx context.Context, input models.UpdateCertificateTemplate) (*models.CertificateTemplateHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.ID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	fieldsCore := make([]models.CertificateFieldCore, 0, len(input.Fields))
+	for _, field := range input.Fields {
+		fieldsCore = append(fieldsCore, models.CertificateFieldCore{
+			Text:     field.Text,
+			X:        field.X,
+			Y:        field.Y,
+			FontSize: field.FontSize,
+			Align:    field.Align,
+		})
+	}
+	templateCore := models.CertificateTemplateCore{
+		ID:         ids[0],
+		Name:       input.Name,
+		Background: input.Background,
+		Width:      input.Width,
+		Height:     input.Height,
+		QrX:        input.QRX,
+		QrY:        input.QRY,
+		QrSize:     input.QRSize,
+		Fields:     fieldsCore,
+	}
+	newTemplate, err := r.certificateService.UpdateTemplate(templateCore, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var templateHttp models.CertificateTemplateHTTP
+	templateHttp.FromCore(newTemplate)
+	return &templateHttp, nil
+}
+
+// DeleteCertificateTemplate is the resolver for the DeleteCertificateTemplate field.
+func (r *mutationResolver) DeleteCertificateTemplate(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.certificateService.DeleteTemplate(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// IssueCertificates is the resolver for the IssueCertificates field.
+func (r *mutationResolver) IssueCertificates(ctx context.Context, templateID string, certificates []*models.NewCertificate) ([]*models.CertificateHTTP, error) {
+	ids, err := utils.ParseIds([]string{templateID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	certificatesCore := make([]models.CertificateCore, 0, len(certificates))
+	for _, certificate := range certificates {
+		userIds, err := utils.ParseIds([]string{certificate.UserID})
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		certificateCore := models.CertificateCore{
+			UserID: userIds[0],
+			Place:  certificate.Place,
+			Score:  certificate.Score,
+		}
+		if certificate.ContestID != nil {
+			contestIds, err := utils.ParseIds([]string{*certificate.ContestID})
+			if err != nil {
+				r.loggers.Err.Printf("%s", err.Error())
+				return nil, &gqlerror.Error{
+					Extensions: map[string]interface{}{
+						"err": err,
+					},
+				}
+			}
+			certificateCore.ContestID = &contestIds[0]
+		}
+		if certificate.Contest != nil {
+			certificateCore.Contest = *certificate.Contest
+		}
+		certificatesCore = append(certificatesCore, certificateCore)
+	}
+	newCertificates, err := r.certificateService.IssueCertificates(ids[0], certificatesCore, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return models.FromCertificatesCore(newCertificates), nil
+}
+
+// RevokeCertificate is the resolver for the RevokeCertificate field.
+func (r *mutationResolver) RevokeCertificate(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.certificateService.RevokeCertificate(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// GetCertificateTemplateByID is the resolver for the GetCertificateTemplateById field.
+func (r *queryResolver) GetCertificateTemplateByID(ctx context.Context, id string) (*models.CertificateTemplateHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	template, err := r.certificateService.GetTemplateById(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var templateHttp models.CertificateTemplateHTTP
+	templateHttp.FromCore(template)
+	return &templateHttp, nil
+}
+
+// GetCertificateTemplates is the resolver for the GetCertificateTemplates field.
+func (r *queryResolver) GetCertificateTemplates(ctx context.Context, page *int, pageSize *int) (*models.CertificateTemplateHTTPList, error) {
+	templates, countRows, err := r.certificateService.GetTemplates(page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.CertificateTemplateHTTPList{
+		CertificateTemplates: models.FromCertificateTemplatesCore(templates),
+		CountRows:            int(countRows),
+	}, nil
+}
+
+// GetCertificatesByTemplate is the resolver for the GetCertificatesByTemplate field.
+func (r *queryResolver) GetCertificatesByTemplate(ctx context.Context, templateID string, page *int, pageSize *int) (*models.CertificateHTTPList, error) {
+	ids, err := utils.ParseIds([]string{templateID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	certificates, countRows, err := r.certificateService.GetCertificatesByTemplate(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.CertificateHTTPList{
+		Certificates: models.FromCertificatesCore(certificates),
+		CountRows:    int(countRows),
+	}, nil
+}
+
+// GetCertificatesByAccessToken is the resolver for the GetCertificatesByAccessToken field.
+func (r *queryResolver) GetCertificatesByAccessToken(ctx context.Context, page *int, pageSize *int) (*models.CertificateHTTPList, error) {
+	certificates, countRows, err := r.certificateService.GetCertificatesByUser(page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.CertificateHTTPList{
+		Certificates: models.FromCertificatesCore(certificates),
+		CountRows:    int(countRows),
+	}, nil
+}
+
+// VerifyCertificate is the resolver for the VerifyCertificate field.
+func (r *queryResolver) VerifyCertificate(ctx context.Context, code string) (*models.CertificatePublicHTTP, error) {
+	certificate, err := r.certificateService.VerifyCertificate(code)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var certificatePublicHttp models.CertificatePublicHTTP
+	certificatePublicHttp.FromCore(certificate)
+	return &certificatePublicHttp, nil
+}
@@ -0,0 +1,187 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/graph"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// AskClarification is the resolver for the AskClarification field.
+func (r *mutationResolver) AskClarification(ctx context.Context, contestID string, taskID *string, question string) (*models.ClarificationHTTP, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var taskId *uint
+	if taskID != nil {
+		taskIds, err := utils.ParseIds([]string{*taskID})
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		taskId = &taskIds[0]
+	}
+	clarification, err := r.clarificationService.AskClarification(ids[0], taskId, question, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var clarificationHttp models.ClarificationHTTP
+	clarificationHttp.FromCore(clarification)
+	return &clarificationHttp, nil
+}
+
+// AnswerClarification is the resolver for the AnswerClarification field.
+func (r *mutationResolver) AnswerClarification(ctx context.Context, id string, answer string, isPublic bool) (*models.ClarificationHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	clarification, err := r.clarificationService.AnswerClarification(ids[0], answer, isPublic, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var clarificationHttp models.ClarificationHTTP
+	clarificationHttp.FromCore(clarification)
+	return &clarificationHttp, nil
+}
+
+// PostAnnouncement is the resolver for the PostAnnouncement field.
+func (r *mutationResolver) PostAnnouncement(ctx context.Context, contestID string, text string) (*models.AnnouncementHTTP, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	announcement, err := r.clarificationService.PostAnnouncement(ids[0], text, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var announcementHttp models.AnnouncementHTTP
+	announcementHttp.FromCore(announcement)
+	return &announcementHttp, nil
+}
+
+// GetClarifications is the resolver for the GetClarifications field.
+func (r *queryResolver) GetClarifications(ctx context.Context, contestID string, page *int, pageSize *int) (*models.ClarificationHTTPList, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	clarifications, countRows, err := r.clarificationService.GetClarifications(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.ClarificationHTTPList{
+		Clarifications: models.FromClarificationsCore(clarifications),
+		CountRows:      int(countRows),
+	}, nil
+}
+
+// GetAnnouncements is the resolver for the GetAnnouncements field.
+func (r *queryResolver) GetAnnouncements(ctx context.Context, contestID string, page *int, pageSize *int) (*models.AnnouncementHTTPList, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	announcements, countRows, err := r.clarificationService.GetAnnouncements(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.AnnouncementHTTPList{
+		Announcements: models.FromAnnouncementsCore(announcements),
+		CountRows:     int(countRows),
+	}, nil
+}
+
+// ContestEvents is the resolver for the ContestEvents field.
+func (r *subscriptionResolver) ContestEvents(ctx context.Context, contestID string) (<-chan *models.ContestEventHTTP, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	events, unsubscribe, err := r.clarificationService.SubscribeContestEvents(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return forwardEvents(ctx, events, unsubscribe, func(event models.ContestEventCore) *models.ContestEventHTTP {
+		var eventHttp models.ContestEventHTTP
+		eventHttp.FromCore(event)
+		return &eventHttp
+	}), nil
+}
+
+// Subscription returns graph.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() graph.SubscriptionResolver { return &subscriptionResolver{r} }
+
+type subscriptionResolver struct{ *Resolver }
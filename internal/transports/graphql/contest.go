@@ -0,0 +1,520 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CreateContest is the resolver for the CreateContest field.
+func (r *mutationResolver) CreateContest(ctx context.Context, input models.NewContest) (*models.ContestHTTP, error) {
+	var unitId *uint
+	if input.UnitID != nil {
+		ids, err := utils.ParseIds([]string{*input.UnitID})
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		unitId = &ids[0]
+	}
+	startAt, err := utils.ParseTimestamp(input.StartAt)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	endAt, err := utils.ParseTimestamp(input.EndAt)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	categoriesCore := make([]models.ContestAgeCategoryCore, 0, len(input.AgeCategories))
+	for _, category := range input.AgeCategories {
+		categoriesCore = append(categoriesCore, models.ContestAgeCategoryCore{
+			Name:   category.Name,
+			MinAge: category.MinAge,
+			MaxAge: category.MaxAge,
+		})
+	}
+	contestCore := models.ContestCore{
+		UnitID:         unitId,
+		Title:          input.Title,
+		Description:    utils.StringPointerToString(input.Description),
+		StartAt:        startAt,
+		EndAt:          endAt,
+		MinAge:         input.MinAge,
+		MaxAge:         input.MaxAge,
+		MinGrade:       input.MinGrade,
+		MaxGrade:       input.MaxGrade,
+		InvitationOnly: input.InvitationOnly,
+		Capacity:       input.Capacity,
+		MinTeamSize:    input.MinTeamSize,
+		MaxTeamSize:    input.MaxTeamSize,
+		AgeCategories:  categoriesCore,
+	}
+	newContest, err := r.contestService.CreateContest(contestCore, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var contestHttp models.ContestHTTP
+	contestHttp.FromCore(newContest)
+	return &contestHttp, nil
+}
+
+// UpdateContest is the resolver for the UpdateContest field.
+func (r *mutationResolver) UpdateContest(ctx context.Context, input models.UpdateContest) (*models.ContestHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.ID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	startAt, err := utils.ParseTimestamp(input.StartAt)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	endAt, err := utils.ParseTimestamp(input.EndAt)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	categoriesCore := make([]models.ContestAgeCategoryCore, 0, len(input.AgeCategories))
+	for _, category := range input.AgeCategories {
+		categoriesCore = append(categoriesCore, models.ContestAgeCategoryCore{
+			Name:   category.Name,
+			MinAge: category.MinAge,
+			MaxAge: category.MaxAge,
+		})
+	}
+	contestCore := models.ContestCore{
+		ID:             ids[0],
+		Title:          input.Title,
+		Description:    input.Description,
+		StartAt:        startAt,
+		EndAt:          endAt,
+		MinAge:         input.MinAge,
+		MaxAge:         input.MaxAge,
+		MinGrade:       input.MinGrade,
+		MaxGrade:       input.MaxGrade,
+		InvitationOnly: input.InvitationOnly,
+		Capacity:       input.Capacity,
+		MinTeamSize:    input.MinTeamSize,
+		MaxTeamSize:    input.MaxTeamSize,
+		AgeCategories:  categoriesCore,
+	}
+	newContest, err := r.contestService.UpdateContest(contestCore, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var contestHttp models.ContestHTTP
+	contestHttp.FromCore(newContest)
+	return &contestHttp, nil
+}
+
+// DeleteContest is the resolver for the DeleteContest field.
+func (r *mutationResolver) DeleteContest(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.contestService.DeleteContest(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// CreateContestTask is the resolver for the CreateContestTask field.
+func (r *mutationResolver) CreateContestTask(ctx context.Context, input models.NewContestTask) (*models.ContestTaskHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.ContestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	task, err := r.contestService.CreateTask(models.ContestTaskCore{
+		ContestID: ids[0],
+		Position:  input.Position,
+		Title:     input.Title,
+		Statement: input.Statement,
+	}, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var taskHttp models.ContestTaskHTTP
+	taskHttp.FromCore(task)
+	return &taskHttp, nil
+}
+
+// UpdateContestTask is the resolver for the UpdateContestTask field.
+func (r *mutationResolver) UpdateContestTask(ctx context.Context, input models.UpdateContestTask) (*models.ContestTaskHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.ID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	task, err := r.contestService.UpdateTask(models.ContestTaskCore{
+		ID:        ids[0],
+		Position:  input.Position,
+		Title:     input.Title,
+		Statement: input.Statement,
+	}, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var taskHttp models.ContestTaskHTTP
+	taskHttp.FromCore(task)
+	return &taskHttp, nil
+}
+
+// DeleteContestTask is the resolver for the DeleteContestTask field.
+func (r *mutationResolver) DeleteContestTask(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.contestService.DeleteTask(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// InviteToContest is the resolver for the InviteToContest field.
+func (r *mutationResolver) InviteToContest(ctx context.Context, contestID string, userIds []string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	userIdsCore, err := utils.ParseIds(userIds)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.contestService.InviteToContest(ids[0], userIdsCore, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RemoveContestInvitation is the resolver for the RemoveContestInvitation field.
+func (r *mutationResolver) RemoveContestInvitation(ctx context.Context, contestID string, userID string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{contestID, userID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.contestService.RemoveInvitation(ids[0], ids[1], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RegisterForContest is the resolver for the RegisterForContest field.
+func (r *mutationResolver) RegisterForContest(ctx context.Context, contestID string) (*models.ContestRegistrationHTTP, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	registration, err := r.contestService.Register(ids[0], ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var registrationHttp models.ContestRegistrationHTTP
+	registrationHttp.FromCore(registration)
+	return &registrationHttp, nil
+}
+
+// CancelContestRegistration is the resolver for the CancelContestRegistration field.
+func (r *mutationResolver) CancelContestRegistration(ctx context.Context, contestID string, userID *string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	// without the user the client cancels the own registration
+	userId := ctx.Value(consts.KeyId).(uint)
+	if userID != nil {
+		userIds, err := utils.ParseIds([]string{*userID})
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		userId = userIds[0]
+	}
+	if err := r.contestService.CancelRegistration(ids[0], userId, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// GiveContestConsent is the resolver for the GiveContestConsent field.
+func (r *mutationResolver) GiveContestConsent(ctx context.Context, registrationID string) (*models.ContestRegistrationHTTP, error) {
+	ids, err := utils.ParseIds([]string{registrationID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	registration, err := r.contestService.GiveConsent(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var registrationHttp models.ContestRegistrationHTTP
+	registrationHttp.FromCore(registration)
+	return &registrationHttp, nil
+}
+
+// GetContestByID is the resolver for the GetContestById field.
+func (r *queryResolver) GetContestByID(ctx context.Context, id string) (*models.ContestHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	contest, err := r.contestService.GetContestById(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var contestHttp models.ContestHTTP
+	contestHttp.FromCore(contest)
+	return &contestHttp, nil
+}
+
+// GetContests is the resolver for the GetContests field.
+func (r *queryResolver) GetContests(ctx context.Context, page *int, pageSize *int) (*models.ContestHTTPList, error) {
+	contests, countRows, err := r.contestService.GetContests(page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.ContestHTTPList{
+		Contests:  models.FromContestsCore(contests),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetContestTasks is the resolver for the GetContestTasks field.
+func (r *queryResolver) GetContestTasks(ctx context.Context, contestID string) ([]*models.ContestTaskHTTP, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	tasks, err := r.contestService.GetTasks(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return models.FromContestTasksCore(tasks), nil
+}
+
+// GetContestRegistrations is the resolver for the GetContestRegistrations field.
+func (r *queryResolver) GetContestRegistrations(ctx context.Context, contestID string, status *models.ContestRegistrationStatus, page *int, pageSize *int) (*models.ContestRegistrationHTTPList, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	registrations, countRows, err := r.contestService.GetRegistrationsByContest(ids[0], status, page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.ContestRegistrationHTTPList{
+		ContestRegistrations: models.FromContestRegistrationsCore(registrations),
+		CountRows:            int(countRows),
+	}, nil
+}
+
+// GetContestRegistrationsByAccessToken is the resolver for the GetContestRegistrationsByAccessToken field.
+func (r *queryResolver) GetContestRegistrationsByAccessToken(ctx context.Context, page *int, pageSize *int) (*models.ContestRegistrationHTTPList, error) {
+	registrations, countRows, err := r.contestService.GetRegistrationsByUser(page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.ContestRegistrationHTTPList{
+		ContestRegistrations: models.FromContestRegistrationsCore(registrations),
+		CountRows:            int(countRows),
+	}, nil
+}
+
+// GetContestRegistrationsAwaitingConsent is the resolver for the GetContestRegistrationsAwaitingConsent field.
+func (r *queryResolver) GetContestRegistrationsAwaitingConsent(ctx context.Context, page *int, pageSize *int) (*models.ContestRegistrationHTTPList, error) {
+	registrations, countRows, err := r.contestService.GetRegistrationsAwaitingConsent(page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.ContestRegistrationHTTPList{
+		ContestRegistrations: models.FromContestRegistrationsCore(registrations),
+		CountRows:            int(countRows),
+	}, nil
+}
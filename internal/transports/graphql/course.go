@@ -6,17 +6,174 @@ package resolvers
 
 import (
 	"context"
-	"fmt"
+	"net/http"
+	"strconv"
 
+	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
+// EnrollOnCourse is the resolver for the EnrollOnCourse field.
+func (r *mutationResolver) EnrollOnCourse(ctx context.Context, courseID string) (*models.Response, error) {
+	if err := r.courseService.EnrollUser(ctx.Value(consts.KeyId).(uint), courseID); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// EnrollUsersOnCourse is the resolver for the EnrollUsersOnCourse field.
+func (r *mutationResolver) EnrollUsersOnCourse(ctx context.Context, courseID string, userIds []string) ([]*models.EnrollmentResultHTTP, error) {
+	ids, err := utils.ParseIds(userIds)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	results := r.courseService.EnrollUsers(courseID, ids,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	return models.FromEnrollmentResultsCore(results), nil
+}
+
+// CreateCohort is the resolver for the CreateCohort field.
+func (r *mutationResolver) CreateCohort(ctx context.Context, courseID string, groupID string, name string) (*models.CohortHTTP, error) {
+	atoi, err := strconv.Atoi(groupID)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": utils.ResponseError{
+					Code:    http.StatusBadRequest,
+					Message: consts.ErrAtoi,
+				},
+			},
+		}
+	}
+	cohort, err := r.cohortService.CreateCohort(courseID, uint(atoi), name,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	cohortHttp := models.CohortHTTP{}
+	cohortHttp.FromCore(cohort)
+	return &cohortHttp, nil
+}
+
+// AddUsersToCohort is the resolver for the AddUsersToCohort field.
+func (r *mutationResolver) AddUsersToCohort(ctx context.Context, cohortID string, userIds []string) ([]*models.EnrollmentResultHTTP, error) {
+	atoi, err := strconv.Atoi(cohortID)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": utils.ResponseError{
+					Code:    http.StatusBadRequest,
+					Message: consts.ErrAtoi,
+				},
+			},
+		}
+	}
+	ids, err := utils.ParseIds(userIds)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	results, err := r.cohortService.AddUsersToCohort(uint(atoi), ids,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return models.FromEnrollmentResultsCore(results), nil
+}
+
 // GetCourseByID is the resolver for the GetCourseById field.
 func (r *queryResolver) GetCourseByID(ctx context.Context, id string) (*models.CourseHTTP, error) {
-	panic(fmt.Errorf("not implemented: GetCourseByID - GetCourseById"))
+	course, err := r.courseService.GetCourseById(id)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	courseHttp := models.CourseHTTP{}
+	courseHttp.FromCore(course)
+	return &courseHttp, nil
 }
 
 // GetCoursesByUser is the resolver for the GetCoursesByUser field.
 func (r *queryResolver) GetCoursesByUser(ctx context.Context) (*models.CoursesListHTTP, error) {
-	panic(fmt.Errorf("not implemented: GetCoursesByUser - GetCoursesByUser"))
+	courses, countRows, err := r.courseService.GetCoursesByUser(ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.CoursesListHTTP{
+		Courses:   models.FromCoursesCore(courses),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetCohortsByCourse is the resolver for the GetCohortsByCourse field.
+func (r *queryResolver) GetCohortsByCourse(ctx context.Context, courseID string) (*models.CohortHTTPList, error) {
+	cohorts, countRows, err := r.cohortService.GetCohortsByCourse(courseID,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.CohortHTTPList{
+		Cohorts:   models.FromCohortsCore(cohorts),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetCohortMismatches is the resolver for the GetCohortMismatches field.
+func (r *queryResolver) GetCohortMismatches(ctx context.Context, page *int, pageSize *int) (*models.CohortMismatchHTTPList, error) {
+	mismatches, countRows, err := r.cohortService.GetCohortMismatches(page, pageSize,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.CohortMismatchHTTPList{
+		CohortMismatches: models.FromCohortMismatchesCore(mismatches),
+		CountRows:        int(countRows),
+	}, nil
 }
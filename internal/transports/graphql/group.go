@@ -0,0 +1,328 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CreateGroup is the resolver for the CreateGroup field.
+func (r *mutationResolver) CreateGroup(ctx context.Context, input models.NewGroup) (*models.GroupHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.UnitID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	group, err := r.groupService.CreateGroup(models.GroupCore{
+		UnitID:      ids[0],
+		Name:        input.Name,
+		Description: utils.StringPointerToString(input.Description),
+	}, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	groupHttp := models.GroupHTTP{}
+	groupHttp.FromCore(group)
+	return &groupHttp, nil
+}
+
+// UpdateGroup is the resolver for the UpdateGroup field.
+func (r *mutationResolver) UpdateGroup(ctx context.Context, input models.UpdateGroup) (*models.GroupHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.ID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	group, err := r.groupService.UpdateGroup(models.GroupCore{
+		ID:          ids[0],
+		Name:        input.Name,
+		Description: input.Description,
+	}, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	groupHttp := models.GroupHTTP{}
+	groupHttp.FromCore(group)
+	return &groupHttp, nil
+}
+
+// DeleteGroup is the resolver for the DeleteGroup field.
+func (r *mutationResolver) DeleteGroup(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.groupService.DeleteGroup(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// AddTeacherToGroup is the resolver for the AddTeacherToGroup field.
+func (r *mutationResolver) AddTeacherToGroup(ctx context.Context, groupID string, teacherID string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{groupID, teacherID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.groupService.AddTeacher(ids[0], ids[1], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RemoveTeacherFromGroup is the resolver for the RemoveTeacherFromGroup field.
+func (r *mutationResolver) RemoveTeacherFromGroup(ctx context.Context, groupID string, teacherID string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{groupID, teacherID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.groupService.DeleteTeacher(ids[0], ids[1], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// AddStudentsToGroup is the resolver for the AddStudentsToGroup field.
+func (r *mutationResolver) AddStudentsToGroup(ctx context.Context, groupID string, studentIds []string) (*models.Response, error) {
+	ids, err := utils.ParseIds(append([]string{groupID}, studentIds...))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.groupService.AddStudents(ids[0], ids[1:], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RemoveStudentFromGroup is the resolver for the RemoveStudentFromGroup field.
+func (r *mutationResolver) RemoveStudentFromGroup(ctx context.Context, groupID string, studentID string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{groupID, studentID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.groupService.DeleteStudent(ids[0], ids[1], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// GetGroupByID is the resolver for the GetGroupById field.
+func (r *queryResolver) GetGroupByID(ctx context.Context, id string) (*models.GroupHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	group, err := r.groupService.GetGroupById(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	groupHttp := models.GroupHTTP{}
+	groupHttp.FromCore(group)
+	return &groupHttp, nil
+}
+
+// GetGroupsByUnit is the resolver for the GetGroupsByUnit field.
+func (r *queryResolver) GetGroupsByUnit(ctx context.Context, unitID string, page *int, pageSize *int) (*models.GroupHTTPList, error) {
+	ids, err := utils.ParseIds([]string{unitID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	groups, countRows, err := r.groupService.GetGroupsByUnit(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.GroupHTTPList{
+		Groups:    models.FromGroupsCore(groups),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetGroupsByAccessToken is the resolver for the GetGroupsByAccessToken field.
+func (r *queryResolver) GetGroupsByAccessToken(ctx context.Context, page *int, pageSize *int) (*models.GroupHTTPList, error) {
+	groups, countRows, err := r.groupService.GetGroupsByClient(page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.GroupHTTPList{
+		Groups:    models.FromGroupsCore(groups),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetGroupTeachers is the resolver for the GetGroupTeachers field.
+func (r *queryResolver) GetGroupTeachers(ctx context.Context, groupID string) (*models.UsersList, error) {
+	ids, err := utils.ParseIds([]string{groupID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	users, countRows, err := r.groupService.GetGroupTeachers(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.UsersList{
+		Users:     models.FromUsersCore(users),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetGroupStudents is the resolver for the GetGroupStudents field.
+func (r *queryResolver) GetGroupStudents(ctx context.Context, groupID string, page *int, pageSize *int) (*models.UsersList, error) {
+	ids, err := utils.ParseIds([]string{groupID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	users, countRows, err := r.groupService.GetGroupStudents(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.UsersList{
+		Users:     models.FromUsersCore(users),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetGroupProjectPages is the resolver for the GetGroupProjectPages field.
+func (r *queryResolver) GetGroupProjectPages(ctx context.Context, groupID string, page *int, pageSize *int) (*models.ProjectPageHTTPList, error) {
+	ids, err := utils.ParseIds([]string{groupID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	projectPages, countRows, err := r.groupService.GetGroupProjectPages(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.ProjectPageHTTPList{
+		ProjectPages: models.FromProjectPagesCore(projectPages),
+		CountRows:    int(countRows),
+	}, nil
+}
@@ -0,0 +1,99 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// LtiSignIn is the resolver for the LtiSignIn field.
+func (r *mutationResolver) LtiSignIn(ctx context.Context, code string) (*models.LtiLaunchHTTP, error) {
+	launch, result, err := r.ltiService.SignIn(code)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	launchHttp := models.LtiLaunchHTTP{}
+	launchHttp.FromCore(launch)
+	launchHttp.SignIn = &models.SignInResponse{
+		AccessToken:                 result.Tokens.Access,
+		RefreshToken:                result.Tokens.Refresh,
+		TwoFactorRequired:           result.TwoFactorRequired,
+		TwoFactorEnrollmentRequired: result.TwoFactorEnrollmentRequired,
+		ChallengeToken:              result.ChallengeToken,
+	}
+	return &launchHttp, nil
+}
+
+// CreateLtiDeepLinkingResponse is the resolver for the CreateLtiDeepLinkingResponse field.
+func (r *mutationResolver) CreateLtiDeepLinkingResponse(ctx context.Context, launchID string, projectPageIds []string) (*models.LtiDeepLinkingFormHTTP, error) {
+	launchIds, err := utils.ParseIds([]string{launchID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	ids, err := utils.ParseIds(projectPageIds)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	returnUrl, message, err := r.ltiService.CreateDeepLinkingResponse(launchIds[0], ids,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.LtiDeepLinkingFormHTTP{
+		URL: returnUrl,
+		Jwt: message,
+	}, nil
+}
+
+// PushLtiScore is the resolver for the PushLtiScore field.
+func (r *mutationResolver) PushLtiScore(ctx context.Context, gradeLinkID string, userID string, scoreGiven float64, scoreMaximum float64, comment *string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{gradeLinkID, userID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var commentValue string
+	if comment != nil {
+		commentValue = *comment
+	}
+	if err := r.ltiService.PushScore(ctx, ids[0], ids[1], scoreGiven, scoreMaximum, commentValue, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
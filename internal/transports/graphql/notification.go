@@ -0,0 +1,115 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// MarkNotificationsRead is the resolver for the MarkNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (*models.Response, error) {
+	notificationIds, err := utils.ParseIds(ids)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.notificationService.MarkRead(notificationIds, ctx.Value(consts.KeyId).(uint)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// MarkAllNotificationsRead is the resolver for the MarkAllNotificationsRead field.
+func (r *mutationResolver) MarkAllNotificationsRead(ctx context.Context) (*models.Response, error) {
+	if err := r.notificationService.MarkAllRead(ctx.Value(consts.KeyId).(uint)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// UpdateNotificationPreference is the resolver for the UpdateNotificationPreference field.
+func (r *mutationResolver) UpdateNotificationPreference(ctx context.Context, input models.UpdateNotificationPreference) (*models.NotificationPreferenceHTTP, error) {
+	preference, err := r.notificationService.UpdatePreference(models.NotificationPreferenceCore{
+		Kind:  input.Kind,
+		InApp: input.InApp,
+		Email: input.Email,
+	}, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var preferenceHttp models.NotificationPreferenceHTTP
+	preferenceHttp.FromCore(preference)
+	return &preferenceHttp, nil
+}
+
+// GetNotifications is the resolver for the GetNotifications field.
+func (r *queryResolver) GetNotifications(ctx context.Context, unreadOnly *bool, page *int, pageSize *int) (*models.NotificationHTTPList, error) {
+	var onlyUnread bool
+	if unreadOnly != nil {
+		onlyUnread = *unreadOnly
+	}
+	notifications, countRows, countUnread, err := r.notificationService.GetNotifications(onlyUnread, page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.NotificationHTTPList{
+		Notifications: models.FromNotificationsCore(notifications),
+		CountRows:     int(countRows),
+		CountUnread:   int(countUnread),
+	}, nil
+}
+
+// GetNotificationPreferences is the resolver for the GetNotificationPreferences field.
+func (r *queryResolver) GetNotificationPreferences(ctx context.Context) ([]*models.NotificationPreferenceHTTP, error) {
+	preferences, err := r.notificationService.GetPreferences(ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return models.FromNotificationPreferencesCore(preferences), nil
+}
+
+// Notifications is the resolver for the Notifications field.
+func (r *subscriptionResolver) Notifications(ctx context.Context) (<-chan *models.NotificationHTTP, error) {
+	notifications, unsubscribe := r.notificationService.SubscribeNotifications(ctx.Value(consts.KeyId).(uint))
+	return forwardEvents(ctx, notifications, unsubscribe, func(notification models.NotificationCore) *models.NotificationHTTP {
+		var notificationHttp models.NotificationHTTP
+		notificationHttp.FromCore(notification)
+		return &notificationHttp
+	}), nil
+}
@@ -0,0 +1,72 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/spf13/viper"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// StartOidcSignIn is the resolver for the StartOidcSignIn field.
+func (r *mutationResolver) StartOidcSignIn(ctx context.Context, provider string) (string, error) {
+	authUrl, browserBinding, err := r.oidcService.GetAuthUrl(ctx, provider)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return "", &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if w, ok := ctx.Value(consts.KeyResponseWriter).(http.ResponseWriter); ok {
+		http.SetCookie(w, &http.Cookie{
+			Name:     consts.OidcBindingCookie,
+			Value:    browserBinding,
+			Path:     "/",
+			MaxAge:   int(viper.GetDuration("oidc.state_ttl").Seconds()),
+			Secure:   viper.GetBool("oidc.cookie_secure"),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return authUrl, nil
+}
+
+// OidcSignIn is the resolver for the OidcSignIn field.
+func (r *mutationResolver) OidcSignIn(ctx context.Context, state string, code string) (*models.SignInResponse, error) {
+	var browserBinding string
+	if request, ok := ctx.Value(consts.KeyRequest).(*http.Request); ok {
+		if cookie, err := request.Cookie(consts.OidcBindingCookie); err == nil {
+			browserBinding = cookie.Value
+		}
+	}
+	result, err := r.oidcService.SignIn(ctx, state, code, browserBinding,
+		ctx.Value(consts.KeyIp).(string), ctx.Value(consts.KeyUserAgent).(string))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return &models.SignInResponse{}, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.SignInResponse{
+		AccessToken:                 result.Tokens.Access,
+		RefreshToken:                result.Tokens.Refresh,
+		TwoFactorRequired:           result.TwoFactorRequired,
+		TwoFactorEnrollmentRequired: result.TwoFactorEnrollmentRequired,
+		ChallengeToken:              result.ChallengeToken,
+	}, nil
+}
+
+// GetOidcProviders is the resolver for the GetOidcProviders field.
+func (r *queryResolver) GetOidcProviders(ctx context.Context) ([]string, error) {
+	return r.oidcService.GetProviders(), nil
+}
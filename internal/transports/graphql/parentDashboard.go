@@ -0,0 +1,27 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// GetParentDashboard is the resolver for the GetParentDashboard field.
+func (r *queryResolver) GetParentDashboard(ctx context.Context) ([]*models.ChildProgressHTTP, error) {
+	dashboard, err := r.parentDashboardService.GetDashboard(ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return models.FromChildrenProgressCore(dashboard), nil
+}
@@ -0,0 +1,147 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CreateParentLinkCode is the resolver for the CreateParentLinkCode field.
+func (r *mutationResolver) CreateParentLinkCode(ctx context.Context, childID *string, requireApproval bool) (*models.CreatedParentLinkCode, error) {
+	var childId *uint
+	if childID != nil {
+		ids, err := utils.ParseIds([]string{*childID})
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		childId = &ids[0]
+	}
+	code, codeCore, err := r.parentLinkService.CreateCode(childId, requireApproval,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	codeHttp := models.ParentLinkCodeHTTP{}
+	codeHttp.FromCore(codeCore)
+	return &models.CreatedParentLinkCode{
+		Code:           code,
+		ParentLinkCode: &codeHttp,
+	}, nil
+}
+
+// RevokeParentLinkCode is the resolver for the RevokeParentLinkCode field.
+func (r *mutationResolver) RevokeParentLinkCode(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.parentLinkService.RevokeCode(ids[0],
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RedeemParentLinkCode is the resolver for the RedeemParentLinkCode field.
+func (r *mutationResolver) RedeemParentLinkCode(ctx context.Context, code string) (*models.ParentLinkCodeHTTP, error) {
+	codeCore, err := r.parentLinkService.RedeemCode(code, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	codeHttp := models.ParentLinkCodeHTTP{}
+	codeHttp.FromCore(codeCore)
+	return &codeHttp, nil
+}
+
+// ApproveParentLink is the resolver for the ApproveParentLink field.
+func (r *mutationResolver) ApproveParentLink(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.parentLinkService.ApproveLink(ids[0], ctx.Value(consts.KeyId).(uint)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RejectParentLink is the resolver for the RejectParentLink field.
+func (r *mutationResolver) RejectParentLink(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.parentLinkService.RejectLink(ids[0], ctx.Value(consts.KeyId).(uint)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// GetParentLinkCodes is the resolver for the GetParentLinkCodes field.
+func (r *queryResolver) GetParentLinkCodes(ctx context.Context, page *int, pageSize *int) (*models.ParentLinkCodeHTTPList, error) {
+	codes, countRows, err := r.parentLinkService.GetCodes(page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.ParentLinkCodeHTTPList{
+		ParentLinkCodes: models.FromParentLinkCodesCore(codes),
+		CountRows:       int(countRows),
+	}, nil
+}
@@ -6,27 +6,109 @@ package resolvers
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // CreateParentRel is the resolver for the CreateParentRel field.
 func (r *mutationResolver) CreateParentRel(ctx context.Context, parentID string, childID string) (*models.Response, error) {
-	panic(fmt.Errorf("not implemented: CreateParentRel - CreateParentRel"))
+	ids, err := utils.ParseIds([]string{parentID, childID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.parentRelService.CreateParentRel(ids[0], ids[1],
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
 }
 
 // DeleteParentRel is the resolver for the DeleteParentRel field.
 func (r *mutationResolver) DeleteParentRel(ctx context.Context, parentID string, childID string) (*models.Response, error) {
-	panic(fmt.Errorf("not implemented: DeleteParentRel - DeleteParentRel"))
+	ids, err := utils.ParseIds([]string{parentID, childID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.parentRelService.DeleteParentRel(ids[0], ids[1],
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
 }
 
 // GetChildrenByParent is the resolver for the GetChildrenByParent field.
 func (r *queryResolver) GetChildrenByParent(ctx context.Context, parentID string) (*models.UsersList, error) {
-	panic(fmt.Errorf("not implemented: GetChildrenByParent - GetChildrenByParent"))
+	ids, err := utils.ParseIds([]string{parentID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	users, countRows, err := r.parentRelService.GetChildrenByParent(ids[0],
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.UsersList{
+		Users:     models.FromUsersCore(users),
+		CountRows: int(countRows),
+	}, nil
 }
 
 // GetParentsByChild is the resolver for the GetParentsByChild field.
 func (r *queryResolver) GetParentsByChild(ctx context.Context, childID string) (*models.UsersList, error) {
-	panic(fmt.Errorf("not implemented: GetParentsByChild - GetParentsByChild"))
+	ids, err := utils.ParseIds([]string{childID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	users, countRows, err := r.parentRelService.GetParentsByChild(ids[0],
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.UsersList{
+		Users:     models.FromUsersCore(users),
+		CountRows: int(countRows),
+	}, nil
 }
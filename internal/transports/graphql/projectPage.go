@@ -16,8 +16,21 @@ import (
 )
 
 // CreateProjectPage is the resolver for the CreateProjectPage field.
-func (r *mutationResolver) CreateProjectPage(ctx context.Context) (*models.ProjectPageHTTP, error) {
-	newProjectPage, err := r.projectPageService.CreateProjectPage(ctx.Value(consts.KeyId).(uint))
+func (r *mutationResolver) CreateProjectPage(ctx context.Context, templateID *string) (*models.ProjectPageHTTP, error) {
+	var templateId *uint
+	if templateID != nil {
+		ids, err := utils.ParseIds([]string{*templateID})
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		templateId = &ids[0]
+	}
+	newProjectPage, err := r.projectPageService.CreateProjectPage(ctx.Value(consts.KeyId).(uint), templateId, ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
@@ -105,7 +118,8 @@ func (r *mutationResolver) SetIsBanned(ctx context.Context, projectPageID string
 			},
 		}
 	}
-	if err := r.projectPageService.SetIsBanned(uint(atoi), isBanned); err != nil {
+	if err := r.projectPageService.SetIsBanned(uint(atoi), isBanned,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
 			Extensions: map[string]interface{}{
@@ -158,7 +172,8 @@ func (r *queryResolver) GetAllProjectPagesByAuthorID(ctx context.Context, id str
 			},
 		}
 	}
-	projects, countRows, err := r.projectPageService.GetProjectsPageByAuthorId(uint(atoi), page, pageSize)
+	projects, countRows, err := r.projectPageService.GetProjectsPageByAuthorId(uint(atoi), page, pageSize,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
@@ -189,3 +204,13 @@ func (r *queryResolver) GetAllProjectPagesByAccessToken(ctx context.Context, pag
 		CountRows:    int(countRows),
 	}, nil
 }
+
+// ProjectPageModerated is the resolver for the ProjectPageModerated field.
+func (r *subscriptionResolver) ProjectPageModerated(ctx context.Context) (<-chan *models.ProjectPageModerationHTTP, error) {
+	events, unsubscribe := r.projectPageService.SubscribeModeration(ctx.Value(consts.KeyId).(uint))
+	return forwardEvents(ctx, events, unsubscribe, func(event models.ModerationEventCore) *models.ProjectPageModerationHTTP {
+		var eventHttp models.ProjectPageModerationHTTP
+		eventHttp.FromCore(event)
+		return &eventHttp
+	}), nil
+}
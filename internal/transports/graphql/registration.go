@@ -0,0 +1,83 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ApproveRegistrations is the resolver for the ApproveRegistrations field.
+func (r *mutationResolver) ApproveRegistrations(ctx context.Context, ids []string, reason *string) (*models.Response, error) {
+	requestIds, err := utils.ParseIds(ids)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var reasonValue string
+	if reason != nil {
+		reasonValue = *reason
+	}
+	if err := r.registrationService.ApproveRegistrations(requestIds, reasonValue, ctx.Value(consts.KeyId).(uint)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RejectRegistrations is the resolver for the RejectRegistrations field.
+func (r *mutationResolver) RejectRegistrations(ctx context.Context, ids []string, reason *string) (*models.Response, error) {
+	requestIds, err := utils.ParseIds(ids)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var reasonValue string
+	if reason != nil {
+		reasonValue = *reason
+	}
+	if err := r.registrationService.RejectRegistrations(requestIds, reasonValue, ctx.Value(consts.KeyId).(uint)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// GetPendingRegistrations is the resolver for the GetPendingRegistrations field.
+func (r *queryResolver) GetPendingRegistrations(ctx context.Context, page *int, pageSize *int) (*models.RegistrationRequestHTTPList, error) {
+	requests, countRows, err := r.registrationService.GetPendingRegistrations(page, pageSize)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.RegistrationRequestHTTPList{
+		RegistrationRequests: models.FromRegistrationRequestsCore(requests),
+		CountRows:            int(countRows),
+	}, nil
+}
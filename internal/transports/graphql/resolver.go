@@ -1,16 +1,36 @@
 package resolvers
 
 import (
+	"context"
 	"github.com/skinnykaen/rpa_clone/internal/services"
 	"github.com/skinnykaen/rpa_clone/pkg/logger"
 )
 
 type Resolver struct {
-	loggers            logger.Loggers
-	userService        services.UserService
-	authService        services.AuthService
-	projectPageService services.ProjectPageService
-	settingsService    services.SettingsService
+	loggers                logger.Loggers
+	userService            services.UserService
+	authService            services.AuthService
+	projectPageService     services.ProjectPageService
+	projectTemplateService services.ProjectTemplateService
+	settingsService        services.SettingsService
+	twoFactorService       services.TwoFactorService
+	loginGuardService      services.LoginGuardService
+	registrationService    services.RegistrationService
+	oidcService            services.OidcService
+	courseService          services.CourseService
+	cohortService          services.CohortService
+	ltiService             services.LtiService
+	parentRelService       services.ParentRelService
+	parentLinkService      services.ParentLinkService
+	parentDashboardService services.ParentDashboardService
+	unitService            services.UnitService
+	groupService           services.GroupService
+	assignmentService      services.AssignmentService
+	certificateService     services.CertificateService
+	contestService         services.ContestService
+	teamService            services.TeamService
+	clarificationService   services.ClarificationService
+	notificationService    services.NotificationService
 }
 
 func SetupResolvers(
@@ -18,13 +38,76 @@ func SetupResolvers(
 	userService services.UserService,
 	authService services.AuthService,
 	projectPageService services.ProjectPageService,
+	projectTemplateService services.ProjectTemplateService,
 	settingsService services.SettingsService,
+	twoFactorService services.TwoFactorService,
+	loginGuardService services.LoginGuardService,
+	registrationService services.RegistrationService,
+	oidcService services.OidcService,
+	courseService services.CourseService,
+	cohortService services.CohortService,
+	ltiService services.LtiService,
+	parentRelService services.ParentRelService,
+	parentLinkService services.ParentLinkService,
+	parentDashboardService services.ParentDashboardService,
+	unitService services.UnitService,
+	groupService services.GroupService,
+	assignmentService services.AssignmentService,
+	certificateService services.CertificateService,
+	contestService services.ContestService,
+	teamService services.TeamService,
+	clarificationService services.ClarificationService,
+	notificationService services.NotificationService,
 ) Resolver {
 	return Resolver{
-		loggers:            loggers,
-		userService:        userService,
-		authService:        authService,
-		projectPageService: projectPageService,
-		settingsService:    settingsService,
+		loggers:                loggers,
+		userService:            userService,
+		authService:            authService,
+		projectPageService:     projectPageService,
+		projectTemplateService: projectTemplateService,
+		settingsService:        settingsService,
+		twoFactorService:       twoFactorService,
+		loginGuardService:      loginGuardService,
+		registrationService:    registrationService,
+		oidcService:            oidcService,
+		courseService:          courseService,
+		cohortService:          cohortService,
+		ltiService:             ltiService,
+		parentRelService:       parentRelService,
+		parentLinkService:      parentLinkService,
+		parentDashboardService: parentDashboardService,
+		unitService:            unitService,
+		groupService:           groupService,
+		assignmentService:      assignmentService,
+		certificateService:     certificateService,
+		contestService:         contestService,
+		teamService:            teamService,
+		clarificationService:   clarificationService,
+		notificationService:    notificationService,
 	}
 }
+
+// forwardEvents converts events for the subscription until the client disconnects, then unsubscribes
+func forwardEvents[C any, H any](ctx context.Context, events <-chan C, unsubscribe func(), convert func(event C) *H) <-chan *H {
+	eventsHttp := make(chan *H)
+	go func() {
+		defer close(eventsHttp)
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				select {
+				case eventsHttp <- convert(event):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return eventsHttp
+}
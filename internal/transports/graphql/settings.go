@@ -31,6 +31,36 @@ func (r *mutationResolver) SetActivationByLink(ctx context.Context, activationBy
 	}, nil
 }
 
+// SetTwoFactorRequired is the resolver for the SetTwoFactorRequired field.
+func (r *mutationResolver) SetTwoFactorRequired(ctx context.Context, role models.Role, required bool) (*models.Response, error) {
+	if err := r.settingsService.SetTwoFactorRequired(role, required); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{
+		Ok: true,
+	}, nil
+}
+
+// SetPasswordPolicy is the resolver for the SetPasswordPolicy field.
+func (r *mutationResolver) SetPasswordPolicy(ctx context.Context, input models.PasswordPolicyInput) (*models.Response, error) {
+	if err := r.settingsService.SetPasswordPolicy(input.ToCore()); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{
+		Ok: true,
+	}, nil
+}
+
 // GetSettings is the resolver for the GetSettings field.
 func (r *queryResolver) GetSettings(ctx context.Context) (*models.Settings, error) {
 	activationLink, err := r.settingsService.GetActivationByLink()
@@ -45,7 +75,45 @@ func (r *queryResolver) GetSettings(ctx context.Context) (*models.Settings, erro
 			},
 		}
 	}
+	twoFactorRequiredRoles, err := r.settingsService.GetTwoFactorRequiredRoles()
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	passwordPolicy, err := r.settingsService.GetPasswordPolicy()
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	passwordPolicyHttp := models.PasswordPolicy{}
+	passwordPolicyHttp.FromCore(passwordPolicy)
 	return &models.Settings{
-		ActivationByLink: activationLink,
+		ActivationByLink:       activationLink,
+		TwoFactorRequiredRoles: twoFactorRequiredRoles,
+		PasswordPolicy:         &passwordPolicyHttp,
 	}, nil
 }
+
+// GetPasswordPolicy is the resolver for the GetPasswordPolicy field.
+func (r *queryResolver) GetPasswordPolicy(ctx context.Context) (*models.PasswordPolicy, error) {
+	passwordPolicy, err := r.settingsService.GetPasswordPolicy()
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	passwordPolicyHttp := models.PasswordPolicy{}
+	passwordPolicyHttp.FromCore(passwordPolicy)
+	return &passwordPolicyHttp, nil
+}
@@ -0,0 +1,313 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CreateTeam is the resolver for the CreateTeam field.
+func (r *mutationResolver) CreateTeam(ctx context.Context, contestID string, name string) (*models.TeamHTTP, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	team, err := r.teamService.CreateTeam(ids[0], name, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var teamHttp models.TeamHTTP
+	teamHttp.FromCore(team)
+	return &teamHttp, nil
+}
+
+// DeleteTeam is the resolver for the DeleteTeam field.
+func (r *mutationResolver) DeleteTeam(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.teamService.DeleteTeam(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// InviteToTeam is the resolver for the InviteToTeam field.
+func (r *mutationResolver) InviteToTeam(ctx context.Context, teamID string, userID string) (*models.TeamInvitationHTTP, error) {
+	ids, err := utils.ParseIds([]string{teamID, userID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	invitation, err := r.teamService.InviteToTeam(ids[0], ids[1], ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var invitationHttp models.TeamInvitationHTTP
+	invitationHttp.FromCore(invitation)
+	return &invitationHttp, nil
+}
+
+// AcceptTeamInvitation is the resolver for the AcceptTeamInvitation field.
+func (r *mutationResolver) AcceptTeamInvitation(ctx context.Context, id string) (*models.TeamHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	team, err := r.teamService.AcceptInvitation(ids[0], ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var teamHttp models.TeamHTTP
+	teamHttp.FromCore(team)
+	return &teamHttp, nil
+}
+
+// DeleteTeamInvitation is the resolver for the DeleteTeamInvitation field.
+func (r *mutationResolver) DeleteTeamInvitation(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.teamService.DeleteInvitation(ids[0], ctx.Value(consts.KeyId).(uint)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RemoveTeamMember is the resolver for the RemoveTeamMember field.
+func (r *mutationResolver) RemoveTeamMember(ctx context.Context, teamID string, userID string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{teamID, userID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.teamService.RemoveMember(ids[0], ids[1], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// SubmitTeamProject is the resolver for the SubmitTeamProject field.
+func (r *mutationResolver) SubmitTeamProject(ctx context.Context, teamID string) (*models.TeamHTTP, error) {
+	ids, err := utils.ParseIds([]string{teamID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	team, err := r.teamService.SubmitTeam(ids[0], ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var teamHttp models.TeamHTTP
+	teamHttp.FromCore(team)
+	return &teamHttp, nil
+}
+
+// SetTeamResult is the resolver for the SetTeamResult field.
+func (r *mutationResolver) SetTeamResult(ctx context.Context, teamID string, score *float64, place *int) (*models.TeamHTTP, error) {
+	ids, err := utils.ParseIds([]string{teamID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	team, err := r.teamService.SetResult(ids[0], score, place, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var teamHttp models.TeamHTTP
+	teamHttp.FromCore(team)
+	return &teamHttp, nil
+}
+
+// GetTeamByID is the resolver for the GetTeamById field.
+func (r *queryResolver) GetTeamByID(ctx context.Context, id string) (*models.TeamHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	team, err := r.teamService.GetTeamById(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	var teamHttp models.TeamHTTP
+	teamHttp.FromCore(team)
+	return &teamHttp, nil
+}
+
+// GetTeamsByContest is the resolver for the GetTeamsByContest field.
+func (r *queryResolver) GetTeamsByContest(ctx context.Context, contestID string, page *int, pageSize *int) (*models.TeamHTTPList, error) {
+	ids, err := utils.ParseIds([]string{contestID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	teams, countRows, err := r.teamService.GetTeamsByContest(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.TeamHTTPList{
+		Teams:     models.FromTeamsCore(teams),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetTeamsByAccessToken is the resolver for the GetTeamsByAccessToken field.
+func (r *queryResolver) GetTeamsByAccessToken(ctx context.Context, page *int, pageSize *int) (*models.TeamHTTPList, error) {
+	teams, countRows, err := r.teamService.GetTeamsByUser(page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.TeamHTTPList{
+		Teams:     models.FromTeamsCore(teams),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetTeamInvitations is the resolver for the GetTeamInvitations field.
+func (r *queryResolver) GetTeamInvitations(ctx context.Context, teamID string) ([]*models.TeamInvitationHTTP, error) {
+	ids, err := utils.ParseIds([]string{teamID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	invitations, err := r.teamService.GetInvitationsByTeam(ids[0], ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return models.FromTeamInvitationsCore(invitations), nil
+}
+
+// GetTeamInvitationsByAccessToken is the resolver for the GetTeamInvitationsByAccessToken field.
+func (r *queryResolver) GetTeamInvitationsByAccessToken(ctx context.Context, page *int, pageSize *int) (*models.TeamInvitationHTTPList, error) {
+	invitations, countRows, err := r.teamService.GetInvitationsByUser(page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.TeamInvitationHTTPList{
+		TeamInvitations: models.FromTeamInvitationsCore(invitations),
+		CountRows:       int(countRows),
+	}, nil
+}
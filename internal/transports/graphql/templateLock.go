@@ -0,0 +1,107 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// SetIsTemplate is the resolver for the SetIsTemplate field.
+func (r *mutationResolver) SetIsTemplate(ctx context.Context, projectPageID string, isTemplate bool) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{projectPageID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.projectTemplateService.SetIsTemplate(ids[0], isTemplate, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// SetTemplateLocks is the resolver for the SetTemplateLocks field.
+func (r *mutationResolver) SetTemplateLocks(ctx context.Context, projectPageID string, locks []*models.NewTemplateLock) ([]*models.TemplateLockHTTP, error) {
+	ids, err := utils.ParseIds([]string{projectPageID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	locksCore := make([]models.TemplateLockCore, 0, len(locks))
+	for _, lock := range locks {
+		locksCore = append(locksCore, models.TemplateLockCore{
+			Kind:       lock.Kind,
+			TargetName: lock.TargetName,
+			BlockID:    utils.StringPointerToString(lock.BlockID),
+		})
+	}
+	newLocks, err := r.projectTemplateService.SetLocks(ids[0], locksCore, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return models.FromTemplateLocksCore(newLocks), nil
+}
+
+// GetProjectTemplates is the resolver for the GetProjectTemplates field.
+func (r *queryResolver) GetProjectTemplates(ctx context.Context, page *int, pageSize *int) (*models.ProjectPageHTTPList, error) {
+	projectPages, countRows, err := r.projectTemplateService.GetTemplates(page, pageSize, ctx.Value(consts.KeyId).(uint))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.ProjectPageHTTPList{
+		ProjectPages: models.FromProjectPagesCore(projectPages),
+		CountRows:    int(countRows),
+	}, nil
+}
+
+// GetTemplateLocks is the resolver for the GetTemplateLocks field.
+func (r *queryResolver) GetTemplateLocks(ctx context.Context, projectPageID string) ([]*models.TemplateLockHTTP, error) {
+	ids, err := utils.ParseIds([]string{projectPageID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	locks, err := r.projectTemplateService.GetLocks(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return models.FromTemplateLocksCore(locks), nil
+}
@@ -0,0 +1,151 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// VerifyTwoFactor is the resolver for the VerifyTwoFactor field.
+func (r *mutationResolver) VerifyTwoFactor(ctx context.Context, challengeToken string, code string) (*models.SignInResponse, error) {
+	tokens, err := r.twoFactorService.VerifyChallenge(challengeToken, code, ctx.Value(consts.KeyIp).(string))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.SignInResponse{
+		AccessToken:  tokens.Access,
+		RefreshToken: tokens.Refresh,
+	}, nil
+}
+
+// EnrollTwoFactor is the resolver for the EnrollTwoFactor field.
+func (r *mutationResolver) EnrollTwoFactor(ctx context.Context, challengeToken *string) (*models.TwoFactorEnrollment, error) {
+	var userId uint
+	if challengeToken != nil {
+		parsedId, err := r.twoFactorService.ParseChallenge(*challengeToken)
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		userId = parsedId
+	} else {
+		// anonymous clients have no id in the context
+		clientId, ok := ctx.Value(consts.KeyId).(uint)
+		if !ok {
+			r.loggers.Err.Printf("%s", consts.ErrAccessDenied)
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": utils.ResponseError{
+						Code:    http.StatusForbidden,
+						Message: consts.ErrAccessDenied,
+					},
+				},
+			}
+		}
+		userId = clientId
+	}
+	secret, provisioningUri, err := r.twoFactorService.Enroll(userId)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: provisioningUri,
+	}, nil
+}
+
+// ConfirmTwoFactorEnrollment is the resolver for the ConfirmTwoFactorEnrollment field.
+func (r *mutationResolver) ConfirmTwoFactorEnrollment(ctx context.Context, challengeToken *string, code string) (*models.TwoFactorConfirmation, error) {
+	var userId uint
+	if challengeToken != nil {
+		parsedId, err := r.twoFactorService.ParseChallenge(*challengeToken)
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		userId = parsedId
+	} else {
+		// anonymous clients have no id in the context
+		clientId, ok := ctx.Value(consts.KeyId).(uint)
+		if !ok {
+			r.loggers.Err.Printf("%s", consts.ErrAccessDenied)
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": utils.ResponseError{
+						Code:    http.StatusForbidden,
+						Message: consts.ErrAccessDenied,
+					},
+				},
+			}
+		}
+		userId = clientId
+	}
+	recoveryCodes, tokens, err := r.twoFactorService.ConfirmEnrollment(userId, code, challengeToken != nil)
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.TwoFactorConfirmation{
+		RecoveryCodes: recoveryCodes,
+		AccessToken:   tokens.Access,
+		RefreshToken:  tokens.Refresh,
+	}, nil
+}
+
+// DisableTwoFactor is the resolver for the DisableTwoFactor field.
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (*models.Response, error) {
+	if err := r.twoFactorService.Disable(ctx.Value(consts.KeyId).(uint), code, ctx.Value(consts.KeyIp).(string)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RegenerateRecoveryCodes is the resolver for the RegenerateRecoveryCodes field.
+func (r *mutationResolver) RegenerateRecoveryCodes(ctx context.Context, code string) (*models.RecoveryCodes, error) {
+	recoveryCodes, err := r.twoFactorService.RegenerateRecoveryCodes(ctx.Value(consts.KeyId).(uint), code,
+		ctx.Value(consts.KeyIp).(string))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.RecoveryCodes{RecoveryCodes: recoveryCodes}, nil
+}
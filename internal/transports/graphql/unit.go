@@ -0,0 +1,222 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.33
+
+import (
+	"context"
+
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CreateUnit is the resolver for the CreateUnit field.
+func (r *mutationResolver) CreateUnit(ctx context.Context, input models.NewUnit) (*models.UnitHTTP, error) {
+	unit, err := r.unitService.CreateUnit(models.UnitCore{
+		Name:        input.Name,
+		Description: utils.StringPointerToString(input.Description),
+	})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	unitHttp := models.UnitHTTP{}
+	unitHttp.FromCore(unit)
+	return &unitHttp, nil
+}
+
+// UpdateUnit is the resolver for the UpdateUnit field.
+func (r *mutationResolver) UpdateUnit(ctx context.Context, input models.UpdateUnit) (*models.UnitHTTP, error) {
+	ids, err := utils.ParseIds([]string{input.ID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	unit, err := r.unitService.UpdateUnit(models.UnitCore{
+		ID:          ids[0],
+		Name:        input.Name,
+		Description: input.Description,
+	})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	unitHttp := models.UnitHTTP{}
+	unitHttp.FromCore(unit)
+	return &unitHttp, nil
+}
+
+// DeleteUnit is the resolver for the DeleteUnit field.
+func (r *mutationResolver) DeleteUnit(ctx context.Context, id string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.unitService.DeleteUnit(ids[0]); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// AddUserToUnit is the resolver for the AddUserToUnit field.
+func (r *mutationResolver) AddUserToUnit(ctx context.Context, unitID string, userID string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{unitID, userID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.unitService.AddMember(ids[0], ids[1], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// RemoveUserFromUnit is the resolver for the RemoveUserFromUnit field.
+func (r *mutationResolver) RemoveUserFromUnit(ctx context.Context, unitID string, userID string) (*models.Response, error) {
+	ids, err := utils.ParseIds([]string{unitID, userID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	if err := r.unitService.DeleteMember(ids[0], ids[1], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.Response{Ok: true}, nil
+}
+
+// GetUnitByID is the resolver for the GetUnitById field.
+func (r *queryResolver) GetUnitByID(ctx context.Context, id string) (*models.UnitHTTP, error) {
+	ids, err := utils.ParseIds([]string{id})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	unit, err := r.unitService.GetUnitById(ids[0], ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	unitHttp := models.UnitHTTP{}
+	unitHttp.FromCore(unit)
+	return &unitHttp, nil
+}
+
+// GetAllUnits is the resolver for the GetAllUnits field.
+func (r *queryResolver) GetAllUnits(ctx context.Context, page *int, pageSize *int) (*models.UnitHTTPList, error) {
+	units, countRows, err := r.unitService.GetAllUnits(page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.UnitHTTPList{
+		Units:     models.FromUnitsCore(units),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetUnitsByUser is the resolver for the GetUnitsByUser field.
+func (r *queryResolver) GetUnitsByUser(ctx context.Context, userID string, page *int, pageSize *int) (*models.UnitHTTPList, error) {
+	ids, err := utils.ParseIds([]string{userID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	units, countRows, err := r.unitService.GetUnitsByUser(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.UnitHTTPList{
+		Units:     models.FromUnitsCore(units),
+		CountRows: int(countRows),
+	}, nil
+}
+
+// GetUnitMembers is the resolver for the GetUnitMembers field.
+func (r *queryResolver) GetUnitMembers(ctx context.Context, unitID string, page *int, pageSize *int) (*models.UsersList, error) {
+	ids, err := utils.ParseIds([]string{unitID})
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	users, countRows, err := r.unitService.GetUnitMembers(ids[0], page, pageSize, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	if err != nil {
+		r.loggers.Err.Printf("%s", err.Error())
+		return nil, &gqlerror.Error{
+			Extensions: map[string]interface{}{
+				"err": err,
+			},
+		}
+	}
+	return &models.UsersList{
+		Users:     models.FromUsersCore(users),
+		CountRows: int(countRows),
+	}, nil
+}
@@ -27,16 +27,39 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input models.NewUser)
 		Nickname:   input.Nickname,
 		IsActive:   true,
 		Role:       input.Role,
+		Grade:      input.Grade,
 	}
-	newUser, err := r.userService.CreateUser(user, ctx.Value(consts.KeyRole).(models.Role))
+	if input.BirthDate != nil {
+		birthDate, err := utils.ParseDate(*input.BirthDate)
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		user.BirthDate = &birthDate
+	}
+	var unitId *uint
+	if input.UnitID != nil {
+		ids, err := utils.ParseIds([]string{*input.UnitID})
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		unitId = &ids[0]
+	}
+	newUser, err := r.userService.CreateUser(user, unitId, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
 			Extensions: map[string]interface{}{
-				"err": utils.ResponseError{
-					Code:    http.StatusInternalServerError,
-					Message: err.Error(),
-				},
+				"err": err,
 			},
 		}
 	}
@@ -67,8 +90,21 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, input models.UpdateUs
 		Lastname:   input.Lastname,
 		Middlename: input.Middlename,
 		Nickname:   input.Nickname,
+		Grade:      input.Grade,
 	}
-	updatedUser, err := r.userService.UpdateUser(user, ctx.Value(consts.KeyRole).(models.Role))
+	if input.BirthDate != nil {
+		birthDate, err := utils.ParseDate(*input.BirthDate)
+		if err != nil {
+			r.loggers.Err.Printf("%s", err.Error())
+			return nil, &gqlerror.Error{
+				Extensions: map[string]interface{}{
+					"err": err,
+				},
+			}
+		}
+		user.BirthDate = &birthDate
+	}
+	updatedUser, err := r.userService.UpdateUser(user, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
@@ -99,15 +135,12 @@ func (r *mutationResolver) DeleteUser(ctx context.Context, id string) (*models.R
 			},
 		}
 	}
-	err = r.userService.DeleteUser(uint(atoi))
+	err = r.userService.DeleteUser(uint(atoi), ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
 			Extensions: map[string]interface{}{
-				"err": utils.ResponseError{
-					Code:    http.StatusInternalServerError,
-					Message: err.Error(),
-				},
+				"err": err,
 			},
 		}
 	}
@@ -128,14 +161,11 @@ func (r *mutationResolver) SetUserIsActive(ctx context.Context, id string, isAct
 			},
 		}
 	}
-	if err := r.userService.SetIsActive(uint(atoi), isActive); err != nil {
+	if err := r.userService.SetIsActive(uint(atoi), isActive, ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role)); err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
 			Extensions: map[string]interface{}{
-				"err": utils.ResponseError{
-					Code:    http.StatusInternalServerError,
-					Message: err.Error(),
-				},
+				"err": err,
 			},
 		}
 	}
@@ -144,7 +174,7 @@ func (r *mutationResolver) SetUserIsActive(ctx context.Context, id string, isAct
 
 // GetUserByAccessToken is the resolver for the GetUserByAccessToken field.
 func (r *queryResolver) GetUserByAccessToken(ctx context.Context) (*models.UserHTTP, error) {
-	user, err := r.userService.GetUserById(ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
+	user, err := r.userService.GetUserById(ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
@@ -175,7 +205,7 @@ func (r *queryResolver) GetUserByID(ctx context.Context, id string) (*models.Use
 			},
 		}
 	}
-	user, err := r.userService.GetUserById(uint(atoi), ctx.Value(consts.KeyRole).(models.Role))
+	user, err := r.userService.GetUserById(uint(atoi), ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return nil, &gqlerror.Error{
@@ -194,7 +224,8 @@ func (r *queryResolver) GetUserByID(ctx context.Context, id string) (*models.Use
 
 // GetAllUsers is the resolver for the GetAllUsers field.
 func (r *queryResolver) GetAllUsers(ctx context.Context, page *int, pageSize *int, active bool, roles []models.Role) (*models.UsersList, error) {
-	users, countRows, err := r.userService.GetAllUsers(page, pageSize, active, roles, ctx.Value(consts.KeyRole).(models.Role))
+	users, countRows, err := r.userService.GetAllUsers(page, pageSize, active, roles,
+		ctx.Value(consts.KeyId).(uint), ctx.Value(consts.KeyRole).(models.Role))
 	if err != nil {
 		r.loggers.Err.Printf("%s", err.Error())
 		return &models.UsersList{}, &gqlerror.Error{
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/internal/services"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type CertificateHandler interface {
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type CertificateHandlerImpl struct {
+	loggers            logger.Loggers
+	certificateService services.CertificateService
+}
+
+var verificationPage = template.Must(template.New("verification").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head><meta charset="utf-8"><title>Проверка сертификата</title></head>
+<body>
+{{if not .}}<h1>Сертификат не найден</h1>
+{{else if .RevokedAt}}<h1>Сертификат отозван</h1>
+{{else}}<h1>Сертификат действителен</h1>{{end}}
+{{if .}}<p>Участник: {{.User.Lastname}} {{.User.Firstname}} {{.User.Middlename}}</p>
+<p>Соревнование: {{.Contest}}</p>
+{{if .Place}}<p>Место: {{.Place}}</p>{{end}}
+{{if .Score}}<p>Баллы: {{.Score}}</p>{{end}}
+<p>Выдан: {{.CreatedAt.Format "02.01.2006"}}</p>{{end}}
+</body>
+</html>`))
+
+// ServeHTTP handles POST /certificates/background with multipart "file" for template backgrounds,
+// GET /certificates/pdf?id=1&id=2 for printing issued certificates and the public GET /certificates/verify?code=
+// opened from the certificate QR code.
+func (c CertificateHandlerImpl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/certificates/background":
+		c.uploadBackground(w, r)
+	case "/certificates/pdf":
+		c.writePdf(w, r)
+	case "/certificates/verify":
+		c.verify(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (c CertificateHandlerImpl) uploadBackground(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+		return
+	}
+	clientRole := r.Context().Value(consts.KeyRole).(models.Role)
+	if clientRole != models.RoleSuperAdmin && clientRole != models.RoleUnitAdmin {
+		http.Error(w, consts.ErrAccessDenied, http.StatusForbidden)
+		return
+	}
+	maxSize := viper.GetInt64("certificates.max_background_size")
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+	if err := r.ParseMultipartForm(maxSize); err != nil {
+		c.loggers.Err.Printf("%s", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		c.loggers.Err.Printf("%s", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.loggers.Err.Printf("%s", err.Error())
+		http.Error(w, "failed to read file", http.StatusInternalServerError)
+		return
+	}
+	filename, err := c.certificateService.UploadBackground(header.Filename, data)
+	if err != nil {
+		c.writeError(w, err)
+		return
+	}
+	jData, err := json.Marshal(map[string]string{
+		"filename": filename,
+	})
+	if err != nil {
+		c.loggers.Err.Printf("%s", err.Error())
+		http.Error(w, "failed to marshal http response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jData)
+}
+
+func (c CertificateHandlerImpl) writePdf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+		return
+	}
+	clientRole := r.Context().Value(consts.KeyRole).(models.Role)
+	if clientRole == models.RoleAnonymous {
+		http.Error(w, consts.ErrAccessDenied, http.StatusForbidden)
+		return
+	}
+	values := r.URL.Query()["id"]
+	if len(values) == 0 {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	ids := make([]uint, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, consts.ErrAtoi, http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+	certificates, err := c.certificateService.GetCertificatesForPrint(ids, r.Context().Value(consts.KeyId).(uint),
+		clientRole)
+	if err != nil {
+		c.writeError(w, err)
+		return
+	}
+	// the document is built in memory, so rendering errors are still reported with their codes
+	var buf bytes.Buffer
+	if err := c.certificateService.WritePdf(&buf, certificates); err != nil {
+		c.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="certificates.pdf"`)
+	if _, err := buf.WriteTo(w); err != nil {
+		c.loggers.Err.Printf("%s", err.Error())
+	}
+}
+
+func (c CertificateHandlerImpl) verify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	var data *models.CertificateCore
+	certificate, err := c.certificateService.VerifyCertificate(r.URL.Query().Get("code"))
+	if err != nil {
+		var responseError utils.ResponseError
+		if !errors.As(err, &responseError) || responseError.Message != consts.ErrNotFoundInDB {
+			c.writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	} else {
+		data = &certificate
+	}
+	if err := verificationPage.Execute(w, data); err != nil {
+		c.loggers.Err.Printf("%s", err.Error())
+	}
+}
+
+func (c CertificateHandlerImpl) writeError(w http.ResponseWriter, err error) {
+	c.loggers.Err.Printf("%s", err.Error())
+	var responseError utils.ResponseError
+	if errors.As(err, &responseError) {
+		http.Error(w, responseError.Message, int(responseError.Code))
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
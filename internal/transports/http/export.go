@@ -0,0 +1,362 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/internal/services"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/skinnykaen/rpa_clone/pkg/xlsx"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ExportHandler interface {
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type ExportHandlerImpl struct {
+	loggers       logger.Loggers
+	exportService services.ExportService
+}
+
+type userColumn struct {
+	name  string
+	value func(user models.UserCore) string
+}
+
+type projectPageColumn struct {
+	name  string
+	value func(projectPage models.ProjectPageCore) string
+}
+
+var userColumns = []userColumn{
+	{"id", func(user models.UserCore) string { return strconv.Itoa(int(user.ID)) }},
+	{"email", func(user models.UserCore) string { return user.Email }},
+	{"nickname", func(user models.UserCore) string { return user.Nickname }},
+	{"lastname", func(user models.UserCore) string { return user.Lastname }},
+	{"firstname", func(user models.UserCore) string { return user.Firstname }},
+	{"middlename", func(user models.UserCore) string { return user.Middlename }},
+	{"role", func(user models.UserCore) string { return user.Role.String() }},
+	{"isActive", func(user models.UserCore) string { return strconv.FormatBool(user.IsActive) }},
+	{"createdAt", func(user models.UserCore) string { return user.CreatedAt.Format(time.DateTime) }},
+}
+
+var projectPageColumns = []projectPageColumn{
+	{"id", func(projectPage models.ProjectPageCore) string { return strconv.Itoa(int(projectPage.ID)) }},
+	{"title", func(projectPage models.ProjectPageCore) string { return projectPage.Title }},
+	{"authorId", func(projectPage models.ProjectPageCore) string { return strconv.Itoa(int(projectPage.AuthorID)) }},
+	{"authorNickname", func(projectPage models.ProjectPageCore) string { return projectPage.User.Nickname }},
+	{"authorName", func(projectPage models.ProjectPageCore) string {
+		return strings.TrimSpace(projectPage.User.Lastname + " " + projectPage.User.Firstname)
+	}},
+	{"isShared", func(projectPage models.ProjectPageCore) string { return strconv.FormatBool(projectPage.IsShared) }},
+	{"isBanned", func(projectPage models.ProjectPageCore) string { return strconv.FormatBool(projectPage.IsBanned) }},
+	{"linkToScratch", func(projectPage models.ProjectPageCore) string { return projectPage.LinkToScratch }},
+	{"createdAt", func(projectPage models.ProjectPageCore) string { return projectPage.CreatedAt.Format(time.DateTime) }},
+	{"projectUpdatedAt", func(projectPage models.ProjectPageCore) string {
+		return projectPage.Project.UpdatedAt.Format(time.DateTime)
+	}},
+}
+
+// resultColumn is a column of the contest results, every member of a team is exported as a row
+type resultColumn struct {
+	name  string
+	value func(team models.TeamCore, member models.UserCore) string
+}
+
+var resultColumns = []resultColumn{
+	{"place", func(team models.TeamCore, member models.UserCore) string {
+		if team.Place == nil {
+			return ""
+		}
+		return strconv.Itoa(*team.Place)
+	}},
+	{"score", func(team models.TeamCore, member models.UserCore) string {
+		if team.Score == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*team.Score, 'f', -1, 64)
+	}},
+	{"teamId", func(team models.TeamCore, member models.UserCore) string { return strconv.Itoa(int(team.ID)) }},
+	{"teamName", func(team models.TeamCore, member models.UserCore) string { return team.Name }},
+	{"submittedAt", func(team models.TeamCore, member models.UserCore) string {
+		if team.SubmittedAt == nil {
+			return ""
+		}
+		return team.SubmittedAt.Format(time.DateTime)
+	}},
+	{"userId", func(team models.TeamCore, member models.UserCore) string { return strconv.Itoa(int(member.ID)) }},
+	{"lastname", func(team models.TeamCore, member models.UserCore) string { return member.Lastname }},
+	{"firstname", func(team models.TeamCore, member models.UserCore) string { return member.Firstname }},
+	{"middlename", func(team models.TeamCore, member models.UserCore) string { return member.Middlename }},
+	{"isCaptain", func(team models.TeamCore, member models.UserCore) string {
+		return strconv.FormatBool(member.ID == team.CaptainID)
+	}},
+}
+
+// tableWriter writes rows of csv or xlsx exports
+type tableWriter interface {
+	Write(row []string) error
+	Flush() error
+	Close() error
+}
+
+type csvTableWriter struct {
+	*csv.Writer
+}
+
+func (c csvTableWriter) Flush() error {
+	c.Writer.Flush()
+	return c.Writer.Error()
+}
+
+func (c csvTableWriter) Close() error {
+	return c.Flush()
+}
+
+// safeTableWriter keeps spreadsheets from running user-controlled values as formulas
+type safeTableWriter struct {
+	tableWriter
+}
+
+func (s safeTableWriter) Write(row []string) error {
+	safeRow := make([]string, len(row))
+	for i, cell := range row {
+		safeRow[i] = neutralizeFormula(cell)
+	}
+	return s.tableWriter.Write(safeRow)
+}
+
+// neutralizeFormula prefixes cells starting like a formula with an apostrophe, spreadsheets show them as text
+func neutralizeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// ServeHTTP streams /export/users, /export/project-pages and /export/results?contestId= as csv or xlsx (?format=xlsx).
+// ?columns=id,email selects and orders columns, users are filtered by ?isActive and repeated ?role.
+func (e ExportHandlerImpl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+		return
+	}
+	clientRole := r.Context().Value(consts.KeyRole).(models.Role)
+	if clientRole != models.RoleSuperAdmin && clientRole != models.RoleUnitAdmin {
+		http.Error(w, consts.ErrAccessDenied, http.StatusForbidden)
+		return
+	}
+	clientId := r.Context().Value(consts.KeyId).(uint)
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		http.Error(w, "incorrect format", http.StatusBadRequest)
+		return
+	}
+	var table tableWriter
+	// the response starts with the first batch, so access errors are still reported with their codes
+	startTable := func(name string, header []string) error {
+		if table != nil {
+			return nil
+		}
+		var err error
+		table, err = newTableWriter(w, format, name)
+		if err != nil {
+			return err
+		}
+		return table.Write(header)
+	}
+	var name string
+	var header []string
+	var err error
+	switch r.URL.Path {
+	case "/export/users":
+		name = "users"
+		isActive := true
+		if value := query.Get("isActive"); value != "" {
+			if isActive, err = strconv.ParseBool(value); err != nil {
+				http.Error(w, "incorrect isActive", http.StatusBadRequest)
+				return
+			}
+		}
+		var roles []models.Role
+		for _, value := range query["role"] {
+			role := models.Role(value)
+			if !role.IsValid() {
+				http.Error(w, "incorrect role", http.StatusBadRequest)
+				return
+			}
+			roles = append(roles, role)
+		}
+		names := make([]string, len(userColumns))
+		for i, column := range userColumns {
+			names[i] = column.name
+		}
+		indexes, ok := selectColumns(query.Get("columns"), names)
+		if !ok {
+			http.Error(w, "incorrect columns", http.StatusBadRequest)
+			return
+		}
+		header = columnNames(indexes, names)
+		err = e.exportService.ExportUsers(isActive, roles, clientId, clientRole, func(users []models.UserCore) error {
+			if err := startTable(name, header); err != nil {
+				return err
+			}
+			for _, user := range users {
+				row := make([]string, len(indexes))
+				for i, index := range indexes {
+					row[i] = userColumns[index].value(user)
+				}
+				if err := table.Write(row); err != nil {
+					return err
+				}
+			}
+			return table.Flush()
+		})
+	case "/export/project-pages":
+		name = "project_pages"
+		names := make([]string, len(projectPageColumns))
+		for i, column := range projectPageColumns {
+			names[i] = column.name
+		}
+		indexes, ok := selectColumns(query.Get("columns"), names)
+		if !ok {
+			http.Error(w, "incorrect columns", http.StatusBadRequest)
+			return
+		}
+		header = columnNames(indexes, names)
+		err = e.exportService.ExportProjectPages(clientId, clientRole, func(projectPages []models.ProjectPageCore) error {
+			if err := startTable(name, header); err != nil {
+				return err
+			}
+			for _, projectPage := range projectPages {
+				row := make([]string, len(indexes))
+				for i, index := range indexes {
+					row[i] = projectPageColumns[index].value(projectPage)
+				}
+				if err := table.Write(row); err != nil {
+					return err
+				}
+			}
+			return table.Flush()
+		})
+	case "/export/results":
+		name = "results"
+		contestId, atoiErr := strconv.Atoi(query.Get("contestId"))
+		if atoiErr != nil || contestId <= 0 {
+			http.Error(w, consts.ErrAtoi, http.StatusBadRequest)
+			return
+		}
+		names := make([]string, len(resultColumns))
+		for i, column := range resultColumns {
+			names[i] = column.name
+		}
+		indexes, ok := selectColumns(query.Get("columns"), names)
+		if !ok {
+			http.Error(w, "incorrect columns", http.StatusBadRequest)
+			return
+		}
+		header = columnNames(indexes, names)
+		err = e.exportService.ExportContestResults(uint(contestId), clientId, clientRole, func(teams []models.TeamCore) error {
+			if err := startTable(name, header); err != nil {
+				return err
+			}
+			for _, team := range teams {
+				for _, member := range team.Members {
+					row := make([]string, len(indexes))
+					for i, index := range indexes {
+						row[i] = resultColumns[index].value(team, member.User)
+					}
+					if err := table.Write(row); err != nil {
+						return err
+					}
+				}
+			}
+			return table.Flush()
+		})
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		e.loggers.Err.Printf("%s", err.Error())
+		if table != nil {
+			// the status is already sent, the broken file is noticed by the client
+			return
+		}
+		var responseError utils.ResponseError
+		if errors.As(err, &responseError) {
+			http.Error(w, responseError.Message, int(responseError.Code))
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := startTable(name, header); err != nil {
+		e.loggers.Err.Printf("%s", err.Error())
+		return
+	}
+	if err := table.Close(); err != nil {
+		e.loggers.Err.Printf("%s", err.Error())
+	}
+}
+
+func newTableWriter(w http.ResponseWriter, format, name string) (tableWriter, error) {
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+"."+format+`"`)
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		table, err := xlsx.NewWriter(w, name)
+		if err != nil {
+			return nil, err
+		}
+		return safeTableWriter{table}, nil
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	// the byte order mark lets spreadsheets detect utf-8
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return nil, err
+	}
+	return safeTableWriter{csvTableWriter{csv.NewWriter(w)}}, nil
+}
+
+// selectColumns returns indexes of the requested columns, all columns are exported if none are requested
+func selectColumns(requested string, names []string) ([]int, bool) {
+	var indexes []int
+	if requested == "" {
+		for i := range names {
+			indexes = append(indexes, i)
+		}
+		return indexes, true
+	}
+	for _, column := range strings.Split(requested, ",") {
+		found := false
+		for i, name := range names {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				indexes = append(indexes, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return indexes, true
+}
+
+func columnNames(indexes []int, names []string) []string {
+	header := make([]string, len(indexes))
+	for i, index := range indexes {
+		header[i] = names[index]
+	}
+	return header
+}
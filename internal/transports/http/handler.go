@@ -6,13 +6,23 @@ import (
 )
 
 type Handlers struct {
-	ProjectHandler ProjectHandler
-	AvatarHandler  AvatarHandler
+	ProjectHandler     ProjectHandler
+	AvatarHandler      AvatarHandler
+	JwksHandler        JwksHandler
+	LtiHandler         LtiHandler
+	UserImportHandler  UserImportHandler
+	ExportHandler      ExportHandler
+	CertificateHandler CertificateHandler
 }
 
 func SetupHandlers(
 	loggers logger.Loggers,
 	projectService services.ProjectService,
+	keyService services.KeyService,
+	ltiService services.LtiService,
+	userImportService services.UserImportService,
+	exportService services.ExportService,
+	certificateService services.CertificateService,
 ) Handlers {
 	return Handlers{
 		ProjectHandler: &ProjectHandlerImpl{
@@ -22,5 +32,26 @@ func SetupHandlers(
 		AvatarHandler: &AvatarHandlerImpl{
 			loggers: loggers,
 		},
+		JwksHandler: &JwksHandlerImpl{
+			loggers:    loggers,
+			keyService: keyService,
+		},
+		LtiHandler: &LtiHandlerImpl{
+			loggers:    loggers,
+			ltiService: ltiService,
+			keyService: keyService,
+		},
+		UserImportHandler: &UserImportHandlerImpl{
+			loggers:           loggers,
+			userImportService: userImportService,
+		},
+		ExportHandler: &ExportHandlerImpl{
+			loggers:       loggers,
+			exportService: exportService,
+		},
+		CertificateHandler: &CertificateHandlerImpl{
+			loggers:            loggers,
+			certificateService: certificateService,
+		},
 	}
 }
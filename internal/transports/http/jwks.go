@@ -0,0 +1,33 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/skinnykaen/rpa_clone/internal/services"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"net/http"
+)
+
+type JwksHandler interface {
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type JwksHandlerImpl struct {
+	loggers    logger.Loggers
+	keyService services.KeyService
+}
+
+func (j JwksHandlerImpl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+		return
+	}
+	jData, err := json.Marshal(j.keyService.JWKS(services.KeyPurposeAccess))
+	if err != nil {
+		j.loggers.Err.Printf("%s", err.Error())
+		http.Error(w, "failed to marshal http response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(jData)
+}
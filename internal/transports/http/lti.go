@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/services"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/spf13/viper"
+	"net/http"
+)
+
+// LtiHandler serves endpoints called by LTI platforms: the login initiation,
+// the launch and the public key set of the tool
+type LtiHandler interface {
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type LtiHandlerImpl struct {
+	loggers    logger.Loggers
+	ltiService services.LtiService
+	keyService services.KeyService
+}
+
+func (l LtiHandlerImpl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/lti/login":
+		// platforms may initiate the login with both GET and POST
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		redirectUrl, browserBinding, err := l.ltiService.Login(
+			r.Form.Get("iss"),
+			r.Form.Get("client_id"),
+			r.Form.Get("login_hint"),
+			r.Form.Get("lti_message_hint"),
+		)
+		if err != nil {
+			l.writeError(w, err)
+			return
+		}
+		http.SetCookie(w, ltiStateCookie(browserBinding))
+		http.Redirect(w, r, redirectUrl, http.StatusFound)
+	case "/lti/launch":
+		if r.Method != http.MethodPost {
+			http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var browserBinding string
+		if cookie, err := r.Cookie(consts.LtiStateCookie); err == nil {
+			browserBinding = cookie.Value
+		}
+		redirectUrl, err := l.ltiService.Launch(r.Context(), r.PostForm.Get("id_token"), r.PostForm.Get("state"),
+			browserBinding)
+		if err != nil {
+			l.writeError(w, err)
+			return
+		}
+		http.Redirect(w, r, redirectUrl, http.StatusFound)
+	case "/lti/jwks":
+		if r.Method != http.MethodGet {
+			http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+			return
+		}
+		jData, err := json.Marshal(l.keyService.JWKS(services.KeyPurposeLti))
+		if err != nil {
+			l.loggers.Err.Printf("%s", err.Error())
+			http.Error(w, "failed to marshal http response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(jData)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ltiStateCookie binds the login to the browser. The platform posts the launch
+// from its own site, so the cookie has to be sent with cross-site requests
+// which browsers allow only for secure cookies.
+func ltiStateCookie(browserBinding string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     consts.LtiStateCookie,
+		Value:    browserBinding,
+		Path:     "/lti/launch",
+		MaxAge:   int(viper.GetDuration("oidc.state_ttl").Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if viper.GetBool("oidc.cookie_secure") {
+		cookie.Secure = true
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	return cookie
+}
+
+func (l LtiHandlerImpl) writeError(w http.ResponseWriter, err error) {
+	l.loggers.Err.Printf("%s", err.Error())
+	status := http.StatusInternalServerError
+	if responseError, ok := err.(utils.ResponseError); ok {
+		status = int(responseError.Code)
+	}
+	http.Error(w, err.Error(), status)
+}
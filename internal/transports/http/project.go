@@ -60,7 +60,7 @@ func (p ProjectHandlerImpl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			project := models.ProjectCore{}
 			project.ID = uint(atoi)
 			project.Json = string(dataBytes)
-			_, err = p.projectService.UpdateProject(project)
+			_, err = p.projectService.UpdateProject(project, r.Context().Value(consts.KeyId).(uint))
 			if err != nil {
 				p.loggers.Err.Printf("%s", err.Error())
 				http.Error(w, err.Error(), http.StatusInternalServerError)
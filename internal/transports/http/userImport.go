@@ -0,0 +1,159 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
+	"github.com/skinnykaen/rpa_clone/internal/models"
+	"github.com/skinnykaen/rpa_clone/internal/services"
+	"github.com/skinnykaen/rpa_clone/pkg/logger"
+	"github.com/skinnykaen/rpa_clone/pkg/pdf"
+	"github.com/skinnykaen/rpa_clone/pkg/utils"
+	"github.com/skinnykaen/rpa_clone/pkg/xlsx"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type UserImportHandler interface {
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type UserImportHandlerImpl struct {
+	loggers           logger.Loggers
+	userImportService services.UserImportService
+}
+
+var credentialsHeader = []string{"No", "Name", "Class", "Unit", "Login", "Password"}
+
+// ServeHTTP creates participants from the uploaded csv or xlsx file and responds with the credentials sheet
+// in csv or pdf (?format=pdf). Incorrect rows are reported as json {"errors": [{"row", "message"}]}.
+func (u UserImportHandlerImpl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/users/import" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "not allowed method", http.StatusMethodNotAllowed)
+			return
+		}
+		clientRole := r.Context().Value(consts.KeyRole).(models.Role)
+		if clientRole != models.RoleSuperAdmin && clientRole != models.RoleUnitAdmin {
+			http.Error(w, consts.ErrAccessDenied, http.StatusForbidden)
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "pdf" {
+			http.Error(w, "incorrect format", http.StatusBadRequest)
+			return
+		}
+		maxFileSize := viper.GetInt64("user_import.max_file_size")
+		r.Body = http.MaxBytesReader(w, r.Body, maxFileSize)
+		if err := r.ParseMultipartForm(maxFileSize); err != nil {
+			u.loggers.Err.Printf("%s", err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			u.loggers.Err.Printf("%s", err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			u.loggers.Err.Printf("%s", err.Error())
+			http.Error(w, "failed to read file", http.StatusInternalServerError)
+			return
+		}
+		rows, err := readImportRows(header.Filename, data)
+		if err != nil {
+			u.loggers.Err.Printf("%s", err.Error())
+			http.Error(w, consts.ErrIncorrectImportFile, http.StatusBadRequest)
+			return
+		}
+		users, rowErrors, err := u.userImportService.ImportUsers(rows, r.Context().Value(consts.KeyId).(uint), clientRole)
+		if err != nil {
+			u.loggers.Err.Printf("%s", err.Error())
+			var responseError utils.ResponseError
+			if errors.As(err, &responseError) {
+				http.Error(w, responseError.Message, int(responseError.Code))
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(rowErrors) > 0 {
+			jData, err := json.Marshal(map[string]interface{}{
+				"errors": rowErrors,
+			})
+			if err != nil {
+				u.loggers.Err.Printf("%s", err.Error())
+				http.Error(w, "failed to marshal http response", http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(jData)
+			return
+		}
+		if err := writeCredentials(w, format, users); err != nil {
+			u.loggers.Err.Printf("%s", err.Error())
+		}
+	}
+}
+
+// readImportRows reads xlsx files by the extension and everything else as csv separated by commas or semicolons
+func readImportRows(filename string, data []byte) ([][]string, error) {
+	if strings.EqualFold(path.Ext(filename), ".xlsx") {
+		return xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	// spreadsheets with russian locale save csv separated by semicolons
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	return reader.ReadAll()
+}
+
+func writeCredentials(w http.ResponseWriter, format string, users []models.ImportedUserCore) error {
+	rows := make([][]string, 0, len(users))
+	for i, user := range users {
+		name := strings.TrimSpace(user.User.Lastname + " " + user.User.Firstname + " " + user.User.Middlename)
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1), name, user.Class, user.Unit.Name, user.User.Nickname, user.Password,
+		})
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if format == "pdf" {
+		// standard pdf fonts have no cyrillic letters
+		for _, row := range rows {
+			for i := range row {
+				row[i] = utils.Transliterate(row[i])
+			}
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `attachment; filename="credentials.pdf"`)
+		return pdf.WriteTable(w, "Participants credentials", credentialsHeader, rows)
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="credentials.csv"`)
+	// the byte order mark lets spreadsheets detect utf-8
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(credentialsHeader); err != nil {
+		return err
+	}
+	return writer.WriteAll(rows)
+}
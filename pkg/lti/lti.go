@@ -0,0 +1,383 @@
+// Package lti is a minimal LTI 1.3 tool: OIDC login initiation, launch id token validation
+// against the platform JWKS, deep linking responses and Assignment and Grade Services scores.
+package lti
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/skinnykaen/rpa_clone/pkg/oidc"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	Version = "1.3.0"
+
+	MessageTypeResourceLink         = "LtiResourceLinkRequest"
+	MessageTypeDeepLinking          = "LtiDeepLinkingRequest"
+	MessageTypeDeepLinkingResponse  = "LtiDeepLinkingResponse"
+	ContentItemTypeLtiResourceLink  = "ltiResourceLink"
+	ScopeScore                      = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+	RoleInstructor                  = "http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"
+	RoleLearner                     = "http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"
+	RoleAdministrator               = "http://purl.imsglobal.org/vocab/lis/v2/institution/person#Administrator"
+	ActivityProgressCompleted       = "Completed"
+	GradingProgressFullyGraded      = "FullyGraded"
+	clientAssertionType             = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	scoreContentType                = "application/vnd.ims.lis.v1.score+json"
+	clientAssertionTtl              = 5 * time.Minute
+	deepLinkingResponseTtl          = 5 * time.Minute
+	accessTokenRefreshBeforeExpires = time.Minute
+)
+
+type Config struct {
+	Issuer        string
+	ClientID      string
+	DeploymentIDs []string
+	AuthLoginURL  string
+	AuthTokenURL  string
+	KeysetURL     string
+}
+
+// Signer signs tool messages with the private key published in the tool JWKS
+type Signer func(claims jwt.Claims) (string, error)
+
+type ResourceLinkClaim struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type EndpointClaim struct {
+	Scope     []string `json:"scope"`
+	LineItems string   `json:"lineitems"`
+	LineItem  string   `json:"lineitem"`
+}
+
+type DeepLinkingSettingsClaim struct {
+	DeepLinkReturnURL string   `json:"deep_link_return_url"`
+	AcceptTypes       []string `json:"accept_types"`
+	AcceptMultiple    bool     `json:"accept_multiple"`
+	Data              string   `json:"data"`
+}
+
+type LaunchClaims struct {
+	jwt.StandardClaims
+	AuthorizedParty     string                    `json:"azp"`
+	Nonce               string                    `json:"nonce"`
+	Email               string                    `json:"email"`
+	GivenName           string                    `json:"given_name"`
+	FamilyName          string                    `json:"family_name"`
+	MiddleName          string                    `json:"middle_name"`
+	MessageType         string                    `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version             string                    `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID        string                    `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	TargetLinkURI       string                    `json:"https://purl.imsglobal.org/spec/lti/claim/target_link_uri"`
+	Roles               []string                  `json:"https://purl.imsglobal.org/spec/lti/claim/roles"`
+	ResourceLink        ResourceLinkClaim         `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link"`
+	Custom              map[string]string         `json:"https://purl.imsglobal.org/spec/lti/claim/custom"`
+	Endpoint            *EndpointClaim            `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"`
+	DeepLinkingSettings *DeepLinkingSettingsClaim `json:"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings"`
+}
+
+// HasRole reports whether the user has any of the roles in the context of the launch
+func (c *LaunchClaims) HasRole(roles ...string) bool {
+	for _, claimRole := range c.Roles {
+		for _, role := range roles {
+			if claimRole == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CanPostScore reports whether scores of the resource link may be sent to the platform gradebook
+func (c *LaunchClaims) CanPostScore() bool {
+	if c.Endpoint == nil || c.Endpoint.LineItem == "" {
+		return false
+	}
+	for _, scope := range c.Endpoint.Scope {
+		if scope == ScopeScore {
+			return true
+		}
+	}
+	return false
+}
+
+type LineItem struct {
+	ScoreMaximum float64 `json:"scoreMaximum"`
+	Label        string  `json:"label,omitempty"`
+	ResourceID   string  `json:"resourceId,omitempty"`
+}
+
+type ContentItem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Custom   map[string]string `json:"custom,omitempty"`
+	LineItem *LineItem         `json:"lineItem,omitempty"`
+}
+
+type DeepLinkingResponseClaims struct {
+	jwt.StandardClaims
+	Nonce        string        `json:"nonce"`
+	MessageType  string        `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version      string        `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID string        `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	Data         string        `json:"https://purl.imsglobal.org/spec/lti-dl/claim/data,omitempty"`
+	ContentItems []ContentItem `json:"https://purl.imsglobal.org/spec/lti-dl/claim/content_items"`
+}
+
+type Score struct {
+	UserID           string  `json:"userId"`
+	ScoreGiven       float64 `json:"scoreGiven"`
+	ScoreMaximum     float64 `json:"scoreMaximum"`
+	Comment          string  `json:"comment,omitempty"`
+	Timestamp        string  `json:"timestamp"`
+	ActivityProgress string  `json:"activityProgress"`
+	GradingProgress  string  `json:"gradingProgress"`
+}
+
+type Platform struct {
+	config Config
+	client *http.Client
+	keySet *oidc.KeySet
+
+	mu          sync.Mutex
+	tokens      map[string]string
+	tokensUntil map[string]time.Time
+}
+
+func NewPlatform(config Config, client *http.Client) *Platform {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Platform{
+		config:      config,
+		client:      client,
+		keySet:      oidc.NewKeySet(config.KeysetURL, client),
+		tokens:      map[string]string{},
+		tokensUntil: map[string]time.Time{},
+	}
+}
+
+func (p *Platform) Issuer() string {
+	return p.config.Issuer
+}
+
+func (p *Platform) ClientID() string {
+	return p.config.ClientID
+}
+
+// LoginURL returns the authentication request of the platform, which answers
+// with the launch id token posted to redirectURI
+func (p *Platform) LoginURL(loginHint, ltiMessageHint, redirectURI, state, nonce string) string {
+	query := url.Values{}
+	query.Set("scope", "openid")
+	query.Set("response_type", "id_token")
+	query.Set("response_mode", "form_post")
+	query.Set("prompt", "none")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("login_hint", loginHint)
+	if ltiMessageHint != "" {
+		query.Set("lti_message_hint", ltiMessageHint)
+	}
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	separator := "?"
+	if strings.Contains(p.config.AuthLoginURL, "?") {
+		separator = "&"
+	}
+	return p.config.AuthLoginURL + separator + query.Encode()
+}
+
+// VerifyLaunch validates the launch id token and returns its claims
+func (p *Platform) VerifyLaunch(ctx context.Context, idToken, nonce string) (*LaunchClaims, error) {
+	claims := &LaunchClaims{}
+	if _, err := jwt.ParseWithClaims(idToken, claims, p.keySet.Keyfunc(ctx),
+		jwt.WithAudience(p.config.ClientID), jwt.WithIssuer(p.config.Issuer)); err != nil {
+		return nil, err
+	}
+	// jwt-go skips aud validation if the claim is absent, but it is required for id tokens
+	if claims.ExpiresAt == nil || len(claims.Audience) == 0 || claims.Issuer != p.config.Issuer {
+		return nil, errors.New("lti id token has no exp, aud or iss claim")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, errors.New("lti id token azp mismatch")
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("lti id token nonce mismatch")
+	}
+	if claims.Version != Version {
+		return nil, fmt.Errorf("unsupported lti version %q", claims.Version)
+	}
+	if !p.isDeployment(claims.DeploymentID) {
+		return nil, fmt.Errorf("unknown lti deployment %q", claims.DeploymentID)
+	}
+	switch claims.MessageType {
+	case MessageTypeResourceLink:
+		if claims.ResourceLink.ID == "" {
+			return nil, errors.New("lti resource link launch has no resource link id")
+		}
+	case MessageTypeDeepLinking:
+		if claims.DeepLinkingSettings == nil || claims.DeepLinkingSettings.DeepLinkReturnURL == "" {
+			return nil, errors.New("lti deep linking launch has no return url")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported lti message type %q", claims.MessageType)
+	}
+	return claims, nil
+}
+
+// NewDeepLinkingResponse returns claims of the message, which the tool signs and posts
+// to the deep link return url of the platform
+func (p *Platform) NewDeepLinkingResponse(deploymentID, data string, items []ContentItem) (DeepLinkingResponseClaims, error) {
+	nonce, err := randomString()
+	if err != nil {
+		return DeepLinkingResponseClaims{}, err
+	}
+	now := time.Now()
+	return DeepLinkingResponseClaims{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    p.config.ClientID,
+			Audience:  jwt.ClaimStrings{p.config.Issuer},
+			IssuedAt:  jwt.At(now),
+			ExpiresAt: jwt.At(now.Add(deepLinkingResponseTtl)),
+		},
+		Nonce:        nonce,
+		MessageType:  MessageTypeDeepLinkingResponse,
+		Version:      Version,
+		DeploymentID: deploymentID,
+		Data:         data,
+		ContentItems: items,
+	}, nil
+}
+
+// PostScore sends the score of the platform user to the line item of the gradebook
+func (p *Platform) PostScore(ctx context.Context, sign Signer, lineItem string, score Score) error {
+	token, err := p.accessToken(ctx, sign, ScopeScore)
+	if err != nil {
+		return err
+	}
+	scoresUrl, err := url.Parse(lineItem)
+	if err != nil {
+		return err
+	}
+	// the line item url may have a query, the scores endpoint is the path suffix
+	scoresUrl.Path = strings.TrimSuffix(scoresUrl.Path, "/") + "/scores"
+	if score.Timestamp == "" {
+		score.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	body, err := json.Marshal(score)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, scoresUrl.String(), strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", scoreContentType)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("lti score request: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// accessToken returns the platform token for the scope, the tool authenticates
+// with the client assertion signed by its private key
+func (p *Platform) accessToken(ctx context.Context, sign Signer, scope string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if token, ok := p.tokens[scope]; ok && time.Now().Add(accessTokenRefreshBeforeExpires).Before(p.tokensUntil[scope]) {
+		return token, nil
+	}
+	jti, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	assertion, err := sign(jwt.StandardClaims{
+		Issuer:    p.config.ClientID,
+		Subject:   p.config.ClientID,
+		Audience:  jwt.ClaimStrings{p.config.AuthTokenURL},
+		IssuedAt:  jwt.At(now),
+		ExpiresAt: jwt.At(now.Add(clientAssertionTtl)),
+		ID:        jti,
+	})
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_assertion_type", clientAssertionType)
+	form.Set("client_assertion", assertion)
+	form.Set("scope", scope)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.AuthTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("lti token request: unexpected status %d", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("lti token response has no access_token")
+	}
+	p.tokens[scope] = token.AccessToken
+	p.tokensUntil[scope] = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return token.AccessToken, nil
+}
+
+// isDeployment checks the deployment id, any deployment is accepted if none are configured
+func (p *Platform) isDeployment(deploymentID string) bool {
+	if len(p.config.DeploymentIDs) == 0 {
+		return deploymentID != ""
+	}
+	for _, id := range p.config.DeploymentIDs {
+		if id == deploymentID {
+			return true
+		}
+	}
+	return false
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,307 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/dgrijalva/jwt-go/v4"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID     = "robbo"
+	testClientSecret = "secret"
+	testRedirectURI  = "http://localhost/oidc/callback"
+	testKid          = "key-1"
+)
+
+// mockProvider is a local OpenID Connect provider: it publishes the discovery document and JWKS
+// and issues id tokens for codes created by authorize
+type mockProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	// issuer is announced in the discovery document, it equals the server url unless a test changes it
+	issuer string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+	// jwksRequests counts requests of the key set
+	jwksRequests int
+}
+
+type mockAuthorization struct {
+	codeChallenge string
+	nonce         string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{key: key, codes: map[string]mockAuthorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/jwks", m.jwks)
+	mux.HandleFunc("/token", m.token)
+	m.server = httptest.NewServer(mux)
+	m.issuer = m.server.URL
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockProvider) provider() *Provider {
+	return NewProvider(Config{
+		Issuer:       m.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURI:  testRedirectURI,
+		Scopes:       []string{"openid", "email"},
+	}, m.server.Client())
+}
+
+// authorize stands for the user signing in at the provider, it returns the authorization code
+func (m *mockProvider) authorize(authUrl string) (string, error) {
+	parsed, err := url.Parse(authUrl)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	code := "code-" + query.Get("state")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes[code] = mockAuthorization{
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+	}
+	return code, nil
+}
+
+func (m *mockProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]string{
+		"issuer":                 m.issuer,
+		"authorization_endpoint": m.server.URL + "/authorize",
+		"token_endpoint":         m.server.URL + "/token",
+		"jwks_uri":               m.server.URL + "/jwks",
+	})
+}
+
+func (m *mockProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.jwksRequests++
+	m.mu.Unlock()
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKid,
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+// token checks the client credentials and the PKCE verifier like a real provider does
+func (m *mockProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok || clientId != testClientID || clientSecret != testClientSecret {
+		writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	m.mu.Lock()
+	authorization, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("redirect_uri") != testRedirectURI {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if GenerateCodeChallenge(r.PostForm.Get("code_verifier")) != authorization.codeChallenge {
+		writeJson(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "code verifier does not match",
+		})
+		return
+	}
+	idToken, err := m.sign(testKid, authorization.nonce)
+	if err != nil {
+		writeJson(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJson(w, http.StatusOK, map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+func (m *mockProvider) sign(kid, nonce string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &Claims{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    m.issuer,
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			ExpiresAt: jwt.At(time.Now().Add(time.Minute)),
+			IssuedAt:  jwt.At(time.Now()),
+		},
+		Nonce:         nonce,
+		Email:         "user@example.com",
+		EmailVerified: true,
+	})
+	token.Header["kid"] = kid
+	return token.SignedString(m.key)
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	m := newMockProvider(t)
+	authUrl, err := m.provider().AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authUrl, m.server.URL+"/authorize?") {
+		t.Fatalf("auth url %q does not use the discovered authorization endpoint", authUrl)
+	}
+	parsed, err := url.Parse(authUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	expected := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURI,
+		"scope":                 "openid email",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        GenerateCodeChallenge("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for name, value := range expected {
+		if query.Get(name) != value {
+			t.Errorf("%s = %q, want %q", name, query.Get(name), value)
+		}
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	m := newMockProvider(t)
+	m.issuer = "https://other.example"
+	if _, err := m.provider().AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err == nil {
+		t.Fatal("discovery with another issuer is accepted")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	m := newMockProvider(t)
+	p := m.provider()
+	ctx := context.Background()
+	authUrl, err := p.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := m.authorize(authUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := p.Exchange(ctx, code, "verifier", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "user@example.com" || !claims.EmailVerified {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+	// the code is redeemed only once
+	if _, err := p.Exchange(ctx, code, "verifier", "nonce"); err == nil {
+		t.Fatal("the code is redeemed twice")
+	}
+}
+
+func TestExchangePkceMismatch(t *testing.T) {
+	m := newMockProvider(t)
+	p := m.provider()
+	ctx := context.Background()
+	authUrl, err := p.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := m.authorize(authUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Exchange(ctx, code, "another verifier", "nonce")
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("exchange with a wrong code verifier: %v", err)
+	}
+}
+
+func TestExchangeNonceMismatch(t *testing.T) {
+	m := newMockProvider(t)
+	p := m.provider()
+	ctx := context.Background()
+	authUrl, err := p.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := m.authorize(authUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Exchange(ctx, code, "verifier", "another nonce")
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("exchange with a wrong nonce: %v", err)
+	}
+}
+
+func TestVerifyIdTokenUnknownKey(t *testing.T) {
+	m := newMockProvider(t)
+	p := m.provider()
+	ctx := context.Background()
+	idToken, err := m.sign("rotated-key", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.VerifyIdToken(ctx, idToken, "nonce"); err == nil {
+		t.Fatal("id token signed with an unknown key is accepted")
+	}
+	// the key set is not refetched on every token with an unknown kid
+	if _, err := p.VerifyIdToken(ctx, idToken, "nonce"); err == nil {
+		t.Fatal("id token signed with an unknown key is accepted")
+	}
+	if m.jwksRequests != 1 {
+		t.Fatalf("key set is requested %d times, want 1", m.jwksRequests)
+	}
+}
+
+func TestVerifyIdTokenWrongSignature(t *testing.T) {
+	m := newMockProvider(t)
+	p := m.provider()
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.key, other = other, m.key
+	idToken, err := m.sign(testKid, "nonce")
+	m.key = other
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.VerifyIdToken(context.Background(), idToken, "nonce"); err == nil {
+		t.Fatal("id token with a wrong signature is accepted")
+	}
+}
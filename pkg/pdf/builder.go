@@ -0,0 +1,66 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// builder collects numbered objects of the document and writes them with the cross-reference table
+type builder struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func newBuilder() *builder {
+	b := &builder{}
+	b.buf.WriteString("%PDF-1.4\n")
+	return b
+}
+
+// reserve returns the number of an object written later, so objects may refer to each other
+func (b *builder) reserve() int {
+	b.offsets = append(b.offsets, -1)
+	return len(b.offsets)
+}
+
+func (b *builder) set(ref int, body string) {
+	b.offsets[ref-1] = b.buf.Len()
+	fmt.Fprintf(&b.buf, "%d 0 obj\n%s\nendobj\n", ref, body)
+}
+
+func (b *builder) add(body string) int {
+	ref := b.reserve()
+	b.set(ref, body)
+	return ref
+}
+
+// addStream adds the stream with the entries of its dictionary, data is compressed unless the filter is given
+func (b *builder) addStream(entries string, data []byte) int {
+	if !bytes.Contains([]byte(entries), []byte("/Filter")) {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		writer.Write(data)
+		writer.Close()
+		data = compressed.Bytes()
+		entries += " /Filter /FlateDecode"
+	}
+	ref := b.reserve()
+	b.offsets[ref-1] = b.buf.Len()
+	fmt.Fprintf(&b.buf, "%d 0 obj\n<< /Length %d%s >>\nstream\n", ref, len(data), entries)
+	b.buf.Write(data)
+	b.buf.WriteString("\nendstream\nendobj\n")
+	return ref
+}
+
+func (b *builder) write(w io.Writer, rootRef int) error {
+	xref := b.buf.Len()
+	fmt.Fprintf(&b.buf, "xref\n0 %d\n0000000000 65535 f \n", len(b.offsets)+1)
+	for _, offset := range b.offsets {
+		fmt.Fprintf(&b.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(b.offsets)+1, rootRef, xref)
+	_, err := w.Write(b.buf.Bytes())
+	return err
+}
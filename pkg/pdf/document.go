@@ -0,0 +1,180 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"sort"
+	"strings"
+)
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Document is a multi page document with images, text in the embedded font and filled rectangles.
+// Coordinates are in points from the bottom left corner of the page.
+type Document struct {
+	font   *Font
+	glyphs map[uint16]struct{}
+	images []*Image
+	pages  []*Page
+}
+
+// Image is added to the document once and may be drawn on any number of pages
+type Image struct {
+	name    string
+	entries string
+	data    []byte
+}
+
+type Page struct {
+	doc     *Document
+	width   float64
+	height  float64
+	content bytes.Buffer
+	images  map[string]struct{}
+}
+
+func NewDocument(font *Font) *Document {
+	return &Document{
+		font:   font,
+		glyphs: make(map[uint16]struct{}),
+	}
+}
+
+// AddImage adds a jpeg or png image. Jpeg images are embedded as they are, others are converted to rgb.
+func (d *Document) AddImage(data []byte) (*Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	img := &Image{name: fmt.Sprintf("Im%d", len(d.images)+1)}
+	switch {
+	case format == "jpeg" && config.ColorModel == color.YCbCrModel:
+		img.entries = fmt.Sprintf(" /Type /XObject /Subtype /Image /Width %d /Height %d "+
+			"/ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode", config.Width, config.Height)
+		img.data = data
+	case format == "jpeg" && config.ColorModel == color.GrayModel:
+		img.entries = fmt.Sprintf(" /Type /XObject /Subtype /Image /Width %d /Height %d "+
+			"/ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode", config.Width, config.Height)
+		img.data = data
+	default:
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		bounds := decoded.Bounds()
+		rgb := make([]byte, 0, 3*bounds.Dx()*bounds.Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				// transparent pixels are drawn over white paper
+				r, g, b, a := decoded.At(x, y).RGBA()
+				white := 0xFFFF - a
+				rgb = append(rgb, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+			}
+		}
+		img.entries = fmt.Sprintf(" /Type /XObject /Subtype /Image /Width %d /Height %d "+
+			"/ColorSpace /DeviceRGB /BitsPerComponent 8", bounds.Dx(), bounds.Dy())
+		img.data = rgb
+	}
+	d.images = append(d.images, img)
+	return img, nil
+}
+
+func (d *Document) AddPage(width, height float64) *Page {
+	page := &Page{
+		doc:    d,
+		width:  width,
+		height: height,
+		images: make(map[string]struct{}),
+	}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// DrawImage draws the image stretched to the rectangle
+func (p *Page) DrawImage(img *Image, x, y, width, height float64) {
+	p.images[img.name] = struct{}{}
+	fmt.Fprintf(&p.content, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q\n", width, height, x, y, img.name)
+}
+
+// Rect draws the filled black rectangle
+func (p *Page) Rect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f %.2f %.2f re f\n", x, y, width, height)
+}
+
+// Text writes the line of text, x is the left, the center or the right of the line depending on the alignment.
+// Characters missing in the font are drawn as the missing glyph.
+func (p *Page) Text(text string, x, y, size float64, align Align) {
+	d := p.doc
+	switch align {
+	case AlignCenter:
+		x -= d.font.TextWidth(text, size) / 2
+	case AlignRight:
+		x -= d.font.TextWidth(text, size)
+	}
+	var hex strings.Builder
+	for _, r := range text {
+		glyph := d.font.glyphs[r]
+		d.glyphs[glyph] = struct{}{}
+		fmt.Fprintf(&hex, "%04X", glyph)
+	}
+	fmt.Fprintf(&p.content, "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, y, hex.String())
+}
+
+// Write writes the document with the font subset widths, the whole font file is embedded
+func (d *Document) Write(w io.Writer) error {
+	b := newBuilder()
+	pagesRef := b.reserve()
+	fontRef := d.writeFont(b)
+	imageRefs := make(map[string]int, len(d.images))
+	for _, img := range d.images {
+		imageRefs[img.name] = b.addStream(img.entries, img.data)
+	}
+	kids := make([]string, len(d.pages))
+	for i, page := range d.pages {
+		var xObjects strings.Builder
+		for name := range page.images {
+			fmt.Fprintf(&xObjects, " /%s %d 0 R", name, imageRefs[name])
+		}
+		contentRef := b.addStream("", page.content.Bytes())
+		kids[i] = fmt.Sprintf("%d 0 R", b.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 %d 0 R >> /XObject <<%s >> >> /Contents %d 0 R >>",
+			pagesRef, page.width, page.height, fontRef, xObjects.String(), contentRef)))
+	}
+	b.set(pagesRef, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	return b.write(w, b.add(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesRef)))
+}
+
+// writeFont writes the font as a composite font with glyph ids used as character codes
+func (d *Document) writeFont(b *builder) int {
+	f := d.font
+	fileRef := b.addStream(fmt.Sprintf(" /Length1 %d", len(f.data)), f.data)
+	descriptorRef := b.add(fmt.Sprintf("<< /Type /FontDescriptor /FontName /EmbeddedFont /Flags 32 "+
+		"/FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent), fileRef))
+	glyphs := make([]int, 0, len(d.glyphs))
+	for glyph := range d.glyphs {
+		glyphs = append(glyphs, int(glyph))
+	}
+	sort.Ints(glyphs)
+	var widths strings.Builder
+	for _, glyph := range glyphs {
+		fmt.Fprintf(&widths, " %d [%d]", glyph, f.scale(f.advance(uint16(glyph))))
+	}
+	cidFontRef := b.add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /EmbeddedFont "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %d 0 R /W [%s ] /CIDToGIDMap /Identity >>", descriptorRef, widths.String()))
+	return b.add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /EmbeddedFont /Encoding /Identity-H "+
+		"/DescendantFonts [%d 0 R] >>", cidFontRef))
+}
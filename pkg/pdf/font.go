@@ -0,0 +1,154 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrIncorrectFont = errors.New("the font must be a truetype font with a unicode cmap")
+
+// Font is a TrueType font embedded into documents, so any text supported by the font can be written
+type Font struct {
+	data       []byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	advances   []int
+	glyphs     map[rune]uint16
+}
+
+// ParseFont reads metrics and the unicode to glyph mapping of the TrueType font
+func ParseFont(data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, ErrIncorrectFont
+	}
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, ErrIncorrectFont
+		}
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, ErrIncorrectFont
+		}
+		tables[string(data[record:record+4])] = data[offset : offset+length]
+	}
+	head, hhea, hmtx, cmap := tables["head"], tables["hhea"], tables["hmtx"], tables["cmap"]
+	if len(head) < 54 || len(hhea) < 36 || cmap == nil {
+		return nil, ErrIncorrectFont
+	}
+	f := &Font{
+		data:       data,
+		unitsPerEm: int(binary.BigEndian.Uint16(head[18:])),
+		ascent:     int(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent:    int(int16(binary.BigEndian.Uint16(hhea[6:]))),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, ErrIncorrectFont
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+	numberOfHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	if len(hmtx) < 4*numberOfHMetrics {
+		return nil, ErrIncorrectFont
+	}
+	f.advances = make([]int, numberOfHMetrics)
+	for i := range f.advances {
+		f.advances[i] = int(binary.BigEndian.Uint16(hmtx[4*i:]))
+	}
+	glyphs, err := parseCmap(cmap)
+	if err != nil {
+		return nil, err
+	}
+	f.glyphs = glyphs
+	return f, nil
+}
+
+// parseCmap reads the format 4 subtable of the windows unicode or the unicode platform
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrIncorrectFont
+	}
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := 4 + 8*i
+		if record+8 > len(cmap) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(cmap[record:])
+		encoding := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if !(platform == 3 && encoding == 1) && platform != 0 {
+			continue
+		}
+		if offset+14 > len(cmap) || binary.BigEndian.Uint16(cmap[offset:]) != 4 {
+			continue
+		}
+		return parseCmapFormat4(cmap[offset:])
+	}
+	return nil, ErrIncorrectFont
+}
+
+func parseCmapFormat4(subtable []byte) (map[rune]uint16, error) {
+	segCount := int(binary.BigEndian.Uint16(subtable[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segCount + 2
+	idDeltas := startCodes + 2*segCount
+	idRangeOffsets := idDeltas + 2*segCount
+	if idRangeOffsets+2*segCount > len(subtable) {
+		return nil, ErrIncorrectFont
+	}
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < segCount; i++ {
+		end := int(binary.BigEndian.Uint16(subtable[endCodes+2*i:]))
+		start := int(binary.BigEndian.Uint16(subtable[startCodes+2*i:]))
+		delta := binary.BigEndian.Uint16(subtable[idDeltas+2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(subtable[idRangeOffsets+2*i:]))
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var glyph uint16
+			if rangeOffset == 0 {
+				glyph = uint16(c) + delta
+			} else {
+				// the offset is counted from the idRangeOffset entry itself
+				index := idRangeOffsets + 2*i + rangeOffset + 2*(c-start)
+				if index+2 > len(subtable) {
+					return nil, ErrIncorrectFont
+				}
+				if glyph = binary.BigEndian.Uint16(subtable[index:]); glyph != 0 {
+					glyph += delta
+				}
+			}
+			if glyph != 0 {
+				glyphs[rune(c)] = glyph
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+// advance returns the width of the glyph in font units
+func (f *Font) advance(glyph uint16) int {
+	if int(glyph) < len(f.advances) {
+		return f.advances[glyph]
+	}
+	return f.advances[len(f.advances)-1]
+}
+
+// TextWidth returns the width of the text in points
+func (f *Font) TextWidth(text string, size float64) float64 {
+	width := 0
+	for _, r := range text {
+		width += f.advance(f.glyphs[r])
+	}
+	return float64(width) * size / float64(f.unitsPerEm)
+}
+
+// scale converts font units to the 1000 units of pdf glyph space
+func (f *Font) scale(value int) int {
+	return value * 1000 / f.unitsPerEm
+}
@@ -0,0 +1,127 @@
+// Package pdf writes plain text tables as PDF documents. Documents use the standard Courier font
+// without embedding, so only printable ASCII is supported and other characters are replaced with '?'.
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	pageWidth   = 595 // A4 in points
+	pageHeight  = 842
+	margin      = 36
+	fontSize    = 9
+	lineHeight  = 13
+	columnSpace = 2
+)
+
+// WriteTable writes the title and the table split into A4 pages, the header is repeated on every page.
+// Cells wider than the page are truncated.
+func WriteTable(w io.Writer, title string, header []string, rows [][]string) error {
+	widths := columnWidths(header, rows)
+	linesPerPage := (pageHeight-2*margin)/lineHeight - 3
+	var pages [][]string
+	for start := 0; start < len(rows) || start == 0; start += linesPerPage {
+		end := start + linesPerPage
+		if end > len(rows) {
+			end = len(rows)
+		}
+		lines := []string{title, "", formatRow(header, widths)}
+		for _, row := range rows[start:end] {
+			lines = append(lines, formatRow(row, widths))
+		}
+		pages = append(pages, lines)
+		if end == len(rows) {
+			break
+		}
+	}
+	return writeDocument(w, pages)
+}
+
+func columnWidths(header []string, rows [][]string) []int {
+	widths := make([]int, len(header))
+	for i, cell := range header {
+		widths[i] = len(cell)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	// the widest columns are narrowed until the table fits the page
+	// a Courier character is 0.6 of the font size wide
+	maxChars := (pageWidth - 2*margin) * 10 / (6 * fontSize)
+	for {
+		total := 0
+		widest := 0
+		for i, width := range widths {
+			total += width + columnSpace
+			if width > widths[widest] {
+				widest = i
+			}
+		}
+		if total-columnSpace <= maxChars || widths[widest] <= 1 {
+			return widths
+		}
+		widths[widest]--
+	}
+}
+
+func formatRow(row []string, widths []int) string {
+	var b strings.Builder
+	for i, width := range widths {
+		var cell string
+		if i < len(row) {
+			cell = toASCII(row[i])
+		}
+		if len(cell) > width {
+			cell = cell[:width]
+		}
+		b.WriteString(cell)
+		if i < len(widths)-1 {
+			b.WriteString(strings.Repeat(" ", width-len(cell)+columnSpace))
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func toASCII(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < ' ' || r > '~' {
+			r = '?'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
+
+// writeDocument writes the catalog, the page tree, the font and a page with its content stream for every page
+func writeDocument(w io.Writer, pages [][]string) error {
+	b := newBuilder()
+	pagesRef := b.reserve()
+	fontRef := b.add("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	kids := make([]string, len(pages))
+	for i, lines := range pages {
+		var content strings.Builder
+		// the ' operator moves to the next line and shows the text
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) '\n", escape(line))
+		}
+		content.WriteString("ET")
+		contentRef := b.addStream("", []byte(content.String()))
+		kids[i] = fmt.Sprintf("%d 0 R", b.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>", pagesRef, pageWidth, pageHeight, fontRef, contentRef)))
+	}
+	b.set(pagesRef, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	return b.write(w, b.add(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesRef)))
+}
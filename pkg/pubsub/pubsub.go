@@ -0,0 +1,58 @@
+// Package pubsub delivers events to subscribers of topics within one server process
+package pubsub
+
+import (
+	"sync"
+)
+
+// Broker publishes events of type T. Slow subscribers lose events instead of blocking publishers.
+type Broker[T any] struct {
+	mu          sync.Mutex
+	bufferSize  int
+	subscribers map[string]map[chan T]func(event T) bool
+}
+
+func NewBroker[T any](bufferSize int) *Broker[T] {
+	return &Broker[T]{
+		bufferSize:  bufferSize,
+		subscribers: make(map[string]map[chan T]func(event T) bool),
+	}
+}
+
+// Subscribe returns the channel of events of the topic accepted by the filter and the function to unsubscribe,
+// which closes the channel. A nil filter accepts all events.
+func (b *Broker[T]) Subscribe(topic string, filter func(event T) bool) (<-chan T, func()) {
+	ch := make(chan T, b.bufferSize)
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan T]func(event T) bool)
+	}
+	b.subscribers[topic][ch] = filter
+	b.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[topic], ch)
+			if len(b.subscribers[topic]) == 0 {
+				delete(b.subscribers, topic)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (b *Broker[T]) Publish(topic string, event T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch, filter := range b.subscribers[topic] {
+		if filter != nil && !filter(event) {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+var errInvalidPasswordHash = errors.New("invalid password hash format")
+
+// PasswordHashParams describes how new password hashes are computed
+type PasswordHashParams struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+}
+
+type argon2idHash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (p PasswordHashParams) Validate() error {
+	switch p.Algorithm {
+	case PasswordAlgorithmBcrypt:
+		if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case PasswordAlgorithmArgon2id:
+		if p.Argon2Memory == 0 || p.Argon2Iterations == 0 || p.Argon2Parallelism == 0 ||
+			p.Argon2SaltLength == 0 || p.Argon2KeyLength == 0 {
+			return errors.New("argon2id parameters must be positive")
+		}
+	default:
+		return fmt.Errorf("unsupported password hashing algorithm %q", p.Algorithm)
+	}
+	return nil
+}
+
+// HashPassword hashes the password with bcrypt or argon2id. Argon2id hashes are encoded
+// in the PHC string format: $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func HashPassword(password string, params PasswordHashParams) (string, error) {
+	if params.Algorithm == PasswordAlgorithmArgon2id {
+		salt := make([]byte, params.Argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, params.Argon2Iterations, params.Argon2Memory,
+			params.Argon2Parallelism, params.Argon2KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+			params.Argon2Memory, params.Argon2Iterations, params.Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), params.BcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// ComparePassword checks the password against a bcrypt or argon2id hash.
+// Returns bcrypt.ErrMismatchedHashAndPassword if the password is wrong.
+func ComparePassword(hashed, password string) error {
+	if !strings.HasPrefix(hashed, "$"+PasswordAlgorithmArgon2id+"$") {
+		return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
+	}
+	h, err := parseArgon2idHash(hashed)
+	if err != nil {
+		return err
+	}
+	key := argon2.IDKey([]byte(password), h.salt, h.iterations, h.memory, h.parallelism, uint32(len(h.key)))
+	if subtle.ConstantTimeCompare(key, h.key) != 1 {
+		return bcrypt.ErrMismatchedHashAndPassword
+	}
+	return nil
+}
+
+// NeedsRehash reports whether the hash was computed with another algorithm or parameters
+func NeedsRehash(hashed string, params PasswordHashParams) bool {
+	if params.Algorithm == PasswordAlgorithmArgon2id {
+		h, err := parseArgon2idHash(hashed)
+		return err != nil || h.memory != params.Argon2Memory || h.iterations != params.Argon2Iterations ||
+			h.parallelism != params.Argon2Parallelism || uint32(len(h.salt)) != params.Argon2SaltLength ||
+			uint32(len(h.key)) != params.Argon2KeyLength
+	}
+	cost, err := bcrypt.Cost([]byte(hashed))
+	return err != nil || cost != params.BcryptCost
+}
+
+func parseArgon2idHash(hashed string) (h argon2idHash, err error) {
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return argon2idHash{}, errInvalidPasswordHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2idHash{}, errInvalidPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.iterations, &h.parallelism); err != nil {
+		return argon2idHash{}, errInvalidPasswordHash
+	}
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return argon2idHash{}, errInvalidPasswordHash
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(h.key) == 0 {
+		return argon2idHash{}, errInvalidPasswordHash
+	}
+	return h, nil
+}
@@ -1,14 +1,21 @@
 package utils
 
 import (
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"github.com/jordan-wright/email"
+	"github.com/skinnykaen/rpa_clone/internal/consts"
 	"github.com/skinnykaen/rpa_clone/internal/models"
 	"github.com/spf13/viper"
-	"golang.org/x/crypto/bcrypt"
+	"net/http"
 	"net/mail"
 	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 func SendEmail(subject, to, body string) (err error) {
@@ -24,15 +31,6 @@ func SendEmail(subject, to, body string) (err error) {
 	return e.Send(viper.GetString("smtp_server_address"), auth)
 }
 
-func HashPassword(s string) string {
-	hashed, _ := bcrypt.GenerateFromPassword([]byte(s), bcrypt.DefaultCost)
-	return string(hashed)
-}
-
-func ComparePassword(hashed string, normal string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(normal))
-}
-
 func IsValidEmail(email string) bool {
 	_, err := mail.ParseAddress(email)
 	return err == nil
@@ -49,6 +47,46 @@ func GetOffsetAndLimit(page, pageSize *int) (offset, limit int) {
 	return
 }
 
+// ParseIds converts graphql ID list to database ids
+func ParseIds(ids []string) ([]uint, error) {
+	parsed := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		atoi, err := strconv.Atoi(id)
+		if err != nil || atoi < 0 {
+			return nil, ResponseError{
+				Code:    http.StatusBadRequest,
+				Message: consts.ErrAtoi,
+			}
+		}
+		parsed = append(parsed, uint(atoi))
+	}
+	return parsed, nil
+}
+
+// ParseTimestamp converts graphql Timestamp in the format of time.DateTime to the local time
+func ParseTimestamp(timestamp string) (time.Time, error) {
+	parsed, err := time.ParseInLocation(time.DateTime, timestamp, time.Local)
+	if err != nil {
+		return time.Time{}, ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectTimestamp,
+		}
+	}
+	return parsed, nil
+}
+
+// ParseDate converts graphql Date in the format of time.DateOnly to the local time
+func ParseDate(date string) (time.Time, error) {
+	parsed, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+	if err != nil {
+		return time.Time{}, ResponseError{
+			Code:    http.StatusBadRequest,
+			Message: consts.ErrIncorrectDate,
+		}
+	}
+	return parsed, nil
+}
+
 func DoesHaveRole(clientRole models.Role, roles []*models.Role) bool {
 	for _, role := range roles {
 		if role.String() == clientRole.String() {
@@ -64,6 +102,78 @@ func GetHashString(s string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+func GetSha256String(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+// GenerateRandomString returns a cryptographically random string of the given length
+// made of lowercase letters and digits
+func GenerateRandomString(length int) (string, error) {
+	const alphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = alphabet[int(buf[i])%len(alphabet)]
+	}
+	return string(buf), nil
+}
+
+// GenerateRandomPassword returns a cryptographically random password of the given length
+// with lowercase and uppercase letters, digits and special characters, similar symbols are excluded
+func GenerateRandomPassword(length int) (string, error) {
+	classes := []string{"abcdefghijkmnpqrstuvwxyz", "ABCDEFGHJKLMNPQRSTUVWXYZ", "23456789", "!#%+-=?@"}
+	if length < len(classes) {
+		length = len(classes)
+	}
+	buf := make([]byte, 2*length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	password := make([]byte, length)
+	for i := range password {
+		// every class appears at least once, the rest is taken from all classes
+		class := classes[i%len(classes)]
+		if i >= len(classes) {
+			class = strings.Join(classes, "")
+		}
+		password[i] = class[int(buf[i])%len(class)]
+	}
+	// shuffle, so the classes are not always in the same positions
+	for i := len(password) - 1; i > 0; i-- {
+		j := int(buf[length+i]) % (i + 1)
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// Transliterate replaces russian letters with latin ones, other characters are kept
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		lower := unicode.ToLower(r)
+		latin, ok := cyrillicToLatin[lower]
+		switch {
+		case !ok:
+			b.WriteRune(r)
+		case lower != r && latin != "":
+			b.WriteString(strings.ToUpper(latin[:1]) + latin[1:])
+		default:
+			b.WriteString(latin)
+		}
+	}
+	return b.String()
+}
+
 func StringPointerToString(p *string) string {
 	var s string
 	if p != nil {
@@ -72,6 +182,14 @@ func StringPointerToString(p *string) string {
 	return s
 }
 
+func IntPointerToInt(p *int) int {
+	var i int
+	if p != nil {
+		i = *p
+	}
+	return i
+}
+
 func BoolPointerToBool(p *bool) bool {
 	var b bool
 	if p != nil {
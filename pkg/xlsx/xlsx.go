@@ -0,0 +1,141 @@
+// Package xlsx reads cell values of the first worksheet of an Office Open XML workbook.
+// Styles, formulas and dates are not interpreted, the cached values are returned as they are stored.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrNoWorksheet = errors.New("the workbook has no worksheets")
+
+type sharedStrings struct {
+	Items []stringItem `xml:"si"`
+}
+
+type stringItem struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s stringItem) String() string {
+	if len(s.Runs) == 0 {
+		return s.Text
+	}
+	var b strings.Builder
+	for _, run := range s.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type worksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string     `xml:"r,attr"`
+			Type   string     `xml:"t,attr"`
+			Value  string     `xml:"v"`
+			Inline stringItem `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadRows returns rows of the first worksheet, missing cells are returned as empty strings
+func ReadRows(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var sheets []*zip.File
+	var strs sharedStrings
+	for _, file := range archive.File {
+		switch {
+		case file.Name == "xl/sharedStrings.xml":
+			if err := decode(file, &strs); err != nil {
+				return nil, err
+			}
+		case path.Dir(file.Name) == "xl/worksheets" && path.Ext(file.Name) == ".xml":
+			sheets = append(sheets, file)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, ErrNoWorksheet
+	}
+	// worksheets are named sheet1.xml, sheet2.xml, ... in the order of the workbook
+	sort.Slice(sheets, func(i, j int) bool {
+		return sheetNumber(sheets[i].Name) < sheetNumber(sheets[j].Name)
+	})
+	var sheet worksheet
+	if err := decode(sheets[0], &sheet); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, sheetRow := range sheet.Rows {
+		var row []string
+		for i, cell := range sheetRow.Cells {
+			column := columnIndex(cell.Ref)
+			if column < 0 {
+				column = i
+			}
+			for len(row) <= column {
+				row = append(row, "")
+			}
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(strs.Items) {
+					return nil, errors.New("incorrect shared string index in cell " + cell.Ref)
+				}
+				row[column] = strs.Items[index].String()
+			case "inlineStr":
+				row[column] = cell.Inline.String()
+			default:
+				row[column] = cell.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func decode(file *zip.File, v interface{}) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+func sheetNumber(name string) int {
+	number, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path.Base(name), "sheet"), ".xml"))
+	if err != nil {
+		return int(^uint(0) >> 1)
+	}
+	return number
+}
+
+// columnIndex converts the column letters of a cell reference like "AB12" to the zero based index
+func columnIndex(ref string) int {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A') + 1
+		letters++
+	}
+	if letters == 0 {
+		return -1
+	}
+	return index - 1
+}